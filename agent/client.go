@@ -248,6 +248,57 @@ func (c *Client) Ping(ctx context.Context) (res PingResponse, err error) {
 	return
 }
 
+// StatusResponse reports the state of the agent and of its tunnels.
+type StatusResponse struct {
+	PingResponse
+	LogFile string
+	Tunnels []TunnelStatus
+}
+
+// TunnelStatus reports the state of a tunnel of the agent, and whether names
+// resolve through it.
+type TunnelStatus struct {
+	Org           string
+	Network       string
+	Peer          string
+	Region        string
+	PeerIP        string
+	Endpoint      string
+	Transport     string
+	LastHandshake time.Time
+	RxBytes       int64
+	TxBytes       int64
+	ResolverOK    bool
+	ResolverError string `json:",omitempty"`
+	Error         string `json:",omitempty"`
+}
+
+func (c *Client) Status(ctx context.Context) (res StatusResponse, err error) {
+	err = c.do(ctx, func(conn net.Conn) (err error) {
+		if err = proto.Write(conn, "status"); err != nil {
+			return
+		}
+
+		var data []byte
+		if data, err = proto.Read(conn); err != nil {
+			return
+		}
+
+		switch {
+		default:
+			err = errInvalidResponse(data)
+		case isOK(data):
+			err = unmarshal(&res, data)
+		case isError(data):
+			err = extractError(data)
+		}
+
+		return
+	})
+
+	return
+}
+
 const okPrefix = "ok "
 
 func isOK(data []byte) bool {
@@ -1,12 +1,14 @@
 package server
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"log"
 	"net"
 	"os"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -32,6 +34,8 @@ type Options struct {
 	Background       bool
 	ConfigFile       string
 	ConfigWebsockets bool
+	ConfigFallback   bool
+	LogFile          string
 }
 
 func Run(ctx context.Context, opt Options) (err error) {
@@ -253,6 +257,15 @@ func (s *server) buildTunnel(ctx context.Context, org *fly.Organization, reestab
 		if tunnel, err = wg.Connect(context.Background(), state); err != nil {
 			return
 		}
+
+		if s.Options.ConfigFallback && !s.tunnelResolves(ctx, tunnel) {
+			s.printf("no response over UDP from the gateway of %q, UDP is likely blocked: falling back to WebSockets", org.Slug)
+
+			_ = tunnel.Close()
+			if tunnel, err = wg.ConnectWS(context.Background(), state); err != nil {
+				return
+			}
+		}
 	}
 
 	s.tunnels[tk] = tunnel
@@ -260,6 +273,16 @@ func (s *server) buildTunnel(ctx context.Context, org *fly.Organization, reestab
 	return
 }
 
+// tunnelResolves reports whether the API resolves through tunnel, which
+// requires the WireGuard handshake with its gateway to have succeeded.
+func (s *server) tunnelResolves(ctx context.Context, tunnel *wg.Tunnel) bool {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := tunnel.LookupAAAA(ctx, "_api.internal")
+	return err == nil
+}
+
 func (s *server) fetchInstances(ctx context.Context, tunnel *wg.Tunnel, app string) (*agent.Instances, error) {
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
@@ -343,6 +366,65 @@ func (s *server) probeTunnel(ctx context.Context, slug, network string) (err err
 	return
 }
 
+// tunnelStatuses reports the state of the tunnels and whether the API
+// resolves through them.
+func (s *server) tunnelStatuses(ctx context.Context) []agent.TunnelStatus {
+	s.mu.Lock()
+	keys := make([]tunnelKey, 0, len(s.tunnels))
+	tunnels := make([]*wg.Tunnel, 0, len(s.tunnels))
+	for tk, tunnel := range s.tunnels {
+		keys = append(keys, tk)
+		tunnels = append(tunnels, tunnel)
+	}
+	s.mu.Unlock()
+
+	statuses := make([]agent.TunnelStatus, len(tunnels))
+
+	eg, ctx := errgroup.WithContext(ctx)
+	for i, tunnel := range tunnels {
+		status := &statuses[i]
+		status.Org = keys[i].orgSlug
+		status.Network = keys[i].networkName
+		if state := tunnel.State; state != nil {
+			status.Peer = state.Name
+			status.Region = state.Region
+			status.PeerIP = state.Peer.Peerip
+		}
+
+		stats, err := tunnel.Stats()
+		if err != nil {
+			status.Error = err.Error()
+			continue
+		}
+		status.Endpoint = stats.Endpoint
+		status.Transport = "udp"
+		if stats.Websockets {
+			status.Transport = "websockets"
+		}
+		status.LastHandshake = stats.LastHandshake
+		status.RxBytes = stats.RxBytes
+		status.TxBytes = stats.TxBytes
+
+		eg.Go(func() error {
+			ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+
+			if _, err := tunnel.LookupAAAA(ctx, "_api.internal"); err != nil {
+				status.ResolverError = err.Error()
+			} else {
+				status.ResolverOK = true
+			}
+			return nil
+		})
+	}
+	_ = eg.Wait()
+
+	slices.SortFunc(statuses, func(a, b agent.TunnelStatus) int {
+		return cmp.Or(cmp.Compare(a.Org, b.Org), cmp.Compare(a.Network, b.Network))
+	})
+	return statuses
+}
+
 func (s *server) validateTunnels() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
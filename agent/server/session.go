@@ -110,6 +110,8 @@ func (s *session) runCommand(ctx context.Context) {
 		handler = (*session).kill
 	case "ping":
 		handler = (*session).ping
+	case "status":
+		handler = (*session).status
 	case "establish":
 		handler = (*session).establish
 	case "reestablish":
@@ -162,6 +164,24 @@ func (s *session) ping(_ context.Context, args ...string) {
 	})
 }
 
+var errMalformedStatus = errors.New("malformed status command")
+
+func (s *session) status(ctx context.Context, args ...string) {
+	if !s.noArgs(args, errMalformedStatus) {
+		return
+	}
+
+	_ = s.marshal(agent.StatusResponse{
+		PingResponse: agent.PingResponse{
+			Version:    buildinfo.Version().String(),
+			PID:        os.Getpid(),
+			Background: s.srv.Options.Background,
+		},
+		LogFile: s.srv.Options.LogFile,
+		Tunnels: s.srv.tunnelStatuses(ctx),
+	})
+}
+
 var errMalformedEstablish = errors.New("malformed establish command")
 
 func (s *session) doEstablish(ctx context.Context, recycle bool, args ...string) {
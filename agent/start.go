@@ -2,6 +2,7 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
@@ -186,6 +187,33 @@ func createLogFile() (path string, err error) {
 	return
 }
 
+// LatestLogFile returns the path of the most recent log file of the agents
+// started in the background, or an empty one when there's none.
+func LatestLogFile() (path string, err error) {
+	dir := filepath.Join(flyctl.ConfigDir(), "agent-logs")
+
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("failed reading agent log directory entries: %w", err)
+	}
+
+	var latest time.Time
+	for _, entry := range entries {
+		inf, err := entry.Info()
+		if err != nil || !inf.Mode().IsRegular() || filepath.Ext(inf.Name()) != ".log" {
+			continue
+		}
+		if inf.ModTime().After(latest) {
+			latest = inf.ModTime()
+			path = filepath.Join(dir, inf.Name())
+		}
+	}
+
+	return path, nil
+}
+
 func setupLogDirectory() (dir string, err error) {
 	dir = filepath.Join(flyctl.ConfigDir(), "agent-logs")
 
@@ -15,8 +15,9 @@ const (
 	ConfigInstaller       = "installer"
 	BuildKitNodeID        = "buildkit_node_id"
 
-	ConfigWireGuardState      = "wire_guard_state"
-	ConfigWireGuardWebsockets = "wire_guard_websockets"
+	ConfigWireGuardState              = "wire_guard_state"
+	ConfigWireGuardWebsockets         = "wire_guard_websockets"
+	ConfigWireGuardWebsocketsFallback = "wire_guard_websockets_fallback"
 
 	ConfigRegistryHost = "registry_host"
 )
@@ -115,7 +115,7 @@ func loadConfig() error {
 	return err
 }
 
-var writeableConfigKeys = []string{ConfigAPIToken, ConfigInstaller, ConfigWireGuardState, ConfigWireGuardWebsockets, BuildKitNodeID}
+var writeableConfigKeys = []string{ConfigAPIToken, ConfigInstaller, ConfigWireGuardState, ConfigWireGuardWebsockets, ConfigWireGuardWebsocketsFallback, BuildKitNodeID}
 
 func saveConfig() error {
 	out := map[string]interface{}{}
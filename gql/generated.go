@@ -431,6 +431,35 @@ type AllAppsResponse struct {
 // GetOrganization returns AllAppsResponse.Organization, and is useful for accessing the field via an interface.
 func (v *AllAppsResponse) GetOrganization() AllAppsOrganization { return v.Organization }
 
+// AllocateEgressIPAddressAllocateEgressIpAddressAllocateEgressIPAddressPayload includes the requested fields of the GraphQL type AllocateEgressIPAddressPayload.
+// The GraphQL type's documentation follows.
+//
+// Autogenerated return type of AllocateEgressIPAddress.
+type AllocateEgressIPAddressAllocateEgressIpAddressAllocateEgressIPAddressPayload struct {
+	V4 string `json:"v4"`
+	V6 string `json:"v6"`
+}
+
+// GetV4 returns AllocateEgressIPAddressAllocateEgressIpAddressAllocateEgressIPAddressPayload.V4, and is useful for accessing the field via an interface.
+func (v *AllocateEgressIPAddressAllocateEgressIpAddressAllocateEgressIPAddressPayload) GetV4() string {
+	return v.V4
+}
+
+// GetV6 returns AllocateEgressIPAddressAllocateEgressIpAddressAllocateEgressIPAddressPayload.V6, and is useful for accessing the field via an interface.
+func (v *AllocateEgressIPAddressAllocateEgressIpAddressAllocateEgressIPAddressPayload) GetV6() string {
+	return v.V6
+}
+
+// AllocateEgressIPAddressResponse is returned by AllocateEgressIPAddress on success.
+type AllocateEgressIPAddressResponse struct {
+	AllocateEgressIpAddress AllocateEgressIPAddressAllocateEgressIpAddressAllocateEgressIPAddressPayload `json:"allocateEgressIpAddress"`
+}
+
+// GetAllocateEgressIpAddress returns AllocateEgressIPAddressResponse.AllocateEgressIpAddress, and is useful for accessing the field via an interface.
+func (v *AllocateEgressIPAddressResponse) GetAllocateEgressIpAddress() AllocateEgressIPAddressAllocateEgressIpAddressAllocateEgressIPAddressPayload {
+	return v.AllocateEgressIpAddress
+}
+
 // AppData includes the GraphQL fields of App requested by the fragment AppData.
 type AppData struct {
 	// Unique application ID
@@ -2389,6 +2418,79 @@ func (v *GetOrganizationResponse) GetOrganization() GetOrganizationOrganization
 	return v.Organization
 }
 
+// ImportCertificateImportCertificateImportCertificatePayload includes the requested fields of the GraphQL type ImportCertificatePayload.
+// The GraphQL type's documentation follows.
+//
+// Autogenerated return type of ImportCertificate.
+type ImportCertificateImportCertificateImportCertificatePayload struct {
+	AppCertificate ImportCertificateImportCertificateImportCertificatePayloadAppCertificate `json:"appCertificate"`
+	Certificate    ImportCertificateImportCertificateImportCertificatePayloadCertificate    `json:"certificate"`
+	Errors         []string                                                                 `json:"errors"`
+}
+
+// GetAppCertificate returns ImportCertificateImportCertificateImportCertificatePayload.AppCertificate, and is useful for accessing the field via an interface.
+func (v *ImportCertificateImportCertificateImportCertificatePayload) GetAppCertificate() ImportCertificateImportCertificateImportCertificatePayloadAppCertificate {
+	return v.AppCertificate
+}
+
+// GetCertificate returns ImportCertificateImportCertificateImportCertificatePayload.Certificate, and is useful for accessing the field via an interface.
+func (v *ImportCertificateImportCertificateImportCertificatePayload) GetCertificate() ImportCertificateImportCertificateImportCertificatePayloadCertificate {
+	return v.Certificate
+}
+
+// GetErrors returns ImportCertificateImportCertificateImportCertificatePayload.Errors, and is useful for accessing the field via an interface.
+func (v *ImportCertificateImportCertificateImportCertificatePayload) GetErrors() []string {
+	return v.Errors
+}
+
+// ImportCertificateImportCertificateImportCertificatePayloadAppCertificate includes the requested fields of the GraphQL type AppCertificate.
+type ImportCertificateImportCertificateImportCertificatePayloadAppCertificate struct {
+	Hostname     string `json:"hostname"`
+	ClientStatus string `json:"clientStatus"`
+}
+
+// GetHostname returns ImportCertificateImportCertificateImportCertificatePayloadAppCertificate.Hostname, and is useful for accessing the field via an interface.
+func (v *ImportCertificateImportCertificateImportCertificatePayloadAppCertificate) GetHostname() string {
+	return v.Hostname
+}
+
+// GetClientStatus returns ImportCertificateImportCertificateImportCertificatePayloadAppCertificate.ClientStatus, and is useful for accessing the field via an interface.
+func (v *ImportCertificateImportCertificateImportCertificatePayloadAppCertificate) GetClientStatus() string {
+	return v.ClientStatus
+}
+
+// ImportCertificateImportCertificateImportCertificatePayloadCertificate includes the requested fields of the GraphQL type Certificate.
+type ImportCertificateImportCertificateImportCertificatePayloadCertificate struct {
+	Hostname  string    `json:"hostname"`
+	Type      string    `json:"type"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// GetHostname returns ImportCertificateImportCertificateImportCertificatePayloadCertificate.Hostname, and is useful for accessing the field via an interface.
+func (v *ImportCertificateImportCertificateImportCertificatePayloadCertificate) GetHostname() string {
+	return v.Hostname
+}
+
+// GetType returns ImportCertificateImportCertificateImportCertificatePayloadCertificate.Type, and is useful for accessing the field via an interface.
+func (v *ImportCertificateImportCertificateImportCertificatePayloadCertificate) GetType() string {
+	return v.Type
+}
+
+// GetExpiresAt returns ImportCertificateImportCertificateImportCertificatePayloadCertificate.ExpiresAt, and is useful for accessing the field via an interface.
+func (v *ImportCertificateImportCertificateImportCertificatePayloadCertificate) GetExpiresAt() time.Time {
+	return v.ExpiresAt
+}
+
+// ImportCertificateResponse is returned by ImportCertificate on success.
+type ImportCertificateResponse struct {
+	ImportCertificate ImportCertificateImportCertificateImportCertificatePayload `json:"importCertificate"`
+}
+
+// GetImportCertificate returns ImportCertificateResponse.ImportCertificate, and is useful for accessing the field via an interface.
+func (v *ImportCertificateResponse) GetImportCertificate() ImportCertificateImportCertificateImportCertificatePayload {
+	return v.ImportCertificate
+}
+
 // ListAddOnPlansAddOnPlansAddOnPlanConnection includes the requested fields of the GraphQL type AddOnPlanConnection.
 // The GraphQL type's documentation follows.
 //
@@ -2910,6 +3012,18 @@ type __AllAppsInput struct {
 // GetOrgSlug returns __AllAppsInput.OrgSlug, and is useful for accessing the field via an interface.
 func (v *__AllAppsInput) GetOrgSlug() string { return v.OrgSlug }
 
+// __AllocateEgressIPAddressInput is used internally by genqlient
+type __AllocateEgressIPAddressInput struct {
+	AppId     string `json:"appId"`
+	MachineId string `json:"machineId"`
+}
+
+// GetAppId returns __AllocateEgressIPAddressInput.AppId, and is useful for accessing the field via an interface.
+func (v *__AllocateEgressIPAddressInput) GetAppId() string { return v.AppId }
+
+// GetMachineId returns __AllocateEgressIPAddressInput.MachineId, and is useful for accessing the field via an interface.
+func (v *__AllocateEgressIPAddressInput) GetMachineId() string { return v.MachineId }
+
 // __CreateAddOnInput is used internally by genqlient
 type __CreateAddOnInput struct {
 	Input CreateAddOnInput `json:"input"`
@@ -3054,6 +3168,26 @@ type __GetOrganizationInput struct {
 // GetSlug returns __GetOrganizationInput.Slug, and is useful for accessing the field via an interface.
 func (v *__GetOrganizationInput) GetSlug() string { return v.Slug }
 
+// __ImportCertificateInput is used internally by genqlient
+type __ImportCertificateInput struct {
+	AppId      string `json:"appId"`
+	Fullchain  string `json:"fullchain"`
+	PrivateKey string `json:"privateKey"`
+	Hostname   string `json:"hostname,omitempty"`
+}
+
+// GetAppId returns __ImportCertificateInput.AppId, and is useful for accessing the field via an interface.
+func (v *__ImportCertificateInput) GetAppId() string { return v.AppId }
+
+// GetFullchain returns __ImportCertificateInput.Fullchain, and is useful for accessing the field via an interface.
+func (v *__ImportCertificateInput) GetFullchain() string { return v.Fullchain }
+
+// GetPrivateKey returns __ImportCertificateInput.PrivateKey, and is useful for accessing the field via an interface.
+func (v *__ImportCertificateInput) GetPrivateKey() string { return v.PrivateKey }
+
+// GetHostname returns __ImportCertificateInput.Hostname, and is useful for accessing the field via an interface.
+func (v *__ImportCertificateInput) GetHostname() string { return v.Hostname }
+
 // __ListAddOnPlansInput is used internally by genqlient
 type __ListAddOnPlansInput struct {
 	AddOnType AddOnType `json:"addOnType"`
@@ -3250,6 +3384,44 @@ func AllApps(
 	return &data_, err_
 }
 
+// The query or mutation executed by AllocateEgressIPAddress.
+const AllocateEgressIPAddress_Operation = `
+mutation AllocateEgressIPAddress ($appId: ID!, $machineId: ID!) {
+	allocateEgressIpAddress(input: {appId:$appId,machineId:$machineId}) {
+		v4
+		v6
+	}
+}
+`
+
+func AllocateEgressIPAddress(
+	ctx_ context.Context,
+	client_ graphql.Client,
+	appId string,
+	machineId string,
+) (*AllocateEgressIPAddressResponse, error) {
+	req_ := &graphql.Request{
+		OpName: "AllocateEgressIPAddress",
+		Query:  AllocateEgressIPAddress_Operation,
+		Variables: &__AllocateEgressIPAddressInput{
+			AppId:     appId,
+			MachineId: machineId,
+		},
+	}
+	var err_ error
+
+	var data_ AllocateEgressIPAddressResponse
+	resp_ := &graphql.Response{Data: &data_}
+
+	err_ = client_.MakeRequest(
+		ctx_,
+		req_,
+		resp_,
+	)
+
+	return &data_, err_
+}
+
 // The query or mutation executed by CreateAddOn.
 const CreateAddOn_Operation = `
 mutation CreateAddOn ($input: CreateAddOnInput!) {
@@ -4015,6 +4187,56 @@ func GetOrganization(
 	return &data_, err_
 }
 
+// The query or mutation executed by ImportCertificate.
+const ImportCertificate_Operation = `
+mutation ImportCertificate ($appId: ID!, $fullchain: String!, $privateKey: String!, $hostname: String) {
+	importCertificate(appId: $appId, fullchain: $fullchain, privateKey: $privateKey, hostname: $hostname) {
+		appCertificate {
+			hostname
+			clientStatus
+		}
+		certificate {
+			hostname
+			type
+			expiresAt
+		}
+		errors
+	}
+}
+`
+
+func ImportCertificate(
+	ctx_ context.Context,
+	client_ graphql.Client,
+	appId string,
+	fullchain string,
+	privateKey string,
+	hostname string,
+) (*ImportCertificateResponse, error) {
+	req_ := &graphql.Request{
+		OpName: "ImportCertificate",
+		Query:  ImportCertificate_Operation,
+		Variables: &__ImportCertificateInput{
+			AppId:      appId,
+			Fullchain:  fullchain,
+			PrivateKey: privateKey,
+			Hostname:   hostname,
+		},
+	}
+	var err_ error
+
+	var data_ ImportCertificateResponse
+	resp_ := &graphql.Response{Data: &data_}
+
+	err_ = client_.MakeRequest(
+		ctx_,
+		req_,
+		resp_,
+	)
+
+	return &data_, err_
+}
+
 // The query or mutation executed by ListAddOnPlans.
 const ListAddOnPlans_Operation = `
 query ListAddOnPlans ($addOnType: AddOnType!) {
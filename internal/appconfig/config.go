@@ -18,6 +18,10 @@ const (
 	DefaultConfigFileName = "fly.toml"
 )
 
+// ConfigFileNames are the names a config file is looked up by in a directory,
+// in order of preference.
+var ConfigFileNames = []string{DefaultConfigFileName, "fly.json", "fly.yaml", "fly.yml"}
+
 type RestartPolicy string
 
 const (
@@ -36,6 +40,7 @@ func NewConfig() *Config {
 // Config wraps the properties of app configuration.
 // NOTE: If you any new setting here, please also add a value for it at testdata/rull-reference.toml
 type Config struct {
+	SchemaVersion  int           `toml:"schema_version,omitempty" json:"schema_version,omitempty"`
 	AppName        string        `toml:"app,omitempty" json:"app,omitempty"`
 	PrimaryRegion  string        `toml:"primary_region,omitempty" json:"primary_region,omitempty"`
 	KillSignal     *string       `toml:"kill_signal,omitempty" json:"kill_signal,omitempty"`
@@ -49,6 +54,10 @@ type Config struct {
 	Deploy       *Deploy           `toml:"deploy,omitempty" json:"deploy,omitempty"`
 	Env          map[string]string `toml:"env,omitempty" json:"env,omitempty"`
 
+	// ProcessEnv holds the [env.<group>] tables, set on top of Env for the
+	// machines of that process group. They are written back within env.
+	ProcessEnv map[string]map[string]string `toml:"-" json:"process_env,omitempty"`
+
 	// Fields that are process group aware must come after Processes
 	Processes        map[string]string         `toml:"processes,omitempty" json:"processes,omitempty"`
 	Mounts           []Mount                   `toml:"mounts,omitempty" json:"mounts,omitempty"`
@@ -56,6 +65,7 @@ type Config struct {
 	Services         []Service                 `toml:"services,omitempty" json:"services,omitempty"`
 	Checks           map[string]*ToplevelCheck `toml:"checks,omitempty" json:"checks,omitempty"`
 	Files            []File                    `toml:"files,omitempty" json:"files,omitempty"`
+	Secrets          []ProcessSecrets          `toml:"secrets,omitempty" json:"secrets,omitempty"`
 	HostDedicationID string                    `toml:"host_dedication_id,omitempty" json:"host_dedication_id,omitempty"`
 
 	MachineChecks []*ServiceMachineCheck `toml:"machine_checks,omitempty" json:"machine_checks,omitempty"`
@@ -74,6 +84,14 @@ type Config struct {
 	// Path to application configuration file, usually fly.toml.
 	configFilePath string
 
+	// Set when the config file extends a base config, and so holds only part
+	// of the settings
+	extendsBase string
+
+	// Set by Flatten when the app restricts the secrets of its groups with
+	// [[secrets]], even if none of them is for the flattened group
+	restrictSecrets bool
+
 	// Set when it fails to unmarshal fly.toml into Config
 	v2UnmarshalError error
 
@@ -92,6 +110,8 @@ type Deploy struct {
 	Strategy              string        `toml:"strategy,omitempty" json:"strategy,omitempty"`
 	MaxUnavailable        *float64      `toml:"max_unavailable,omitempty" json:"max_unavailable,omitempty"`
 	WaitTimeout           *fly.Duration `toml:"wait_timeout,omitempty" json:"wait_timeout,omitempty"`
+	CanaryCount           *int          `toml:"canary_count,omitempty" json:"canary_count,omitempty"`
+	BakeTime              *fly.Duration `toml:"bake_time,omitempty" json:"bake_time,omitempty"`
 }
 
 type File struct {
@@ -99,12 +119,14 @@ type File struct {
 	LocalPath  string   `toml:"local_path,omitempty" json:"local_path,omitempty"`
 	SecretName string   `toml:"secret_name,omitempty" json:"secret_name,omitempty"`
 	RawValue   string   `toml:"raw_value,omitempty" json:"raw_value,omitempty"`
+	Mode       uint32   `toml:"mode,omitempty" json:"mode,omitempty"`
 	Processes  []string `json:"processes,omitempty" toml:"processes,omitempty"`
 }
 
 func (f File) toMachineFile() (*fly.File, error) {
 	file := &fly.File{
 		GuestPath: f.GuestPath,
+		Mode:      f.Mode,
 	}
 	switch {
 	case f.LocalPath != "":
@@ -123,6 +145,15 @@ func (f File) toMachineFile() (*fly.File, error) {
 	return file, nil
 }
 
+// ProcessSecrets restricts the machines of process groups to the secrets it
+// names, instead of all the secrets of the app. Without processes it applies
+// to the default process group, like the other per-group sections. Once any
+// [[secrets]] is set, the groups none of them list get no secrets.
+type ProcessSecrets struct {
+	Names     []string `toml:"names,omitempty" json:"names,omitempty"`
+	Processes []string `toml:"processes,omitempty" json:"processes,omitempty"`
+}
+
 type Static struct {
 	GuestPath     string `toml:"guest_path" json:"guest_path,omitempty" validate:"required"`
 	UrlPrefix     string `toml:"url_prefix" json:"url_prefix,omitempty" validate:"required"`
@@ -278,3 +278,9 @@ func TestURL(t *testing.T) {
 	}}
 	assert.Nil(t, cfg.URL())
 }
+
+func TestMergeFilesMode(t *testing.T) {
+	cfg := &Config{Files: []File{{GuestPath: "/etc/tls/key.pem", SecretName: "TLS_KEY", Mode: 0o600}}}
+	assert.NoError(t, cfg.MergeFiles(nil))
+	assert.Equal(t, []*fly.File{{GuestPath: "/etc/tls/key.pem", SecretName: fly.Pointer("TLS_KEY"), Mode: 0o600}}, cfg.MergedFiles)
+}
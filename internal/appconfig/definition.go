@@ -16,6 +16,9 @@ func (c *Config) ToDefinition() (*fly.Definition, error) {
 	if err := toml.Unmarshal(buf, definition); err != nil {
 		return nil, err
 	}
+	if len(c.ProcessEnv) > 0 {
+		(*definition)["env"] = c.envWithProcessEnv()
+	}
 	return definition, nil
 }
 
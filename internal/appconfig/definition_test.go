@@ -136,6 +136,7 @@ func TestToDefinition(t *testing.T) {
 	definition, err := cfg.ToDefinition()
 	assert.NoError(t, err)
 	assert.Equal(t, &fly.Definition{
+		"schema_version":     int64(1),
 		"app":                "foo",
 		"primary_region":     "sea",
 		"kill_signal":        "SIGTERM",
@@ -260,9 +261,12 @@ func TestToDefinition(t *testing.T) {
 			"release_command": "release command",
 			"strategy":        "rolling-eyes",
 			"max_unavailable": 0.2,
+			"canary_count":    int64(2),
+			"bake_time":       "5m0s",
 		},
 		"env": map[string]any{
-			"FOO": "BAR",
+			"FOO":  "BAR",
+			"task": map[string]string{"FOO": "TASK"},
 		},
 		"metrics": []any{
 			map[string]any{
@@ -291,6 +295,7 @@ func TestToDefinition(t *testing.T) {
 			map[string]any{
 				"guest_path":  "/path/to/secret.txt",
 				"secret_name": "SUPER_SECRET",
+				"mode":        int64(0o600),
 			},
 			map[string]any{
 				"guest_path": "/path/to/config.yaml",
@@ -298,6 +303,12 @@ func TestToDefinition(t *testing.T) {
 				"processes":  []any{"web"},
 			},
 		},
+		"secrets": []any{
+			map[string]any{
+				"names":     []any{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY"},
+				"processes": []any{"task"},
+			},
+		},
 		"mounts": []any{map[string]any{
 			"source":             "data",
 			"destination":        "/data",
@@ -339,6 +350,14 @@ func TestToDefinition(t *testing.T) {
 					"hard_limit": int64(22),
 					"soft_limit": int64(13),
 				},
+				"tls_options": map[string]any{
+					"min_version": "TLSv1.2",
+					"hsts": map[string]any{
+						"max_age":            int64(63072000),
+						"include_subdomains": true,
+						"preload":            true,
+					},
+				},
 				"ports": []any{
 					map[string]any{
 						"port":       int64(80),
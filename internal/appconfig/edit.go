@@ -0,0 +1,563 @@
+package appconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+	"github.com/samber/lo"
+	fly "github.com/superfly/fly-go"
+	"gopkg.in/yaml.v2"
+)
+
+var tomlBareKeyRE = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// parseKeyPath splits a key path like services[0].ports[1].port or
+// env."SOME.KEY" into its keys, as strings, and array indexes, as ints.
+func parseKeyPath(keyPath string) ([]any, error) {
+	var parts []any
+	s := keyPath
+	for s != "" {
+		switch {
+		case s[0] == '[':
+			end := strings.IndexByte(s, ']')
+			if end < 0 || len(parts) == 0 {
+				return nil, fmt.Errorf("invalid key path %q", keyPath)
+			}
+			index, err := strconv.Atoi(s[1:end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid key path %q: bad index %q", keyPath, s[1:end])
+			}
+			parts = append(parts, index)
+			s = s[end+1:]
+		case s[0] == '"':
+			end := strings.IndexByte(s[1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid key path %q: unterminated quote", keyPath)
+			}
+			parts = append(parts, s[1:end+1])
+			s = s[end+2:]
+		default:
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid key path %q: empty key", keyPath)
+			}
+			parts = append(parts, s[:end])
+			s = s[end:]
+		}
+		if strings.HasPrefix(s, ".") {
+			s = s[1:]
+			if s == "" || s[0] == '[' {
+				return nil, fmt.Errorf("invalid key path %q: empty key", keyPath)
+			}
+		} else if s != "" && s[0] != '[' {
+			return nil, fmt.Errorf("invalid key path %q", keyPath)
+		}
+	}
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("empty key path")
+	}
+	return parts, nil
+}
+
+func formatKeyPath(parts []any) string {
+	var b strings.Builder
+	for _, part := range parts {
+		switch part := part.(type) {
+		case int:
+			fmt.Fprintf(&b, "[%d]", part)
+		case string:
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			if tomlBareKeyRE.MatchString(part) {
+				b.WriteString(part)
+			} else {
+				b.WriteString(strconv.Quote(part))
+			}
+		}
+	}
+	return b.String()
+}
+
+// GetValue returns the setting at keyPath, e.g. services[0].internal_port,
+// decoded from the JSON form of the config.
+func (c *Config) GetValue(keyPath string) (any, error) {
+	parts, err := parseKeyPath(keyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	buf, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	if err := json.Unmarshal(buf, &v); err != nil {
+		return nil, err
+	}
+
+	for i, part := range parts {
+		found := false
+		switch part := part.(type) {
+		case string:
+			if m, ok := v.(map[string]any); ok {
+				v, found = m[part]
+			}
+		case int:
+			if a, ok := v.([]any); ok && part < len(a) {
+				v, found = a[part], true
+			}
+		}
+		if !found || v == nil {
+			return nil, fmt.Errorf("%s is not set", formatKeyPath(parts[:i+1]))
+		}
+	}
+	return v, nil
+}
+
+// SetConfigFileValues applies assignments like services[0].internal_port=8081
+// to buf, the content of the config file at path, and returns the new
+// content. TOML files keep their comments and formatting, JSON and YAML files
+// are encoded again. Values are read as TOML, e.g. 8081 or [80, 443], or
+// else as a string.
+func SetConfigFileValues(path string, buf []byte, assignments []string) ([]byte, error) {
+	var keyPaths [][]any
+	for _, assignment := range assignments {
+		keyPath, value, ok := strings.Cut(assignment, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid assignment %q, expected <key path>=<value>", assignment)
+		}
+		parts, err := parseKeyPath(strings.TrimSpace(keyPath))
+		if err != nil {
+			return nil, err
+		}
+		keyPaths = append(keyPaths, parts)
+
+		if ConfigFormat(path) == "toml" {
+			buf, err = setTOMLValue(buf, parts, tomlLiteral(value))
+		} else {
+			buf, err = setEncodedValue(path, buf, parts, value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("setting %s: %w", formatKeyPath(parts), err)
+		}
+	}
+
+	cfgMap, err := decodeConfigMap(path, buf)
+	if err != nil {
+		return nil, err
+	}
+	if cfgMap, err = patchRoot(cfgMap); err != nil {
+		return nil, err
+	}
+	unknown := findUnknownKeys(cfgMap)
+	for _, parts := range keyPaths {
+		keyPath := formatKeyPath(parts)
+		for _, u := range unknown {
+			if keyPath == u || strings.HasPrefix(keyPath, u+".") || strings.HasPrefix(keyPath, u+"[") {
+				return nil, fmt.Errorf("%s is not a known setting", u)
+			}
+		}
+	}
+	if _, err := mapToConfig(cfgMap); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// tomlLiteral returns value as written if it's a TOML value, or else quoted
+// as a string.
+func tomlLiteral(value string) string {
+	value = strings.TrimSpace(value)
+	var m map[string]any
+	if err := toml.Unmarshal([]byte("v = "+value), &m); err == nil && len(m) == 1 {
+		return value
+	}
+	buf, _ := toml.Marshal(map[string]string{"v": value})
+	return strings.TrimSuffix(strings.TrimPrefix(string(buf), "v = "), "\n")
+}
+
+func typedValue(value string) any {
+	var m map[string]any
+	if err := toml.Unmarshal([]byte("v = "+strings.TrimSpace(value)), &m); err == nil && len(m) == 1 {
+		return m["v"]
+	}
+	return strings.TrimSpace(value)
+}
+
+// setEncodedValue sets a value in a JSON or YAML config file, which doesn't
+// keep its formatting.
+func setEncodedValue(path string, buf []byte, parts []any, value string) ([]byte, error) {
+	cfgMap, err := decodeConfigMap(path, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	var parent any = cfgMap
+	for i, part := range parts {
+		last := i == len(parts)-1
+		switch part := part.(type) {
+		case string:
+			m, ok := parent.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%s is not a table", formatKeyPath(parts[:i]))
+			}
+			if last {
+				m[part] = typedValue(value)
+			} else if _, ok := m[part]; !ok {
+				m[part] = map[string]any{}
+			}
+			parent = m[part]
+		case int:
+			a, ok := parent.([]any)
+			if !ok || part >= len(a) {
+				return nil, fmt.Errorf("%s doesn't exist", formatKeyPath(parts[:i+1]))
+			}
+			if last {
+				a[part] = typedValue(value)
+			}
+			parent = a[part]
+		}
+	}
+
+	if ConfigFormat(path) == "json" {
+		return json.MarshalIndent(cfgMap, "", "  ")
+	}
+	return yaml.Marshal(cfgMap)
+}
+
+// tomlDocument indexes the key/values and tables of a TOML file by key path,
+// e.g. services[0].ports[1].port, with array of tables indexed like arrays.
+type tomlDocument struct {
+	buf    []byte
+	values map[string]tomlRange
+	tables map[string]*tomlTable
+}
+
+type tomlRange struct {
+	start, end int
+}
+
+type tomlTable struct {
+	// insertAt is the offset of the line after the last key of the table, or
+	// after its header, and indent the indentation of its keys.
+	insertAt int
+	indent   string
+}
+
+func parseTOMLDocument(buf []byte) (*tomlDocument, error) {
+	doc := &tomlDocument{
+		buf:    buf,
+		values: map[string]tomlRange{},
+		tables: map[string]*tomlTable{"": {insertAt: -1}},
+	}
+	root := doc.tables[""]
+	arrayTables := map[string]int{}
+	current := ""
+
+	p := unstable.Parser{}
+	p.Reset(buf)
+	for p.NextExpression() {
+		e := p.Expression()
+		var keys []string
+		var first, last *unstable.Node
+		for it := e.Key(); it.Next(); {
+			last = it.Node()
+			if first == nil {
+				first = last
+			}
+			keys = append(keys, string(last.Data))
+		}
+		if first == nil {
+			continue
+		}
+		lineStart := bytes.LastIndexByte(buf[:first.Raw.Offset], '\n') + 1
+		keyEnd := int(last.Raw.Offset + last.Raw.Length)
+
+		switch e.Kind {
+		case unstable.Table, unstable.ArrayTable:
+			if root.insertAt < 0 {
+				root.insertAt = lineStart
+			}
+			path := ""
+			for i, key := range keys {
+				path = joinKeyPath(path, formatKeyPath([]any{key}))
+				if i == len(keys)-1 && e.Kind == unstable.ArrayTable {
+					arrayTables[path]++
+				}
+				if n, ok := arrayTables[path]; ok {
+					path = fmt.Sprintf("%s[%d]", path, n-1)
+				}
+			}
+			doc.tables[path] = &tomlTable{
+				insertAt: nextLine(buf, keyEnd),
+				indent:   indentAt(buf, lineStart) + "  ",
+			}
+			current = path
+
+		case unstable.KeyValue:
+			path := current
+			for _, key := range keys {
+				path = joinKeyPath(path, formatKeyPath([]any{key}))
+			}
+			eq := bytes.IndexByte(buf[keyEnd:], '=')
+			if eq < 0 {
+				continue
+			}
+			start := keyEnd + eq + 1
+			for start < len(buf) && (buf[start] == ' ' || buf[start] == '\t') {
+				start++
+			}
+			end := tomlValueEnd(buf, start)
+			doc.values[path] = tomlRange{start: start, end: end}
+
+			table := doc.tables[current]
+			table.insertAt = nextLine(buf, end)
+			table.indent = indentAt(buf, lineStart)
+		}
+	}
+	if err := p.Error(); err != nil {
+		return nil, err
+	}
+	if root.insertAt < 0 {
+		root.insertAt = len(buf)
+	}
+	return doc, nil
+}
+
+// tomlValueEnd returns the end of the value starting at start, before any
+// comment following it on the same line.
+func tomlValueEnd(buf []byte, start int) int {
+	depth := 0
+	end := start
+	for i := start; i < len(buf); i++ {
+		switch c := buf[i]; c {
+		case '"', '\'':
+			delim := []byte{c}
+			if bytes.HasPrefix(buf[i:], []byte{c, c, c}) {
+				delim = []byte{c, c, c}
+			}
+			j := i + len(delim)
+			for j < len(buf) && !bytes.HasPrefix(buf[j:], delim) {
+				if c == '"' && buf[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			i = min(j+len(delim), len(buf)) - 1
+			end = i + 1
+		case '[', '{':
+			depth++
+			end = i + 1
+		case ']', '}':
+			depth--
+			end = i + 1
+		case '#':
+			if depth == 0 {
+				return end
+			}
+			for i+1 < len(buf) && buf[i+1] != '\n' {
+				i++
+			}
+		case '\n':
+			if depth == 0 {
+				return end
+			}
+		case ' ', '\t', '\r', ',':
+			if c == ',' {
+				end = i + 1
+			}
+		default:
+			end = i + 1
+		}
+	}
+	return end
+}
+
+func nextLine(buf []byte, offset int) int {
+	if i := bytes.IndexByte(buf[offset:], '\n'); i >= 0 {
+		return offset + i + 1
+	}
+	return len(buf)
+}
+
+func indentAt(buf []byte, lineStart int) string {
+	end := lineStart
+	for end < len(buf) && (buf[end] == ' ' || buf[end] == '\t') {
+		end++
+	}
+	return string(buf[lineStart:end])
+}
+
+// setTOMLValue replaces the value at parts in buf with literal, or adds it to
+// the closest enclosing table.
+func setTOMLValue(buf []byte, parts []any, literal string) ([]byte, error) {
+	doc, err := parseTOMLDocument(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	if r, ok := doc.values[formatKeyPath(parts)]; ok {
+		return splice(buf, r.start, r.end, literal), nil
+	}
+	for i := len(parts) - 1; i >= 0; i-- {
+		prefix := formatKeyPath(parts[:i])
+		if _, ok := doc.values[prefix]; ok && i > 0 {
+			return nil, fmt.Errorf("%s is an inline value, set it as a whole", prefix)
+		}
+		if table, ok := doc.tables[prefix]; ok {
+			return doc.insert(table, prefix == "", parts[:i], parts[i:], literal)
+		}
+	}
+	return nil, fmt.Errorf("can't find where to set it")
+}
+
+func (doc *tomlDocument) insert(table *tomlTable, root bool, tablePath, keys []any, literal string) ([]byte, error) {
+	for i, key := range keys {
+		if _, ok := key.(int); ok {
+			return nil, fmt.Errorf("%s doesn't exist", formatKeyPath(append(tablePath, keys[:i+1]...)))
+		}
+	}
+
+	buf := doc.buf
+	if root && len(keys) > 1 {
+		// A new table at the end, rather than a dotted key among the top
+		// level settings
+		var b bytes.Buffer
+		b.Write(buf)
+		if len(buf) > 0 && !bytes.HasSuffix(buf, []byte("\n")) {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "\n[%s]\n  %s = %s\n", formatKeyPath(keys[:len(keys)-1]), formatKeyPath(keys[len(keys)-1:]), literal)
+		return b.Bytes(), nil
+	}
+
+	line := fmt.Sprintf("%s%s = %s\n", table.indent, formatKeyPath(keys), literal)
+	if table.insertAt == len(buf) && len(buf) > 0 && !bytes.HasSuffix(buf, []byte("\n")) {
+		line = "\n" + line
+	}
+	return splice(buf, table.insertAt, table.insertAt, line), nil
+}
+
+func splice(buf []byte, start, end int, s string) []byte {
+	out := make([]byte, 0, len(buf)+len(s))
+	out = append(out, buf[:start]...)
+	out = append(out, s...)
+	return append(out, buf[end:]...)
+}
+
+// configFileEntry is a table of a config file section, like [mounts] or one of
+// its [[mounts]], as written in the file.
+type configFileEntry struct {
+	keyPath string
+	values  map[string]any
+}
+
+// configFileEntries returns the tables of section in buf, the content of the
+// config file at path: section itself when it's a single table, or
+// section[0], section[1]... for an array of tables. Sections only set by the
+// base of an extended config aren't in the file and have no entries.
+func configFileEntries(path string, buf []byte, section string) ([]configFileEntry, error) {
+	cfgMap, err := decodeConfigMap(path, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := cfgMap[section].(type) {
+	case map[string]any:
+		return []configFileEntry{{keyPath: section, values: v}}, nil
+	case []any:
+		var entries []configFileEntry
+		for i, x := range v {
+			if m, ok := x.(map[string]any); ok {
+				entries = append(entries, configFileEntry{keyPath: formatKeyPath([]any{section, i}), values: m})
+			}
+		}
+		return entries, nil
+	case []map[string]any:
+		var entries []configFileEntry
+		for i, m := range v {
+			entries = append(entries, configFileEntry{keyPath: formatKeyPath([]any{section, i}), values: m})
+		}
+		return entries, nil
+	}
+	return nil, nil
+}
+
+// SetConfigFileMountAutoExtend sets the auto_extend_size_* settings of the
+// [mounts] with source in buf, the content of the config file at path, like
+// SetConfigFileValues does. Empty increment and limit are only written to
+// clear a setting the file has. It returns the new content and the number of
+// mounts changed.
+func SetConfigFileMountAutoExtend(path string, buf []byte, source string, threshold int, increment, limit string) ([]byte, int, error) {
+	entries, err := configFileEntries(path, buf, "mounts")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var assignments []string
+	changed := 0
+	for _, e := range entries {
+		if e.values["source"] != source {
+			continue
+		}
+		changed++
+		assignments = append(assignments, fmt.Sprintf("%s.auto_extend_size_threshold=%d", e.keyPath, threshold))
+		for _, kv := range [][2]string{{"auto_extend_size_increment", increment}, {"auto_extend_size_limit", limit}} {
+			if _, ok := e.values[kv[0]]; ok || kv[1] != "" {
+				assignments = append(assignments, fmt.Sprintf("%s.%s=%q", e.keyPath, kv[0], kv[1]))
+			}
+		}
+	}
+	if changed == 0 {
+		return buf, 0, nil
+	}
+
+	buf, err = SetConfigFileValues(path, buf, assignments)
+	return buf, changed, err
+}
+
+// SetConfigFileAutostop is SetAutostop for buf, the content of the config file
+// at path, keeping its comments and formatting like SetConfigFileValues. It
+// returns the new content and the number of services changed.
+func (c *Config) SetConfigFileAutostop(path string, buf []byte, groupName string, autostop fly.MachineAutostop, autostart bool, minRunning int) ([]byte, int, error) {
+	var entries []configFileEntry
+	for _, section := range []string{"http_service", "services"} {
+		sectionEntries, err := configFileEntries(path, buf, section)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, sectionEntries...)
+	}
+
+	var assignments []string
+	changed := 0
+	for _, e := range entries {
+		processes, _ := e.values["processes"].([]any)
+		groups := lo.Map(processes, func(p any, _ int) string { return fmt.Sprint(p) })
+		if !c.flattenGroupsMatch(groupName, groups) {
+			continue
+		}
+		changed++
+		assignments = append(assignments,
+			fmt.Sprintf("%s.auto_stop_machines=%q", e.keyPath, autostop.String()),
+			fmt.Sprintf("%s.auto_start_machines=%t", e.keyPath, autostart),
+			fmt.Sprintf("%s.min_machines_running=%d", e.keyPath, minRunning),
+		)
+	}
+	if changed == 0 {
+		return buf, 0, nil
+	}
+
+	buf, err := SetConfigFileValues(path, buf, assignments)
+	return buf, changed, err
+}
@@ -0,0 +1,219 @@
+package appconfig
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	fly "github.com/superfly/fly-go"
+)
+
+func TestParseKeyPath(t *testing.T) {
+	parts, err := parseKeyPath(`services[0].ports[1].port`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"services", 0, "ports", 1, "port"}, parts)
+	assert.Equal(t, "services[0].ports[1].port", formatKeyPath(parts))
+
+	parts, err = parseKeyPath(`env."SOME.KEY"`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"env", "SOME.KEY"}, parts)
+	assert.Equal(t, `env."SOME.KEY"`, formatKeyPath(parts))
+
+	for _, keyPath := range []string{"", "a..b", "a.", "[0]", "a[x]", "a[0]b"} {
+		_, err := parseKeyPath(keyPath)
+		assert.Error(t, err, keyPath)
+	}
+}
+
+func TestSetConfigFileValues(t *testing.T) {
+	path := "./testdata/edit.toml"
+	buf, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	out, err := SetConfigFileValues(path, buf, []string{
+		"env.LOG_LEVEL=debug",
+		"env.PORT=8081",
+		"services[0].internal_port=8081",
+		"services[0].concurrency.soft_limit=20",
+		"kill_timeout=10s",
+		"http_service.force_https=true",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `# fly.toml app configuration file
+app = "foo"
+primary_region = "ord" # closest to users
+kill_timeout = '10s'
+
+[env]
+  LOG_LEVEL = 'debug' # or debug
+  PORT = 8081
+
+[[services]]
+  internal_port = 8081
+  protocol = "tcp"
+
+  [services.concurrency]
+    hard_limit = 25
+    soft_limit = 20
+
+  [[services.ports]]
+    port = 80
+    handlers = ["http"]
+
+[http_service]
+  force_https = true
+`, string(out))
+
+	cfg, err := unmarshalTOML(out)
+	require.NoError(t, err)
+	assert.Equal(t, "8081", cfg.Env["PORT"])
+	assert.Equal(t, 8081, cfg.Services[0].InternalPort)
+}
+
+func TestSetConfigFileValuesErrors(t *testing.T) {
+	path := "./testdata/edit.toml"
+	buf, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	_, err = SetConfigFileValues(path, buf, []string{"services[1].internal_port=80"})
+	assert.ErrorContains(t, err, "services[1] doesn't exist")
+
+	_, err = SetConfigFileValues(path, buf, []string{"services[0].intenal_port=80"})
+	assert.ErrorContains(t, err, "services[0].intenal_port is not a known setting")
+
+	_, err = SetConfigFileValues(path, buf, []string{"services[0].ports[0].handlers[0]=tls"})
+	assert.ErrorContains(t, err, "services[0].ports[0].handlers is an inline value")
+
+	_, err = SetConfigFileValues(path, buf, []string{"services[0].internal_port=http"})
+	assert.Error(t, err)
+
+	_, err = SetConfigFileValues(path, buf, []string{"app"})
+	assert.ErrorContains(t, err, "invalid assignment")
+}
+
+func TestSetConfigFileValuesJSON(t *testing.T) {
+	out, err := SetConfigFileValues("fly.json", []byte(`{"app": "foo", "services": [{"internal_port": 8080}]}`), []string{
+		"services[0].internal_port=8081",
+		"env.LOG_LEVEL=debug",
+	})
+	require.NoError(t, err)
+
+	cfg, err := unmarshalJSON(out)
+	require.NoError(t, err)
+	assert.Equal(t, 8081, cfg.Services[0].InternalPort)
+	assert.Equal(t, map[string]string{"LOG_LEVEL": "debug"}, cfg.Env)
+}
+
+func TestConfigGetValue(t *testing.T) {
+	cfg, err := LoadConfig("./testdata/edit.toml")
+	require.NoError(t, err)
+
+	v, err := cfg.GetValue("services[0].ports[0].port")
+	require.NoError(t, err)
+	assert.Equal(t, float64(80), v)
+
+	v, err = cfg.GetValue("env.LOG_LEVEL")
+	require.NoError(t, err)
+	assert.Equal(t, "info", v)
+
+	_, err = cfg.GetValue("services[1]")
+	assert.ErrorContains(t, err, "services[1] is not set")
+}
+
+func TestSetConfigFileMountAutoExtend(t *testing.T) {
+	path := "fly.toml"
+	buf := []byte(`app = "foo"
+
+[mounts]
+  source = "data" # the app's data
+  destination = "/data"
+`)
+	out, changed, err := SetConfigFileMountAutoExtend(path, buf, "data", 80, "5GB", "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, changed)
+	assert.Equal(t, `app = "foo"
+
+[mounts]
+  source = "data" # the app's data
+  destination = "/data"
+  auto_extend_size_threshold = 80
+  auto_extend_size_increment = "5GB"
+`, string(out))
+
+	// Turning it off clears the settings the file has
+	out, changed, err = SetConfigFileMountAutoExtend(path, out, "data", 0, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, changed)
+	assert.Contains(t, string(out), "auto_extend_size_threshold = 0\n  auto_extend_size_increment = \"\"\n")
+
+	buf = []byte(`app = "foo"
+
+[[mounts]]
+  source = "logs"
+  destination = "/logs"
+
+[[mounts]]
+  source = "data"
+  destination = "/data"
+  processes = ["db"]
+`)
+	out, changed, err = SetConfigFileMountAutoExtend(path, buf, "data", 90, "10GB", "100GB")
+	require.NoError(t, err)
+	assert.Equal(t, 1, changed)
+	assert.Contains(t, string(out), `  processes = ["db"]
+  auto_extend_size_threshold = 90
+  auto_extend_size_increment = "10GB"
+  auto_extend_size_limit = "100GB"
+`)
+
+	_, changed, err = SetConfigFileMountAutoExtend(path, buf, "missing", 90, "10GB", "")
+	require.NoError(t, err)
+	assert.Equal(t, 0, changed)
+}
+
+func TestSetConfigFileAutostop(t *testing.T) {
+	path := "fly.toml"
+	buf := []byte(`app = "foo"
+
+[processes]
+  web = "run-web"
+  worker = "run-worker"
+
+[http_service]
+  internal_port = 8080
+  auto_stop_machines = true # idle web machines
+  processes = ["web"]
+
+[[services]]
+  internal_port = 9000
+  processes = ["worker"]
+`)
+	cfg, err := unmarshalTOML(buf)
+	require.NoError(t, err)
+
+	out, changed, err := cfg.SetConfigFileAutostop(path, buf, "web", fly.MachineAutostopSuspend, true, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, changed)
+	assert.Equal(t, `app = "foo"
+
+[processes]
+  web = "run-web"
+  worker = "run-worker"
+
+[http_service]
+  internal_port = 8080
+  auto_stop_machines = "suspend" # idle web machines
+  processes = ["web"]
+  auto_start_machines = true
+  min_machines_running = 1
+
+[[services]]
+  internal_port = 9000
+  processes = ["worker"]
+`, string(out))
+
+	_, changed, err = cfg.SetConfigFileAutostop(path, buf, "cron", fly.MachineAutostopStop, false, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, changed)
+}
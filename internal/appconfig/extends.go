@@ -0,0 +1,130 @@
+package appconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v2"
+)
+
+// extendsKey names the base config a config file is applied on top of, e.g.
+// extends = "../base/fly.toml", relative to the directory of the file.
+const extendsKey = "extends"
+
+// loadExtendedConfig builds the config of the file at path, which extends a
+// base config, from its decoded content cfgMap.
+func loadExtendedConfig(path string, cfgMap map[string]any) (*Config, error) {
+	base := fmt.Sprint(cfgMap[extendsKey])
+	cfgMap, err := resolveExtends(path, cfgMap, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := applyPatches(cfgMap)
+	if err != nil {
+		cfg = &Config{v2UnmarshalError: err}
+		if name, ok := (cfgMap["app"]).(string); ok {
+			cfg.AppName = name
+		}
+	}
+	cfg.extendsBase = base
+	return cfg, nil
+}
+
+// loadConfigMap reads and decodes the config file at path, merged on top of
+// the configs it extends.
+func loadConfigMap(path string) (map[string]any, error) {
+	cfgMap, err := decodeConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return resolveExtends(path, cfgMap, nil)
+}
+
+// resolveExtends merges cfgMap, the content of the file at path, on top of the
+// base config it extends, if any. seen holds the files extending it, to catch
+// cycles.
+func resolveExtends(path string, cfgMap map[string]any, seen []string) (map[string]any, error) {
+	raw, ok := cfgMap[extendsKey]
+	if !ok {
+		return cfgMap, nil
+	}
+	delete(cfgMap, extendsKey)
+
+	base, ok := raw.(string)
+	if !ok || base == "" {
+		return nil, fmt.Errorf("%s: '%s' must be the path of a config file", path, extendsKey)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	seen = append(seen, absPath)
+
+	if !filepath.IsAbs(base) {
+		base = filepath.Join(filepath.Dir(absPath), base)
+	}
+	if slices.Contains(seen, base) {
+		return nil, fmt.Errorf("%s: '%s' loops back to %s", path, extendsKey, base)
+	}
+
+	baseMap, err := decodeConfigFile(base)
+	if err != nil {
+		return nil, fmt.Errorf("%s extends %s: %w", path, base, err)
+	}
+	baseMap, err = resolveExtends(base, baseMap, seen)
+	if err != nil {
+		return nil, err
+	}
+	return mergeConfigMaps(baseMap, cfgMap), nil
+}
+
+// mergeConfigMaps applies local on top of base: tables are merged key by key,
+// any other value, including arrays like [[services]], replaces the base one.
+func mergeConfigMaps(base, local map[string]any) map[string]any {
+	for k, v := range local {
+		localTable, ok1 := v.(map[string]any)
+		baseTable, ok2 := base[k].(map[string]any)
+		if ok1 && ok2 {
+			base[k] = mergeConfigMaps(baseTable, localTable)
+		} else {
+			base[k] = v
+		}
+	}
+	return base
+}
+
+func decodeConfigFile(path string) (map[string]any, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeConfigMap(path, buf)
+}
+
+// decodeConfigMap decodes buf, the content of the config file at path, in the
+// format given by its extension.
+func decodeConfigMap(path string, buf []byte) (cfgMap map[string]any, err error) {
+	cfgMap = map[string]any{}
+	switch ConfigFormat(path) {
+	case "json":
+		err = json.Unmarshal(buf, &cfgMap)
+	case "yaml":
+		err = yaml.Unmarshal(buf, &cfgMap)
+		stringifyYAMLMapKeys(cfgMap)
+	default:
+		err = toml.Unmarshal(buf, &cfgMap)
+		var derr *toml.DecodeError
+		if errors.As(err, &derr) {
+			row, col := derr.Position()
+			err = fmt.Errorf("row %d column %d\n%s", row, col, derr.String())
+		}
+	}
+	return cfgMap, err
+}
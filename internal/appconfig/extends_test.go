@@ -0,0 +1,67 @@
+package appconfig
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	fly "github.com/superfly/fly-go"
+)
+
+func TestLoadConfigExtends(t *testing.T) {
+	cfg, err := LoadConfig("./testdata/extends/app/fly.toml")
+	require.NoError(t, err)
+
+	assert.Equal(t, "foo", cfg.AppName)
+	assert.Equal(t, "ord", cfg.PrimaryRegion)
+	assert.Equal(t, fly.Pointer("SIGTERM"), cfg.KillSignal)
+	// Tables are merged key by key
+	assert.Equal(t, map[string]string{"LOG_LEVEL": "debug", "REGION_HINT": "us"}, cfg.Env)
+	require.NotNil(t, cfg.HTTPService)
+	assert.Equal(t, 3000, cfg.HTTPService.InternalPort)
+	assert.True(t, cfg.HTTPService.ForceHTTPS)
+	// Arrays of tables replace the base ones
+	require.Len(t, cfg.Compute, 1)
+	assert.Equal(t, "performance-1x", cfg.Compute[0].Size)
+	assert.Empty(t, cfg.Compute[0].Memory)
+
+	unknown, err := unknownKeysInFile("./testdata/extends/app/fly.toml")
+	require.NoError(t, err)
+	assert.Empty(t, unknown)
+}
+
+func TestWriteExtendedConfig(t *testing.T) {
+	cfg, err := LoadConfig("./testdata/extends/app/fly.toml")
+	require.NoError(t, err)
+
+	// Writing it back would drop the extends setting and flatten the base
+	err = cfg.WriteToFile(cfg.ConfigFilePath())
+	assert.ErrorContains(t, err, "extends ../base.toml")
+
+	path := filepath.Join(t.TempDir(), "fly.toml")
+	require.NoError(t, cfg.WriteToFile(path))
+}
+
+func TestLoadConfigExtendsLoop(t *testing.T) {
+	_, err := LoadConfig("./testdata/extends/loop-a.toml")
+	require.ErrorContains(t, err, "loops back to")
+}
+
+func TestMergeConfigMaps(t *testing.T) {
+	base := map[string]any{
+		"app":      "base",
+		"env":      map[string]any{"A": "1", "B": "2"},
+		"services": []any{map[string]any{"internal_port": 80}},
+	}
+	local := map[string]any{
+		"app":      "local",
+		"env":      map[string]any{"B": "3"},
+		"services": []any{},
+	}
+	assert.Equal(t, map[string]any{
+		"app":      "local",
+		"env":      map[string]any{"A": "1", "B": "3"},
+		"services": []any{},
+	}, mergeConfigMaps(base, local))
+}
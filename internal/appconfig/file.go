@@ -4,6 +4,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 )
 
 func ResolveConfigFileFromPath(p string) (string, error) {
@@ -23,12 +24,30 @@ func ResolveConfigFileFromPath(p string) (string, error) {
 
 	// Ok, something exists. Is it a file - yes? return the path
 	if pd.IsDir() {
+		for _, name := range ConfigFileNames {
+			if _, err := os.Stat(path.Join(p, name)); err == nil {
+				return path.Join(p, name), nil
+			}
+		}
 		return path.Join(p, DefaultConfigFileName), nil
 	}
 
 	return p, nil
 }
 
+// ConfigFormat returns the format of the config file at p from its extension:
+// "json", "yaml" for .yaml and .yml files, or "toml" for anything else.
+func ConfigFormat(p string) string {
+	switch strings.ToLower(filepath.Ext(p)) {
+	case ".json":
+		return "json"
+	case ".yaml", ".yml":
+		return "yaml"
+	default:
+		return "toml"
+	}
+}
+
 func ConfigFileExistsAtPath(p string) (bool, error) {
 	p, err := ResolveConfigFileFromPath(p)
 	if err != nil {
@@ -0,0 +1,460 @@
+package appconfig
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/samber/lo"
+	fly "github.com/superfly/fly-go"
+	"gopkg.in/yaml.v2"
+)
+
+// composeFile is the part of a Compose file FromCompose translates.
+type composeFile struct {
+	Name     string                     `yaml:"name"`
+	Services map[string]*composeService `yaml:"services"`
+	Extra    map[string]any             `yaml:",inline"`
+}
+
+type composeService struct {
+	Image       string              `yaml:"image"`
+	Build       any                 `yaml:"build"`
+	Command     any                 `yaml:"command"`
+	Ports       []any               `yaml:"ports"`
+	Environment any                 `yaml:"environment"`
+	Volumes     []any               `yaml:"volumes"`
+	Healthcheck *composeHealthcheck `yaml:"healthcheck"`
+	Restart     string              `yaml:"restart"`
+	Extra       map[string]any      `yaml:",inline"`
+}
+
+type composeHealthcheck struct {
+	Test        any    `yaml:"test"`
+	Interval    string `yaml:"interval"`
+	Timeout     string `yaml:"timeout"`
+	StartPeriod string `yaml:"start_period"`
+	Disable     bool   `yaml:"disable"`
+}
+
+// Compose settings with no equivalent that don't matter on Fly.io, e.g.
+// depends_on since all the process groups are deployed together.
+var (
+	composeIgnoredKeys        = []string{"version", "volumes", "networks"}
+	composeServiceIgnoredKeys = []string{"container_name", "depends_on", "links", "networks", "hostname", "expose", "stdin_open", "tty"}
+)
+
+var (
+	composeHealthcheckURL = regexp.MustCompile(`https?://(?:localhost|127\.0\.0\.1|0\.0\.0\.0)(?::(\d+))?(/[^\s'"]*)?`)
+	volumeNameInvalidChar = regexp.MustCompile(`[^a-z0-9_]`)
+)
+
+// FromCompose translates the services of a Compose file into an app config
+// with a process group per service running the app's image. It returns a
+// warning for every setting it can't translate.
+func FromCompose(appName string, buf []byte) (*Config, []string, error) {
+	var compose composeFile
+	if err := yaml.Unmarshal(buf, &compose); err != nil {
+		return nil, nil, err
+	}
+	if len(compose.Services) == 0 {
+		return nil, nil, fmt.Errorf("no services found in the compose file")
+	}
+
+	var warnings []string
+	warnf := func(section, msg string, vals ...any) {
+		warnings = append(warnings, warning(section, msg, vals...))
+	}
+
+	cfg := NewConfig()
+	cfg.SchemaVersion = CurrentSchemaVersion
+	cfg.AppName = appName
+	if cfg.AppName == "" {
+		cfg.AppName = compose.Name
+	}
+
+	for _, key := range sortedKeys(compose.Extra) {
+		if !lo.Contains(composeIgnoredKeys, key) {
+			warnf(key, "top level '%s' isn't translated", key)
+		}
+	}
+
+	names := sortedKeys(compose.Services)
+
+	// A Fly app runs a single image, the one the first service builds or else
+	// the image of the first service
+	source := composeSource(compose.Services[names[0]])
+	for _, name := range names {
+		if compose.Services[name].Build != nil {
+			source = composeSource(compose.Services[name])
+			break
+		}
+	}
+
+	var groups []string
+	for _, name := range names {
+		svc := compose.Services[name]
+		if s := composeSource(svc); s != source {
+			warnf("services."+name, "runs %s while the app runs %s; deploy it as a separate app", s, source)
+			continue
+		}
+		groups = append(groups, name)
+	}
+
+	if svc := compose.Services[groups[0]]; svc.Build != nil {
+		cfg.Build = composeBuild(svc.Build, func(msg string, vals ...any) { warnf("services."+groups[0]+".build", msg, vals...) })
+	} else {
+		cfg.Build = &Build{Image: svc.Image}
+	}
+
+	cfg.Processes = map[string]string{}
+	groupEnv := map[string]map[string]string{}
+	for _, name := range groups {
+		svc := compose.Services[name]
+		section := "services." + name
+		warn := func(msg string, vals ...any) { warnf(section, msg, vals...) }
+
+		cfg.Processes[name] = composeCommand(svc.Command)
+		groupEnv[name] = composeEnvironment(svc.Environment, warn)
+
+		for _, raw := range svc.Ports {
+			if service := composePortService(name, raw, warn); service != nil {
+				cfg.Services = append(cfg.Services, *service)
+			}
+		}
+
+		for _, raw := range svc.Volumes {
+			mount := composeMount(name, raw, warn)
+			if mount == nil {
+				continue
+			}
+			if lo.ContainsBy(cfg.Mounts, func(m Mount) bool { return slices.Equal(m.Processes, mount.Processes) }) {
+				warn("mounts %s on %s, only one volume can be mounted per machine", mount.Source, mount.Destination)
+				continue
+			}
+			cfg.Mounts = append(cfg.Mounts, *mount)
+		}
+
+		if check := composeCheck(name, svc.Healthcheck, warn); check != nil {
+			if cfg.Checks == nil {
+				cfg.Checks = map[string]*ToplevelCheck{}
+			}
+			cfg.Checks[name] = check
+		}
+
+		if restart := composeRestart(name, svc.Restart, warn); restart != nil {
+			cfg.Restart = append(cfg.Restart, *restart)
+		}
+
+		for _, key := range sortedKeys(svc.Extra) {
+			if !lo.Contains(composeServiceIgnoredKeys, key) {
+				warn("'%s' isn't translated", key)
+			}
+		}
+	}
+
+	cfg.Env, cfg.ProcessEnv = splitComposeEnv(groupEnv)
+	return cfg, warnings, nil
+}
+
+// composeSource describes the image a service runs.
+func composeSource(svc *composeService) string {
+	switch build := svc.Build.(type) {
+	case nil:
+		return "image " + svc.Image
+	case map[any]any:
+		context, _ := build["context"].(string)
+		dockerfile, _ := build["dockerfile"].(string)
+		return "the image built from " + path.Join(lo.Ternary(context == "", ".", context), lo.Ternary(dockerfile == "", "Dockerfile", dockerfile))
+	default:
+		return "the image built from " + path.Join(fmt.Sprint(build), "Dockerfile")
+	}
+}
+
+func composeBuild(raw any, warn func(string, ...any)) *Build {
+	build := &Build{}
+	context := "."
+	dockerfile := ""
+
+	switch raw := raw.(type) {
+	case string:
+		context = raw
+	case map[any]any:
+		for k, v := range raw {
+			switch k {
+			case "context":
+				context = fmt.Sprint(v)
+			case "dockerfile":
+				dockerfile = fmt.Sprint(v)
+			case "target":
+				build.DockerBuildTarget = fmt.Sprint(v)
+			case "args":
+				build.Args = composeEnvironment(v, warn)
+			default:
+				warn("'%v' isn't translated", k)
+			}
+		}
+	}
+
+	if context = path.Clean(context); context != "." {
+		warn("builds from %s, fly deploy builds from the directory it runs in", context)
+		build.Dockerfile = path.Join(context, lo.Ternary(dockerfile == "", "Dockerfile", dockerfile))
+	} else {
+		build.Dockerfile = dockerfile
+	}
+	return build
+}
+
+// composeCommand returns a command as set in [processes], quoting the
+// arguments of list commands that need it.
+func composeCommand(raw any) string {
+	switch raw := raw.(type) {
+	case string:
+		return raw
+	case []any:
+		args := lo.Map(raw, func(arg any, _ int) string {
+			s := fmt.Sprint(arg)
+			if s == "" || strings.ContainsAny(s, " \t\n'\"\\$") {
+				return strconv.Quote(s)
+			}
+			return s
+		})
+		return strings.Join(args, " ")
+	default:
+		return ""
+	}
+}
+
+// composeEnvironment reads environment and build args, given as a map or
+// a list of KEY=VALUE.
+func composeEnvironment(raw any, warn func(string, ...any)) map[string]string {
+	env := map[string]string{}
+	set := func(k string, v any, ok bool) {
+		if !ok || v == nil {
+			warn("%s takes its value from the environment of the host; set it with fly secrets set", k)
+			return
+		}
+		value := fmt.Sprint(v)
+		if strings.Contains(value, "${") {
+			warn("%s uses variable substitution, which isn't translated", k)
+		}
+		env[k] = value
+	}
+
+	switch raw := raw.(type) {
+	case map[any]any:
+		keys := lo.Map(lo.Keys(raw), func(k any, _ int) string { return fmt.Sprint(k) })
+		sort.Strings(keys)
+		for _, k := range keys {
+			set(k, raw[k], true)
+		}
+	case []any:
+		for _, item := range raw {
+			k, v, ok := strings.Cut(fmt.Sprint(item), "=")
+			set(k, v, ok)
+		}
+	}
+	return env
+}
+
+// splitComposeEnv keeps the variables set the same way for every group in
+// env, and the others in the [env.<group>] table of their group.
+func splitComposeEnv(groupEnv map[string]map[string]string) (map[string]string, map[string]map[string]string) {
+	env := map[string]string{}
+	processEnv := map[string]map[string]string{}
+
+	for group, vars := range groupEnv {
+		for k, v := range vars {
+			shared := lo.EveryBy(lo.Values(groupEnv), func(other map[string]string) bool {
+				otherValue, ok := other[k]
+				return ok && otherValue == v
+			})
+			if shared {
+				env[k] = v
+				continue
+			}
+			if processEnv[group] == nil {
+				processEnv[group] = map[string]string{}
+			}
+			processEnv[group][k] = v
+		}
+	}
+
+	if len(env) == 0 {
+		env = nil
+	}
+	if len(processEnv) == 0 {
+		processEnv = nil
+	}
+	return env, processEnv
+}
+
+// composePortService translates a published port, e.g. "8080:80/tcp" or
+// its long form, into a service of the group. Ports 80 and 443 are served
+// over HTTP and HTTPS.
+func composePortService(group string, raw any, warn func(string, ...any)) *Service {
+	var target, published, protocol string
+	switch raw := raw.(type) {
+	case map[any]any:
+		target = fmt.Sprint(raw["target"])
+		published = fmt.Sprint(lo.Ternary(raw["published"] == nil, raw["target"], raw["published"]))
+		protocol, _ = raw["protocol"].(string)
+	default:
+		spec := fmt.Sprint(raw)
+		spec, protocol, _ = strings.Cut(spec, "/")
+		parts := strings.Split(spec, ":")
+		target = parts[len(parts)-1]
+		published = target
+		if len(parts) > 1 {
+			// The host IP, if any, comes first
+			published = parts[len(parts)-2]
+		}
+	}
+
+	internalPort, err1 := strconv.Atoi(target)
+	port, err2 := strconv.Atoi(published)
+	if err1 != nil || err2 != nil {
+		warn("port %v isn't translated, only single ports are", raw)
+		return nil
+	}
+
+	service := &Service{
+		Protocol:     lo.Ternary(protocol == "", "tcp", protocol),
+		InternalPort: internalPort,
+		Processes:    []string{group},
+	}
+	if service.Protocol == "tcp" && (port == 80 || port == 443) {
+		service.Ports = []fly.MachinePort{
+			{Port: fly.Pointer(80), Handlers: []string{"http"}},
+			{Port: fly.Pointer(443), Handlers: []string{"tls", "http"}},
+		}
+	} else {
+		service.Ports = []fly.MachinePort{{Port: fly.Pointer(port)}}
+	}
+	return service
+}
+
+// composeMount translates a volume mount, e.g. "data:/var/lib/data" or its
+// long form, into a mount of the group. Bind mounts aren't translated.
+func composeMount(group string, raw any, warn func(string, ...any)) *Mount {
+	var source, target string
+	switch raw := raw.(type) {
+	case map[any]any:
+		if kind, _ := raw["type"].(string); kind != "" && kind != "volume" {
+			warn("%s mount on %v isn't translated, only volumes are", kind, raw["target"])
+			return nil
+		}
+		source, _ = raw["source"].(string)
+		target, _ = raw["target"].(string)
+	default:
+		parts := strings.Split(fmt.Sprint(raw), ":")
+		if len(parts) == 1 {
+			target = parts[0]
+		} else {
+			source, target = parts[0], parts[1]
+		}
+	}
+
+	if strings.HasPrefix(source, ".") || strings.HasPrefix(source, "/") || strings.HasPrefix(source, "~") {
+		warn("bind mount of %s on %s isn't translated; copy the files into the image or use [[files]]", source, target)
+		return nil
+	}
+	if source == "" {
+		source = group + "_data"
+	}
+
+	return &Mount{
+		Source:      volumeNameInvalidChar.ReplaceAllString(strings.ToLower(source), "_"),
+		Destination: target,
+		Processes:   []string{group},
+	}
+}
+
+// composeCheck translates a healthcheck probing a local URL, e.g. with curl
+// or wget, into an HTTP check of the group. Fly.io checks can't run other
+// commands.
+func composeCheck(group string, hc *composeHealthcheck, warn func(string, ...any)) *ToplevelCheck {
+	if hc == nil || hc.Disable {
+		return nil
+	}
+
+	var test string
+	switch raw := hc.Test.(type) {
+	case string:
+		test = raw
+	case []any:
+		args := lo.Map(raw, func(arg any, _ int) string { return fmt.Sprint(arg) })
+		if len(args) > 0 && args[0] == "NONE" {
+			return nil
+		}
+		test = strings.Join(args, " ")
+	}
+
+	match := composeHealthcheckURL.FindStringSubmatch(test)
+	if match == nil {
+		warn("healthcheck %q isn't translated, add an HTTP or TCP check to [checks]", test)
+		return nil
+	}
+
+	port := 80
+	if match[1] != "" {
+		port, _ = strconv.Atoi(match[1])
+	}
+	check := &ToplevelCheck{
+		Type:      fly.Pointer("http"),
+		Port:      fly.Pointer(port),
+		HTTPPath:  fly.Pointer(lo.Ternary(match[2] == "", "/", match[2])),
+		Processes: []string{group},
+	}
+
+	for _, d := range []struct {
+		value string
+		field **fly.Duration
+	}{
+		{hc.Interval, &check.Interval},
+		{hc.Timeout, &check.Timeout},
+		{hc.StartPeriod, &check.GracePeriod},
+	} {
+		if d.value == "" {
+			continue
+		}
+		duration, err := fly.ParseDuration(d.value)
+		if err != nil {
+			warn("healthcheck duration %s isn't translated: %s", d.value, err)
+			continue
+		}
+		*d.field = duration
+	}
+	return check
+}
+
+func composeRestart(group, policy string, warn func(string, ...any)) *Restart {
+	restart := &Restart{Processes: []string{group}}
+	policy, retries, _ := strings.Cut(policy, ":")
+
+	switch policy {
+	case "":
+		return nil
+	case "always", "unless-stopped":
+		restart.Policy = RestartPolicyAlways
+	case "no":
+		restart.Policy = RestartPolicyNever
+	case "on-failure":
+		restart.Policy = RestartPolicyOnFailure
+		restart.MaxRetries, _ = strconv.Atoi(retries)
+	default:
+		warn("restart policy %s isn't translated", policy)
+		return nil
+	}
+	return restart
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := lo.Keys(m)
+	sort.Strings(keys)
+	return keys
+}
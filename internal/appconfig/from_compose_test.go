@@ -0,0 +1,107 @@
+package appconfig
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	fly "github.com/superfly/fly-go"
+)
+
+func TestFromCompose(t *testing.T) {
+	buf, err := os.ReadFile("./testdata/compose/docker-compose.yml")
+	require.NoError(t, err)
+
+	cfg, warnings, err := FromCompose("", buf)
+	require.NoError(t, err)
+
+	assert.Equal(t, "shop", cfg.AppName)
+	assert.Equal(t, &Build{}, cfg.Build)
+	assert.Equal(t, map[string]string{
+		"web":    `bundle exec puma -C config/puma.rb`,
+		"worker": "bundle exec sidekiq",
+	}, cfg.Processes)
+	assert.Equal(t, map[string]string{"RAILS_ENV": "production"}, cfg.Env)
+	assert.Equal(t, map[string]map[string]string{
+		"web":    {"PORT": "3000"},
+		"worker": {"QUEUES": "default,mailers"},
+	}, cfg.ProcessEnv)
+
+	assert.Equal(t, []Service{
+		{
+			Protocol:     "tcp",
+			InternalPort: 3000,
+			Ports:        []fly.MachinePort{{Port: fly.Pointer(8080)}},
+			Processes:    []string{"web"},
+		},
+		{
+			Protocol:     "tcp",
+			InternalPort: 3000,
+			Ports: []fly.MachinePort{
+				{Port: fly.Pointer(80), Handlers: []string{"http"}},
+				{Port: fly.Pointer(443), Handlers: []string{"tls", "http"}},
+			},
+			Processes: []string{"web"},
+		},
+	}, cfg.Services)
+
+	assert.Equal(t, []Mount{{Source: "uploads", Destination: "/app/uploads", Processes: []string{"web"}}}, cfg.Mounts)
+
+	assert.Equal(t, map[string]*ToplevelCheck{
+		"web": {
+			Type:        fly.Pointer("http"),
+			Port:        fly.Pointer(3000),
+			HTTPPath:    fly.Pointer("/up"),
+			Interval:    fly.MustParseDuration("30s"),
+			Timeout:     fly.MustParseDuration("5s"),
+			GracePeriod: fly.MustParseDuration("1m"),
+			Processes:   []string{"web"},
+		},
+	}, cfg.Checks)
+
+	assert.Equal(t, []Restart{
+		{Policy: RestartPolicyAlways, Processes: []string{"web"}},
+		{Policy: RestartPolicyOnFailure, MaxRetries: 3, Processes: []string{"worker"}},
+	}, cfg.Restart)
+
+	assert.Equal(t, []string{
+		warning("secrets", "top level 'secrets' isn't translated"),
+		warning("services.db", "runs image postgres:16 while the app runs the image built from Dockerfile; deploy it as a separate app"),
+		warning("services.web", "SECRET_KEY_BASE takes its value from the environment of the host; set it with fly secrets set"),
+		warning("services.web", "bind mount of ./config on /app/config isn't translated; copy the files into the image or use [[files]]"),
+		warning("services.worker", `healthcheck "pgrep sidekiq" isn't translated, add an HTTP or TCP check to [checks]`),
+		warning("services.worker", "'logging' isn't translated"),
+	}, warnings)
+
+	require.NoError(t, cfg.SetMachinesPlatform())
+	err, x := cfg.Validate(_getValidationContext(t))
+	require.NoError(t, err, x)
+}
+
+func TestFromComposeImage(t *testing.T) {
+	cfg, warnings, err := FromCompose("my-app", []byte(`
+services:
+  app:
+    image: nginx:1.27
+    ports: ["80"]
+    volumes:
+      - type: volume
+        source: html-data
+        target: /usr/share/nginx/html
+`))
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	assert.Equal(t, "my-app", cfg.AppName)
+	assert.Equal(t, &Build{Image: "nginx:1.27"}, cfg.Build)
+	assert.Equal(t, map[string]string{"app": ""}, cfg.Processes)
+	assert.Equal(t, []Mount{{Source: "html_data", Destination: "/usr/share/nginx/html", Processes: []string{"app"}}}, cfg.Mounts)
+	assert.Nil(t, cfg.Env)
+	assert.Nil(t, cfg.ProcessEnv)
+}
+
+func TestFromComposeNoServices(t *testing.T) {
+	_, _, err := FromCompose("", []byte("version: '3'\n"))
+	assert.Error(t, err)
+}
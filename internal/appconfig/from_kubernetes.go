@@ -0,0 +1,416 @@
+package appconfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/docker/go-units"
+	"github.com/samber/lo"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/flyctl/helpers"
+	"gopkg.in/yaml.v2"
+)
+
+// k8sObject is the part of a Kubernetes manifest FromKubernetes translates:
+// the spec of Deployments and StatefulSets and of Services, and the data of
+// ConfigMaps.
+type k8sObject struct {
+	Kind     string `yaml:"kind"`
+	Metadata struct {
+		Name string `yaml:"name"`
+	} `yaml:"metadata"`
+	Spec struct {
+		// Deployment and StatefulSet
+		Replicas *int `yaml:"replicas"`
+		Template struct {
+			Metadata struct {
+				Labels map[string]string `yaml:"labels"`
+			} `yaml:"metadata"`
+			Spec k8sPodSpec `yaml:"spec"`
+		} `yaml:"template"`
+
+		// Service
+		Type     string            `yaml:"type"`
+		Selector map[string]string `yaml:"selector"`
+		Ports    []k8sServicePort  `yaml:"ports"`
+	} `yaml:"spec"`
+	Data map[string]string `yaml:"data"`
+}
+
+type k8sPodSpec struct {
+	Containers     []k8sContainer `yaml:"containers"`
+	InitContainers []any          `yaml:"initContainers"`
+	Volumes        []k8sVolume    `yaml:"volumes"`
+}
+
+type k8sVolume struct {
+	Name                  string `yaml:"name"`
+	PersistentVolumeClaim *struct {
+		ClaimName string `yaml:"claimName"`
+	} `yaml:"persistentVolumeClaim"`
+}
+
+type k8sContainer struct {
+	Name    string   `yaml:"name"`
+	Image   string   `yaml:"image"`
+	Command []string `yaml:"command"`
+	Args    []string `yaml:"args"`
+	Env     []struct {
+		Name      string  `yaml:"name"`
+		Value     *string `yaml:"value"`
+		ValueFrom *struct {
+			ConfigMapKeyRef *k8sKeyRef `yaml:"configMapKeyRef"`
+			SecretKeyRef    *k8sKeyRef `yaml:"secretKeyRef"`
+		} `yaml:"valueFrom"`
+	} `yaml:"env"`
+	EnvFrom []struct {
+		ConfigMapRef *k8sKeyRef `yaml:"configMapRef"`
+		SecretRef    *k8sKeyRef `yaml:"secretRef"`
+	} `yaml:"envFrom"`
+	Ports []struct {
+		Name          string `yaml:"name"`
+		ContainerPort int    `yaml:"containerPort"`
+	} `yaml:"ports"`
+	Resources struct {
+		Limits   map[string]string `yaml:"limits"`
+		Requests map[string]string `yaml:"requests"`
+	} `yaml:"resources"`
+	ReadinessProbe *k8sProbe `yaml:"readinessProbe"`
+	LivenessProbe  *k8sProbe `yaml:"livenessProbe"`
+	VolumeMounts   []struct {
+		Name      string `yaml:"name"`
+		MountPath string `yaml:"mountPath"`
+	} `yaml:"volumeMounts"`
+}
+
+type k8sKeyRef struct {
+	Name string `yaml:"name"`
+	Key  string `yaml:"key"`
+}
+
+type k8sServicePort struct {
+	Port       int    `yaml:"port"`
+	TargetPort any    `yaml:"targetPort"`
+	Protocol   string `yaml:"protocol"`
+}
+
+type k8sProbe struct {
+	HTTPGet *struct {
+		Path string `yaml:"path"`
+		Port any    `yaml:"port"`
+	} `yaml:"httpGet"`
+	TCPSocket *struct {
+		Port any `yaml:"port"`
+	} `yaml:"tcpSocket"`
+	Exec                any `yaml:"exec"`
+	InitialDelaySeconds int `yaml:"initialDelaySeconds"`
+	PeriodSeconds       int `yaml:"periodSeconds"`
+	TimeoutSeconds      int `yaml:"timeoutSeconds"`
+}
+
+// Kinds with no equivalent that don't matter on Fly.io, e.g. Ingress since
+// the services of the app are reachable from the Fly.io proxy.
+var k8sIgnoredKinds = []string{"Namespace", "Ingress", "HorizontalPodAutoscaler", "PersistentVolumeClaim", "ServiceAccount", "PodDisruptionBudget", "NetworkPolicy"}
+
+// FromKubernetes translates the Deployments and StatefulSets of Kubernetes
+// manifests into an app config with a process group per workload running the
+// app's image, their Services into services and ConfigMaps into env. It also
+// returns the number of machines of each group, from its replicas, and a
+// warning for every setting it can't translate.
+func FromKubernetes(appName string, manifests ...[]byte) (*Config, map[string]int, []string, error) {
+	var (
+		workloads  []*k8sObject
+		services   []*k8sObject
+		configMaps = map[string]map[string]string{}
+		warnings   []string
+	)
+	warnf := func(section, msg string, vals ...any) {
+		warnings = append(warnings, warning(section, msg, vals...))
+	}
+
+	for _, manifest := range manifests {
+		decoder := yaml.NewDecoder(bytes.NewReader(manifest))
+		for {
+			var obj k8sObject
+			err := decoder.Decode(&obj)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, nil, nil, err
+			}
+
+			section := obj.Kind + "/" + obj.Metadata.Name
+			switch obj.Kind {
+			case "":
+			case "Deployment", "StatefulSet":
+				workloads = append(workloads, &obj)
+			case "Service":
+				services = append(services, &obj)
+			case "ConfigMap":
+				configMaps[obj.Metadata.Name] = obj.Data
+			case "Secret":
+				warnf(section, "isn't translated; set its values with fly secrets set")
+			default:
+				if !lo.Contains(k8sIgnoredKinds, obj.Kind) {
+					warnf(section, "isn't translated")
+				}
+			}
+		}
+	}
+	if len(workloads) == 0 {
+		return nil, nil, nil, fmt.Errorf("no Deployment or StatefulSet found in the manifests")
+	}
+
+	cfg := NewConfig()
+	cfg.SchemaVersion = CurrentSchemaVersion
+	cfg.AppName = appName
+	cfg.Processes = map[string]string{}
+	counts := map[string]int{}
+	groupEnv := map[string]map[string]string{}
+	// A Fly app runs a single image, the one of the first workload
+	var image string
+
+	for _, w := range workloads {
+		section := w.Kind + "/" + w.Metadata.Name
+		warn := func(msg string, vals ...any) { warnf(section, msg, vals...) }
+
+		pod := w.Spec.Template.Spec
+		if len(pod.Containers) == 0 {
+			warn("has no containers")
+			continue
+		}
+		container := pod.Containers[0]
+		if image == "" {
+			image = container.Image
+		}
+		if container.Image != image {
+			warn("runs image %s while the app runs %s; deploy it as a separate app", container.Image, image)
+			continue
+		}
+		for _, sidecar := range pod.Containers[1:] {
+			warn("container %s isn't translated, only the first container of a pod is", sidecar.Name)
+		}
+		if len(pod.InitContainers) > 0 {
+			warn("init containers aren't translated; run them with [deploy] release_command or in the entrypoint")
+		}
+
+		group := w.Metadata.Name
+		cfg.Processes[group] = composeCommand(lo.ToAnySlice(append(container.Command, container.Args...)))
+		counts[group] = 1
+		if w.Spec.Replicas != nil {
+			counts[group] = *w.Spec.Replicas
+		}
+		groupEnv[group] = k8sEnv(container, configMaps, warn)
+
+		if compute := k8sCompute(group, container, warn); compute != nil {
+			cfg.Compute = append(cfg.Compute, compute)
+		}
+		if check := k8sCheck(group, container, warn); check != nil {
+			if cfg.Checks == nil {
+				cfg.Checks = map[string]*ToplevelCheck{}
+			}
+			cfg.Checks[group] = check
+		}
+
+		for _, vm := range container.VolumeMounts {
+			volume, ok := lo.Find(pod.Volumes, func(v k8sVolume) bool { return v.Name == vm.Name })
+			if !ok || volume.PersistentVolumeClaim == nil {
+				warn("volume %s on %s isn't translated, only persistent volume claims are", vm.Name, vm.MountPath)
+				continue
+			}
+			mount := Mount{
+				Source:      volumeNameInvalidChar.ReplaceAllString(strings.ToLower(volume.PersistentVolumeClaim.ClaimName), "_"),
+				Destination: vm.MountPath,
+				Processes:   []string{group},
+			}
+			if lo.ContainsBy(cfg.Mounts, func(m Mount) bool { return slices.Equal(m.Processes, mount.Processes) }) {
+				warn("mounts %s on %s, only one volume can be mounted per machine", mount.Source, mount.Destination)
+				continue
+			}
+			cfg.Mounts = append(cfg.Mounts, mount)
+		}
+
+		labels := w.Spec.Template.Metadata.Labels
+		for _, svc := range services {
+			selected := len(svc.Spec.Selector) > 0 && lo.EveryBy(lo.Entries(svc.Spec.Selector), func(e lo.Entry[string, string]) bool {
+				return labels[e.Key] == e.Value
+			})
+			if !selected {
+				continue
+			}
+			svcWarn := func(msg string, vals ...any) { warnf("Service/"+svc.Metadata.Name, msg, vals...) }
+			for _, port := range svc.Spec.Ports {
+				target, ok := k8sContainerPort(container, lo.Ternary(port.TargetPort == nil, any(port.Port), port.TargetPort))
+				if !ok {
+					svcWarn("target port %v isn't a port of %s", port.TargetPort, section)
+					continue
+				}
+				spec := fmt.Sprintf("%d:%d/%s", port.Port, target, strings.ToLower(lo.Ternary(port.Protocol == "", "TCP", port.Protocol)))
+				if service := composePortService(group, spec, svcWarn); service != nil {
+					cfg.Services = append(cfg.Services, *service)
+				}
+			}
+		}
+	}
+
+	cfg.Build = &Build{Image: image}
+	cfg.Env, cfg.ProcessEnv = splitComposeEnv(groupEnv)
+	return cfg, counts, warnings, nil
+}
+
+// k8sEnv reads the env of a container, taking the values referencing
+// ConfigMaps from configMaps.
+func k8sEnv(container k8sContainer, configMaps map[string]map[string]string, warn func(string, ...any)) map[string]string {
+	env := map[string]string{}
+	configMap := func(name string) map[string]string {
+		data, ok := configMaps[name]
+		if !ok {
+			warn("ConfigMap %s isn't in the manifests", name)
+		}
+		return data
+	}
+
+	for _, from := range container.EnvFrom {
+		switch {
+		case from.ConfigMapRef != nil:
+			for k, v := range configMap(from.ConfigMapRef.Name) {
+				env[k] = v
+			}
+		case from.SecretRef != nil:
+			warn("env from Secret %s isn't translated; set its values with fly secrets set", from.SecretRef.Name)
+		}
+	}
+
+	for _, e := range container.Env {
+		switch {
+		case e.Value != nil:
+			env[e.Name] = *e.Value
+		case e.ValueFrom != nil && e.ValueFrom.ConfigMapKeyRef != nil:
+			ref := e.ValueFrom.ConfigMapKeyRef
+			if v, ok := configMap(ref.Name)[ref.Key]; ok {
+				env[e.Name] = v
+			}
+		case e.ValueFrom != nil && e.ValueFrom.SecretKeyRef != nil:
+			warn("%s comes from Secret %s; set it with fly secrets set", e.Name, e.ValueFrom.SecretKeyRef.Name)
+		default:
+			warn("%s isn't translated, only values and ConfigMap keys are", e.Name)
+		}
+	}
+	return env
+}
+
+// k8sCompute translates the resource limits of a container, or else its
+// requests, into the VM of the group, rounding the CPUs and the memory up to
+// a size machines can have.
+func k8sCompute(group string, container k8sContainer, warn func(string, ...any)) *Compute {
+	resources := container.Resources.Limits
+	if len(resources) == 0 {
+		resources = container.Resources.Requests
+	}
+	if len(resources) == 0 {
+		return nil
+	}
+
+	compute := &Compute{Processes: []string{group}}
+	if cpu, ok := resources["cpu"]; ok {
+		var cpus float64
+		var err error
+		if millis, ok := strings.CutSuffix(cpu, "m"); ok {
+			cpus, err = strconv.ParseFloat(millis, 64)
+			cpus /= 1000
+		} else {
+			cpus, err = strconv.ParseFloat(cpu, 64)
+		}
+		if err != nil {
+			warn("cpu %s isn't translated: %s", cpu, err)
+		} else {
+			compute.MachineGuest = &fly.MachineGuest{CPUs: int(math.Ceil(cpus))}
+		}
+	}
+	if memory, ok := resources["memory"]; ok {
+		mb, err := helpers.ParseSize(memory, units.RAMInBytes, units.MiB)
+		if err != nil {
+			warn("memory %s isn't translated: %s", memory, err)
+		} else {
+			compute.Memory = fmt.Sprintf("%dmb", (mb+255)/256*256)
+		}
+	}
+	if compute.MachineGuest == nil && compute.Memory == "" {
+		return nil
+	}
+	return compute
+}
+
+// k8sCheck translates the readiness probe of a container, or else its
+// liveness probe, into a check of the group.
+func k8sCheck(group string, container k8sContainer, warn func(string, ...any)) *ToplevelCheck {
+	probe := container.ReadinessProbe
+	if probe == nil {
+		probe = container.LivenessProbe
+	}
+	if probe == nil {
+		return nil
+	}
+
+	check := &ToplevelCheck{Processes: []string{group}}
+	var port any
+	switch {
+	case probe.HTTPGet != nil:
+		check.Type = fly.Pointer("http")
+		check.HTTPPath = fly.Pointer(lo.Ternary(probe.HTTPGet.Path == "", "/", probe.HTTPGet.Path))
+		port = probe.HTTPGet.Port
+	case probe.TCPSocket != nil:
+		check.Type = fly.Pointer("tcp")
+		port = probe.TCPSocket.Port
+	default:
+		warn("probe isn't translated, only HTTP and TCP probes are; add a check to [checks]")
+		return nil
+	}
+
+	p, ok := k8sContainerPort(container, port)
+	if !ok {
+		warn("probe port %v isn't a port of container %s", port, container.Name)
+		return nil
+	}
+	check.Port = fly.Pointer(p)
+
+	for _, d := range []struct {
+		seconds int
+		field   **fly.Duration
+	}{
+		{probe.PeriodSeconds, &check.Interval},
+		{probe.TimeoutSeconds, &check.Timeout},
+		{probe.InitialDelaySeconds, &check.GracePeriod},
+	} {
+		if d.seconds > 0 {
+			*d.field = fly.MustParseDuration(fmt.Sprintf("%ds", d.seconds))
+		}
+	}
+	return check
+}
+
+// k8sContainerPort resolves a port given by number or by the name of one of
+// the ports of container.
+func k8sContainerPort(container k8sContainer, port any) (int, bool) {
+	switch port := port.(type) {
+	case int:
+		return port, true
+	case string:
+		if n, err := strconv.Atoi(port); err == nil {
+			return n, true
+		}
+		for _, p := range container.Ports {
+			if p.Name == port {
+				return p.ContainerPort, true
+			}
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,90 @@
+package appconfig
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	fly "github.com/superfly/fly-go"
+)
+
+func TestFromKubernetes(t *testing.T) {
+	deployment, err := os.ReadFile("./testdata/kubernetes/deployment.yaml")
+	require.NoError(t, err)
+	service, err := os.ReadFile("./testdata/kubernetes/service.yaml")
+	require.NoError(t, err)
+
+	cfg, counts, warnings, err := FromKubernetes("shop", deployment, service)
+	require.NoError(t, err)
+
+	assert.Equal(t, "shop", cfg.AppName)
+	assert.Equal(t, &Build{Image: "registry.example.com/shop:1.4.2"}, cfg.Build)
+	assert.Equal(t, map[string]string{
+		"web":    "bundle exec puma -C config/puma.rb",
+		"worker": "bundle exec sidekiq",
+	}, cfg.Processes)
+	assert.Equal(t, map[string]int{"web": 3, "worker": 1}, counts)
+	assert.Equal(t, map[string]string{"RAILS_ENV": "production", "LOG_LEVEL": "info"}, cfg.Env)
+	assert.Equal(t, map[string]map[string]string{
+		"web":    {"PORT": "3000"},
+		"worker": {"QUEUES": "default,mailers"},
+	}, cfg.ProcessEnv)
+
+	assert.Equal(t, []Service{
+		{
+			Protocol:     "tcp",
+			InternalPort: 3000,
+			Ports: []fly.MachinePort{
+				{Port: fly.Pointer(80), Handlers: []string{"http"}},
+				{Port: fly.Pointer(443), Handlers: []string{"tls", "http"}},
+			},
+			Processes: []string{"web"},
+		},
+		{
+			Protocol:     "tcp",
+			InternalPort: 9394,
+			Ports:        []fly.MachinePort{{Port: fly.Pointer(9394)}},
+			Processes:    []string{"web"},
+		},
+	}, cfg.Services)
+
+	assert.Equal(t, []*Compute{{
+		Memory:       "768mb",
+		MachineGuest: &fly.MachineGuest{CPUs: 2},
+		Processes:    []string{"web"},
+	}}, cfg.Compute)
+
+	assert.Equal(t, []Mount{{Source: "shop_uploads", Destination: "/app/uploads", Processes: []string{"web"}}}, cfg.Mounts)
+
+	assert.Equal(t, map[string]*ToplevelCheck{
+		"web": {
+			Type:        fly.Pointer("http"),
+			Port:        fly.Pointer(3000),
+			HTTPPath:    fly.Pointer("/up"),
+			Interval:    fly.MustParseDuration("15s"),
+			Timeout:     fly.MustParseDuration("2s"),
+			GracePeriod: fly.MustParseDuration("10s"),
+			Processes:   []string{"web"},
+		},
+	}, cfg.Checks)
+
+	assert.Equal(t, []string{
+		warning("Secret/shop-secrets", "isn't translated; set its values with fly secrets set"),
+		warning("CronJob/cleanup", "isn't translated"),
+		warning("Deployment/web", "container log-shipper isn't translated, only the first container of a pod is"),
+		warning("Deployment/web", "SECRET_KEY_BASE comes from Secret shop-secrets; set it with fly secrets set"),
+		warning("Deployment/web", "volume tmp on /tmp isn't translated, only persistent volume claims are"),
+		warning("Deployment/worker", "probe isn't translated, only HTTP and TCP probes are; add a check to [checks]"),
+		warning("StatefulSet/redis", "runs image redis:7 while the app runs registry.example.com/shop:1.4.2; deploy it as a separate app"),
+	}, warnings)
+
+	require.NoError(t, cfg.SetMachinesPlatform())
+	err, x := cfg.Validate(_getValidationContext(t))
+	require.NoError(t, err, x)
+}
+
+func TestFromKubernetesNoWorkloads(t *testing.T) {
+	_, _, _, err := FromKubernetes("", []byte("apiVersion: v1\nkind: Service\nmetadata:\n  name: web\n"))
+	assert.Error(t, err)
+}
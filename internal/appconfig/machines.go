@@ -2,6 +2,7 @@ package appconfig
 
 import (
 	"fmt"
+	"reflect"
 
 	"github.com/docker/go-units"
 	"github.com/google/shlex"
@@ -344,6 +345,9 @@ func (c *Config) updateMachineConfig(src *fly.MachineConfig) (*fly.MachineConfig
 	mConfig.Files = nil
 	fly.MergeFiles(mConfig, c.MergedFiles)
 
+	// Secrets
+	c.tomachineSetSecrets(mConfig)
+
 	// Guest
 	if guest, err := c.toMachineGuest(); err != nil {
 		return nil, err
@@ -395,6 +399,39 @@ func (c *Config) tomachineSetStopConfig(mConfig *fly.MachineConfig) error {
 	return nil
 }
 
+// tomachineSetSecrets restricts the processes of the machine to the secrets
+// named by the [[secrets]] of its group, instead of all the secrets of the
+// app, once the app has any [[secrets]]. A group none of them lists gets no
+// secrets.
+func (c *Config) tomachineSetSecrets(mConfig *fly.MachineConfig) {
+	restricted := c.restrictSecrets || len(c.Secrets) > 0
+
+	var secrets []fly.MachineSecret
+	for _, s := range c.Secrets {
+		for _, name := range s.Names {
+			if !lo.ContainsBy(secrets, func(x fly.MachineSecret) bool { return x.EnvVar == name }) {
+				secrets = append(secrets, fly.MachineSecret{EnvVar: name, Name: name})
+			}
+		}
+	}
+
+	if restricted && len(mConfig.Processes) == 0 {
+		mConfig.Processes = []fly.MachineProcess{{}}
+	}
+	for i := range mConfig.Processes {
+		mConfig.Processes[i].Secrets = secrets
+		mConfig.Processes[i].IgnoreAppSecrets = restricted
+	}
+
+	// Drop the process added for the secrets once they aren't restricted anymore
+	mConfig.Processes = lo.Reject(mConfig.Processes, func(p fly.MachineProcess, _ int) bool {
+		return reflect.DeepEqual(p, fly.MachineProcess{})
+	})
+	if len(mConfig.Processes) == 0 {
+		mConfig.Processes = nil
+	}
+}
+
 func (c *Config) toMachineGuest() (*fly.MachineGuest, error) {
 	// XXX: Don't be extra smart here, keep it backwards compatible with apps that don't have a [[compute]] section.
 	// Think about apps that counts on `fly deploy` to respect whatever was set by `fly scale` or the --vm-* family flags.
@@ -826,3 +826,123 @@ func TestToMachineConfig_hostdedicationid(t *testing.T) {
 		})
 	}
 }
+
+func TestToMachineConfig_processEnv(t *testing.T) {
+	cfg, err := LoadConfig("./testdata/tomachine-processenv.toml")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"LOG_LEVEL": "info", "QUEUE": "default"}, cfg.Env)
+	assert.Equal(t, map[string]map[string]string{"worker": {"QUEUE": "jobs", "CONCURRENCY": "4"}}, cfg.ProcessEnv)
+
+	got, err := cfg.ToMachineConfig("web", nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"LOG_LEVEL":         "info",
+		"QUEUE":             "default",
+		"FLY_PROCESS_GROUP": "web",
+		"PRIMARY_REGION":    "ord",
+	}, got.Env)
+
+	got, err = cfg.ToMachineConfig("worker", nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"LOG_LEVEL":         "info",
+		"QUEUE":             "jobs",
+		"CONCURRENCY":       "4",
+		"FLY_PROCESS_GROUP": "worker",
+		"PRIMARY_REGION":    "ord",
+	}, got.Env)
+
+	// The global env must not be changed by the worker's
+	assert.Equal(t, "default", cfg.Env["QUEUE"])
+}
+
+func TestToMachineConfig_restartTable(t *testing.T) {
+	cfg, err := LoadConfig("./testdata/tomachine-restart.toml")
+	require.NoError(t, err)
+	assert.Equal(t, []Restart{{Policy: RestartPolicyOnFailure, MaxRetries: 5, Processes: []string{"worker"}}}, cfg.Restart)
+
+	got, err := cfg.ToMachineConfig("worker", nil)
+	require.NoError(t, err)
+	assert.Equal(t, &fly.MachineRestart{Policy: fly.MachineRestartPolicyOnFailure, MaxRetries: 5}, got.Restart)
+
+	got, err = cfg.ToMachineConfig("web", nil)
+	require.NoError(t, err)
+	assert.Nil(t, got.Restart)
+
+	// The Machines API name of the never policy
+	cfg, err = unmarshalTOML([]byte("app = \"foo\"\n[restart]\npolicy = \"no\"\nretries = 2\n"))
+	require.NoError(t, err)
+	got, err = cfg.ToMachineConfig("", nil)
+	require.NoError(t, err)
+	assert.Equal(t, &fly.MachineRestart{Policy: fly.MachineRestartPolicyNo, MaxRetries: 2}, got.Restart)
+}
+
+func TestToMachineConfig_secrets(t *testing.T) {
+	cfg, err := LoadConfig("./testdata/tomachine-secrets.toml")
+	require.NoError(t, err)
+
+	got, err := cfg.ToMachineConfig("worker", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []fly.MachineProcess{{
+		Secrets: []fly.MachineSecret{
+			{EnvVar: "AWS_ACCESS_KEY_ID", Name: "AWS_ACCESS_KEY_ID"},
+			{EnvVar: "AWS_SECRET_ACCESS_KEY", Name: "AWS_SECRET_ACCESS_KEY"},
+			{EnvVar: "DATABASE_URL", Name: "DATABASE_URL"},
+		},
+		IgnoreAppSecrets: true,
+	}}, got.Processes)
+
+	// The secrets of existing processes are replaced, other settings are kept
+	src := &fly.MachineConfig{Processes: []fly.MachineProcess{{
+		CmdOverride:      []string{"run", "web"},
+		Secrets:          []fly.MachineSecret{{EnvVar: "AWS_ACCESS_KEY_ID"}},
+		IgnoreAppSecrets: true,
+	}}}
+	got, err = cfg.ToMachineConfig("web", src)
+	require.NoError(t, err)
+	assert.Equal(t, []fly.MachineProcess{{
+		CmdOverride:      []string{"run", "web"},
+		Secrets:          []fly.MachineSecret{{EnvVar: "DATABASE_URL", Name: "DATABASE_URL"}},
+		IgnoreAppSecrets: true,
+	}}, got.Processes)
+
+	// Groups no [[secrets]] lists get no secrets
+	got, err = cfg.ToMachineConfig("cron", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []fly.MachineProcess{{IgnoreAppSecrets: true}}, got.Processes)
+
+	// Without [[secrets]] the machines get all the secrets of the app again
+	cfg.Secrets = nil
+	got, err = cfg.ToMachineConfig("worker", &fly.MachineConfig{Processes: []fly.MachineProcess{{
+		Secrets:          []fly.MachineSecret{{EnvVar: "DATABASE_URL", Name: "DATABASE_URL"}},
+		IgnoreAppSecrets: true,
+	}}})
+	require.NoError(t, err)
+	assert.Nil(t, got.Processes)
+}
+
+func TestToMachineConfig_tlsOptions(t *testing.T) {
+	cfg, err := LoadConfig("./testdata/tomachine-tls-options.toml")
+	require.NoError(t, err)
+
+	got, err := cfg.ToMachineConfig("", nil)
+	require.NoError(t, err)
+	require.Len(t, got.Services, 2)
+
+	// [http_service] applies its policy to the 443 port only
+	httpPorts := got.Services[0].Ports
+	assert.Nil(t, httpPorts[0].TLSOptions)
+	assert.Nil(t, httpPorts[0].HTTPOptions)
+	assert.Equal(t, &fly.TLSOptions{ALPN: []string{"h2"}, Versions: []string{"TLSv1.3"}}, httpPorts[1].TLSOptions)
+	assert.Equal(t, map[string]any{
+		"Strict-Transport-Security": "max-age=31536000; includeSubDomains",
+	}, httpPorts[1].HTTPOptions.Response.Headers)
+
+	// The tls_options of a port take precedence
+	ports := got.Services[1].Ports
+	assert.Equal(t, &fly.TLSOptions{ALPN: []string{"grpc"}, Versions: []string{"TLSv1.2", "TLSv1.3"}}, ports[0].TLSOptions)
+	assert.Nil(t, ports[1].TLSOptions)
+
+	// The ports of the config are left untouched
+	assert.Equal(t, &fly.TLSOptions{ALPN: []string{"grpc"}}, cfg.Services[0].Ports[0].TLSOptions)
+}
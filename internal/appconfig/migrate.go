@@ -0,0 +1,195 @@
+package appconfig
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/superfly/flyctl/iostreams"
+)
+
+// CurrentSchemaVersion is the version of the config file schema. Files with
+// an older or no schema_version may use deprecated forms of settings, which
+// are rewritten on every load until the file is migrated.
+const CurrentSchemaVersion = 1
+
+// Migration is a config file rewritten to the current schema.
+type Migration struct {
+	Path string
+	// Deprecated describes every setting of the file in a deprecated form.
+	Deprecated []string
+	Original   []byte
+	Migrated   []byte
+}
+
+// MigrateConfigFile rewrites the config file at path to the current schema,
+// without writing it back.
+func MigrateConfigFile(path string) (*Migration, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfgMap, err := decodeConfigMap(path, buf)
+	if err != nil {
+		return nil, err
+	}
+	if base, ok := cfgMap[extendsKey]; ok {
+		return nil, fmt.Errorf("%s extends %v, migrate each file on its own", path, base)
+	}
+	deprecated := deprecatedForms(cfgMap)
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.v2UnmarshalError != nil {
+		return nil, cfg.v2UnmarshalError
+	}
+	if cfg.SchemaVersion > CurrentSchemaVersion {
+		return nil, fmt.Errorf("%s has schema_version %d, newer than the version %d this flyctl supports; update flyctl", path, cfg.SchemaVersion, CurrentSchemaVersion)
+	}
+	cfg.SchemaVersion = CurrentSchemaVersion
+
+	migrated := bytes.NewBuffer(leadingComments(buf))
+	switch ConfigFormat(path) {
+	case "json", "yaml":
+		var b []byte
+		if ConfigFormat(path) == "json" {
+			b, err = json.MarshalIndent(cfg, "", "  ")
+		} else {
+			b, err = cfg.MarshalAsYAML()
+		}
+		if err == nil {
+			_, err = prettyPrintJSONandYAML(migrated, b)
+		}
+	default:
+		var b []byte
+		if b, err = cfg.marshalTOML(); err == nil {
+			migrated.Write(b)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migration{
+		Path:       path,
+		Deprecated: deprecated,
+		Original:   buf,
+		Migrated:   migrated.Bytes(),
+	}, nil
+}
+
+// Changed reports whether migrating changes the file.
+func (m *Migration) Changed() bool {
+	return !bytes.Equal(m.Original, m.Migrated)
+}
+
+// Diff returns the changes to the file, colored for the terminal.
+func (m *Migration) Diff(colorize *iostreams.ColorScheme) string {
+	return prettyDiff(string(m.Original), string(m.Migrated), colorize)
+}
+
+// leadingComments returns the comment lines at the top of a TOML or YAML
+// file, like the header flyctl writes, to keep them when migrating it.
+func leadingComments(buf []byte) []byte {
+	var out bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "#") && strings.TrimSpace(line) != "" {
+			break
+		}
+		out.WriteString(line + "\n")
+	}
+	return out.Bytes()
+}
+
+// deprecatedForms returns a description of every setting of the decoded,
+// not yet patched, cfgMap that the patches rewrite from a deprecated form.
+func deprecatedForms(cfgMap map[string]any) []string {
+	var found []string
+	add := func(format string, a ...any) {
+		found = append(found, fmt.Sprintf(format, a...))
+	}
+	isTable := func(key string) bool {
+		_, ok := cfgMap[key].(map[string]any)
+		return ok
+	}
+	isArray := func(key string) bool {
+		_, ok := cfgMap[key].([]any)
+		return ok
+	}
+	isNumber := func(v any) bool {
+		return v != nil && !isString(v)
+	}
+
+	if experimental, ok := cfgMap["experimental"].(map[string]any); ok {
+		if _, ok := experimental["kill_timeout"]; ok {
+			add("experimental.kill_timeout moves to kill_timeout")
+		}
+		for _, k := range []string{"metrics_port", "metrics_path"} {
+			if _, ok := experimental[k]; ok {
+				add("experimental.%s moves to [[metrics]]", k)
+			}
+		}
+	}
+	if isNumber(cfgMap["kill_timeout"]) {
+		add("kill_timeout in seconds becomes a duration")
+	}
+	if build, ok := cfgMap["build"].(map[string]any); ok && build["build_target"] != nil {
+		add("build.build_target becomes build.build-target")
+	}
+	if isArray("env") {
+		add("[[env]] becomes an [env] table")
+	}
+	if isArray("processes") {
+		add("[[processes]] becomes a [processes] table")
+	}
+	if isArray("checks") {
+		add("[[checks]] become [checks.<name>] tables")
+	}
+	for _, k := range []string{"compute", "computes"} {
+		if _, ok := cfgMap[k]; ok {
+			add("[%s] becomes [[vm]]", k)
+		}
+	}
+	for _, k := range []string{"vm", "services"} {
+		if isTable(k) {
+			add("[%s] becomes [[%s]]", k, k)
+		}
+	}
+	for _, k := range []string{"mount", "metric"} {
+		if _, ok := cfgMap[k]; ok {
+			add("[%s] becomes [[%ss]]", k, k)
+		}
+	}
+
+	services, _ := ensureArrayOfMap(cfgMap["services"])
+	for i, service := range services {
+		if isString(service["concurrency"]) {
+			add("services[%d].concurrency as \"soft,hard\" becomes a [services.concurrency] table", i)
+		}
+		for _, checkType := range []string{"tcp_checks", "http_checks"} {
+			checks, _ := ensureArrayOfMap(service[checkType])
+			for j, check := range checks {
+				for _, attr := range []string{"interval", "timeout", "grace_period"} {
+					if isNumber(check[attr]) {
+						add("services[%d].%s[%d].%s in milliseconds becomes a duration", i, checkType, j, attr)
+					}
+				}
+			}
+		}
+	}
+
+	return found
+}
+
+func isString(v any) bool {
+	_, ok := v.(string)
+	return ok
+}
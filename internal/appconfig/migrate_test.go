@@ -0,0 +1,64 @@
+package appconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateConfigFile(t *testing.T) {
+	migration, err := MigrateConfigFile("./testdata/old-format.toml")
+	require.NoError(t, err)
+
+	assert.True(t, migration.Changed())
+	assert.Equal(t, []string{
+		"build.build_target becomes build.build-target",
+		"[[processes]] becomes a [processes] table",
+		"[mount] becomes [[mounts]]",
+		`services[0].concurrency as "soft,hard" becomes a [services.concurrency] table`,
+		"services[0].tcp_checks[0].interval in milliseconds becomes a duration",
+		"services[0].tcp_checks[0].timeout in milliseconds becomes a duration",
+		"services[0].http_checks[0].interval in milliseconds becomes a duration",
+		"services[0].http_checks[0].timeout in milliseconds becomes a duration",
+	}, migration.Deprecated)
+	assert.Contains(t, string(migration.Migrated), "schema_version = 1\n")
+
+	// The migrated file loads the same and needs no more migration
+	path := filepath.Join(t.TempDir(), "fly.toml")
+	require.NoError(t, os.WriteFile(path, migration.Migrated, 0o644))
+
+	original, err := LoadConfig("./testdata/old-format.toml")
+	require.NoError(t, err)
+	migrated, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, 1, migrated.SchemaVersion)
+	original.configFilePath, migrated.configFilePath = "", ""
+	original.SchemaVersion = 1
+	assert.Equal(t, original, migrated)
+
+	again, err := MigrateConfigFile(path)
+	require.NoError(t, err)
+	assert.False(t, again.Changed())
+	assert.Empty(t, again.Deprecated)
+}
+
+func TestMigrateConfigFileKeepsHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fly.toml")
+	require.NoError(t, os.WriteFile(path, []byte("# generated by hand\n\napp = \"foo\"\n\n[experimental]\n  kill_timeout = 10\n"), 0o644))
+
+	migration, err := MigrateConfigFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"experimental.kill_timeout moves to kill_timeout"}, migration.Deprecated)
+	assert.Equal(t, "# generated by hand\n\nschema_version = 1\napp = 'foo'\nkill_timeout = '10s'\n", string(migration.Migrated))
+}
+
+func TestMigrateConfigFileNewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fly.toml")
+	require.NoError(t, os.WriteFile(path, []byte("schema_version = 99\napp = \"foo\"\n"), 0o644))
+
+	_, err := MigrateConfigFile(path)
+	assert.ErrorContains(t, err, "newer than the version 1")
+}
@@ -19,6 +19,7 @@ var configPatches = []patchFuncType{
 	patchCompute,
 	patchMounts,
 	patchMetrics,
+	patchRestart,
 	patchTopFields,
 	patchBuild,
 }
@@ -64,6 +65,27 @@ func patchEnv(cfg map[string]any) (map[string]any, error) {
 	if !ok {
 		return cfg, nil
 	}
+
+	// Tables within env, e.g. [env.worker], only apply to that process group
+	if table, ok := raw.(map[string]any); ok {
+		processEnv := map[string]map[string]string{}
+		for group, v := range table {
+			groupRaw, ok := v.(map[string]any)
+			if !ok {
+				continue
+			}
+			groupEnv, err := _patchEnv(groupRaw)
+			if err != nil {
+				return nil, fmt.Errorf("env.%s: %w", group, err)
+			}
+			processEnv[group] = groupEnv
+			delete(table, group)
+		}
+		if len(processEnv) > 0 {
+			cfg["process_env"] = processEnv
+		}
+	}
+
 	env, err := _patchEnv(raw)
 	if err != nil {
 		return nil, err
@@ -204,8 +226,10 @@ func patchExperimental(cfg map[string]any) (map[string]any, error) {
 			if _, ok := cfg["kill_timeout"]; !ok {
 				cfg["kill_timeout"] = _castDuration(v, time.Second)
 			}
+			delete(cast, k)
 		case "metrics_port", "metrics_path":
 			metrics[strings.TrimPrefix(k, "metrics_")] = v
+			delete(cast, k)
 		}
 	}
 
@@ -264,6 +288,32 @@ func patchMounts(cfg map[string]any) (map[string]any, error) {
 	return cfg, nil
 }
 
+// patchRestart accepts a single [restart] table, the max_retries name of
+// retries and the "no" policy of the Machines API.
+func patchRestart(cfg map[string]any) (map[string]any, error) {
+	raw, ok := cfg["restart"]
+	if !ok {
+		return cfg, nil
+	}
+	restarts, err := ensureArrayOfMap(raw)
+	if err != nil {
+		return nil, fmt.Errorf("Error processing restart: %w", err)
+	}
+	for _, restart := range restarts {
+		if v, ok := restart["max_retries"]; ok {
+			if _, ok := restart["retries"]; !ok {
+				restart["retries"] = v
+			}
+			delete(restart, "max_retries")
+		}
+		if restart["policy"] == "no" {
+			restart["policy"] = string(RestartPolicyNever)
+		}
+	}
+	cfg["restart"] = restarts
+	return cfg, nil
+}
+
 func patchMetrics(cfg map[string]any) (map[string]any, error) {
 	var metrics []map[string]any
 	for _, k := range []string{"metric", "metrics"} {
@@ -305,6 +355,7 @@ func patchTopLevelChecks(cfg map[string]any) (map[string]any, error) {
 			if !ok {
 				return nil, fmt.Errorf("check item name not a string")
 			}
+			delete(cast2, "name")
 
 			subChecks, err := _patchTopLevelChecks(map[string]any{name: raw2})
 			if err != nil {
@@ -90,7 +90,8 @@ func (c *Config) flattenGroupsMatch(target string, toCheck []string) bool {
 
 // Flatten generates a machine config specific to a process_group.
 //
-// Only services, mounts, checks, metrics, files and restarts specific to the provided process group will be in the returned config.
+// Only services, mounts, checks, metrics, files, secrets and restarts specific to the provided process group will be in the returned config.
+// Its env holds the global variables overridden by the ones of the group's [env.<group>] table.
 func (c *Config) Flatten(groupName string) (*Config, error) {
 	if err := c.SetMachinesPlatform(); err != nil {
 		return nil, fmt.Errorf("can not flatten an invalid v2 application config: %w", err)
@@ -113,6 +114,12 @@ func (c *Config) Flatten(groupName string) (*Config, error) {
 		return dst.flattenGroupMatches(groupName, k)
 	})
 
+	// [env.<group>]
+	if groupEnv, ok := dst.ProcessEnv[groupName]; ok {
+		dst.Env = lo.Assign(dst.Env, groupEnv)
+	}
+	dst.ProcessEnv = nil
+
 	// [checks]
 	dst.Checks = lo.PickBy(dst.Checks, func(_ string, check *ToplevelCheck) bool {
 		return matchesGroups(check.Processes)
@@ -154,6 +161,15 @@ func (c *Config) Flatten(groupName string) (*Config, error) {
 		dst.Files[i].Processes = []string{groupName}
 	}
 
+	// [[secrets]]
+	dst.restrictSecrets = c.restrictSecrets || len(c.Secrets) > 0
+	dst.Secrets = lo.Filter(dst.Secrets, func(x ProcessSecrets, _ int) bool {
+		return matchesGroups(x.Processes)
+	})
+	for i := range dst.Secrets {
+		dst.Secrets[i].Processes = []string{groupName}
+	}
+
 	// [[metrics]]
 	dst.Metrics = lo.Filter(dst.Metrics, func(x *Metrics, _ int) bool {
 		return matchesGroups(x.Processes)
@@ -226,3 +242,16 @@ func (c *Config) InitCmd(groupName string) ([]string, error) {
 	}
 	return cmd, nil
 }
+
+// envWithProcessEnv returns env as written in fly.toml, with a table for the
+// variables of each process group.
+func (c *Config) envWithProcessEnv() map[string]any {
+	env := make(map[string]any, len(c.Env)+len(c.ProcessEnv))
+	for k, v := range c.Env {
+		env[k] = v
+	}
+	for group, groupEnv := range c.ProcessEnv {
+		env[group] = groupEnv
+	}
+	return env
+}
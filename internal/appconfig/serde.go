@@ -9,13 +9,14 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/itchyny/json2yaml"
 	"github.com/pelletier/go-toml/v2"
+	"github.com/samber/lo"
 	"github.com/superfly/flyctl/helpers"
 	"github.com/superfly/flyctl/iostreams"
 	"gopkg.in/yaml.v2"
@@ -31,19 +32,28 @@ const flyConfigHeader = `# fly.%s app configuration file generated for %s on %s
 // used to detect the start of a new object or array in JSON or YAML
 var startObjectOrArray = regexp.MustCompile(`^\s*"?\w+"?:( [[{])?$`)
 
-// LoadConfig loads the app config at the given path.
+// LoadConfig loads the app config at the given path, in the format given by its
+// extension, see ConfigFormat. A config setting
+// extends = "<path>" is applied on top of that base config, see mergeConfigMaps.
+// Such a config can't be written back to its file, which would drop the
+// inheritance; change its settings with SetConfigFileValues instead.
 func LoadConfig(path string) (cfg *Config, err error) {
 	buf, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
 
-	if strings.HasSuffix(path, ".json") {
-		cfg, err = unmarshalJSON(buf)
-	} else if strings.HasSuffix(path, ".yaml") {
-		cfg, err = unmarshalYAML(buf)
+	if cfgMap, decodeErr := decodeConfigMap(path, buf); decodeErr == nil && cfgMap[extendsKey] != nil {
+		cfg, err = loadExtendedConfig(path, cfgMap)
 	} else {
-		cfg, err = unmarshalTOML(buf)
+		switch ConfigFormat(path) {
+		case "json":
+			cfg, err = unmarshalJSON(buf)
+		case "yaml":
+			cfg, err = unmarshalYAML(buf)
+		default:
+			cfg, err = unmarshalTOML(buf)
+		}
 	}
 	if err != nil {
 		return nil, err
@@ -86,6 +96,9 @@ func (c *Config) WriteTo(w io.Writer, format string) (int64, error) {
 }
 
 func (c *Config) WriteToFile(filename string) (err error) {
+	if c.extendsBase != "" && filename == c.configFilePath {
+		return fmt.Errorf("%s extends %s and can't be written back without dropping its base settings, change it with 'fly config set'", filename, c.extendsBase)
+	}
 	if err = helpers.MkdirAll(filename); err != nil {
 		return
 	}
@@ -100,13 +113,15 @@ func (c *Config) WriteToFile(filename string) (err error) {
 		}
 	}()
 
-	_, err = c.WriteTo(file, strings.TrimLeft(strings.ToLower(filepath.Ext(filename)), "."))
+	_, err = c.WriteTo(file, ConfigFormat(filename))
 	return
 }
 
 func (c *Config) WriteToDisk(ctx context.Context, path string) (err error) {
 	io := iostreams.FromContext(ctx)
-	err = c.WriteToFile(path)
+	if err = c.WriteToFile(path); err != nil {
+		return
+	}
 	fmt.Fprintf(io.Out, "Wrote config file %s\n", helpers.PathRelativeToCWD(path))
 	return
 }
@@ -116,7 +131,17 @@ func (c *Config) MarshalJSON() ([]byte, error) {
 	if c == nil {
 		return json.Marshal(nil)
 	}
-	return json.Marshal(*c)
+	if len(c.ProcessEnv) == 0 {
+		return json.Marshal(*c)
+	}
+
+	// Write the process group env within env, like in fly.toml
+	type config Config
+	return json.Marshal(struct {
+		config
+		Env        map[string]any `json:"env,omitempty"`
+		ProcessEnv any            `json:"process_env,omitempty"`
+	}{config: config(*c), Env: c.envWithProcessEnv()})
 }
 
 // MarshalAsYAML first marshals the config to JSON and then converts it to YAML
@@ -126,7 +151,7 @@ func (c *Config) MarshalAsYAML() ([]byte, error) {
 	if c == nil {
 		return json.Marshal(nil)
 	}
-	jsonConfig, err := json.Marshal(*c)
+	jsonConfig, err := c.MarshalJSON()
 
 	if err != nil {
 		return nil, err
@@ -163,11 +188,34 @@ func (c *Config) marshalTOML() ([]byte, error) {
 		if err := encoder.Encode(c); err != nil {
 			return nil, err
 		}
+		if err := marshalProcessEnv(&b, c.ProcessEnv); err != nil {
+			return nil, err
+		}
 	}
 
 	return b.Bytes(), nil
 }
 
+// marshalProcessEnv appends the [env.<group>] tables, which the encoder can't
+// write within [env] since Env only holds strings.
+func marshalProcessEnv(b *bytes.Buffer, processEnv map[string]map[string]string) error {
+	groups := lo.Keys(processEnv)
+	slices.Sort(groups)
+	for _, group := range groups {
+		buf, err := toml.Marshal(processEnv[group])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "\n  [env.%s]\n", group)
+		for _, line := range strings.SplitAfter(string(buf), "\n") {
+			if line != "" {
+				b.WriteString("    " + line)
+			}
+		}
+	}
+	return nil
+}
+
 func unmarshalTOML(buf []byte) (*Config, error) {
 	cfgMap := map[string]any{}
 	if err := toml.Unmarshal(buf, &cfgMap); err != nil {
@@ -323,6 +323,7 @@ func TestLoadTOMLAppConfigReferenceFormat(t *testing.T) {
 	assert.Equal(t, &Config{
 		configFilePath:   "./testdata/full-reference.toml",
 		defaultGroupName: "app",
+		SchemaVersion:    1,
 		AppName:          "foo",
 		KillSignal:       fly.Pointer("SIGTERM"),
 		KillTimeout:      fly.MustParseDuration("3s"),
@@ -391,11 +392,16 @@ func TestLoadTOMLAppConfigReferenceFormat(t *testing.T) {
 			ReleaseCommand: "release command",
 			Strategy:       "rolling-eyes",
 			MaxUnavailable: fly.Pointer(0.2),
+			CanaryCount:    fly.Pointer(2),
+			BakeTime:       fly.MustParseDuration("5m"),
 		},
 
 		Env: map[string]string{
 			"FOO": "BAR",
 		},
+		ProcessEnv: map[string]map[string]string{
+			"task": {"FOO": "TASK"},
+		},
 
 		Metrics: []*Metrics{
 			{
@@ -424,10 +430,12 @@ func TestLoadTOMLAppConfigReferenceFormat(t *testing.T) {
 				HardLimit: 10,
 				SoftLimit: 4,
 			},
-			TLSOptions: &fly.TLSOptions{
-				ALPN:              []string{"h2", "http/1.1"},
-				Versions:          []string{"TLSv1.2", "TLSv1.3"},
-				DefaultSelfSigned: fly.Pointer(false),
+			TLSOptions: &TLSOptions{
+				TLSOptions: fly.TLSOptions{
+					ALPN:              []string{"h2", "http/1.1"},
+					Versions:          []string{"TLSv1.2", "TLSv1.3"},
+					DefaultSelfSigned: fly.Pointer(false),
+				},
 			},
 			HTTPOptions: &fly.HTTPOptions{
 				Compress:    fly.Pointer(true),
@@ -493,6 +501,7 @@ func TestLoadTOMLAppConfigReferenceFormat(t *testing.T) {
 			{
 				GuestPath:  "/path/to/secret.txt",
 				SecretName: "SUPER_SECRET",
+				Mode:       0o600,
 			},
 			{
 				GuestPath: "/path/to/config.yaml",
@@ -501,6 +510,13 @@ func TestLoadTOMLAppConfigReferenceFormat(t *testing.T) {
 			},
 		},
 
+		Secrets: []ProcessSecrets{
+			{
+				Names:     []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY"},
+				Processes: []string{"task"},
+			},
+		},
+
 		Mounts: []Mount{{
 			Source:            "data",
 			Destination:       "/data",
@@ -547,6 +563,15 @@ func TestLoadTOMLAppConfigReferenceFormat(t *testing.T) {
 					SoftLimit: 13,
 				},
 
+				TLSOptions: &TLSOptions{
+					MinVersion: "TLSv1.2",
+					HSTS: &HSTSOptions{
+						MaxAge:            fly.Pointer(63072000),
+						IncludeSubdomains: true,
+						Preload:           true,
+					},
+				},
+
 				Ports: []fly.MachinePort{
 					{
 						Port:       fly.Pointer(80),
@@ -655,6 +680,58 @@ func TestIsSameYAMLAppConfigReferenceFormat(t *testing.T) {
 	require.Equal(t, TOMLcfg, YAMLcfg)
 }
 
+func TestIsSameYMLAppConfigReferenceFormat(t *testing.T) {
+	const TOMLpath = "./testdata/full-reference.toml"
+	TOMLcfg, err := LoadConfig(TOMLpath)
+	require.NoError(t, err)
+
+	YMLpath := filepath.Join(t.TempDir(), "fly.yml")
+	err = TOMLcfg.WriteToFile(YMLpath)
+	require.NoError(t, err)
+
+	buf, err := os.ReadFile(YMLpath)
+	require.NoError(t, err)
+	assert.Contains(t, string(buf), "app: foo")
+
+	YMLcfg, err := LoadConfig(YMLpath)
+	require.NoError(t, err)
+
+	TOMLcfg.configFilePath = ""
+	YMLcfg.configFilePath = ""
+	require.Equal(t, TOMLcfg, YMLcfg)
+}
+
+func TestConfigFormat(t *testing.T) {
+	assert.Equal(t, "toml", ConfigFormat("fly.toml"))
+	assert.Equal(t, "json", ConfigFormat("fly.json"))
+	assert.Equal(t, "json", ConfigFormat("/some/dir/FLY.JSON"))
+	assert.Equal(t, "yaml", ConfigFormat("fly.yaml"))
+	assert.Equal(t, "yaml", ConfigFormat("fly.yml"))
+	assert.Equal(t, "toml", ConfigFormat("fly.production"))
+}
+
+func TestResolveConfigFileFromPath(t *testing.T) {
+	dir := t.TempDir()
+
+	path, err := ResolveConfigFileFromPath(dir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "fly.toml"), path)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "fly.yaml"), []byte("app: foo\n"), 0o644))
+	path, err = ResolveConfigFileFromPath(dir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "fly.yaml"), path)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "fly.json"), []byte(`{"app": "foo"}`), 0o644))
+	path, err = ResolveConfigFileFromPath(dir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "fly.json"), path)
+
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, "foo", cfg.AppName)
+}
+
 func TestJSONPrettyPrint(t *testing.T) {
 	const path = "./testdata/full-reference.toml"
 	cfg, err := LoadConfig(path)
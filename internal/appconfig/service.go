@@ -3,6 +3,8 @@ package appconfig
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/samber/lo"
 	fly "github.com/superfly/fly-go"
@@ -23,8 +25,32 @@ type Service struct {
 	HTTPChecks         []*ServiceHTTPCheck            `json:"http_checks,omitempty" toml:"http_checks,omitempty"`
 	MachineChecks      []*ServiceMachineCheck         `json:"machine_checks,omitempty" toml:"machine_checks,omitempty"`
 	Processes          []string                       `json:"processes,omitempty" toml:"processes,omitempty"`
+	TLSOptions         *TLSOptions                    `json:"tls_options,omitempty" toml:"tls_options,omitempty"`
 }
 
+// TLSOptions is the TLS policy of the TLS ports of a service. The
+// tls_options of a port take precedence over the ones of its service.
+type TLSOptions struct {
+	fly.TLSOptions
+	// MinVersion sets the versions of the ports to it and the ones above
+	MinVersion string       `json:"min_version,omitempty" toml:"min_version,omitempty"`
+	HSTS       *HSTSOptions `json:"hsts,omitempty" toml:"hsts,omitempty"`
+}
+
+// HSTSOptions sets the Strict-Transport-Security header of the responses of
+// the HTTP over TLS ports of a service.
+type HSTSOptions struct {
+	MaxAge            *int `json:"max_age,omitempty" toml:"max_age,omitempty"`
+	IncludeSubdomains bool `json:"include_subdomains,omitempty" toml:"include_subdomains,omitempty"`
+	Preload           bool `json:"preload,omitempty" toml:"preload,omitempty"`
+}
+
+// tlsVersions are the TLS versions the proxy supports, in ascending order.
+var tlsVersions = []string{"TLSv1.2", "TLSv1.3"}
+
+// defaultHSTSMaxAge is a year, in seconds.
+const defaultHSTSMaxAge = 31536000
+
 type ServiceTCPCheck struct {
 	Interval    *fly.Duration `json:"interval,omitempty" toml:"interval,omitempty"`
 	Timeout     *fly.Duration `json:"timeout,omitempty" toml:"timeout,omitempty"`
@@ -62,7 +88,7 @@ type HTTPService struct {
 	MinMachinesRunning *int                           `json:"min_machines_running,omitempty" toml:"min_machines_running,omitempty"`
 	Processes          []string                       `json:"processes,omitempty" toml:"processes,omitempty"`
 	Concurrency        *fly.MachineServiceConcurrency `toml:"concurrency,omitempty" json:"concurrency,omitempty"`
-	TLSOptions         *fly.TLSOptions                `json:"tls_options,omitempty" toml:"tls_options,omitempty"`
+	TLSOptions         *TLSOptions                    `json:"tls_options,omitempty" toml:"tls_options,omitempty"`
 	HTTPOptions        *fly.HTTPOptions               `json:"http_options,omitempty" toml:"http_options,omitempty"`
 	HTTPChecks         []*ServiceHTTPCheck            `json:"checks,omitempty" toml:"checks,omitempty"`
 	MachineChecks      []*ServiceMachineCheck         `json:"machine_checks,omitempty" toml:"machine_checks,omitempty"`
@@ -76,6 +102,7 @@ func (s *HTTPService) ToService() *Service {
 		Processes:     s.Processes,
 		HTTPChecks:    s.HTTPChecks,
 		MachineChecks: s.MachineChecks,
+		TLSOptions:    s.TLSOptions,
 		Ports: []fly.MachinePort{{
 			Port:        fly.IntPointer(80),
 			Handlers:    []string{"http"},
@@ -85,7 +112,6 @@ func (s *HTTPService) ToService() *Service {
 			Port:        fly.IntPointer(443),
 			Handlers:    []string{"http", "tls"},
 			HTTPOptions: s.HTTPOptions,
-			TLSOptions:  s.TLSOptions,
 		}},
 		AutoStopMachines:   s.AutoStopMachines,
 		AutoStartMachines:  s.AutoStartMachines,
@@ -111,6 +137,9 @@ func (svc *Service) toMachineService() *fly.MachineService {
 		Autostart:          svc.AutoStartMachines,
 		MinMachinesRunning: svc.MinMachinesRunning,
 	}
+	if svc.TLSOptions != nil {
+		s.Ports = svc.TLSOptions.applyTo(svc.Ports)
+	}
 
 	for _, tc := range svc.TCPChecks {
 		s.Checks = append(s.Checks, *tc.toMachineCheck())
@@ -121,6 +150,83 @@ func (svc *Service) toMachineService() *fly.MachineService {
 	return s
 }
 
+// applyTo returns a copy of ports with the TLS policy applied to the TLS
+// ports, leaving the options the ports set themselves.
+func (o *TLSOptions) applyTo(ports []fly.MachinePort) []fly.MachinePort {
+	applied := make([]fly.MachinePort, len(ports))
+	for i, port := range ports {
+		if slices.Contains(port.Handlers, "tls") {
+			port.TLSOptions = o.portOptions(port.TLSOptions)
+			if o.HSTS != nil && slices.Contains(port.Handlers, "http") {
+				port.HTTPOptions = o.HSTS.httpOptions(port.HTTPOptions)
+			}
+		}
+		applied[i] = port
+	}
+	return applied
+}
+
+func (o *TLSOptions) portOptions(port *fly.TLSOptions) *fly.TLSOptions {
+	opts := o.TLSOptions
+	if i := slices.Index(tlsVersions, o.MinVersion); i >= 0 && len(opts.Versions) == 0 {
+		opts.Versions = slices.Clone(tlsVersions[i:])
+	}
+	if port != nil {
+		if len(port.ALPN) > 0 {
+			opts.ALPN = port.ALPN
+		}
+		if len(port.Versions) > 0 {
+			opts.Versions = port.Versions
+		}
+		if port.DefaultSelfSigned != nil {
+			opts.DefaultSelfSigned = port.DefaultSelfSigned
+		}
+	}
+	return &opts
+}
+
+// header returns the value of the Strict-Transport-Security header.
+func (h *HSTSOptions) header() string {
+	maxAge := defaultHSTSMaxAge
+	if h.MaxAge != nil {
+		maxAge = *h.MaxAge
+	}
+	value := fmt.Sprintf("max-age=%d", maxAge)
+	if h.IncludeSubdomains {
+		value += "; includeSubDomains"
+	}
+	if h.Preload {
+		value += "; preload"
+	}
+	return value
+}
+
+// httpOptions returns a copy of opts setting the Strict-Transport-Security
+// header, unless the port already sets it.
+func (h *HSTSOptions) httpOptions(opts *fly.HTTPOptions) *fly.HTTPOptions {
+	var copied fly.HTTPOptions
+	if opts != nil {
+		copied = *opts
+	}
+	var response fly.HTTPResponseOptions
+	if copied.Response != nil {
+		response = *copied.Response
+	}
+
+	headers := make(map[string]any, len(response.Headers)+1)
+	set := false
+	for k, v := range response.Headers {
+		headers[k] = v
+		set = set || strings.EqualFold(k, "Strict-Transport-Security")
+	}
+	if !set {
+		headers["Strict-Transport-Security"] = h.header()
+	}
+	response.Headers = headers
+	copied.Response = &response
+	return &copied
+}
+
 func (chk *ServiceHTTPCheck) toMachineCheck() *fly.MachineCheck {
 	return &fly.MachineCheck{
 		Type:              fly.Pointer("http"),
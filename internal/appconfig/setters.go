@@ -127,3 +127,27 @@ func (c *Config) SetStatics(statics []Static) {
 func (c *Config) SetMounts(volumes []Mount) {
 	c.Mounts = volumes
 }
+
+// SetAutostop sets the autostop, autostart and minimum running machines of the
+// services used by groupName and returns how many services were changed.
+// Services shared with other groups are changed for those groups too.
+func (c *Config) SetAutostop(groupName string, autostop fly.MachineAutostop, autostart bool, minRunning int) int {
+	changed := 0
+	if c.HTTPService != nil && c.flattenGroupsMatch(groupName, c.HTTPService.Processes) {
+		c.HTTPService.AutoStopMachines = fly.Pointer(autostop)
+		c.HTTPService.AutoStartMachines = fly.Pointer(autostart)
+		c.HTTPService.MinMachinesRunning = fly.Pointer(minRunning)
+		changed++
+	}
+	for i := range c.Services {
+		service := &c.Services[i]
+		if !c.flattenGroupsMatch(groupName, service.Processes) {
+			continue
+		}
+		service.AutoStopMachines = fly.Pointer(autostop)
+		service.AutoStartMachines = fly.Pointer(autostart)
+		service.MinMachinesRunning = fly.Pointer(minRunning)
+		changed++
+	}
+	return changed
+}
@@ -172,3 +172,23 @@ func TestSetKillSignal(t *testing.T) {
 	cfg.SetKillSignal("TERM")
 	assert.Equal(t, cfg.KillSignal, fly.Pointer("TERM"))
 }
+
+func TestSetAutostop(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Processes = map[string]string{"web": "run-web", "worker": "run-worker"}
+	cfg.HTTPService = &HTTPService{InternalPort: 8080, Processes: []string{"web"}}
+	cfg.Services = []Service{
+		{InternalPort: 9000, Processes: []string{"worker"}},
+		{InternalPort: 9001, Processes: []string{"web", "worker"}},
+	}
+
+	changed := cfg.SetAutostop("web", fly.MachineAutostopSuspend, true, 1)
+	assert.Equal(t, 2, changed)
+	assert.Equal(t, fly.Pointer(fly.MachineAutostopSuspend), cfg.HTTPService.AutoStopMachines)
+	assert.Equal(t, fly.Pointer(true), cfg.HTTPService.AutoStartMachines)
+	assert.Equal(t, fly.Pointer(1), cfg.HTTPService.MinMachinesRunning)
+	assert.Nil(t, cfg.Services[0].AutoStopMachines)
+	assert.Equal(t, fly.Pointer(1), cfg.Services[1].MinMachinesRunning)
+
+	assert.Equal(t, 0, cfg.SetAutostop("cron", fly.MachineAutostopStop, false, 0))
+}
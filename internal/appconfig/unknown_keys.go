@@ -0,0 +1,120 @@
+package appconfig
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// unknownKeysInFile reads the config file at path like LoadConfig and returns
+// the keys findUnknownKeys reports.
+func unknownKeysInFile(path string) ([]string, error) {
+	cfgMap, err := loadConfigMap(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfgMap, err = patchRoot(cfgMap)
+	if err != nil {
+		return nil, err
+	}
+	return findUnknownKeys(cfgMap), nil
+}
+
+// findUnknownKeys returns the path, e.g. services[0].intenal_port, of every
+// key of the patched cfgMap that doesn't match a field of Config, and so is
+// silently ignored when decoding it.
+func findUnknownKeys(cfgMap map[string]any) []string {
+	// Round trip through JSON for the maps and slices to have the same types
+	// whatever the patches produced
+	buf, err := json.Marshal(cfgMap)
+	if err != nil {
+		return nil
+	}
+	var raw any
+	if err := json.Unmarshal(buf, &raw); err != nil {
+		return nil
+	}
+
+	var unknown []string
+	walkUnknownKeys(raw, reflect.TypeOf(Config{}), "", &unknown)
+	sort.Strings(unknown)
+	return unknown
+}
+
+func walkUnknownKeys(raw any, t reflect.Type, path string, unknown *[]string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if path != "" && (reflect.PointerTo(t).Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType)) {
+		return
+	}
+
+	switch raw := raw.(type) {
+	case map[string]any:
+		switch t.Kind() {
+		case reflect.Struct:
+			fields := jsonFields(t)
+			for key, value := range raw {
+				ft, ok := fields[key]
+				if !ok {
+					*unknown = append(*unknown, joinKeyPath(path, key))
+					continue
+				}
+				walkUnknownKeys(value, ft, joinKeyPath(path, key), unknown)
+			}
+		case reflect.Map:
+			for key, value := range raw {
+				walkUnknownKeys(value, t.Elem(), joinKeyPath(path, key), unknown)
+			}
+		}
+	case []any:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for i, value := range raw {
+				walkUnknownKeys(value, t.Elem(), path+"["+strconv.Itoa(i)+"]", unknown)
+			}
+		}
+	}
+}
+
+// jsonFields maps the JSON names of the fields of struct t to their types,
+// including the fields of embedded structs.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		switch {
+		case name == "-":
+		case f.Anonymous && name == "":
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			for k, v := range jsonFields(ft) {
+				fields[k] = v
+			}
+		case !f.IsExported():
+		case name == "":
+			fields[f.Name] = f.Type
+		default:
+			fields[name] = f.Type
+		}
+	}
+	return fields
+}
+
+func joinKeyPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -21,12 +21,71 @@ var (
 	MachinesDeployStrategies = []string{"canary", "rolling", "immediate", "bluegreen"}
 )
 
+// ValidationFinding is a problem found validating a config, at the path of
+// the setting it is about, e.g. services[0].internal_port, when there is one.
+type ValidationFinding struct {
+	Path     string `json:"path,omitempty"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// ValidationReport holds the findings of validating a config, along with the
+// human readable account of it.
+type ValidationReport struct {
+	Findings []ValidationFinding
+	info     string
+	err      error
+}
+
+func (r *ValidationReport) errorf(path, format string, a ...any) {
+	msg := fmt.Sprintf(format, a...)
+	r.info += msg + "\n"
+	r.Findings = append(r.Findings, ValidationFinding{Path: path, Severity: SeverityError, Message: msg})
+	r.err = ValidationError
+}
+
+func (r *ValidationReport) warnf(path, format string, a ...any) {
+	msg := fmt.Sprintf(format, a...)
+	r.info += fmt.Sprintf("%s %s\n", aurora.Yellow("WARN"), msg)
+	r.Findings = append(r.Findings, ValidationFinding{Path: path, Severity: SeverityWarning, Message: msg})
+}
+
+// Err is nil when the config is valid.
+func (r *ValidationReport) Err() error {
+	if r.err != nil {
+		return errors.New("App configuration is not valid")
+	}
+	return nil
+}
+
+// Info is the human readable account of the validation.
+func (r *ValidationReport) Info() string {
+	return r.info
+}
+
 func (cfg *Config) Validate(ctx context.Context) (err error, extra_info string) {
 	if cfg == nil {
 		return errors.New("App config file not found"), ""
 	}
+	report := cfg.ValidateReport(ctx, false)
+	return report.Err(), report.Info()
+}
 
-	validators := []func() (string, error){
+// ValidateReport validates cfg like Validate. Strict validation also reports
+// the keys of the config file that don't match any setting as errors.
+func (cfg *Config) ValidateReport(ctx context.Context, strict bool) *ValidationReport {
+	report := &ValidationReport{}
+	if cfg == nil {
+		report.errorf("", "App config file not found")
+		return report
+	}
+
+	validators := []func(*ValidationReport){
 		cfg.validateBuildStrategies,
 		cfg.validateDeploySection,
 		cfg.validateChecksSection,
@@ -35,30 +94,34 @@ func (cfg *Config) Validate(ctx context.Context) (err error, extra_info string)
 		cfg.validateMachineConversion,
 		cfg.validateConsoleCommand,
 		cfg.validateMounts,
+		cfg.validateStatics,
+		cfg.validateFiles,
 		cfg.validateRestartPolicy,
+		cfg.validateSecrets,
+		cfg.validateSchemaVersion,
+	}
+	if strict {
+		validators = append(validators, cfg.validateUnknownKeys)
 	}
 
-	extra_info = fmt.Sprintf("Validating %s\n", cfg.ConfigFilePath())
+	report.info = fmt.Sprintf("Validating %s\n", cfg.ConfigFilePath())
 
 	for _, vFunc := range validators {
-		info, vErr := vFunc()
-		extra_info += info
-		if vErr != nil {
-			err = vErr
-		}
+		vFunc(report)
 	}
 
 	if cfg.v2UnmarshalError != nil {
-		err = cfg.v2UnmarshalError
+		report.Findings = append(report.Findings, ValidationFinding{Severity: SeverityError, Message: cfg.v2UnmarshalError.Error()})
+		report.err = cfg.v2UnmarshalError
 	}
 
-	if err != nil {
-		extra_info += fmt.Sprintf("\n   %s%s\n", aurora.Red("✘"), err)
-		return errors.New("App configuration is not valid"), extra_info
+	if report.err != nil {
+		report.info += fmt.Sprintf("\n   %s%s\n", aurora.Red("✘"), report.err)
+		return report
 	}
 
-	extra_info += fmt.Sprintf("%s Configuration is valid\n", aurora.Green("✓"))
-	return nil, extra_info
+	report.info += fmt.Sprintf("%s Configuration is valid\n", aurora.Green("✓"))
+	return report
 }
 
 func (cfg *Config) ValidateGroups(ctx context.Context, groups []string) (err error, extraInfo string) {
@@ -79,90 +142,97 @@ func (cfg *Config) ValidateGroups(ctx context.Context, groups []string) (err err
 	return
 }
 
-func (cfg *Config) validateBuildStrategies() (extraInfo string, err error) {
+func (cfg *Config) validateBuildStrategies(r *ValidationReport) {
 	buildStrats := cfg.BuildStrategies()
 	if len(buildStrats) > 1 {
 		// TODO: validate that most users are not affected by this and/or fixing this, then make it fail validation
-		msg := fmt.Sprintf("%s more than one build configuration found: [%s]", aurora.Yellow("WARN"), strings.Join(buildStrats, ", "))
-		extraInfo += msg + "\n"
+		msg := fmt.Sprintf("more than one build configuration found: [%s]", strings.Join(buildStrats, ", "))
+		r.warnf("build", "%s", msg)
 		sentry.CaptureException(errors.New(msg))
 	}
-	return
 }
 
-func (cfg *Config) validateDeploySection() (extraInfo string, err error) {
+func (cfg *Config) validateDeploySection(r *ValidationReport) {
 	if cfg.Deploy == nil {
 		return
 	}
 
 	if _, vErr := shlex.Split(cfg.Deploy.ReleaseCommand); vErr != nil {
-		extraInfo += fmt.Sprintf("Can't shell split release command: '%s'\n", cfg.Deploy.ReleaseCommand)
-		err = ValidationError
+		r.errorf("deploy.release_command", "Can't shell split release command: '%s'", cfg.Deploy.ReleaseCommand)
 	}
 
 	if s := cfg.Deploy.Strategy; s != "" {
 		if !slices.Contains(MachinesDeployStrategies, s) {
-			extraInfo += fmt.Sprintf(
+			r.errorf("deploy.strategy",
 				"unsupported deployment strategy '%s'; Apps v2 supports the following strategies: %s", s,
 				strings.Join(MachinesDeployStrategies, ", "),
 			)
-			err = ValidationError
 		}
 
 		if s == "canary" && len(cfg.Mounts) > 0 {
-			extraInfo += "error canary deployment strategy is not supported when using mounted volumes"
-			err = ValidationError
+			r.errorf("deploy.strategy", "error canary deployment strategy is not supported when using mounted volumes")
 		}
 	}
 
-	return
+	if n := cfg.Deploy.CanaryCount; n != nil && *n < 1 {
+		r.errorf("deploy.canary_count", "canary_count must be at least 1, got %d", *n)
+	}
+	if d := cfg.Deploy.BakeTime; d != nil && d.Duration < 0 {
+		r.errorf("deploy.bake_time", "bake_time can't be negative, got %s", d)
+	}
+	if (cfg.Deploy.CanaryCount != nil || cfg.Deploy.BakeTime != nil) && cfg.DeployStrategy() != "canary" {
+		r.warnf("deploy", "canary_count and bake_time only apply to the canary strategy, ignoring them for '%s'", cfg.DeployStrategy())
+	}
 }
 
-func (cfg *Config) validateChecksSection() (extraInfo string, err error) {
+func (cfg *Config) validateChecksSection(r *ValidationReport) {
 	for name, check := range cfg.Checks {
+		path := "checks." + name
 		if _, vErr := check.toMachineCheck(); vErr != nil {
-			extraInfo += fmt.Sprintf("Can't process top level check '%s': %s\n", name, vErr)
-			err = ValidationError
+			r.errorf(path, "Can't process top level check '%s': %s", name, vErr)
 		}
 		// minimum interval in flaps is set to 2 seconds.
 		if check.Interval != nil && check.Interval.Duration.Seconds() < 2 {
-			extraInfo += fmt.Sprintf("Check '%s' interval is too short: %s, minimum is 2 seconds\n", name, check.Interval.Duration)
-			err = ValidationError
+			r.errorf(path+".interval", "Check '%s' interval is too short: %s, minimum is 2 seconds", name, check.Interval.Duration)
 		}
 
 		// max timeout in flaps in set to 60s
 		if check.Timeout != nil && check.Timeout.Duration.Seconds() > 60 {
-			extraInfo += fmt.Sprintf("Check '%s' timeout is too long: %s, maximum is 60 seconds\n", name, check.Timeout.Duration)
-			err = ValidationError
+			r.errorf(path+".timeout", "Check '%s' timeout is too long: %s, maximum is 60 seconds", name, check.Timeout.Duration)
 		}
 	}
-
-	return
 }
 
-func (cfg *Config) validateServicesSection() (extraInfo string, err error) {
+func (cfg *Config) validateServicesSection(r *ValidationReport) {
 	validGroupNames := cfg.ProcessNames()
 	// The following is different than len(validGroupNames) because
 	// it can be zero when there is no [processes] section
 	processCount := len(cfg.Processes)
 
-	for _, service := range cfg.AllServices() {
+	for i, service := range cfg.AllServices() {
+		// AllServices puts [http_service] first
+		path, httpChecks := fmt.Sprintf("services[%d]", i), "http_checks"
+		if cfg.HTTPService != nil {
+			path = fmt.Sprintf("services[%d]", i-1)
+			if i == 0 {
+				path, httpChecks = "http_service", "checks"
+			}
+		}
+
 		switch {
 		case len(service.Processes) == 0 && processCount > 0:
-			extraInfo += fmt.Sprintf(
-				"Service has no processes set but app has %d processes defined; update fly.toml to set processes for each service\n",
+			r.errorf(path+".processes",
+				"Service has no processes set but app has %d processes defined; update fly.toml to set processes for each service",
 				processCount,
 			)
-			err = ValidationError
 		default:
 			for _, processName := range service.Processes {
 				if !slices.Contains(validGroupNames, processName) {
-					extraInfo += fmt.Sprintf(
+					r.errorf(path+".processes",
 						"Service specifies '%s' as one of its processes, but no processes are defined with that name; "+
-							"update fly.toml [processes] to add '%s' process or remove it from service's processes list\n",
+							"update fly.toml [processes] to add '%s' process or remove it from service's processes list",
 						processName, processName,
 					)
-					err = ValidationError
 				}
 			}
 		}
@@ -170,56 +240,92 @@ func (cfg *Config) validateServicesSection() (extraInfo string, err error) {
 		if len(service.Ports) == 0 {
 			// XXX: Warn about services without ports instead of hard failing so users have time to
 			//      fix fly.toml configuration -- 2024-01-15
-			extraInfo += fmt.Sprintf(
-				"WARNING: Service must expose at least one port. Add a [[services.ports]] section to fly.toml; " +
-					"Check docs at https://fly.io/docs/reference/configuration/#services-ports \n " +
+			r.warnf(path+".ports",
+				"Service must expose at least one port. Add a [[services.ports]] section to fly.toml; "+
+					"Check docs at https://fly.io/docs/reference/configuration/#services-ports \n "+
 					"Validation for _services without ports_ will hard fail after February 15, 2024.",
 			)
-			//err = ValidationError
 		}
 
-		for _, check := range service.TCPChecks {
-			extraInfo += validateServiceCheckDurations(check.Interval, check.Timeout, check.GracePeriod, "TCP")
+		for j, check := range service.TCPChecks {
+			validateServiceCheckDurations(r, fmt.Sprintf("%s.tcp_checks[%d]", path, j), check.Interval, check.Timeout, check.GracePeriod, "TCP")
+		}
+
+		for j, check := range service.HTTPChecks {
+			validateServiceCheckDurations(r, fmt.Sprintf("%s.%s[%d]", path, httpChecks, j), check.Interval, check.Timeout, check.GracePeriod, "HTTP")
 		}
 
-		for _, check := range service.HTTPChecks {
-			extraInfo += validateServiceCheckDurations(check.Interval, check.Timeout, check.GracePeriod, "HTTP")
+		if service.TLSOptions != nil {
+			validateServiceTLSOptions(r, path+".tls_options", service.TLSOptions, service.Ports)
 		}
 	}
-	return extraInfo, err
 }
 
-func validateServiceCheckDurations(interval, timeout, gracePeriod *fly.Duration, proto string) (extraInfo string) {
-	extraInfo += validateSingleServiceCheckDuration(interval, false, proto, "an interval")
-	extraInfo += validateSingleServiceCheckDuration(timeout, false, proto, "a timeout")
-	extraInfo += validateSingleServiceCheckDuration(gracePeriod, true, proto, "a grace period")
-	return
+func validateServiceTLSOptions(r *ValidationReport, path string, opts *TLSOptions, ports []fly.MachinePort) {
+	for _, version := range opts.Versions {
+		if !slices.Contains(tlsVersions, version) {
+			r.errorf(path+".versions", "Unsupported TLS version '%s'; must be one of %s", version, strings.Join(tlsVersions, ", "))
+		}
+	}
+
+	if opts.MinVersion != "" {
+		switch {
+		case !slices.Contains(tlsVersions, opts.MinVersion):
+			r.errorf(path+".min_version", "Unsupported TLS version '%s'; must be one of %s", opts.MinVersion, strings.Join(tlsVersions, ", "))
+		case len(opts.Versions) > 0:
+			r.errorf(path+".min_version", "Set either min_version or versions, not both")
+		}
+	}
+
+	var tlsPorts []fly.MachinePort
+	for _, port := range ports {
+		if slices.Contains(port.Handlers, "tls") {
+			tlsPorts = append(tlsPorts, port)
+		}
+	}
+	if len(tlsPorts) == 0 {
+		r.warnf(path, "Service has tls_options but no ports with the tls handler; they have no effect")
+	}
+
+	if opts.HSTS != nil {
+		if opts.HSTS.MaxAge != nil && *opts.HSTS.MaxAge < 0 {
+			r.errorf(path+".hsts.max_age", "HSTS max_age must be a number of seconds, not %d", *opts.HSTS.MaxAge)
+		}
+		if !slices.ContainsFunc(tlsPorts, func(p fly.MachinePort) bool { return slices.Contains(p.Handlers, "http") }) {
+			r.warnf(path+".hsts", "Service has hsts but no ports with both the http and tls handlers; it has no effect")
+		}
+	}
+}
+
+func validateServiceCheckDurations(r *ValidationReport, path string, interval, timeout, gracePeriod *fly.Duration, proto string) {
+	validateSingleServiceCheckDuration(r, path+".interval", interval, false, proto, "an interval")
+	validateSingleServiceCheckDuration(r, path+".timeout", timeout, false, proto, "a timeout")
+	validateSingleServiceCheckDuration(r, path+".grace_period", gracePeriod, true, proto, "a grace period")
 }
 
-func validateSingleServiceCheckDuration(d *fly.Duration, zeroOK bool, proto, description string) (extraInfo string) {
+func validateSingleServiceCheckDuration(r *ValidationReport, path string, d *fly.Duration, zeroOK bool, proto, description string) {
 	switch {
 	case d == nil:
 		// Do nothing.
 	case zeroOK && d.Duration != 0 && d.Duration < time.Second:
-		extraInfo += fmt.Sprintf(
-			"%s Service %s check has %s that is non-zero and less than 1 second (%v); this will be raised to 1 second\n",
-			aurora.Yellow("WARN"), proto, description, d.Duration,
+		r.warnf(path,
+			"Service %s check has %s that is non-zero and less than 1 second (%v); this will be raised to 1 second",
+			proto, description, d.Duration,
 		)
 	case !zeroOK && d.Duration < time.Second:
-		extraInfo += fmt.Sprintf(
-			"%s Service %s check has %s less than 1 second (%v); this will be raised to 1 second\n",
-			aurora.Yellow("WARN"), proto, description, d.Duration,
+		r.warnf(path,
+			"Service %s check has %s less than 1 second (%v); this will be raised to 1 second",
+			proto, description, d.Duration,
 		)
 	case d.Duration > time.Minute:
-		extraInfo += fmt.Sprintf(
-			"%s Service %s check has %s greater than 1 minute (%v); this will be lowered to 1 minute\n",
-			aurora.Yellow("WARN"), proto, description, d.Duration,
+		r.warnf(path,
+			"Service %s check has %s greater than 1 minute (%v); this will be lowered to 1 minute",
+			proto, description, d.Duration,
 		)
 	}
-	return
 }
 
-func (cfg *Config) validateProcessesSection() (extraInfo string, err error) {
+func (cfg *Config) validateProcessesSection(r *ValidationReport) {
 	for processName, cmdStr := range cfg.Processes {
 		if cmdStr == "" {
 			continue
@@ -227,57 +333,102 @@ func (cfg *Config) validateProcessesSection() (extraInfo string, err error) {
 
 		_, vErr := shlex.Split(cmdStr)
 		if vErr != nil {
-			extraInfo += fmt.Sprintf(
-				"Could not parse command for '%s' process group; check [processes] section: %s\n",
+			r.errorf("processes."+processName,
+				"Could not parse command for '%s' process group; check [processes] section: %s",
 				processName, vErr,
 			)
-			err = ValidationError
 		}
 	}
 
-	return extraInfo, err
+	for group := range cfg.ProcessEnv {
+		if !slices.Contains(cfg.ProcessNames(), group) {
+			r.warnf("env."+group,
+				"[env.%s] sets variables for a process group that doesn't exist; check [processes] section",
+				group,
+			)
+		}
+	}
 }
 
-func (cfg *Config) validateMachineConversion() (extraInfo string, err error) {
+func (cfg *Config) validateMachineConversion(r *ValidationReport) {
 	for _, name := range cfg.ProcessNames() {
-		if _, vErr := cfg.ToMachineConfig(name, nil); err != nil {
-			extraInfo += fmt.Sprintf("Converting to machine in process group '%s' will fail because of: %s", name, vErr)
-			err = ValidationError
+		if _, vErr := cfg.ToMachineConfig(name, nil); vErr != nil {
+			r.errorf("", "Converting to machine in process group '%s' will fail because of: %s", name, vErr)
 		}
 	}
-	return
 }
 
-func (cfg *Config) validateConsoleCommand() (extraInfo string, err error) {
+func (cfg *Config) validateConsoleCommand(r *ValidationReport) {
 	if _, vErr := shlex.Split(cfg.ConsoleCommand); vErr != nil {
-		extraInfo += fmt.Sprintf("Can't shell split console command: '%s'\n", cfg.ConsoleCommand)
-		err = ValidationError
+		r.errorf("console_command", "Can't shell split console command: '%s'", cfg.ConsoleCommand)
 	}
-	return
 }
 
-func (cfg *Config) validateMounts() (extraInfo string, err error) {
+func (cfg *Config) validateStatics(r *ValidationReport) {
+	prefixes := map[string]int{}
+	for i, s := range cfg.Statics {
+		path := fmt.Sprintf("statics[%d]", i)
+
+		if s.TigrisBucket == "" && !strings.HasPrefix(s.GuestPath, "/") {
+			r.errorf(path+".guest_path", "static '%s' has a guest_path '%s' which isn't an absolute path in the image", s.UrlPrefix, s.GuestPath)
+		}
+		if !strings.HasPrefix(s.UrlPrefix, "/") {
+			r.warnf(path+".url_prefix", "static url_prefix '%s' should start with '/'", s.UrlPrefix)
+		}
+		if j, ok := prefixes[s.UrlPrefix]; ok {
+			r.warnf(path+".url_prefix", "static url_prefix '%s' is already used by statics[%d]", s.UrlPrefix, j)
+		}
+		prefixes[s.UrlPrefix] = i
+		if strings.Contains(s.IndexDocument, "/") {
+			r.errorf(path+".index_document", "static index_document '%s' must be a file name, not a path", s.IndexDocument)
+		}
+	}
+}
+
+func (cfg *Config) validateFiles(r *ValidationReport) {
+	for i, f := range cfg.Files {
+		path := fmt.Sprintf("files[%d]", i)
+
+		if !strings.HasPrefix(f.GuestPath, "/") {
+			r.errorf(path+".guest_path", "file guest_path '%s' must be an absolute path", f.GuestPath)
+		}
+
+		sources := 0
+		for _, source := range []string{f.LocalPath, f.SecretName, f.RawValue} {
+			if source != "" {
+				sources++
+			}
+		}
+		if sources != 1 {
+			r.errorf(path, "file '%s' must set one of local_path, secret_name or raw_value", f.GuestPath)
+		}
+
+		if f.Mode > 0o7777 {
+			r.errorf(path+".mode", "file '%s' has an invalid mode %#o, write it in octal like 0o600", f.GuestPath, f.Mode)
+		}
+	}
+}
+
+func (cfg *Config) validateMounts(r *ValidationReport) {
 	if cfg.configFilePath == "--flatten--" && len(cfg.Mounts) > 1 {
-		extraInfo += fmt.Sprintf("group '%s' has more than one [[mounts]] section defined\n", cfg.defaultGroupName)
-		err = ValidationError
+		r.errorf("mounts", "group '%s' has more than one [[mounts]] section defined", cfg.defaultGroupName)
 	}
 
-	for _, m := range cfg.Mounts {
+	for i, m := range cfg.Mounts {
+		path := fmt.Sprintf("mounts[%d]", i)
+
 		if m.InitialSize != "" {
 			v, vErr := helpers.ParseSize(m.InitialSize, units.FromHumanSize, units.GB)
 			switch {
 			case vErr != nil:
-				extraInfo += fmt.Sprintf("mount '%s' with initial_size '%s' will fail because of: %s\n", m.Source, m.InitialSize, vErr)
-				err = ValidationError
+				r.errorf(path+".initial_size", "mount '%s' with initial_size '%s' will fail because of: %s", m.Source, m.InitialSize, vErr)
 			case v < 1:
-				extraInfo += fmt.Sprintf("mount '%s' has an initial_size '%s' value which is smaller than 1GB\n", m.Source, m.InitialSize)
-				err = ValidationError
+				r.errorf(path+".initial_size", "mount '%s' has an initial_size '%s' value which is smaller than 1GB", m.Source, m.InitialSize)
 			}
 		}
 
 		if m.SnapshotRetention != nil && (*m.SnapshotRetention < 1 || *m.SnapshotRetention > 60) {
-			extraInfo += fmt.Sprintf("mount '%s' has a snapshot_retention value which is not between 1 and 60 days inclusive\n", m.Source)
-			err = ValidationError
+			r.errorf(path+".snapshot_retention", "mount '%s' has a snapshot_retention value which is not between 1 and 60 days inclusive", m.Source)
 		}
 
 		var autoExtendSizeIncrement, autoExtendSizeLimit int
@@ -286,72 +437,145 @@ func (cfg *Config) validateMounts() (extraInfo string, err error) {
 			autoExtendSizeIncrement, vErr = helpers.ParseSize(m.AutoExtendSizeIncrement, units.FromHumanSize, units.GB)
 			switch {
 			case vErr != nil:
-				extraInfo += fmt.Sprintf("mount '%s' with auto_extend_size_increment '%s' will fail because of: %s\n", m.Source, m.AutoExtendSizeIncrement, vErr)
-				err = ValidationError
+				r.errorf(path+".auto_extend_size_increment", "mount '%s' with auto_extend_size_increment '%s' will fail because of: %s", m.Source, m.AutoExtendSizeIncrement, vErr)
 			case autoExtendSizeIncrement < 1:
-				extraInfo += fmt.Sprintf("mount '%s' has an auto_extend_size_increment '%s' value which is smaller than 1GB\n", m.Source, m.AutoExtendSizeIncrement)
-				err = ValidationError
+				r.errorf(path+".auto_extend_size_increment", "mount '%s' has an auto_extend_size_increment '%s' value which is smaller than 1GB", m.Source, m.AutoExtendSizeIncrement)
 			}
 		}
 		if m.AutoExtendSizeLimit != "" {
 			autoExtendSizeLimit, vErr = helpers.ParseSize(m.AutoExtendSizeLimit, units.FromHumanSize, units.GB)
 			switch {
 			case vErr != nil:
-				extraInfo += fmt.Sprintf("mount '%s' with auto_extend_size_limit '%s' will fail because of: %s\n", m.Source, m.AutoExtendSizeLimit, vErr)
-				err = ValidationError
+				r.errorf(path+".auto_extend_size_limit", "mount '%s' with auto_extend_size_limit '%s' will fail because of: %s", m.Source, m.AutoExtendSizeLimit, vErr)
 			case autoExtendSizeLimit < 1:
-				extraInfo += fmt.Sprintf("mount '%s' has an auto_extend_size_limit '%s' value which is smaller than 1GB\n", m.Source, m.AutoExtendSizeLimit)
-				err = ValidationError
+				r.errorf(path+".auto_extend_size_limit", "mount '%s' has an auto_extend_size_limit '%s' value which is smaller than 1GB", m.Source, m.AutoExtendSizeLimit)
 			}
 		}
 
 		if m.AutoExtendSizeThreshold != 0 || autoExtendSizeIncrement != 0 || autoExtendSizeLimit != 0 {
 			if m.AutoExtendSizeThreshold != 0 && autoExtendSizeIncrement == 0 && autoExtendSizeLimit == 0 {
-				extraInfo += fmt.Sprintf("mount '%s' auto_extend_size_threshold, auto_extend_size_increment and auto_extend_size_limit must be all defined or none\n", m.Source)
-				err = ValidationError
+				r.errorf(path, "mount '%s' auto_extend_size_threshold, auto_extend_size_increment and auto_extend_size_limit must be all defined or none", m.Source)
 			}
 			if m.AutoExtendSizeThreshold < 50 || m.AutoExtendSizeThreshold > 99 {
-				extraInfo += fmt.Sprintf("mount '%s' auto_extend_size_threshold must be between 50 and 99\n", m.Source)
-				err = ValidationError
+				r.errorf(path+".auto_extend_size_threshold", "mount '%s' auto_extend_size_threshold must be between 50 and 99", m.Source)
 			}
 			if autoExtendSizeIncrement < 1 || autoExtendSizeIncrement > 100 {
-				extraInfo += fmt.Sprintf("mount '%s' auto_extend_size_increment must be between 1GB and 100GB\n", m.Source)
-				err = ValidationError
+				r.errorf(path+".auto_extend_size_increment", "mount '%s' auto_extend_size_increment must be between 1GB and 100GB", m.Source)
 			}
 			if autoExtendSizeLimit != 0 && (autoExtendSizeLimit < 1 || autoExtendSizeLimit > 500) {
-				extraInfo += fmt.Sprintf("mount '%s' auto_extend_size_limit must be between 1GB and 500GB\n", m.Source)
-				err = ValidationError
+				r.errorf(path+".auto_extend_size_limit", "mount '%s' auto_extend_size_limit must be between 1GB and 500GB", m.Source)
 			}
 		}
 	}
-	return
 }
 
-func (cfg *Config) validateRestartPolicy() (extraInfo string, err error) {
+func (cfg *Config) validateRestartPolicy(r *ValidationReport) {
 	if cfg.Restart == nil {
 		return
 	}
 
-	for _, restart := range cfg.Restart {
+	for i, restart := range cfg.Restart {
+		path := fmt.Sprintf("restart[%d]", i)
 		validGroupNames := cfg.ProcessNames()
 
 		// first make sure restart.Processes matches a valid process name.
 		for _, processName := range restart.Processes {
 			if !slices.Contains(validGroupNames, processName) {
-				extraInfo += fmt.Sprintf("Restart policy specifies '%s' as one of its processes, but no processes are defined with that name; "+
-					"update fly.toml [processes] to add '%s' process or remove it from restart policy's processes list\n",
+				r.errorf(path+".processes", "Restart policy specifies '%s' as one of its processes, but no processes are defined with that name; "+
+					"update fly.toml [processes] to add '%s' process or remove it from restart policy's processes list",
 					processName, processName,
 				)
-				err = ValidationError
 			}
 		}
 
 		_, vErr := parseRestartPolicy(restart.Policy)
 		if vErr != nil {
-			extraInfo += fmt.Sprintf("%s\n", vErr)
-			err = ValidationError
+			r.errorf(path+".policy", "%s", vErr)
+		}
+
+		switch {
+		case restart.MaxRetries < 0:
+			r.errorf(path+".retries", "Restart policy retries must be zero or more")
+		case restart.MaxRetries > 0 && restart.Policy != RestartPolicyOnFailure:
+			r.warnf(path+".retries", "Restart policy retries only apply to the '%s' policy", RestartPolicyOnFailure)
 		}
 	}
+}
 
-	return
+func (cfg *Config) validateSecrets(r *ValidationReport) {
+	validGroupNames := cfg.ProcessNames()
+	seen := map[string]bool{}
+
+	for i, secrets := range cfg.Secrets {
+		path := fmt.Sprintf("secrets[%d]", i)
+
+		for _, processName := range secrets.Processes {
+			if !slices.Contains(validGroupNames, processName) {
+				r.errorf(path+".processes", "Secrets specify '%s' as one of their processes, but no processes are defined with that name; "+
+					"update fly.toml [processes] to add '%s' process or remove it from the secrets' processes list",
+					processName, processName,
+				)
+			}
+		}
+
+		groups := secrets.Processes
+		if len(groups) == 0 {
+			groups = []string{cfg.DefaultProcessName()}
+		}
+		for _, group := range groups {
+			if seen[group] {
+				r.warnf(path+".processes", "Process group '%s' is in several [[secrets]], it gets the secrets of all of them", group)
+			}
+			seen[group] = true
+		}
+
+		for _, name := range secrets.Names {
+			if name == "" || strings.ContainsAny(name, "= \t") {
+				r.errorf(path+".names", "Secret name '%s' is invalid", name)
+			}
+		}
+	}
+
+	if len(cfg.Secrets) == 0 {
+		return
+	}
+	for _, group := range validGroupNames {
+		if !seen[group] {
+			r.warnf("secrets", "Process group '%s' isn't in any [[secrets]], its machines get no secrets; add it to the processes of a [[secrets]] to give it some", group)
+		}
+	}
+}
+
+func (cfg *Config) validateSchemaVersion(r *ValidationReport) {
+	if cfg.SchemaVersion > CurrentSchemaVersion {
+		r.warnf("schema_version", "schema_version %d is newer than the version %d this flyctl supports; update flyctl", cfg.SchemaVersion, CurrentSchemaVersion)
+		return
+	}
+	if cfg.SchemaVersion == CurrentSchemaVersion {
+		return
+	}
+
+	cfgMap, err := loadConfigMap(cfg.ConfigFilePath())
+	if err != nil {
+		// Configs that don't come from a file have nothing to check
+		return
+	}
+	if deprecated := deprecatedForms(cfgMap); len(deprecated) > 0 {
+		r.warnf("schema_version",
+			"The config file uses deprecated forms of settings, run 'fly config migrate' to rewrite them:\n  %s",
+			strings.Join(deprecated, "\n  "),
+		)
+	}
+}
+
+func (cfg *Config) validateUnknownKeys(r *ValidationReport) {
+	unknownKeys, err := unknownKeysInFile(cfg.ConfigFilePath())
+	if err != nil {
+		// Configs that don't come from a file have nothing to check, and
+		// files that can't be parsed are already reported
+		return
+	}
+	for _, key := range unknownKeys {
+		r.errorf(key, "Unknown key '%s'; it doesn't match any setting and is ignored", key)
+	}
 }
@@ -3,10 +3,12 @@ package appconfig
 import (
 	"context"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/require"
+	fly "github.com/superfly/fly-go"
 	"github.com/superfly/flyctl/internal/cmdutil/preparers"
 	"github.com/superfly/flyctl/internal/flag"
 	"github.com/superfly/flyctl/internal/logger"
@@ -57,6 +59,25 @@ func TestConfig_ValidateMounts(t *testing.T) {
 	require.Contains(t, x, "group 'app' has more than one [[mounts]] section defined")
 }
 
+func TestConfig_ValidateStatics(t *testing.T) {
+	cfg, err := LoadConfig("./testdata/validate-statics.toml")
+	require.NoError(t, err)
+	require.NoError(t, cfg.SetMachinesPlatform())
+
+	err, x := cfg.Validate(_getValidationContext(t))
+	require.Error(t, err, x)
+	require.Contains(t, x, "static '/static' has a guest_path 'app/public' which isn't an absolute path in the image")
+	require.Contains(t, x, "static url_prefix '/static' is already used by statics[0]")
+	require.Contains(t, x, "static index_document 'docs/index.html' must be a file name, not a path")
+	require.Contains(t, x, "static url_prefix 'media' should start with '/'")
+
+	for _, f := range cfg.ValidateReport(_getValidationContext(t), false).Findings {
+		if strings.HasSuffix(f.Path, ".url_prefix") {
+			require.Equal(t, SeverityWarning, f.Severity, f.Message)
+		}
+	}
+}
+
 func TestConfig_ValidateServices(t *testing.T) {
 	cfg, err := LoadConfig("./testdata/validate-services.toml")
 	require.NoError(t, err)
@@ -71,3 +92,171 @@ func TestConfig_ValidateServices(t *testing.T) {
 	err, x = cfg.ValidateGroups(ctx, []string{"success"})
 	require.NoErrorf(t, err, x)
 }
+
+func TestConfig_ValidateDeploy(t *testing.T) {
+	cfg, err := LoadConfig("./testdata/validate-deploy.toml")
+	require.NoError(t, err)
+	require.NoError(t, cfg.SetMachinesPlatform())
+
+	ctx := _getValidationContext(t)
+	err, x := cfg.Validate(ctx)
+	require.Error(t, err, x)
+	require.Contains(t, x, "canary_count must be at least 1, got 0")
+
+	cfg.Deploy.CanaryCount = fly.Pointer(2)
+	err, x = cfg.Validate(ctx)
+	require.NoError(t, err, x)
+	require.NotContains(t, x, "only apply to the canary strategy")
+
+	cfg.Deploy.Strategy = "rolling"
+	err, x = cfg.Validate(ctx)
+	require.NoError(t, err, x)
+	require.Contains(t, x, "canary_count and bake_time only apply to the canary strategy, ignoring them for 'rolling'")
+}
+
+func TestConfig_ValidateRestart(t *testing.T) {
+	cfg, err := LoadConfig("./testdata/validate-restart.toml")
+	require.NoError(t, err)
+	require.NoError(t, cfg.SetMachinesPlatform())
+
+	ctx := _getValidationContext(t)
+	err, x := cfg.Validate(ctx)
+	require.NoError(t, err, x)
+	require.Contains(t, x, "Restart policy retries only apply to the 'on-failure' policy")
+
+	cfg.Restart[0].Policy = RestartPolicyOnFailure
+	err, x = cfg.Validate(ctx)
+	require.NoError(t, err, x)
+	require.NotContains(t, x, "Restart policy retries")
+
+	cfg.Restart[0].MaxRetries = -1
+	err, x = cfg.Validate(ctx)
+	require.Error(t, err, x)
+	require.Contains(t, x, "Restart policy retries must be zero or more")
+}
+
+func TestConfig_ValidateStrict(t *testing.T) {
+	cfg, err := LoadConfig("./testdata/validate-strict.toml")
+	require.NoError(t, err)
+	require.NoError(t, cfg.SetMachinesPlatform())
+
+	ctx := _getValidationContext(t)
+	report := cfg.ValidateReport(ctx, false)
+	require.Error(t, report.Err(), report.Info())
+	require.Equal(t, []ValidationFinding{{
+		Path:     "mounts[0].initial_size",
+		Severity: SeverityError,
+		Message:  "mount 'data' has an initial_size '15Mb' value which is smaller than 1GB",
+	}}, report.Findings)
+
+	report = cfg.ValidateReport(ctx, true)
+	require.Error(t, report.Err(), report.Info())
+	var unknown []string
+	for _, f := range report.Findings {
+		if strings.HasPrefix(f.Message, "Unknown key") {
+			require.Equal(t, SeverityError, f.Severity)
+			unknown = append(unknown, f.Path)
+		}
+	}
+	require.Equal(t, []string{"primry_region", "services[0].intenal_port", "vm[0].memroy"}, unknown)
+	require.Contains(t, report.Info(), "Unknown key 'services[0].intenal_port'")
+}
+
+func TestConfig_ValidateSecrets(t *testing.T) {
+	cfg, err := unmarshalTOML([]byte(`
+app = "foo"
+[processes]
+web = ""
+worker = ""
+[[secrets]]
+names = ["DATABASE_URL", "NOT A NAME"]
+processes = ["web", "missing"]
+[[secrets]]
+names = ["AWS_ACCESS_KEY_ID"]
+processes = ["web"]
+`))
+	require.NoError(t, err)
+	require.NoError(t, cfg.SetMachinesPlatform())
+
+	err, x := cfg.Validate(_getValidationContext(t))
+	require.Error(t, err)
+	require.Contains(t, x, "Secrets specify 'missing' as one of their processes")
+	require.Contains(t, x, "Secret name 'NOT A NAME' is invalid")
+	require.Contains(t, x, "Process group 'web' is in several [[secrets]]")
+	require.NotContains(t, x, "Process group 'web' isn't in any [[secrets]]")
+
+	// Without processes, [[secrets]] is for the default group only, as it is
+	// once flattened
+	cfg, err = unmarshalTOML([]byte(`
+app = "foo"
+[processes]
+app = ""
+worker = ""
+[[secrets]]
+names = ["DATABASE_URL"]
+`))
+	require.NoError(t, err)
+	require.NoError(t, cfg.SetMachinesPlatform())
+
+	_, x = cfg.Validate(_getValidationContext(t))
+	require.Contains(t, x, "Process group 'worker' isn't in any [[secrets]], its machines get no secrets")
+	require.NotContains(t, x, "Process group 'app' isn't in any")
+
+	app, err := cfg.Flatten("app")
+	require.NoError(t, err)
+	require.Equal(t, []ProcessSecrets{{Names: []string{"DATABASE_URL"}, Processes: []string{"app"}}}, app.Secrets)
+	worker, err := cfg.Flatten("worker")
+	require.NoError(t, err)
+	require.Empty(t, worker.Secrets)
+	require.True(t, worker.restrictSecrets)
+}
+
+func TestConfig_ValidateTLSOptions(t *testing.T) {
+	cfg, err := unmarshalTOML([]byte(`
+app = "foo"
+[[services]]
+internal_port = 8080
+[services.tls_options]
+min_version = "TLSv1.0"
+versions = ["TLSv1.3"]
+[services.tls_options.hsts]
+max_age = -1
+[[services.ports]]
+port = 443
+handlers = ["tls"]
+`))
+	require.NoError(t, err)
+	require.NoError(t, cfg.SetMachinesPlatform())
+
+	err, x := cfg.Validate(_getValidationContext(t))
+	require.Error(t, err, x)
+	require.Contains(t, x, "Unsupported TLS version 'TLSv1.0'")
+	require.Contains(t, x, "HSTS max_age must be a number of seconds, not -1")
+	require.Contains(t, x, "Service has hsts but no ports with both the http and tls handlers")
+}
+
+func TestConfig_ValidateFiles(t *testing.T) {
+	cfg, err := unmarshalTOML([]byte(`
+app = "foo"
+[[files]]
+guest_path = "etc/tls/key.pem"
+secret_name = "TLS_KEY"
+mode = 0o600
+[[files]]
+guest_path = "/etc/creds.json"
+secret_name = "CREDS"
+raw_value = "e30K"
+[[files]]
+guest_path = "/etc/app.conf"
+raw_value = "e30K"
+mode = 600000
+`))
+	require.NoError(t, err)
+	require.NoError(t, cfg.SetMachinesPlatform())
+
+	err, x := cfg.Validate(_getValidationContext(t))
+	require.Error(t, err, x)
+	require.Contains(t, x, "file guest_path 'etc/tls/key.pem' must be an absolute path")
+	require.Contains(t, x, "file '/etc/creds.json' must set one of local_path, secret_name or raw_value")
+	require.Contains(t, x, "file '/etc/app.conf' has an invalid mode 02223700, write it in octal like 0o600")
+}
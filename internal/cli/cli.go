@@ -100,6 +100,12 @@ func Run(ctx context.Context, io *iostreams.IOStreams, args ...string) int {
 	// shutdown background tasks, giving up to 5s for them to finish
 	task.FromContext(ctx).ShutdownWithTimeout(5 * time.Second)
 
+	if code, ok := flyerr.GetExitCode(err); ok {
+		// The command already reported whatever output it had; just relay
+		// the exit code.
+		return code
+	}
+
 	switch {
 	case err == nil:
 		return 0
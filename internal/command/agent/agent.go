@@ -29,9 +29,14 @@ func New() (cmd *cobra.Command) {
 	cmd.AddCommand(
 		newRun(),
 		newPing(),
+		newStatus(),
+		newLogs(),
 		newStart(),
 		newStop(),
 		newRestart(),
+		newInstallService(),
+		newUninstallService(),
+		newServiceStatus(),
 	)
 
 	if env.IsTruthy("DEV") {
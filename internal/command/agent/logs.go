@@ -0,0 +1,127 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/azazeal/pause"
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/agent"
+	"github.com/superfly/flyctl/iostreams"
+
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/flag"
+)
+
+func newLogs() (cmd *cobra.Command) {
+	const (
+		short = "Show the logs of the Fly agent"
+		long  = `Show the logs of the Fly agent running in the background, or of the
+last one when none runs, to find out why it failed.
+`
+	)
+
+	cmd = command.New("logs", short, long, runLogs)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.Int{
+			Name:        "lines",
+			Shorthand:   "n",
+			Description: "Number of lines to show from the end of the log, 0 for all of it",
+			Default:     100,
+		},
+		flag.Bool{
+			Name:        "follow",
+			Shorthand:   "f",
+			Description: "Keep showing the lines appended to the log",
+		},
+	)
+	return
+}
+
+func runLogs(ctx context.Context) (err error) {
+	out := iostreams.FromContext(ctx).Out
+
+	path, err := agentLogFile(ctx)
+	if err != nil {
+		return
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close() // skipcq: GO-S2307
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return
+	}
+	if _, err = out.Write(lastLines(data, flag.GetInt(ctx, "lines"))); err != nil {
+		return
+	}
+
+	if !flag.GetBool(ctx, "follow") {
+		return
+	}
+
+	for {
+		if _, err = io.Copy(out, f); err != nil {
+			return
+		}
+		if pause.For(ctx, 500*time.Millisecond); ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// agentLogFile returns the path of the log file of the running agent, or of
+// the last agent that ran in the background.
+func agentLogFile(ctx context.Context) (string, error) {
+	if client, err := agent.DefaultClient(ctx); err == nil {
+		status, err := client.Status(ctx)
+		switch {
+		case err != nil:
+			return "", fmt.Errorf("failed getting the agent status: %w", err)
+		case status.LogFile == "":
+			return "", errors.New("the running agent doesn't log to a file: it runs in the foreground or as a system service, its logs are in their output")
+		default:
+			return status.LogFile, nil
+		}
+	}
+
+	path, err := agent.LatestLogFile()
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		return "", errors.New("the agent isn't running and there are no logs of previous agents")
+	}
+	fmt.Fprintf(iostreams.FromContext(ctx).ErrOut, "The agent isn't running, showing the logs of the last one: %s\n", path)
+	return path, nil
+}
+
+// lastLines returns the last n lines of data, or all of it when n is 0.
+func lastLines(data []byte, n int) []byte {
+	if n <= 0 {
+		return data
+	}
+
+	end := len(bytes.TrimSuffix(data, []byte("\n")))
+	for i := end; i > 0; i-- {
+		if data[i-1] == '\n' {
+			if n--; n == 0 {
+				return data[i:]
+			}
+		}
+	}
+	return data
+}
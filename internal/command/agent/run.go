@@ -67,6 +67,8 @@ func run(ctx context.Context) error {
 		Background:       logPath != "",
 		ConfigFile:       state.ConfigFile(ctx),
 		ConfigWebsockets: viper.GetBool(flyctl.ConfigWireGuardWebsockets),
+		ConfigFallback:   viper.GetBool(flyctl.ConfigWireGuardWebsocketsFallback),
+		LogFile:          logPath,
 	}
 
 	return server.Run(ctx, opt)
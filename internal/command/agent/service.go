@@ -0,0 +1,167 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/agent"
+	"github.com/superfly/flyctl/iostreams"
+
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
+)
+
+func newInstallService() (cmd *cobra.Command) {
+	const (
+		short = "Install the Fly agent as a system service"
+		long  = `Install the Fly agent as a service of the current user, so its WireGuard
+tunnels survive logouts and reboots:
+
+  Linux    a systemd user unit, with lingering enabled for the user
+  macOS    a launchd agent, started at login
+  Windows  a scheduled task, started at logon
+
+The service runs the agent with the credentials of the flyctl config file,
+log in with 'fly auth login' first. Remove it with 'fly agent uninstall-service'.
+`
+	)
+
+	cmd = command.New("install-service", short, long, runInstallService)
+
+	cmd.Args = cobra.NoArgs
+
+	return
+}
+
+func newUninstallService() (cmd *cobra.Command) {
+	const (
+		short = "Uninstall the Fly agent system service"
+		long  = short + "\n"
+	)
+
+	cmd = command.New("uninstall-service", short, long, runUninstallService)
+
+	cmd.Args = cobra.NoArgs
+
+	return
+}
+
+func newServiceStatus() (cmd *cobra.Command) {
+	const (
+		short = "Show the status of the Fly agent system service"
+		long  = short + "\n"
+	)
+
+	cmd = command.New("service-status", short, long, runServiceStatus)
+
+	cmd.Args = cobra.NoArgs
+
+	return
+}
+
+func runInstallService(ctx context.Context) (err error) {
+	io := iostreams.FromContext(ctx)
+
+	// The service can't read the tokens of the environment of this shell
+	if config.Tokens(ctx).FromFile() == "" {
+		return errors.New("the agent service reads its credentials from the flyctl config file, log in with 'fly auth login' first")
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return
+	}
+
+	svc, err := newAgentService()
+	if err != nil {
+		return
+	}
+
+	// The service takes over from any agent already running
+	if client, err := agent.DefaultClient(ctx); err == nil {
+		_ = client.Kill(ctx)
+	}
+
+	if err = svc.install(ctx, exe); err != nil {
+		return fmt.Errorf("failed installing the agent service: %w", err)
+	}
+
+	fmt.Fprintf(io.Out, "Installed the Fly agent as a %s service (%s)\n", svc.manager, svc.path)
+	return
+}
+
+func runUninstallService(ctx context.Context) (err error) {
+	io := iostreams.FromContext(ctx)
+
+	svc, err := newAgentService()
+	if err != nil {
+		return
+	}
+
+	if err = svc.uninstall(ctx); err != nil {
+		return fmt.Errorf("failed uninstalling the agent service: %w", err)
+	}
+
+	fmt.Fprintf(io.Out, "Uninstalled the Fly agent %s service\n", svc.manager)
+	return
+}
+
+func runServiceStatus(ctx context.Context) (err error) {
+	io := iostreams.FromContext(ctx)
+
+	svc, err := newAgentService()
+	if err != nil {
+		return
+	}
+
+	installed := true
+	if _, err := os.Stat(svc.path); errors.Is(err, os.ErrNotExist) {
+		installed = false
+	}
+
+	state := "not installed"
+	if installed {
+		if state, err = svc.status(ctx); err != nil {
+			return fmt.Errorf("failed getting the status of the agent service: %w", err)
+		}
+	}
+
+	fmt.Fprintf(io.Out, "%-10s: %s\n", "Manager", svc.manager)
+	fmt.Fprintf(io.Out, "%-10s: %s\n", "Service", svc.path)
+	fmt.Fprintf(io.Out, "%-10s: %s\n", "Status", state)
+
+	if client, err := agent.DefaultClient(ctx); err == nil {
+		if pong, err := client.Ping(ctx); err == nil {
+			fmt.Fprintf(io.Out, "%-10s: %d (%s)\n", "Agent PID", pong.PID, pong.Version)
+		}
+	}
+
+	return nil
+}
+
+// agentService is the agent service of the service manager of the OS.
+type agentService struct {
+	// manager names the service manager
+	manager string
+	// path is the file defining the service
+	path string
+}
+
+// serviceCommand runs a command of the service manager, returning its output.
+func serviceCommand(ctx context.Context, name string, args ...string) (string, error) {
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return out.String(), fmt.Errorf("%s %s failed: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(out.String()))
+	}
+	return out.String(), nil
+}
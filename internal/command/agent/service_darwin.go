@@ -0,0 +1,94 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const launchdLabel = "io.fly.agent"
+
+func newAgentService() (*agentService, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	return &agentService{
+		manager: "launchd",
+		path:    filepath.Join(home, "Library", "LaunchAgents", launchdLabel+".plist"),
+	}, nil
+}
+
+func launchdDomain() string {
+	return fmt.Sprintf("gui/%d", os.Getuid())
+}
+
+func (s *agentService) install(ctx context.Context, exe string) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+
+	plist := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>%s</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>%s</string>
+		<string>agent</string>
+		<string>run</string>
+	</array>
+	<key>EnvironmentVariables</key>
+	<dict>
+		<key>FLY_NO_UPDATE_CHECK</key>
+		<string>1</string>
+	</dict>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<true/>
+	<key>StandardErrorPath</key>
+	<string>%s</string>
+</dict>
+</plist>
+`, launchdLabel, exe, filepath.Join(home, "Library", "Logs", "fly-agent.log"))
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.path, []byte(plist), 0o644); err != nil {
+		return err
+	}
+
+	// Replace the service of a previous install
+	_, _ = serviceCommand(ctx, "launchctl", "bootout", launchdDomain()+"/"+launchdLabel)
+	_, err = serviceCommand(ctx, "launchctl", "bootstrap", launchdDomain(), s.path)
+	return err
+}
+
+func (s *agentService) uninstall(ctx context.Context) error {
+	if _, err := os.Stat(s.path); os.IsNotExist(err) {
+		return fmt.Errorf("the agent service isn't installed")
+	}
+
+	_, _ = serviceCommand(ctx, "launchctl", "bootout", launchdDomain()+"/"+launchdLabel)
+	return os.Remove(s.path)
+}
+
+func (s *agentService) status(ctx context.Context) (string, error) {
+	out, err := serviceCommand(ctx, "launchctl", "print", launchdDomain()+"/"+launchdLabel)
+	if err != nil {
+		return "not loaded", nil
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if state, ok := strings.CutPrefix(strings.TrimSpace(line), "state = "); ok {
+			return state, nil
+		}
+	}
+	return "loaded", nil
+}
@@ -0,0 +1,86 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"github.com/superfly/flyctl/iostreams"
+)
+
+const systemdUnit = "fly-agent.service"
+
+func newAgentService() (*agentService, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return &agentService{
+		manager: "systemd",
+		path:    filepath.Join(dir, "systemd", "user", systemdUnit),
+	}, nil
+}
+
+func (s *agentService) install(ctx context.Context, exe string) error {
+	unit := fmt.Sprintf(`[Unit]
+Description=Fly.io agent, managing the WireGuard tunnels of flyctl
+Wants=network-online.target
+After=network-online.target
+
+[Service]
+ExecStart=%q agent run
+Environment=FLY_NO_UPDATE_CHECK=1
+Restart=on-failure
+RestartSec=5
+
+[Install]
+WantedBy=default.target
+`, exe)
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.path, []byte(unit), 0o644); err != nil {
+		return err
+	}
+
+	if _, err := serviceCommand(ctx, "systemctl", "--user", "daemon-reload"); err != nil {
+		return err
+	}
+	if _, err := serviceCommand(ctx, "systemctl", "--user", "enable", "--now", systemdUnit); err != nil {
+		return err
+	}
+
+	// Lingering keeps the user units running after logout and starts them at
+	// boot
+	if u, err := user.Current(); err == nil {
+		if _, err := serviceCommand(ctx, "loginctl", "enable-linger", u.Username); err != nil {
+			fmt.Fprintf(iostreams.FromContext(ctx).ErrOut, "Warning: the agent will stop at logout, enabling lingering failed: %v\n", err)
+		}
+	}
+	return nil
+}
+
+func (s *agentService) uninstall(ctx context.Context) error {
+	if _, err := os.Stat(s.path); os.IsNotExist(err) {
+		return fmt.Errorf("the agent service isn't installed")
+	}
+
+	if _, err := serviceCommand(ctx, "systemctl", "--user", "disable", "--now", systemdUnit); err != nil {
+		return err
+	}
+	if err := os.Remove(s.path); err != nil {
+		return err
+	}
+	_, err := serviceCommand(ctx, "systemctl", "--user", "daemon-reload")
+	return err
+}
+
+func (s *agentService) status(ctx context.Context) (string, error) {
+	// is-active exits non-zero for units that aren't active
+	out, _ := serviceCommand(ctx, "systemctl", "--user", "is-active", systemdUnit)
+	return strings.TrimSpace(out), nil
+}
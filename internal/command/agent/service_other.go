@@ -0,0 +1,19 @@
+//go:build !linux && !darwin && !windows
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+)
+
+func newAgentService() (*agentService, error) {
+	return nil, fmt.Errorf("installing the agent as a service isn't supported on %s", runtime.GOOS)
+}
+
+func (s *agentService) install(context.Context, string) error { return nil }
+
+func (s *agentService) uninstall(context.Context) error { return nil }
+
+func (s *agentService) status(context.Context) (string, error) { return "", nil }
@@ -0,0 +1,64 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const scheduledTask = "FlyAgent"
+
+func newAgentService() (*agentService, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return &agentService{
+		manager: "Task Scheduler",
+		// schtasks keeps its tasks to itself, this marks the install
+		path: filepath.Join(dir, "fly", "agent-service"),
+	}, nil
+}
+
+func (s *agentService) install(ctx context.Context, exe string) error {
+	run := fmt.Sprintf(`"%s" agent run`, exe)
+	if _, err := serviceCommand(ctx, "schtasks", "/Create", "/F", "/TN", scheduledTask, "/TR", run, "/SC", "ONLOGON", "/RL", "LIMITED"); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.path, []byte(scheduledTask+"\n"), 0o600); err != nil {
+		return err
+	}
+
+	_, err := serviceCommand(ctx, "schtasks", "/Run", "/TN", scheduledTask)
+	return err
+}
+
+func (s *agentService) uninstall(ctx context.Context) error {
+	_, _ = serviceCommand(ctx, "schtasks", "/End", "/TN", scheduledTask)
+	if _, err := serviceCommand(ctx, "schtasks", "/Delete", "/F", "/TN", scheduledTask); err != nil {
+		return err
+	}
+	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *agentService) status(ctx context.Context) (string, error) {
+	out, err := serviceCommand(ctx, "schtasks", "/Query", "/TN", scheduledTask, "/FO", "LIST")
+	if err != nil {
+		return "not registered", nil
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if status, ok := strings.CutPrefix(strings.TrimSpace(line), "Status:"); ok {
+			return strings.TrimSpace(status), nil
+		}
+	}
+	return "registered", nil
+}
@@ -0,0 +1,103 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/agent"
+	"github.com/superfly/flyctl/iostreams"
+
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/format"
+	"github.com/superfly/flyctl/internal/render"
+)
+
+func newStatus() (cmd *cobra.Command) {
+	const (
+		short = "Show the status of the Fly agent and its tunnels"
+		long  = `Show the status of the Fly agent and of its WireGuard tunnels: for each
+organization and network, the gateway it connects to and how, its last
+handshake, the bytes transferred and whether .internal names resolve through it.
+`
+	)
+
+	cmd = command.New("status", short, long, runStatus)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd, flag.JSONOutput())
+	return
+}
+
+func runStatus(ctx context.Context) (err error) {
+	var client *agent.Client
+	if client, err = dial(ctx); err != nil {
+		return
+	}
+
+	var status agent.StatusResponse
+	if status, err = client.Status(ctx); err != nil {
+		err = fmt.Errorf("failed getting the agent status: %w", err)
+
+		return
+	}
+
+	out := iostreams.FromContext(ctx).Out
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(out, status)
+	}
+
+	logFile := status.LogFile
+	if logFile == "" {
+		logFile = "-"
+	}
+	fmt.Fprintf(out, "%-10s: %d\n", "PID", status.PID)
+	fmt.Fprintf(out, "%-10s: %s\n", "Version", status.Version)
+	fmt.Fprintf(out, "%-10s: %t\n", "Background", status.Background)
+	fmt.Fprintf(out, "%-10s: %s\n", "Log file", logFile)
+	fmt.Fprintln(out)
+
+	if len(status.Tunnels) == 0 {
+		fmt.Fprintln(out, "No tunnels are open")
+		return
+	}
+
+	rows := make([][]string, 0, len(status.Tunnels))
+	for _, t := range status.Tunnels {
+		handshake, resolver := "never", "ok"
+		if !t.LastHandshake.IsZero() {
+			handshake = format.RelativeTime(t.LastHandshake)
+		}
+		switch {
+		case t.Error != "":
+			resolver = t.Error
+		case !t.ResolverOK:
+			resolver = t.ResolverError
+		}
+
+		network := t.Network
+		if network == "" {
+			network = "default"
+		}
+
+		rows = append(rows, []string{
+			t.Org,
+			network,
+			t.Peer,
+			t.Region,
+			t.Transport,
+			t.Endpoint,
+			handshake,
+			humanize.Bytes(uint64(t.RxBytes)),
+			humanize.Bytes(uint64(t.TxBytes)),
+			resolver,
+		})
+	}
+
+	return render.Table(out, "Tunnels", rows, "Org", "Network", "Peer", "Region", "Transport", "Endpoint", "Last Handshake", "Received", "Sent", "Resolver")
+}
@@ -45,10 +45,7 @@ fetch one with 'fly config save -a <app_name>'.`
 			Name:        "generate-name",
 			Description: "Generate an app name",
 		},
-		flag.String{
-			Name:        "network",
-			Description: "Specify custom network id",
-		},
+		flag.Network(),
 		flag.Bool{
 			Name:        "machines",
 			Description: "Use the machines platform",
@@ -0,0 +1,186 @@
+package certificates
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/azazeal/pause"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyutil"
+	"github.com/superfly/flyctl/iostreams"
+	"golang.org/x/net/publicsuffix"
+)
+
+// dnsProviders are the DNS providers --dns-provider automates the DNS-01
+// challenge with, and the environment variables their API token is read from
+// when --dns-token isn't given.
+var dnsProviders = map[string]string{
+	CLOUDFLARE: "CLOUDFLARE_API_TOKEN",
+}
+
+// dnsProviderToken returns the API token of the DNS provider of --dns-provider.
+func dnsProviderToken(ctx context.Context, provider string) (string, error) {
+	env, ok := dnsProviders[provider]
+	if !ok {
+		return "", fmt.Errorf("unsupported DNS provider %s, only %s is supported", provider, CLOUDFLARE)
+	}
+	if token := flag.GetString(ctx, "dns-token"); token != "" {
+		return token, nil
+	}
+	if token := os.Getenv(env); token != "" {
+		return token, nil
+	}
+	return "", fmt.Errorf("--dns-token or %s must be set to use --dns-provider %s", env, provider)
+}
+
+// automateDNSChallenge creates the CNAME record of the DNS-01 challenge of
+// cert with the API of provider, then waits for the platform to see it.
+func automateDNSChallenge(ctx context.Context, provider, token string, cert *fly.AppCertificate) (*fly.AppCertificate, error) {
+	io := iostreams.FromContext(ctx)
+	colorize := io.ColorScheme()
+
+	if cert.DNSValidationHostname == "" || cert.DNSValidationTarget == "" {
+		return nil, fmt.Errorf("the certificate for %s has no DNS-01 challenge", cert.Hostname)
+	}
+
+	zone, err := publicsuffix.EffectiveTLDPlusOne(strings.TrimPrefix(cert.Hostname, "*."))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the DNS zone of %s: %w", cert.Hostname, err)
+	}
+
+	fmt.Fprintf(io.Out, "Creating the record %s with %s...\n",
+		colorize.Bold(fmt.Sprintf("CNAME %s %s", cert.DNSValidationHostname, cert.DNSValidationTarget)), provider)
+
+	cf := &cloudflareClient{token: token}
+	if err := cf.setCNAME(ctx, zone, cert.DNSValidationHostname, cert.DNSValidationTarget); err != nil {
+		return nil, fmt.Errorf("failed to create the DNS-01 challenge record: %w", err)
+	}
+
+	return waitForDNSChallenge(ctx, cert.Hostname, flag.GetDuration(ctx, "dns-timeout"))
+}
+
+// waitForDNSChallenge polls the certificate of hostname until the platform
+// sees its DNS-01 challenge record.
+func waitForDNSChallenge(ctx context.Context, hostname string, timeout time.Duration) (*fly.AppCertificate, error) {
+	io := iostreams.FromContext(ctx)
+	apiClient := flyutil.ClientFromContext(ctx)
+	appName := appconfig.NameFromContext(ctx)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	fmt.Fprintln(io.Out, "Waiting for the record to propagate...")
+	for {
+		cert, _, err := apiClient.CheckAppCertificate(ctx, appName, hostname)
+		switch {
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			return nil, fmt.Errorf("the DNS-01 challenge record of %s wasn't seen within %s, check it with 'fly certs check %s'", hostname, timeout, hostname)
+		case err != nil:
+			return nil, err
+		case cert.AcmeDNSConfigured:
+			fmt.Fprintf(io.Out, "The DNS-01 challenge record of %s is configured\n", hostname)
+			return cert, nil
+		}
+
+		pause.For(ctx, 5*time.Second)
+	}
+}
+
+// cloudflareClient creates DNS records with the Cloudflare API.
+type cloudflareClient struct {
+	token string
+}
+
+const cloudflareAPI = "https://api.cloudflare.com/client/v4"
+
+type cloudflareRecord struct {
+	ID      string `json:"id,omitempty"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Content string `json:"content"`
+	TTL     int    `json:"ttl"`
+	Proxied bool   `json:"proxied"`
+}
+
+// setCNAME creates, or updates, the CNAME record name of zone.
+func (c *cloudflareClient) setCNAME(ctx context.Context, zone, name, target string) error {
+	var zones []struct {
+		ID string `json:"id"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/zones?name="+url.QueryEscape(zone), nil, &zones); err != nil {
+		return err
+	}
+	if len(zones) == 0 {
+		return fmt.Errorf("the zone %s isn't on the Cloudflare account of the token", zone)
+	}
+	zonePath := "/zones/" + zones[0].ID + "/dns_records"
+
+	var existing []cloudflareRecord
+	if err := c.do(ctx, http.MethodGet, zonePath+"?type=CNAME&name="+url.QueryEscape(name), nil, &existing); err != nil {
+		return err
+	}
+
+	// Proxied records would hide the challenge from Let's Encrypt
+	record := cloudflareRecord{Type: "CNAME", Name: name, Content: target, TTL: 60}
+	if len(existing) > 0 {
+		return c.do(ctx, http.MethodPut, zonePath+"/"+existing[0].ID, record, nil)
+	}
+	return c.do(ctx, http.MethodPost, zonePath, record, nil)
+}
+
+func (c *cloudflareClient) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader = http.NoBody
+	if in != nil {
+		buf, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(buf)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, cloudflareAPI+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // skipcq: GO-S2307
+
+	var result struct {
+		Success bool `json:"success"`
+		Errors  []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("unexpected Cloudflare API response (%s): %w", resp.Status, err)
+	}
+	if !result.Success {
+		messages := make([]string, 0, len(result.Errors))
+		for _, e := range result.Errors {
+			messages = append(messages, e.Message)
+		}
+		return fmt.Errorf("Cloudflare API error (%s): %s", resp.Status, strings.Join(messages, "; "))
+	}
+	if out != nil {
+		return json.Unmarshal(result.Result, out)
+	}
+	return nil
+}
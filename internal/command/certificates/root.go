@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net"
 	"strings"
+	"time"
 
 	"github.com/dustin/go-humanize"
 	fly "github.com/superfly/fly-go"
@@ -36,6 +37,7 @@ certificates issued for the hostname/domain by Let's Encrypt.`
 		newCertificatesRemove(),
 		newCertificatesShow(),
 		newCertificatesCheck(),
+		newCertificatesUpload(),
 	)
 	return cmd
 }
@@ -62,7 +64,18 @@ func newCertificatesAdd() *cobra.Command {
 	const (
 		short = "Add a certificate for an app."
 		long  = `Add a certificate for an application. Takes a hostname
-as a parameter for the certificate.`
+as a parameter for the certificate.
+
+Wildcard certificates, like "*.example.com", are validated with a DNS-01
+challenge: a CNAME record the platform asks for. With --dns-provider, the
+record is created with the API of the DNS provider and the command waits for
+it to be seen, e.g.
+
+  fly certs add "*.example.com" --dns-provider cloudflare
+
+The API token is read from --dns-token or, for Cloudflare, the
+CLOUDFLARE_API_TOKEN environment variable. It needs the permission to edit
+the DNS records of the zone.`
 	)
 	cmd := command.New("add <hostname>", short, long, runCertificatesAdd,
 		command.RequireSession,
@@ -72,6 +85,19 @@ as a parameter for the certificate.`
 		flag.App(),
 		flag.AppConfig(),
 		flag.JSONOutput(),
+		flag.String{
+			Name:        "dns-provider",
+			Description: "Create the DNS-01 challenge record with the API of this DNS provider: cloudflare",
+		},
+		flag.String{
+			Name:        "dns-token",
+			Description: "API token of the DNS provider of --dns-provider",
+		},
+		flag.Duration{
+			Name:        "dns-timeout",
+			Description: "How long to wait for the DNS-01 challenge record to be seen",
+			Default:     5 * time.Minute,
+		},
 	)
 	cmd.Args = cobra.ExactArgs(1)
 	cmd.Aliases = []string{"create"}
@@ -121,7 +147,12 @@ func newCertificatesCheck() *cobra.Command {
 	const (
 		short = "Checks DNS configuration"
 		long  = `Checks the DNS configuration for the specified hostname.
-Displays results in the same format as the SHOW command.`
+Displays results in the same format as the SHOW command.
+
+With --watch, the certificate is checked until it's issued, printing the DNS
+records still missing or incorrect as they change, and the command exits
+with a non-zero status if it isn't issued within --timeout, e.g. to wait for
+custom domains in CI.`
 	)
 	cmd := command.New("check <hostname>", short, long, runCertificatesCheck,
 		command.RequireSession,
@@ -131,6 +162,20 @@ Displays results in the same format as the SHOW command.`
 		flag.App(),
 		flag.AppConfig(),
 		flag.JSONOutput(),
+		flag.Bool{
+			Name:        "watch",
+			Description: "Check the certificate until it's issued",
+		},
+		flag.Duration{
+			Name:        "timeout",
+			Description: "How long to wait for the certificate to be issued with --watch",
+			Default:     10 * time.Minute,
+		},
+		flag.Int{
+			Name:        "rate",
+			Description: "Seconds between checks with --watch",
+			Default:     5,
+		},
 	)
 	cmd.Args = cobra.ExactArgs(1)
 	return cmd
@@ -140,7 +185,7 @@ func runCertificatesList(ctx context.Context) error {
 	appName := appconfig.NameFromContext(ctx)
 	apiClient := flyutil.ClientFromContext(ctx)
 
-	certs, err := apiClient.GetAppCertificates(ctx, appName)
+	certs, err := appCertificates(ctx, apiClient, appName)
 	if err != nil {
 		return err
 	}
@@ -148,6 +193,40 @@ func runCertificatesList(ctx context.Context) error {
 	return printCertificates(ctx, certs)
 }
 
+// appCertificates lists the certificates of an app with their source and
+// expiry, which GetAppCertificates leaves out.
+func appCertificates(ctx context.Context, apiClient flyutil.Client, appName string) ([]fly.AppCertificate, error) {
+	const query = `
+		query($appName: String!) {
+			app(name: $appName) {
+				certificates {
+					nodes {
+						createdAt
+						hostname
+						clientStatus
+						source
+						issued {
+							nodes {
+								type
+								expiresAt
+							}
+						}
+					}
+				}
+			}
+		}
+	`
+
+	req := apiClient.NewRequest(query)
+	req.Var("appName", appName)
+
+	data, err := apiClient.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return data.App.Certificates.Nodes, nil
+}
+
 func runCertificatesShow(ctx context.Context) error {
 	apiClient := flyutil.ClientFromContext(ctx)
 	appName := appconfig.NameFromContext(ctx)
@@ -172,6 +251,10 @@ func runCertificatesCheck(ctx context.Context) error {
 	appName := appconfig.NameFromContext(ctx)
 	hostname := flag.FirstArg(ctx)
 
+	if flag.GetBool(ctx, "watch") {
+		return watchCertificate(ctx, hostname)
+	}
+
 	cert, hostcheck, err := apiClient.CheckAppCertificate(ctx, appName, hostname)
 	if err != nil {
 		return err
@@ -191,11 +274,29 @@ func runCertificatesAdd(ctx context.Context) error {
 	appName := appconfig.NameFromContext(ctx)
 	hostname := flag.FirstArg(ctx)
 
+	provider := flag.GetString(ctx, "dns-provider")
+	var token string
+	if provider != "" {
+		var err error
+		if token, err = dnsProviderToken(ctx, provider); err != nil {
+			return err
+		}
+	}
+
 	cert, hostcheck, err := apiClient.AddCertificate(ctx, appName, hostname)
 	if err != nil {
 		return err
 	}
 
+	if provider != "" && !cert.AcmeDNSConfigured {
+		if cert, err = automateDNSChallenge(ctx, provider, token, cert); err != nil {
+			return err
+		}
+		if _, hostcheck, err = apiClient.CheckAppCertificate(ctx, appName, hostname); err != nil {
+			return err
+		}
+	}
+
 	return reportNextStepCert(ctx, hostname, cert, hostcheck)
 }
 
@@ -466,7 +567,7 @@ func readableCertAuthority(ca string) string {
 	return ca
 }
 
-func printCertificates(ctx context.Context, certs []fly.AppCertificateCompact) error {
+func printCertificates(ctx context.Context, certs []fly.AppCertificate) error {
 	io := iostreams.FromContext(ctx)
 
 	if config.FromContext(ctx).JSONOutput {
@@ -474,14 +575,29 @@ func printCertificates(ctx context.Context, certs []fly.AppCertificateCompact) e
 		return nil
 	}
 
-	fmt.Fprintf(io.Out, "%-25s %-20s %s\n", "Host Name", "Added", "Status")
+	fmt.Fprintf(io.Out, "%-25s %-20s %-20s %-10s %s\n", "Host Name", "Added", "Status", "Source", "Expires")
 	for _, v := range certs {
-		fmt.Fprintf(io.Out, "%-25s %-20s %s\n", v.Hostname, humanize.Time(v.CreatedAt), v.ClientStatus)
+		expires := ""
+		if expiresAt := certificateExpiry(v); !expiresAt.IsZero() {
+			expires = humanize.Time(expiresAt)
+		}
+		fmt.Fprintf(io.Out, "%-25s %-20s %-20s %-10s %s\n", v.Hostname, humanize.Time(v.CreatedAt), v.ClientStatus, v.Source, expires)
 	}
 
 	return nil
 }
 
+// certificateExpiry returns when the first of the issued certificates of cert
+// expires, or the zero time when none are issued.
+func certificateExpiry(cert fly.AppCertificate) (expiresAt time.Time) {
+	for _, issued := range cert.Issued.Nodes {
+		if expiresAt.IsZero() || issued.ExpiresAt.Before(expiresAt) {
+			expiresAt = issued.ExpiresAt
+		}
+	}
+	return expiresAt
+}
+
 func getAlternateHostname(hostname string) string {
 	if strings.Split(hostname, ".")[0] == "www" {
 		return strings.Replace(hostname, "www.", "", 1)
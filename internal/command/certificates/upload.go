@@ -0,0 +1,158 @@
+package certificates
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/superfly/flyctl/gql"
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyutil"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func newCertificatesUpload() *cobra.Command {
+	const (
+		short = "Upload a certificate for an app."
+		long  = `Upload a certificate and its private key for an application, for
+certificates that can't be issued with ACME, like EV or internally-issued
+ones. The certificate file holds the full chain, the certificate first,
+in PEM format.
+
+The hostname of the certificate is its Common Name, unless --hostname is
+given. Uploading another certificate for the same hostname rotates it.
+'fly certs list' shows the source and expiry of the certificates.`
+	)
+	cmd := command.New("upload", short, long, runCertificatesUpload,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.JSONOutput(),
+		flag.String{
+			Name:        "cert",
+			Description: "Path of the PEM certificate chain",
+		},
+		flag.String{
+			Name:        "key",
+			Description: "Path of the PEM private key of the certificate",
+		},
+		flag.String{
+			Name:        "hostname",
+			Description: "Hostname of the certificate, its Common Name by default",
+		},
+	)
+	cmd.Args = cobra.NoArgs
+	cmd.Aliases = []string{"import"}
+	return cmd
+}
+
+func runCertificatesUpload(ctx context.Context) error {
+	io := iostreams.FromContext(ctx)
+	colorize := io.ColorScheme()
+	client := flyutil.ClientFromContext(ctx).GenqClient()
+	appName := appconfig.NameFromContext(ctx)
+
+	certPath, keyPath := flag.GetString(ctx, "cert"), flag.GetString(ctx, "key")
+	if certPath == "" || keyPath == "" {
+		return errors.New("--cert and --key must be specified")
+	}
+
+	fullchain, err := os.ReadFile(certPath)
+	if err != nil {
+		return err
+	}
+	privateKey, err := os.ReadFile(keyPath)
+	if err != nil {
+		return err
+	}
+
+	leaf, err := parseCertificatePair(fullchain, privateKey)
+	if err != nil {
+		return err
+	}
+
+	hostname := flag.GetString(ctx, "hostname")
+	if hostname == "" {
+		hostname = leaf.Subject.CommonName
+	}
+	// Wildcard hostnames are left to the platform to check
+	if !strings.HasPrefix(hostname, "*.") {
+		if err := leaf.VerifyHostname(hostname); err != nil {
+			return fmt.Errorf("%s isn't valid for %s: %w", certPath, hostname, err)
+		}
+	}
+
+	_ = `# @genqlient
+	mutation ImportCertificate(
+		$appId: ID!,
+		$fullchain: String!,
+		$privateKey: String!,
+		# @genqlient(omitempty: true)
+		$hostname: String,
+	) {
+		importCertificate(appId: $appId, fullchain: $fullchain, privateKey: $privateKey, hostname: $hostname) {
+			appCertificate {
+				hostname
+				clientStatus
+			}
+			certificate {
+				hostname
+				type
+				expiresAt
+			}
+			errors
+		}
+	}
+	`
+
+	resp, err := gql.ImportCertificate(ctx, client, appName, string(fullchain), string(privateKey), hostname)
+	if err != nil {
+		return err
+	}
+	if errs := resp.ImportCertificate.Errors; len(errs) > 0 {
+		return fmt.Errorf("failed to upload the certificate: %s", strings.Join(errs, "; "))
+	}
+
+	imported := resp.ImportCertificate.Certificate
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(io.Out, imported)
+	}
+
+	fmt.Fprintf(io.Out, "Certificate for %s uploaded to app %s, it expires on %s\n",
+		colorize.Bold(imported.Hostname),
+		colorize.Bold(appName),
+		imported.ExpiresAt.Format(time.RFC3339),
+	)
+	return nil
+}
+
+// parseCertificatePair checks that a PEM certificate chain and private key
+// match and returns the certificate of the chain.
+func parseCertificatePair(fullchain, privateKey []byte) (*x509.Certificate, error) {
+	pair, err := tls.X509KeyPair(fullchain, privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate or private key: %w", err)
+	}
+
+	leaf, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate: %w", err)
+	}
+	if time.Now().After(leaf.NotAfter) {
+		return nil, fmt.Errorf("the certificate expired on %s", leaf.NotAfter.Format(time.RFC3339))
+	}
+	return leaf, nil
+}
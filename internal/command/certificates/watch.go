@@ -0,0 +1,110 @@
+package certificates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/azazeal/pause"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyutil"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func watchCertificate(ctx context.Context, hostname string) error {
+	rate := flag.GetInt(ctx, "rate")
+	if rate < 1 || rate > 3600 {
+		return errors.New("--rate must be in the [1, 3600] range")
+	}
+
+	return WatchCertificate(ctx, hostname, flag.GetDuration(ctx, "timeout"), time.Duration(rate)*time.Second)
+}
+
+// WatchCertificate checks the certificate of hostname every interval until
+// it's issued, printing what's still missing whenever it changes, and fails
+// if it isn't issued within timeout.
+func WatchCertificate(ctx context.Context, hostname string, timeout, interval time.Duration) error {
+	io := iostreams.FromContext(ctx)
+	colorize := io.ColorScheme()
+	apiClient := flyutil.ClientFromContext(ctx)
+	appName := appconfig.NameFromContext(ctx)
+
+	ips, err := apiClient.GetIPAddresses(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var last string
+	for {
+		cert, hostcheck, err := apiClient.CheckAppCertificate(ctx, appName, hostname)
+		switch {
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			return fmt.Errorf("the certificate for %s wasn't issued within %s, last status: %s", hostname, timeout, last)
+		case err != nil:
+			return err
+		case cert.ClientStatus == "Ready":
+			printCertificate(ctx, cert)
+			return nil
+		}
+
+		status := fmt.Sprintf("%s, waiting for %s", cert.ClientStatus, strings.Join(pendingCertRequirements(cert, hostcheck, ips), "; "))
+		if status != last {
+			fmt.Fprintf(io.Out, "%s %s\n", colorize.Gray(time.Now().UTC().Format("15:04:05")), status)
+			last = status
+		}
+
+		pause.For(ctx, interval)
+	}
+}
+
+// pendingCertRequirements lists the DNS records a certificate still waits
+// for, as far as they can be told from its check.
+func pendingCertRequirements(cert *fly.AppCertificate, hostcheck *fly.HostnameCheck, ips []fly.IPAddress) []string {
+	var pending []string
+
+	// Wildcard certificates are only validated with the DNS-01 challenge
+	if cert.IsWildcard && !cert.AcmeDNSConfigured {
+		pending = append(pending, fmt.Sprintf("CNAME %s %s", cert.DNSValidationHostname, cert.DNSValidationTarget))
+	}
+
+	if !cert.IsWildcard && !cert.Configured {
+		var addresses []string
+		for _, ip := range ips {
+			if ip.Type == "v4" || ip.Type == "shared_v4" || ip.Type == "v6" {
+				addresses = append(addresses, ip.Address)
+			}
+		}
+
+		var wrong []string
+		for _, record := range append(append([]string{}, hostcheck.ARecords...), hostcheck.AAAARecords...) {
+			ip := net.ParseIP(record)
+			matches := false
+			for _, address := range addresses {
+				matches = matches || ip.Equal(net.ParseIP(address))
+			}
+			if !matches {
+				wrong = append(wrong, record)
+			}
+		}
+
+		switch {
+		case len(wrong) > 0:
+			pending = append(pending, fmt.Sprintf("%s to resolve to %s instead of %s", cert.Hostname, strings.Join(addresses, ", "), strings.Join(wrong, ", ")))
+		case len(hostcheck.ARecords) == 0 && len(hostcheck.AAAARecords) == 0:
+			pending = append(pending, fmt.Sprintf("A/AAAA records of %s for %s", cert.Hostname, strings.Join(addresses, ", ")))
+		}
+	}
+
+	if len(pending) == 0 {
+		pending = append(pending, "the certificate to be issued")
+	}
+	return pending
+}
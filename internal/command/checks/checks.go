@@ -1,6 +1,8 @@
 package checks
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
 	"github.com/superfly/flyctl/internal/command"
 	"github.com/superfly/flyctl/internal/flag"
@@ -20,5 +22,22 @@ func New() *cobra.Command {
 	)
 	flag.Add(listCmd, flag.JSONOutput())
 	cmd.AddCommand(listCmd)
+
+	// fly checks history
+	historyLong := `Show the history of health checks: the last transition of each check
+along with the events of machines, like exits and restarts, and the machines
+exiting unexpectedly over and over.
+
+The platform only keeps the last transition of checks. With --follow, checks
+are polled and their transitions recorded as they happen, and the checks that
+change state repeatedly are reported as flapping when stopped.`
+	historyCmd := command.New("history [check-name]", "Show the history of health checks", historyLong, runAppCheckHistory, command.RequireSession, command.RequireAppName)
+	historyCmd.Args = cobra.MaximumNArgs(1)
+	flag.Add(historyCmd, commonFlags,
+		flag.String{Name: "machine", Description: "Only show the checks of this machine"},
+		flag.Bool{Name: "follow", Shorthand: "f", Description: "Record check transitions as they happen"},
+		flag.Duration{Name: "interval", Default: 5 * time.Second, Description: "Polling interval of --follow"},
+	)
+	cmd.AddCommand(historyCmd)
 	return cmd
 }
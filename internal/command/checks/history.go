@@ -0,0 +1,263 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/azazeal/pause"
+	"github.com/samber/lo"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/fly-go/flaps"
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flapsutil"
+	"github.com/superfly/flyctl/internal/format"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+// flapThreshold is how many state changes make a check or a machine flapping.
+const flapThreshold = 3
+
+func runAppCheckHistory(ctx context.Context) error {
+	appName := appconfig.NameFromContext(ctx)
+	out := iostreams.FromContext(ctx).Out
+
+	var checkName string
+	if args := flag.Args(ctx); len(args) > 0 {
+		checkName = args[0]
+	}
+
+	flapsClient, err := flapsutil.NewClientWithOptions(ctx, flaps.NewClientOpts{
+		AppName: appName,
+	})
+	if err != nil {
+		return err
+	}
+
+	machines, err := historyMachines(ctx, flapsClient)
+	if err != nil {
+		return err
+	}
+
+	if flag.GetBool(ctx, "follow") {
+		return followChecks(ctx, out, flapsClient, machines, checkName)
+	}
+
+	var checkRows [][]string
+	for _, machine := range machines {
+		for _, check := range machine.Checks {
+			if checkName != "" && check.Name != checkName {
+				continue
+			}
+
+			since := "-"
+			if check.UpdatedAt != nil {
+				since = format.RelativeTime(*check.UpdatedAt)
+			}
+			checkRows = append(checkRows, []string{machine.ID, check.Name, string(check.Status), since, check.Output})
+		}
+	}
+	if err := render.Table(out, "Last check transitions", checkRows, "Machine", "Check", "Status", "Since", "Output"); err != nil {
+		return err
+	}
+
+	var eventRows [][]string
+	var flapping []string
+	for _, machine := range machines {
+		events := machine.Events
+		sort.Slice(events, func(i, j int) bool { return events[i].Timestamp > events[j].Timestamp })
+
+		var exits int
+		for _, event := range events {
+			info := ""
+			if event.Request != nil && event.Request.ExitEvent != nil {
+				exit := event.Request.ExitEvent
+				info = fmt.Sprintf("exit_code=%d oom_killed=%t requested_stop=%t", exit.ExitCode, exit.OOMKilled, exit.RequestedStop)
+				if !exit.RequestedStop {
+					exits++
+				}
+			}
+			eventRows = append(eventRows, []string{
+				event.Time().UTC().Format(time.RFC3339),
+				machine.ID,
+				event.Type,
+				event.Status,
+				event.Source,
+				info,
+			})
+		}
+
+		if exits >= flapThreshold {
+			flapping = append(flapping, fmt.Sprintf("%s exited unexpectedly %d times since %s", machine.ID, exits, events[len(events)-1].Time().UTC().Format(time.RFC3339)))
+		}
+	}
+	sort.SliceStable(eventRows, func(i, j int) bool { return eventRows[i][0] > eventRows[j][0] })
+	if err := render.Table(out, "Machine events", eventRows, "Time", "Machine", "Event", "Status", "Source", "Info"); err != nil {
+		return err
+	}
+
+	if len(flapping) > 0 {
+		fmt.Fprintln(out, "Flapping machines:")
+		for _, f := range flapping {
+			fmt.Fprintf(out, "  %s\n", f)
+		}
+		fmt.Fprintln(out)
+	}
+
+	fmt.Fprintln(out, "The platform only keeps the last transition of checks; run with --follow to record them as they happen.")
+	return nil
+}
+
+// historyMachines returns the machines of the app, or the one of --machine,
+// along with their events.
+func historyMachines(ctx context.Context, flapsClient flapsutil.FlapsClient) ([]*fly.Machine, error) {
+	if id := flag.GetString(ctx, "machine"); id != "" {
+		machine, err := flapsClient.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return []*fly.Machine{machine}, nil
+	}
+
+	list, err := flapsClient.ListActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+
+	// Listed machines come without their events
+	machines := make([]*fly.Machine, 0, len(list))
+	for _, m := range list {
+		machine, err := flapsClient.Get(ctx, m.ID)
+		if err != nil {
+			return nil, err
+		}
+		machines = append(machines, machine)
+	}
+	return machines, nil
+}
+
+// checkTransition is a change of the status of a check of a machine.
+type checkTransition struct {
+	At      time.Time
+	Machine string
+	Check   string
+	From    fly.ConsulCheckStatus
+	To      fly.ConsulCheckStatus
+	Output  string
+}
+
+// checkTracker records the transitions of checks between polls.
+type checkTracker struct {
+	statuses    map[string]fly.ConsulCheckStatus
+	transitions map[string]int
+}
+
+func newCheckTracker() *checkTracker {
+	return &checkTracker{
+		statuses:    map[string]fly.ConsulCheckStatus{},
+		transitions: map[string]int{},
+	}
+}
+
+// observe returns the transitions of the checks named checkName, or all of
+// them, since the previous observation.
+func (t *checkTracker) observe(machines []*fly.Machine, checkName string, now time.Time) []checkTransition {
+	var transitions []checkTransition
+	for _, machine := range machines {
+		for _, check := range machine.Checks {
+			if checkName != "" && check.Name != checkName {
+				continue
+			}
+
+			key := machine.ID + "/" + check.Name
+			prev, ok := t.statuses[key]
+			t.statuses[key] = check.Status
+			if !ok || prev == check.Status {
+				continue
+			}
+
+			t.transitions[key]++
+			transitions = append(transitions, checkTransition{
+				At:      now,
+				Machine: machine.ID,
+				Check:   check.Name,
+				From:    prev,
+				To:      check.Status,
+				Output:  check.Output,
+			})
+		}
+	}
+	return transitions
+}
+
+// flapping returns the checks, as machine/check, that changed state at least
+// flapThreshold times.
+func (t *checkTracker) flapping() map[string]int {
+	flapping := map[string]int{}
+	for key, n := range t.transitions {
+		if n >= flapThreshold {
+			flapping[key] = n
+		}
+	}
+	return flapping
+}
+
+func followChecks(ctx context.Context, out io.Writer, flapsClient flapsutil.FlapsClient, machines []*fly.Machine, checkName string) error {
+	interval := flag.GetDuration(ctx, "interval")
+	if interval < time.Second {
+		interval = time.Second
+	}
+
+	started := time.Now()
+	tracker := newCheckTracker()
+	tracker.observe(machines, checkName, started)
+
+	fmt.Fprintf(out, "Recording check transitions every %s, press Ctrl-C to stop\n", interval)
+
+	for {
+		pause.For(ctx, interval)
+		if ctx.Err() != nil {
+			break
+		}
+
+		current, err := flapsClient.ListActive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			fmt.Fprintf(out, "Failed to list machines: %v\n", err)
+			continue
+		}
+		if id := flag.GetString(ctx, "machine"); id != "" {
+			current = lo.Filter(current, func(m *fly.Machine, _ int) bool { return m.ID == id })
+		}
+
+		for _, t := range tracker.observe(current, checkName, time.Now()) {
+			fmt.Fprintf(out, "%s %s %s: %s → %s %s\n", t.At.UTC().Format(time.RFC3339), t.Machine, t.Check, t.From, t.To, t.Output)
+		}
+	}
+
+	flapping := tracker.flapping()
+	keys := make([]string, 0, len(flapping))
+	for key := range flapping {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fmt.Fprintln(out)
+	if len(keys) == 0 {
+		fmt.Fprintf(out, "No flapping checks in the last %s\n", time.Since(started).Round(time.Second))
+		return nil
+	}
+
+	fmt.Fprintf(out, "Flapping checks in the last %s:\n", time.Since(started).Round(time.Second))
+	for _, key := range keys {
+		fmt.Fprintf(out, "  %s changed state %d times\n", key, flapping[key])
+	}
+	return nil
+}
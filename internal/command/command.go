@@ -10,7 +10,6 @@ import (
 	"path/filepath"
 	"runtime"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/skratchdot/open-golang/open"
@@ -511,7 +510,9 @@ func startMetrics(ctx context.Context) (context.Context, error) {
 
 func notifyStatuspageIncidents(ctx context.Context) (context.Context, error) {
 	if shouldIgnore(ctx, [][]string{
+		{"incidents"},
 		{"incidents", "list"},
+		{"status"},
 	}) {
 		return ctx, nil
 	}
@@ -646,22 +647,19 @@ func LoadAppConfigIfPresent(ctx context.Context) (context.Context, error) {
 	return ctx, nil
 }
 
-// appConfigFilePaths returns the possible paths at which we may find a fly.toml
-// in order of preference. it takes into consideration whether the user has
-// specified a command-line path to a config file.
+// appConfigFilePaths returns the possible paths at which we may find a fly.toml,
+// fly.json or fly.yaml in order of preference. it takes into consideration
+// whether the user has specified a command-line path to a config file.
 func appConfigFilePaths(ctx context.Context) (paths []string) {
+	dir := state.WorkingDirectory(ctx)
 	if p := flag.GetAppConfigFilePath(ctx); p != "" {
-		paths = append(paths, p, filepath.Join(p, appconfig.DefaultConfigFileName))
-
-		return
+		paths = append(paths, p)
+		dir = p
 	}
 
-	wd := state.WorkingDirectory(ctx)
-	paths = append(paths,
-		filepath.Join(wd, appconfig.DefaultConfigFileName),
-		filepath.Join(wd, strings.Replace(appconfig.DefaultConfigFileName, ".toml", ".json", 1)),
-		filepath.Join(wd, strings.Replace(appconfig.DefaultConfigFileName, ".toml", ".yaml", 1)),
-	)
+	for _, name := range appconfig.ConfigFileNames {
+		paths = append(paths, filepath.Join(dir, name))
+	}
 
 	return
 }
@@ -1,9 +1,14 @@
 package config
 
 import (
+	"context"
+
 	"github.com/spf13/cobra"
 
+	"github.com/superfly/flyctl/internal/appconfig"
 	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/state"
 )
 
 // New initializes and returns a new platform Command.
@@ -19,6 +24,22 @@ func New() (cmd *cobra.Command) {
 		newSave(),
 		newValidate(),
 		newEnv(),
+		newDiff(),
+		newImport(),
+		newMigrate(),
+		newGet(),
+		newSet(),
+		newStatics(),
 	)
 	return
 }
+
+// localConfigPath returns the path of the config file given with --config, or
+// else of the one in the working directory.
+func localConfigPath(ctx context.Context) (string, error) {
+	path := state.WorkingDirectory(ctx)
+	if flag.IsSpecified(ctx, "config") {
+		path = flag.GetString(ctx, "config")
+	}
+	return appconfig.ResolveConfigFileFromPath(path)
+}
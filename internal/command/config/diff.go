@@ -0,0 +1,205 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/superfly/fly-go/flaps"
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flapsutil"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func newDiff() (cmd *cobra.Command) {
+	const (
+		short = "Show the differences between the local and deployed configuration"
+		long  = `Compare the local fly.toml with the configuration of the app deployed on
+the Fly service, section by section. Settings only in the local file are
+prefixed with +, settings only deployed with -, and changed settings with ~.
+The next deploy applies the local side.`
+	)
+	cmd = command.New("diff", short, long, runDiff,
+		command.RequireSession,
+		command.RequireAppName,
+		command.LoadAppConfigIfPresent,
+	)
+	cmd.Args = cobra.NoArgs
+	flag.Add(cmd, flag.App(), flag.AppConfig())
+	return
+}
+
+// configSections are compared in this order, each listing the parts of the
+// config it covers.
+var configSections = []struct {
+	name string
+	get  func(*appconfig.Config) map[string]any
+}{
+	{"app", func(c *appconfig.Config) map[string]any {
+		return map[string]any{"primary_region": c.PrimaryRegion, "kill_signal": c.KillSignal, "kill_timeout": c.KillTimeout, "console_command": c.ConsoleCommand}
+	}},
+	{"env", func(c *appconfig.Config) map[string]any {
+		// The env of process groups is keyed env.<group>.<KEY>, apart from
+		// the env.<KEY> of the app, even when a group is named like a key
+		env := map[string]any{"env": c.Env}
+		for group, groupEnv := range c.ProcessEnv {
+			env["env."+group] = groupEnv
+		}
+		return env
+	}},
+	{"processes", func(c *appconfig.Config) map[string]any { return map[string]any{"processes": c.Processes} }},
+	{"services", func(c *appconfig.Config) map[string]any {
+		return map[string]any{"http_service": c.HTTPService, "services": c.Services}
+	}},
+	{"checks", func(c *appconfig.Config) map[string]any {
+		return map[string]any{"checks": c.Checks, "machine_checks": c.MachineChecks}
+	}},
+	{"mounts", func(c *appconfig.Config) map[string]any { return map[string]any{"mounts": c.Mounts} }},
+	{"compute", func(c *appconfig.Config) map[string]any {
+		return map[string]any{"vm": c.Compute, "host_dedication_id": c.HostDedicationID, "swap_size_mb": c.SwapSizeMB}
+	}},
+	{"deploy", func(c *appconfig.Config) map[string]any {
+		return map[string]any{"deploy": c.Deploy, "restart": c.Restart, "experimental": c.Experimental}
+	}},
+	{"files", func(c *appconfig.Config) map[string]any {
+		return map[string]any{"files": c.Files, "statics": c.Statics, "metrics": c.Metrics}
+	}},
+}
+
+type configChange struct {
+	path   string
+	local  string
+	remote string
+}
+
+func runDiff(ctx context.Context) error {
+	var (
+		io       = iostreams.FromContext(ctx)
+		appName  = appconfig.NameFromContext(ctx)
+		localCfg = appconfig.ConfigFromContext(ctx)
+	)
+
+	if localCfg == nil {
+		return fmt.Errorf("No local fly.toml found")
+	}
+
+	flapsClient, err := flapsutil.NewClientWithOptions(ctx, flaps.NewClientOpts{
+		AppName: appName,
+	})
+	if err != nil {
+		return err
+	}
+	ctx = flapsutil.NewContextWithClient(ctx, flapsClient)
+
+	remoteCfg, err := appconfig.FromRemoteApp(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	for _, section := range configSections {
+		changes, err := diffSection(section.get(localCfg), section.get(remoteCfg))
+		if err != nil {
+			return err
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		changed = true
+		printChanges(io.Out, io.ColorScheme(), section.name, changes)
+	}
+
+	if !changed {
+		fmt.Fprintf(io.Out, "The local configuration matches the one deployed for %s\n", appName)
+	}
+	return nil
+}
+
+// diffSection compares the flattened JSON form of both sides, so empty and
+// unset settings are the same and the order of map keys doesn't matter.
+func diffSection(local, remote map[string]any) ([]configChange, error) {
+	localValues, err := flattenConfig(local)
+	if err != nil {
+		return nil, err
+	}
+	remoteValues, err := flattenConfig(remote)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []configChange
+	for path, lv := range localValues {
+		if rv := remoteValues[path]; rv != lv {
+			changes = append(changes, configChange{path: path, local: lv, remote: rv})
+		}
+	}
+	for path, rv := range remoteValues {
+		if _, ok := localValues[path]; !ok {
+			changes = append(changes, configChange{path: path, remote: rv})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].path < changes[j].path })
+	return changes, nil
+}
+
+// flattenConfig maps the path of every leaf setting in v, e.g.
+// services[0].internal_port, to its JSON value.
+func flattenConfig(v any) (map[string]string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+
+	values := map[string]string{}
+	var walk func(prefix string, v any)
+	walk = func(prefix string, v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			for k, child := range v {
+				path := k
+				if prefix != "" {
+					path = prefix + "." + k
+				}
+				walk(path, child)
+			}
+		case []any:
+			for i, child := range v {
+				walk(prefix+"["+strconv.Itoa(i)+"]", child)
+			}
+		case nil:
+		case string:
+			if v != "" {
+				values[prefix] = strconv.Quote(v)
+			}
+		default:
+			data, _ := json.Marshal(v)
+			values[prefix] = string(data)
+		}
+	}
+	walk("", generic)
+	return values, nil
+}
+
+func printChanges(w io.Writer, colorize *iostreams.ColorScheme, section string, changes []configChange) {
+	fmt.Fprintln(w, colorize.Bold(section))
+	for _, c := range changes {
+		switch {
+		case c.remote == "":
+			fmt.Fprintln(w, colorize.Green(fmt.Sprintf("  + %s = %s", c.path, c.local)))
+		case c.local == "":
+			fmt.Fprintln(w, colorize.Red(fmt.Sprintf("  - %s = %s", c.path, c.remote)))
+		default:
+			fmt.Fprintln(w, colorize.Yellow(fmt.Sprintf("  ~ %s = %s -> %s", c.path, c.remote, c.local)))
+		}
+	}
+}
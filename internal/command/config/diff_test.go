@@ -0,0 +1,86 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/superfly/flyctl/internal/appconfig"
+)
+
+func TestDiffSection(t *testing.T) {
+	tests := []struct {
+		name   string
+		local  map[string]any
+		remote map[string]any
+		want   []configChange
+	}{
+		{
+			name:   "same",
+			local:  map[string]any{"kill_signal": "SIGINT", "kill_timeout": 5},
+			remote: map[string]any{"kill_timeout": 5, "kill_signal": "SIGINT"},
+		},
+		{
+			name:   "empty and unset",
+			local:  map[string]any{"console_command": "", "swap_size_mb": nil},
+			remote: map[string]any{},
+		},
+		{
+			name:   "changed",
+			local:  map[string]any{"kill_signal": "SIGTERM"},
+			remote: map[string]any{"kill_signal": "SIGINT"},
+			want:   []configChange{{path: "kill_signal", local: `"SIGTERM"`, remote: `"SIGINT"`}},
+		},
+		{
+			name:   "added and removed",
+			local:  map[string]any{"primary_region": "ams"},
+			remote: map[string]any{"kill_timeout": 5},
+			want: []configChange{
+				{path: "kill_timeout", remote: "5"},
+				{path: "primary_region", local: `"ams"`},
+			},
+		},
+		{
+			name: "nested",
+			local: map[string]any{"services": []any{
+				map[string]any{"internal_port": 8080, "ports": []any{map[string]any{"port": 443}}},
+			}},
+			remote: map[string]any{"services": []any{
+				map[string]any{"internal_port": 8080, "ports": []any{map[string]any{"port": 80}}},
+			}},
+			want: []configChange{{path: "services[0].ports[0].port", local: "443", remote: "80"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := diffSection(tt.local, tt.remote)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, changes)
+		})
+	}
+}
+
+func TestFlattenConfigEnv(t *testing.T) {
+	cfg := &appconfig.Config{
+		Env: map[string]string{"LOG_LEVEL": "info", "worker": "1"},
+		ProcessEnv: map[string]map[string]string{
+			"worker": {"LOG_LEVEL": "debug"},
+		},
+	}
+
+	var env map[string]any
+	for _, section := range configSections {
+		if section.name == "env" {
+			env = section.get(cfg)
+		}
+	}
+
+	values, err := flattenConfig(env)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"env.LOG_LEVEL":        `"info"`,
+		"env.worker":           `"1"`,
+		"env.worker.LOG_LEVEL": `"debug"`,
+	}, values)
+}
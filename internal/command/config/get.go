@@ -0,0 +1,53 @@
+package config
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func newGet() (cmd *cobra.Command) {
+	const (
+		short = "Print a setting of the local config file"
+		long  = `Print the setting at the given key path of the local config file, like
+env.LOG_LEVEL, services[0].internal_port or http_service.concurrency.
+
+Strings are printed as is, other values in JSON format. Settings are read as
+flyctl understands them, after rewriting deprecated forms, so the value may be
+written differently in the file. Fails when the setting isn't set.`
+		usage = "get <key path>"
+	)
+	cmd = command.New(usage, short, long, runGet)
+	cmd.Args = cobra.ExactArgs(1)
+	flag.Add(cmd, flag.AppConfig())
+	return
+}
+
+func runGet(ctx context.Context) error {
+	io := iostreams.FromContext(ctx)
+
+	path, err := localConfigPath(ctx)
+	if err != nil {
+		return err
+	}
+	cfg, err := appconfig.LoadConfig(path)
+	if err != nil {
+		return err
+	}
+
+	v, err := cfg.GetValue(flag.FirstArg(ctx))
+	if err != nil {
+		return err
+	}
+	if s, ok := v.(string); ok {
+		fmt.Fprintln(io.Out, s)
+		return nil
+	}
+	return render.JSON(io.Out, v)
+}
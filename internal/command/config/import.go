@@ -0,0 +1,130 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/prompt"
+	"github.com/superfly/flyctl/internal/state"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func newImport() (cmd *cobra.Command) {
+	const (
+		short = "Create a config file from a Docker Compose file or Kubernetes manifests"
+		long  = `Create an application's config file from a Docker Compose file. Every
+service running the app's image, the one the first service builds, becomes a
+process group with its command, environment, ports, volume mounts,
+healthcheck and restart policy. Services running other images, like
+databases, are left out to be deployed as separate apps.
+
+With --k8s, the config file is created from Kubernetes manifests instead. Every
+Deployment or StatefulSet running the app's image, the one of the first
+workload, becomes a process group with the command, environment, resources,
+probe and persistent volume of its first container. ConfigMaps referenced by
+the environment are inlined, and Services selecting the workload become its
+services. The command to scale each group to its replicas is printed.
+
+A warning is printed for every setting that can't be translated.`
+	)
+	cmd = command.New("import", short, long, runImport)
+	cmd.Args = cobra.NoArgs
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Yes(),
+		flag.String{
+			Name:        "compose",
+			Description: "Path of the Docker Compose file to import",
+			Default:     "docker-compose.yml",
+		},
+		flag.StringSlice{
+			Name:        "k8s",
+			Description: "Paths of the Kubernetes manifests to import, comma separated, instead of a Docker Compose file",
+		},
+	)
+	return
+}
+
+func runImport(ctx context.Context) error {
+	io := iostreams.FromContext(ctx)
+
+	var (
+		cfg      *appconfig.Config
+		counts   map[string]int
+		warnings []string
+	)
+	if manifests := flag.GetStringSlice(ctx, "k8s"); len(manifests) > 0 {
+		if flag.IsSpecified(ctx, "compose") {
+			return fmt.Errorf("--compose and --k8s can't be used together")
+		}
+		var bufs [][]byte
+		for _, manifest := range manifests {
+			buf, err := os.ReadFile(manifest)
+			if err != nil {
+				return err
+			}
+			bufs = append(bufs, buf)
+		}
+
+		var err error
+		cfg, counts, warnings, err = appconfig.FromKubernetes(flag.GetApp(ctx), bufs...)
+		if err != nil {
+			return fmt.Errorf("failed to import %s: %w", strings.Join(manifests, ", "), err)
+		}
+	} else {
+		buf, err := os.ReadFile(flag.GetString(ctx, "compose"))
+		if err != nil {
+			return err
+		}
+
+		cfg, warnings, err = appconfig.FromCompose(flag.GetApp(ctx), buf)
+		if err != nil {
+			return fmt.Errorf("failed to import %s: %w", flag.GetString(ctx, "compose"), err)
+		}
+	}
+
+	path := state.WorkingDirectory(ctx)
+	if flag.IsSpecified(ctx, "config") {
+		path = flag.GetString(ctx, "config")
+	}
+	configfilename, err := appconfig.ResolveConfigFileFromPath(path)
+	if err != nil {
+		return err
+	}
+
+	if exists, _ := appconfig.ConfigFileExistsAtPath(configfilename); exists && !flag.GetYes(ctx) {
+		confirmation, err := prompt.Confirmf(ctx,
+			"An existing configuration file has been found\nOverwrite file '%s'", configfilename)
+		if err != nil {
+			return err
+		}
+		if !confirmation {
+			return nil
+		}
+	}
+
+	for _, w := range warnings {
+		fmt.Fprintln(io.ErrOut, io.ColorScheme().Yellow(w))
+	}
+
+	if err := cfg.WriteToDisk(ctx, configfilename); err != nil {
+		return err
+	}
+
+	if len(counts) > 0 {
+		groups := lo.Keys(counts)
+		sort.Strings(groups)
+		scale := lo.Map(groups, func(group string, _ int) string { return fmt.Sprintf("%s=%d", group, counts[group]) })
+		fmt.Fprintf(io.Out, "After the first deploy, run the same number of machines as replicas with:\n  fly scale count %s\n", strings.Join(scale, " "))
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/superfly/flyctl/helpers"
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/prompt"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func newMigrate() (cmd *cobra.Command) {
+	const (
+		short = "Rewrite the config file to the current schema"
+		long  = `Rewrite the local config file to the current schema version, replacing the
+deprecated forms of settings, like kill_timeout in [experimental] or checks
+given as an array, with their current form and setting schema_version.
+
+The changes are shown before the file is written. Comments other than the
+ones at the top of the file are not kept.`
+	)
+	cmd = command.New("migrate", short, long, runMigrate)
+	cmd.Args = cobra.NoArgs
+	flag.Add(cmd,
+		flag.AppConfig(),
+		flag.Yes(),
+		flag.Bool{
+			Name:        "dry-run",
+			Description: "Only show the changes, without writing the file",
+		},
+	)
+	return
+}
+
+func runMigrate(ctx context.Context) error {
+	var (
+		io       = iostreams.FromContext(ctx)
+		colorize = io.ColorScheme()
+	)
+
+	path, err := localConfigPath(ctx)
+	if err != nil {
+		return err
+	}
+
+	migration, err := appconfig.MigrateConfigFile(path)
+	if err != nil {
+		return err
+	}
+	relPath := helpers.PathRelativeToCWD(path)
+
+	if !migration.Changed() {
+		fmt.Fprintf(io.Out, "%s already uses the current schema, version %d\n", relPath, appconfig.CurrentSchemaVersion)
+		return nil
+	}
+
+	if len(migration.Deprecated) > 0 {
+		fmt.Fprintf(io.Out, "Deprecated settings in %s:\n", relPath)
+		for _, d := range migration.Deprecated {
+			fmt.Fprintf(io.Out, "  %s\n", d)
+		}
+		fmt.Fprintln(io.Out)
+	}
+	fmt.Fprintf(io.Out, "Changes to %s:\n%s\n", relPath, migration.Diff(colorize))
+
+	if flag.GetBool(ctx, "dry-run") {
+		return nil
+	}
+
+	if !flag.GetYes(ctx) {
+		switch confirmed, err := prompt.Confirmf(ctx, "Write the changes to %s?", relPath); {
+		case err == nil:
+			if !confirmed {
+				return nil
+			}
+		case prompt.IsNonInteractive(err):
+			return prompt.NonInteractiveError("--yes flag must be specified when not running interactively")
+		default:
+			return err
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, migration.Migrated, info.Mode()); err != nil {
+		return err
+	}
+	fmt.Fprintf(io.Out, "Migrated %s to schema version %d\n", relPath, appconfig.CurrentSchemaVersion)
+	return nil
+}
@@ -23,7 +23,8 @@ func newSave() (cmd *cobra.Command) {
 	const (
 		short = "Save an app's config file"
 		long  = `Save an application's configuration locally. The configuration data is
-retrieved from the Fly service and saved in TOML format.`
+retrieved from the Fly service and saved in the format of the existing config
+file, fly.toml, fly.json or fly.yaml, or in TOML format when there is none.`
 	)
 	cmd = command.New("save", short, long, runSave,
 		command.RequireSession,
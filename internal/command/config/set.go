@@ -0,0 +1,60 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/superfly/flyctl/helpers"
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func newSet() (cmd *cobra.Command) {
+	const (
+		short = "Change settings of the local config file"
+		long  = `Change settings of the local config file in place, given as key path and
+value pairs like env.LOG_LEVEL=debug or services[0].internal_port=8081.
+
+Values are read as TOML, e.g. 8081, true or ["http", "tls"], or else as a
+string. Settings that aren't in the file yet are added to their table, which is
+created when missing. Comments and formatting of fly.toml are kept, while
+fly.json and fly.yaml are written again. Nothing is written when a setting is
+unknown or invalid.`
+		usage = "set <key path>=<value> [<key path>=<value> ...]"
+	)
+	cmd = command.New(usage, short, long, runSet)
+	cmd.Args = cobra.MinimumNArgs(1)
+	flag.Add(cmd, flag.AppConfig())
+	return
+}
+
+func runSet(ctx context.Context) error {
+	io := iostreams.FromContext(ctx)
+
+	path, err := localConfigPath(ctx)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	buf, err = appconfig.SetConfigFileValues(path, buf, flag.Args(ctx))
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, buf, info.Mode()); err != nil {
+		return err
+	}
+	fmt.Fprintf(io.Out, "Updated %s\n", helpers.PathRelativeToCWD(path))
+	return nil
+}
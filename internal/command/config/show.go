@@ -3,13 +3,17 @@ package config
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/spf13/cobra"
+	fly "github.com/superfly/fly-go"
 	"github.com/superfly/fly-go/flaps"
 	"github.com/superfly/flyctl/internal/appconfig"
 	"github.com/superfly/flyctl/internal/command"
 	"github.com/superfly/flyctl/internal/flag"
 	"github.com/superfly/flyctl/internal/flapsutil"
+	"github.com/superfly/flyctl/internal/render"
 	"github.com/superfly/flyctl/iostreams"
 )
 
@@ -17,7 +21,14 @@ func newShow() (cmd *cobra.Command) {
 	const (
 		short = "Show an app's configuration"
 		long  = `Show an application's configuration. The configuration is presented by default
-in JSON format. The configuration data is retrieved from the Fly service.`
+in JSON format. The configuration data is retrieved from the Fly service, or
+with --local read from the local fly.toml, fly.json or fly.yaml.
+
+With --machine-config, the machine config of each process group is shown
+instead, as deploy sends it to create the group's machines, to find out where
+their services, checks or guest come from. Deploy then sets the image, the
+release metadata, the volume to mount and, when the config has none, the
+default guest.`
 	)
 	cmd = command.New("show", short, long, runShow,
 		command.RequireSession,
@@ -29,7 +40,7 @@ in JSON format. The configuration data is retrieved from the Fly service.`
 	flag.Add(cmd, flag.App(), flag.AppConfig(),
 		flag.Bool{
 			Name:        "local",
-			Description: "Parse and show local fly.toml file instead of fetching from the Fly service",
+			Description: "Parse and show the local config file instead of fetching from the Fly service",
 		},
 		flag.Bool{
 			Name:        "yaml",
@@ -39,6 +50,11 @@ in JSON format. The configuration data is retrieved from the Fly service.`
 			Name:        "toml",
 			Description: "Show configuration in TOML format",
 		},
+		flag.Bool{
+			Name:        "machine-config",
+			Description: "Show the machine config of each process group, in JSON format",
+		},
+		flag.ProcessGroup("With --machine-config, only show the machine config of this process group"),
 	)
 	return
 }
@@ -65,10 +81,14 @@ func runShow(ctx context.Context) error {
 	} else {
 		cfg = appconfig.ConfigFromContext(ctx)
 		if cfg == nil {
-			return fmt.Errorf("No local fly.toml found")
+			return fmt.Errorf("No local fly.toml, fly.json or fly.yaml found")
 		}
 	}
 
+	if flag.GetBool(ctx, "machine-config") {
+		return showMachineConfigs(ctx, cfg)
+	}
+
 	format := "json"
 
 	if flag.GetBool(ctx, "yaml") {
@@ -85,3 +105,32 @@ func runShow(ctx context.Context) error {
 
 	return nil
 }
+
+// showMachineConfigs prints the machine config of every process group of cfg,
+// or only of the one given by --process-group, by group name.
+func showMachineConfigs(ctx context.Context, cfg *appconfig.Config) error {
+	io := iostreams.FromContext(ctx)
+
+	if err := cfg.SetMachinesPlatform(); err != nil {
+		return err
+	}
+
+	groups := cfg.ProcessNames()
+	if group := flag.GetProcessGroup(ctx); group != "" {
+		if !slices.Contains(groups, group) {
+			return fmt.Errorf("process group '%s' not found in the config, it has %s", group, strings.Join(groups, ", "))
+		}
+		groups = []string{group}
+	}
+
+	mConfigs := make(map[string]*fly.MachineConfig, len(groups))
+	for _, group := range groups {
+		mConfig, err := cfg.ToMachineConfig(group, nil)
+		if err != nil {
+			return fmt.Errorf("failed to build the machine config of process group '%s': %w", group, err)
+		}
+		mConfigs[group] = mConfig
+	}
+
+	return render.JSON(io.Out, mConfigs)
+}
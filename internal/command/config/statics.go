@@ -0,0 +1,276 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"github.com/superfly/flyctl/helpers"
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func newStatics() (cmd *cobra.Command) {
+	const (
+		short = "Work with the [[statics]] of the local config file"
+		long  = `Work with the [[statics]] of the local config file, the directories of the
+image the Fly.io proxy serves without going through the app.`
+	)
+	cmd = command.New("statics", short, long, nil)
+	cmd.AddCommand(newStaticsPreview())
+	return
+}
+
+func newStaticsPreview() (cmd *cobra.Command) {
+	const (
+		short = "Serve the statics of the local config file locally"
+		long  = `Serve the [[statics]] of the local config file on localhost, each under its
+url_prefix, to check what they will serve once deployed.
+
+The guest_path of a static is a directory of the image, served from the local
+directory the Dockerfile copies to it, e.g. ./public for
+"COPY public /app/public". When the Dockerfile doesn't tell, like for files
+built in another stage, map the guest path to a local directory with --map.
+Statics served from a Tigris bucket aren't previewed.`
+	)
+	cmd = command.New("preview", short, long, runStaticsPreview)
+	cmd.Args = cobra.NoArgs
+	flag.Add(cmd,
+		flag.AppConfig(),
+		flag.Int{
+			Name:        "port",
+			Description: "Port to serve the statics on",
+			Default:     8080,
+		},
+		flag.StringArray{
+			Name:        "map",
+			Description: "Serve a guest path from a local directory, as GUEST_PATH=LOCAL_DIR. Can be specified multiple times.",
+		},
+	)
+	return
+}
+
+func runStaticsPreview(ctx context.Context) error {
+	var (
+		io       = iostreams.FromContext(ctx)
+		colorize = io.ColorScheme()
+	)
+
+	configPath, err := localConfigPath(ctx)
+	if err != nil {
+		return err
+	}
+	cfg, err := appconfig.LoadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	if len(cfg.Statics) == 0 {
+		return fmt.Errorf("%s has no [[statics]]", helpers.PathRelativeToCWD(configPath))
+	}
+
+	mapped := map[string]string{}
+	for _, m := range flag.GetStringArray(ctx, "map") {
+		guestPath, localDir, ok := strings.Cut(m, "=")
+		if !ok {
+			return fmt.Errorf("invalid --map %q, expected GUEST_PATH=LOCAL_DIR", m)
+		}
+		mapped[path.Clean(guestPath)] = localDir
+	}
+
+	contextDir := filepath.Dir(configPath)
+	dockerfile := cfg.Dockerfile()
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+	copies, err := dockerfileCopies(filepath.Join(contextDir, dockerfile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	served := 0
+	for _, s := range cfg.Statics {
+		prefix := "/" + strings.Trim(s.UrlPrefix, "/")
+		if s.TigrisBucket != "" {
+			fmt.Fprintf(io.ErrOut, "%s %s is served from the Tigris bucket %s, skipping\n", colorize.Yellow("WARN"), prefix, s.TigrisBucket)
+			continue
+		}
+
+		dir, ok := mapped[path.Clean(s.GuestPath)]
+		if !ok {
+			dir, ok = localStaticsDir(copies, contextDir, s.GuestPath)
+		}
+		if !ok {
+			fmt.Fprintf(io.ErrOut, "%s can't find the local directory of %s, skipping; map it with --map %s=<dir>\n", colorize.Yellow("WARN"), s.GuestPath, s.GuestPath)
+			continue
+		}
+
+		pattern := strings.TrimSuffix(prefix, "/") + "/"
+		handler := http.StripPrefix(strings.TrimSuffix(prefix, "/"), staticsHandler(dir, s.IndexDocument))
+		if err := registerHandler(mux, pattern, handler); err != nil {
+			fmt.Fprintf(io.ErrOut, "%s %s is served by another static, skipping\n", colorize.Yellow("WARN"), prefix)
+			continue
+		}
+		fmt.Fprintf(io.Out, "  %s -> %s\n", colorize.Bold(pattern), helpers.PathRelativeToCWD(dir))
+		served++
+	}
+	if served == 0 {
+		return fmt.Errorf("no statics to serve")
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort("localhost", strconv.Itoa(flag.GetInt(ctx, "port"))))
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{Handler: mux}
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+
+	fmt.Fprintf(io.Out, "Serving the statics on http://%s, press Ctrl+C to stop\n", listener.Addr())
+	if err := srv.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// registerHandler adds handler to mux, failing rather than panicking when
+// another handler already has pattern.
+func registerHandler(mux *http.ServeMux, pattern string, handler http.Handler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	mux.Handle(pattern, handler)
+	return nil
+}
+
+// staticsHandler serves the files of dir like the Fly.io proxy: directories
+// aren't listed, they serve index, if set.
+func staticsHandler(dir, index string) http.Handler {
+	files := http.FileServer(http.Dir(dir))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/") {
+			files.ServeHTTP(w, r)
+			return
+		}
+		if index == "" {
+			http.NotFound(w, r)
+			return
+		}
+
+		f, err := http.Dir(dir).Open(path.Join(r.URL.Path, index))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil || info.IsDir() {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeContent(w, r, index, info.ModTime(), f)
+	})
+}
+
+// dockerfileCopy is a COPY or ADD of local files to the final image.
+type dockerfileCopy struct {
+	sources []string
+	dest    string
+}
+
+// dockerfileCopies returns the COPY and ADD instructions of the last stage of
+// a Dockerfile copying files from the build context, with absolute
+// destinations.
+func dockerfileCopies(dockerfile string) ([]dockerfileCopy, error) {
+	f, err := os.Open(dockerfile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	result, err := parser.Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", dockerfile, err)
+	}
+
+	workdir := "/"
+	resolve := func(p string) string {
+		if path.IsAbs(p) {
+			return path.Clean(p)
+		}
+		return path.Join(workdir, p)
+	}
+
+	var copies []dockerfileCopy
+	for _, node := range result.AST.Children {
+		var args []string
+		for arg := node.Next; arg != nil; arg = arg.Next {
+			args = append(args, arg.Value)
+		}
+
+		switch strings.ToLower(node.Value) {
+		case "from":
+			workdir = "/"
+			copies = nil
+		case "workdir":
+			if len(args) > 0 {
+				workdir = resolve(args[0])
+			}
+		case "copy", "add":
+			fromStage := lo.ContainsBy(node.Flags, func(f string) bool { return strings.HasPrefix(f, "--from") })
+			if len(args) < 2 || fromStage {
+				continue
+			}
+			copies = append(copies, dockerfileCopy{
+				sources: args[:len(args)-1],
+				dest:    resolve(args[len(args)-1]),
+			})
+		}
+	}
+	return copies, nil
+}
+
+// localStaticsDir returns the local directory that the last of copies
+// covering guestPath copies to it.
+func localStaticsDir(copies []dockerfileCopy, contextDir, guestPath string) (string, bool) {
+	guestPath = path.Clean(guestPath)
+	for i := len(copies) - 1; i >= 0; i-- {
+		c := copies[i]
+
+		var rel string
+		switch {
+		case guestPath == c.dest:
+			rel = "."
+		case c.dest == "/":
+			rel = strings.TrimPrefix(guestPath, "/")
+		case strings.HasPrefix(guestPath, c.dest+"/"):
+			rel = strings.TrimPrefix(guestPath, c.dest+"/")
+		default:
+			continue
+		}
+
+		for _, source := range c.sources {
+			dir := filepath.Join(contextDir, filepath.FromSlash(source), filepath.FromSlash(rel))
+			if info, err := os.Stat(dir); err == nil && info.IsDir() {
+				return dir, true
+			}
+		}
+	}
+	return "", false
+}
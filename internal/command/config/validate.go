@@ -7,7 +7,9 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/superfly/flyctl/internal/appconfig"
 	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
 	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/render"
 	"github.com/superfly/flyctl/iostreams"
 )
 
@@ -15,14 +17,24 @@ func newValidate() (cmd *cobra.Command) {
 	const (
 		short = "Validate an app's config file"
 		long  = `Validates an application's config file against the Fly platform to
-ensure it is correct and meaningful to the platform.`
+ensure it is correct and meaningful to the platform. With --strict, keys that
+don't match any setting are errors instead of being ignored. With --json, each
+finding is listed with the path of the setting it is about, e.g.
+services[0].internal_port, and its severity, for editors and CI to annotate
+fly.toml. Config files in JSON (fly.json) and YAML (fly.yaml) are validated
+the same way.`
 	)
 	cmd = command.New("validate", short, long, runValidate,
 		command.RequireSession,
 		command.RequireAppName,
 	)
 	cmd.Args = cobra.NoArgs
-	flag.Add(cmd, flag.App(), flag.AppConfig())
+	flag.Add(cmd, flag.App(), flag.AppConfig(), flag.JSONOutput(),
+		flag.Bool{
+			Name:        "strict",
+			Description: "Report keys that don't match any setting as errors",
+		},
+	)
 	return
 }
 
@@ -33,7 +45,22 @@ func runValidate(ctx context.Context) error {
 	if err := cfg.SetMachinesPlatform(); err != nil {
 		return err
 	}
-	err, extra_info := cfg.Validate(ctx)
-	fmt.Fprintln(io.Out, extra_info)
-	return err
+	report := cfg.ValidateReport(ctx, flag.GetBool(ctx, "strict"))
+
+	if config.FromContext(ctx).JSONOutput {
+		findings := report.Findings
+		if findings == nil {
+			findings = []appconfig.ValidationFinding{}
+		}
+		if err := render.JSON(io.Out, map[string]any{
+			"valid":    report.Err() == nil,
+			"findings": findings,
+		}); err != nil {
+			return err
+		}
+		return report.Err()
+	}
+
+	fmt.Fprintln(io.Out, report.Info())
+	return report.Err()
 }
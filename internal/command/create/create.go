@@ -22,10 +22,7 @@ func New() (cmd *cobra.Command) {
 			Name:        "generate-name",
 			Description: "Generate an app name",
 		},
-		flag.String{
-			Name:        "network",
-			Description: "Specify custom network id",
-		},
+		flag.Network(),
 		flag.Bool{
 			Name:        "machines",
 			Description: "Use the machines platform",
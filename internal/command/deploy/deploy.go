@@ -167,7 +167,11 @@ var CommonFlags = flag.Set{
 	flag.String{
 		Name:        "signal",
 		Shorthand:   "s",
-		Description: "Signal to stop the machine with for bluegreen strategy (default: SIGINT)",
+		Description: "Signal to stop machines with before this deploy updates them, kill_signal still applies to later stops (default: the app's kill_signal)",
+	},
+	flag.String{
+		Name:        "stop-timeout",
+		Description: "Time duration to wait for machines to stop gracefully before this deploy kills them, kill_timeout still applies to later stops (default: the app's kill_timeout)",
 	},
 	flag.String{
 		Name:        "deploy-retries",
@@ -462,6 +466,11 @@ func deployToMachines(
 		return err
 	}
 
+	stopTimeout, err := parseDurationFlag(ctx, "stop-timeout")
+	if err != nil {
+		return err
+	}
+
 	files, err := command.FilesFromCommand(ctx)
 	if err != nil {
 		return err
@@ -568,6 +577,7 @@ func deployToMachines(
 		SkipReleaseCommand:    flag.GetBool(ctx, "skip-release-command"),
 		WaitTimeout:           waitTimeout,
 		StopSignal:            flag.GetString(ctx, "signal"),
+		StopTimeout:           stopTimeout,
 		ReleaseCmdTimeout:     releaseCmdTimeout,
 		LeaseTimeout:          leaseTimeout,
 		MaxUnavailable:        maxUnavailable,
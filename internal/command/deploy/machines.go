@@ -56,6 +56,7 @@ type MachineDeploymentArgs struct {
 	RestartOnly           bool
 	WaitTimeout           *time.Duration
 	StopSignal            string
+	StopTimeout           *time.Duration
 	LeaseTimeout          *time.Duration
 	ReleaseCmdTimeout     *time.Duration
 	Guest                 *fly.MachineGuest
@@ -92,6 +93,7 @@ func argsFromManifest(manifest *DeployManifest, app *fly.AppCompact) MachineDepl
 		RestartOnly:           manifest.RestartOnly,
 		WaitTimeout:           manifest.WaitTimeout,
 		StopSignal:            manifest.StopSignal,
+		StopTimeout:           manifest.StopTimeout,
 		LeaseTimeout:          manifest.LeaseTimeout,
 		ReleaseCmdTimeout:     manifest.ReleaseCmdTimeout,
 		Guest:                 manifest.Guest,
@@ -130,9 +132,12 @@ type machineDeployment struct {
 	skipDNSChecks         bool
 	skipReleaseCommand    bool
 	maxUnavailable        float64
+	canaryCount           int
+	bakeTime              time.Duration
 	restartOnly           bool
 	waitTimeout           time.Duration
 	stopSignal            string
+	stopTimeout           time.Duration
 	leaseTimeout          time.Duration
 	leaseDelayBetween     time.Duration
 	releaseCmdTimeout     time.Duration
@@ -229,6 +234,11 @@ func NewMachineDeployment(ctx context.Context, args MachineDeploymentArgs) (_ Ma
 		leaseTimeout = DefaultLeaseTtl
 	}
 
+	var stopTimeout time.Duration
+	if args.StopTimeout != nil {
+		stopTimeout = *args.StopTimeout
+	}
+
 	leaseDelayBetween := (leaseTimeout - 1*time.Second) / 3
 	if waitTimeout != DefaultWaitTimeout || leaseTimeout != DefaultLeaseTtl {
 		terminal.Infof("Using wait timeout: %s lease timeout: %s delay between lease refreshes: %s\n", waitTimeout, leaseTimeout, leaseDelayBetween)
@@ -241,6 +251,17 @@ func NewMachineDeployment(ctx context.Context, args MachineDeploymentArgs) (_ Ma
 		maxUnavailable = *appConfig.Deploy.MaxUnavailable
 	}
 
+	canaryCount := 1
+	var bakeTime time.Duration
+	if appConfig.Deploy != nil {
+		if appConfig.Deploy.CanaryCount != nil {
+			canaryCount = *appConfig.Deploy.CanaryCount
+		}
+		if appConfig.Deploy.BakeTime != nil {
+			bakeTime = appConfig.Deploy.BakeTime.Duration
+		}
+	}
+
 	maxConcurrent := args.MaxConcurrent
 	if maxConcurrent < 1 {
 		maxConcurrent = 1
@@ -260,8 +281,11 @@ func NewMachineDeployment(ctx context.Context, args MachineDeploymentArgs) (_ Ma
 		skipReleaseCommand:    args.SkipReleaseCommand,
 		restartOnly:           args.RestartOnly,
 		maxUnavailable:        maxUnavailable,
+		canaryCount:           canaryCount,
+		bakeTime:              bakeTime,
 		waitTimeout:           waitTimeout,
 		stopSignal:            args.StopSignal,
+		stopTimeout:           stopTimeout,
 		leaseTimeout:          leaseTimeout,
 		leaseDelayBetween:     leaseDelayBetween,
 		releaseCmdTimeout:     releaseCmdTimeout,
@@ -409,6 +433,19 @@ func (md *machineDeployment) setMachinesForDeployment(ctx context.Context) error
 		fmt.Fprintf(md.io.ErrOut, "%s filter%s applied, deploying to %d/%d machines\n", filtersAppliedStr, s, len(machines), nMachines)
 	}
 
+	var cordoned []string
+	machines = slices.DeleteFunc(machines, func(m *fly.Machine) bool {
+		if machine.IsCordoned(m) {
+			cordoned = append(cordoned, m.ID)
+			return true
+		}
+		return false
+	})
+	if len(cordoned) > 0 {
+		fmt.Fprintf(md.io.ErrOut, "%s Skipping %d cordoned machine(s): %s. Run `fly machine uncordon` to include them in deployments.\n",
+			aurora.Yellow("[WARNING]"), len(cordoned), strings.Join(cordoned, ", "))
+	}
+
 	for _, m := range machines {
 		if m.Config != nil && m.Config.Metadata != nil {
 			m.Config.Metadata[fly.MachineConfigMetadataKeyFlyctlVersion] = buildinfo.Version().String()
@@ -97,6 +97,10 @@ func (md *machineDeployment) DeployMachinesApp(ctx context.Context) error {
 		}
 	}
 
+	if !md.restartOnly && err == nil {
+		md.checkStatics(ctx)
+	}
+
 	if err != nil {
 		tracing.RecordError(span, err, "failed to deploy machines")
 	}
@@ -121,6 +125,10 @@ func (md *machineDeployment) updateMachine(ctx context.Context, e *machineUpdate
 		return nil
 	}
 
+	if err := md.stopMachineGracefully(ctx, e, sl); err != nil {
+		return err
+	}
+
 	if e.launchInput.RequiresReplacement {
 		return replaceMachine()
 	}
@@ -148,6 +156,29 @@ func (md *machineDeployment) updateMachine(ctx context.Context, e *machineUpdate
 	return nil
 }
 
+// stopMachineGracefully stops a started machine with the --signal and
+// --stop-timeout given to deploy before it's updated or replaced. They only
+// apply to this stop: the stop config of the machine, from kill_signal and
+// kill_timeout, is left as is for later stops. Without them, the machine is
+// stopped according to its stop config by the update.
+func (md *machineDeployment) stopMachineGracefully(ctx context.Context, e *machineUpdateEntry, sl statuslogger.StatusLine) error {
+	lm := e.leasableMachine
+	if md.stopSignal == "" && md.stopTimeout == 0 {
+		return nil
+	}
+	if lm.Machine().State != fly.MachineStateStarted {
+		return nil
+	}
+
+	sl.Logf("Stopping %s", lm.FormattedMachineId())
+	if err := lm.Stop(ctx, strings.ToUpper(md.stopSignal), md.stopTimeout); err != nil {
+		return fmt.Errorf("could not stop machine %s: %w", lm.Machine().ID, err)
+	}
+
+	// Leave room for the stop timeout on top of the usual wait
+	return lm.WaitForState(ctx, fly.MachineStateStopped, md.waitTimeout+md.stopTimeout, false)
+}
+
 func (md *machineDeployment) waitForMachine(ctx context.Context, e *machineUpdateEntry, sl statuslogger.StatusLine) error {
 	lm := e.leasableMachine
 	// Don't wait for SkipLaunch machines, they are updated but not started
@@ -220,54 +251,65 @@ func (md *machineDeployment) deployCanaryMachines(ctx context.Context) (err erro
 	defer span.End()
 
 	groupsInConfig := md.ProcessNames()
-	total := len(groupsInConfig)
+	total := len(groupsInConfig) * md.canaryCount
 	sl := statuslogger.Create(ctx, total, true)
 	defer sl.Destroy(false)
 
 	errors, ctx := errgroup.WithContext(ctx)
 
-	for idx, name := range groupsInConfig {
-		ctx := statuslogger.NewContext(ctx, sl.Line(idx))
-		statuslogger.LogfStatus(ctx,
-			statuslogger.StatusRunning,
-			"Creating canary machine for group %s",
-			md.colorize.Bold(name),
-		)
-
-		// variable name shadowing to make go-vet happy
-		name := name
-		idx := idx
-		errors.Go(func() error {
-			var err error
-			lm, err := md.spawnMachineInGroup(ctx, name, nil,
-				withMeta(metadata{key: "fly_canary", value: "true"}),
-				withGuest(md.inferCanaryGuest(name)),
-				withDns(&fly.DNSConfig{SkipRegistration: true}),
+	for groupIdx, name := range groupsInConfig {
+		for i := 0; i < md.canaryCount; i++ {
+			idx := groupIdx*md.canaryCount + i
+			ctx := statuslogger.NewContext(ctx, sl.Line(idx))
+			statuslogger.LogfStatus(ctx,
+				statuslogger.StatusRunning,
+				"Creating canary machine for group %s",
+				md.colorize.Bold(name),
 			)
-			if err != nil {
-				tracing.RecordError(span, err, "failed to provision canary machine")
-				firstLine, _, _ := strings.Cut(err.Error(), "\n")
-				statuslogger.LogfStatus(ctx, statuslogger.StatusFailure, "Failed to create canary machine: %s", firstLine)
-				return err
-			}
 
-			defer func() {
-				if err == nil {
-					if destroyErr := machcmd.Destroy(ctx, md.app, lm.Machine(), true); destroyErr != nil {
-						err = destroyErr
+			// variable name shadowing to make go-vet happy
+			name := name
+			errors.Go(func() error {
+				var err error
+				lm, err := md.spawnMachineInGroup(ctx, name, nil,
+					withMeta(metadata{key: "fly_canary", value: "true"}),
+					withGuest(md.inferCanaryGuest(name)),
+					withDns(&fly.DNSConfig{SkipRegistration: true}),
+				)
+				if err != nil {
+					tracing.RecordError(span, err, "failed to provision canary machine")
+					firstLine, _, _ := strings.Cut(err.Error(), "\n")
+					statuslogger.LogfStatus(ctx, statuslogger.StatusFailure, "Failed to create canary machine: %s", firstLine)
+					return err
+				}
+
+				defer func() {
+					if err == nil {
+						if destroyErr := machcmd.Destroy(ctx, md.app, lm.Machine(), true); destroyErr != nil {
+							err = destroyErr
+						}
 					}
+				}()
+
+				if err = md.runTestMachines(ctx, lm.Machine(), sl.Line(idx)); err != nil {
+					tracing.RecordError(span, err, "failed to run test machine for canary machine")
+					firstLine, _, _ := strings.Cut(err.Error(), "\n")
+					statuslogger.LogfStatus(ctx, statuslogger.StatusFailure, "Failed to run test machine for canary machine: %s", firstLine)
+					return err
 				}
-			}()
 
-			if err = md.runTestMachines(ctx, lm.Machine(), sl.Line(idx)); err != nil {
-				tracing.RecordError(span, err, "failed to run test machine for canary machine")
-				firstLine, _, _ := strings.Cut(err.Error(), "\n")
-				statuslogger.LogfStatus(ctx, statuslogger.StatusFailure, "Failed to run test machine for canary machine: %s", firstLine)
-				return err
-			}
+				if md.bakeTime > 0 {
+					statuslogger.LogfStatus(ctx, statuslogger.StatusRunning, "Baking canary machine %s for %s", md.colorize.Bold(lm.FormattedMachineId()), md.bakeTime)
+				}
+				if err = md.bakeCanaries(ctx, lm.Machine()); err != nil {
+					tracing.RecordError(span, err, "canary machine failed while baking")
+					statuslogger.LogfStatus(ctx, statuslogger.StatusFailure, "%s", err)
+					return err
+				}
 
-			return err
-		})
+				return err
+			})
+		}
 	}
 
 	if err := errors.Wait(); err != nil {
@@ -277,6 +319,51 @@ func (md *machineDeployment) deployCanaryMachines(ctx context.Context) (err erro
 	return nil
 }
 
+// bakeCanaries keeps the canary machines running for the bake_time of the
+// [deploy] section, then fails if any of them isn't started and healthy.
+// Canaries the proxy may stop when idle can also be stopped or suspended, and
+// failing checks get until the wait timeout to pass again, as a single failed
+// run right at the end of the bake doesn't make a bad release.
+func (md *machineDeployment) bakeCanaries(ctx context.Context, machines ...*fly.Machine) error {
+	if md.bakeTime <= 0 {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(md.bakeTime):
+	}
+
+	for _, m := range machines {
+		m, err := md.flapsClient.Get(ctx, m.ID)
+		if err != nil {
+			return err
+		}
+		switch {
+		case m.State == fly.MachineStateStarted:
+		case (m.State == fly.MachineStateStopped || m.State == fly.MachineStateSuspended) && canAutostop(m):
+			continue
+		default:
+			return fmt.Errorf("canary machine %s is %s after baking for %s", m.ID, m.State, md.bakeTime)
+		}
+		if checks := m.AllHealthChecks(); checks.Critical > 0 {
+			lm := machine.NewLeasableMachine(md.flapsClient, md.io, m, false)
+			if err := lm.WaitForHealthchecksToPass(ctx, md.waitTimeout); err != nil {
+				return fmt.Errorf("canary machine %s has %d failing health checks after baking for %s: %w", m.ID, checks.Critical, md.bakeTime, err)
+			}
+		}
+	}
+	return nil
+}
+
+// canAutostop reports whether the proxy may stop or suspend m when it's idle.
+func canAutostop(m *fly.Machine) bool {
+	return lo.SomeBy(m.Config.Services, func(s fly.MachineService) bool {
+		return s.Autostop != nil && *s.Autostop != fly.MachineAutostopOff
+	})
+}
+
 // Create machines for new process groups
 func (md *machineDeployment) deployCreateMachinesForGroups(ctx context.Context, processGroupMachineDiff ProcessGroupsDiff) (err error) {
 	groupsWithAutostopEnabled := make(map[string]bool)
@@ -613,15 +700,14 @@ func (md *machineDeployment) updateExistingMachinesWRecovery(ctx context.Context
 			skipLeaseAcquisition: false,
 		})
 	case "canary":
-		// create a new app state with just a single machine being updated, then the rest of the machines
+		// create a new app state with just the canary machines being updated, then the rest of the machines
 		canaryAppState := *oldAppState
-		canaryAppState.Machines = []*fly.Machine{oldAppState.Machines[0]}
+		canaryAppState.Machines = oldAppState.Machines[:min(md.canaryCount, len(oldAppState.Machines))]
 
 		newCanaryAppState := newAppState
-		canaryMach, _ := lo.Find(newAppState.Machines, func(m *fly.Machine) bool {
-			return m.ID == oldAppState.Machines[0].ID
+		newCanaryAppState.Machines = lo.Filter(newAppState.Machines, func(m *fly.Machine, _ int) bool {
+			return lo.ContainsBy(canaryAppState.Machines, func(old *fly.Machine) bool { return old.ID == m.ID })
 		})
-		newCanaryAppState.Machines = []*fly.Machine{canaryMach}
 
 		if err := md.updateMachinesWRecovery(ctx, &canaryAppState, &newCanaryAppState, nil, updateMachineSettings{
 			pushForward:          true,
@@ -632,6 +718,13 @@ func (md *machineDeployment) updateExistingMachinesWRecovery(ctx context.Context
 			return err
 		}
 
+		if md.bakeTime > 0 {
+			fmt.Fprintf(md.io.Out, "Baking canary machines for %s\n", md.bakeTime)
+		}
+		if err := md.bakeCanaries(ctx, newCanaryAppState.Machines...); err != nil {
+			return err
+		}
+
 		return md.updateMachinesWRecovery(ctx, oldAppState, &newAppState, nil, updateMachineSettings{
 			pushForward:          true,
 			skipHealthChecks:     md.skipHealthChecks,
@@ -1294,6 +1387,42 @@ func (md *machineDeployment) doSmokeChecks(ctx context.Context, lm machine.Leasa
 	return err
 }
 
+// checkStatics warns about the [[statics]] whose guest_path doesn't exist in
+// the deployed image, since the Fly.io proxy then silently fails to serve them.
+func (md *machineDeployment) checkStatics(ctx context.Context) {
+	var paths []string
+	for _, s := range md.appConfig.Statics {
+		if s.TigrisBucket == "" {
+			paths = append(paths, s.GuestPath)
+		}
+	}
+	if len(paths) == 0 {
+		return
+	}
+
+	machines, err := md.flapsClient.ListActive(ctx)
+	if err != nil {
+		return
+	}
+	m, ok := lo.Find(machines, func(m *fly.Machine) bool {
+		return m.State == fly.MachineStateStarted && m.Config != nil && m.Config.Image == md.img
+	})
+	if !ok {
+		return
+	}
+
+	missing, err := machine.MissingPaths(ctx, m, paths)
+	if err != nil {
+		terminal.Debugf("Couldn't check the statics guest paths in machine %s: %v\n", m.ID, err)
+		return
+	}
+	for _, s := range md.appConfig.Statics {
+		if slices.Contains(missing, s.GuestPath) {
+			terminal.Warnf("[[statics]] guest_path %s doesn't exist in the image, requests to %s won't be served from it\n", s.GuestPath, s.UrlPrefix)
+		}
+	}
+}
+
 func (md *machineDeployment) checkDNS(ctx context.Context) error {
 	ctx, span := tracing.GetTracer().Start(ctx, "check_dns")
 	defer span.End()
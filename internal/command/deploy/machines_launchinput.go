@@ -18,6 +18,7 @@ func (md *machineDeployment) launchInputForRestart(origMachineRaw *fly.Machine)
 
 	return &fly.LaunchMachineInput{
 		ID:         origMachineRaw.ID,
+		Name:       origMachineRaw.Name,
 		Config:     mConfig,
 		Region:     origMachineRaw.Region,
 		SkipLaunch: skipLaunch(origMachineRaw, mConfig),
@@ -174,7 +175,9 @@ func (md *machineDeployment) launchInputForUpdate(origMachineRaw *fly.Machine) (
 		if len(oMounts) > 0 && len(mMounts) > 0 {
 			// Attempting to rellocate a machine with a volume attached to a different host
 			return nil, fmt.Errorf("can't rellocate machine '%s' to dedication id '%s' because it has an attached volume."+
-				" Retry after forking the volume with `fly volume fork --host-dedication-id %s %s`", mID, hdid, hdid, mMounts[0].Volume)
+				" Retry after forking the volume with `fly volume fork --host-dedication-id %s %s`"+
+				", or the volumes of the whole group with `fly volume fork --all --process-group %s --host-dedication-id %s --attach`",
+				mID, hdid, hdid, mMounts[0].Volume, processGroup, hdid)
 		}
 		machineShouldBeReplaced = true
 		// Set HostDedicationID here for the apps that doesn't have a [[compute]] section in fly.toml
@@ -186,6 +189,7 @@ func (md *machineDeployment) launchInputForUpdate(origMachineRaw *fly.Machine) (
 
 	return &fly.LaunchMachineInput{
 		ID:                  mID,
+		Name:                origMachineRaw.Name,
 		Region:              origMachineRaw.Region,
 		Config:              mConfig,
 		SkipLaunch:          skipLaunch(origMachineRaw, mConfig),
@@ -3,6 +3,7 @@ package deploy
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -32,6 +33,7 @@ func TestLaunchInputForUpdate(t *testing.T) {
 	t.Run("MountsAndAutoResize", testLaunchInputForOnMountsAndAutoResize)
 	t.Run("UpdateKeepUnmanagedFields", testLaunchInputForUpdateKeepUnmanagedFields)
 	t.Run("UpdateClearStandbysWithServices", testLaunchInputForUpdateClearStandbysWithServices)
+	t.Run("UpdateKeepStopConfig", testLaunchInputForUpdateKeepStopConfig)
 	t.Run("LaunchFiles", testLaunchInputForLaunchFiles)
 	t.Run("LaunchFiles", testLaunchInputForUpdateFiles)
 }
@@ -110,6 +112,29 @@ func testLaunchInputForBasic(t *testing.T) {
 	assert.Equal(t, want, li)
 }
 
+// Test that deploy's --signal and --stop-timeout don't replace the stop config
+// from kill_signal and kill_timeout
+func testLaunchInputForUpdateKeepStopConfig(t *testing.T) {
+	md, err := stabMachineDeployment(&appconfig.Config{
+		AppName:       "my-cool-app",
+		PrimaryRegion: "scl",
+		KillSignal:    fly.Pointer("SIGTERM"),
+	})
+	require.NoError(t, err)
+	md.stopSignal = "sigusr1"
+	md.stopTimeout = 2 * time.Minute
+
+	origMachineRaw := &fly.Machine{
+		ID:         "ab1234567890",
+		Region:     "scl",
+		HostStatus: fly.HostStatusOk,
+		Config:     &fly.MachineConfig{},
+	}
+	li, err := md.launchInputForUpdate(origMachineRaw)
+	require.NoError(t, err)
+	assert.Equal(t, &fly.StopConfig{Signal: fly.Pointer("SIGTERM")}, li.Config.StopConfig)
+}
+
 // Test machines on unreachable hosts
 func testLaunchInputForUpdateHostStatusUnreachable(t *testing.T) {
 	md, err := stabMachineDeployment(&appconfig.Config{
@@ -422,6 +447,7 @@ func testLaunchInputForUpdateKeepUnmanagedFields(t *testing.T) {
 
 	origMachineRaw := &fly.Machine{
 		ID:     "ab1234567890",
+		Name:   "renamed-worker",
 		Region: "ord",
 		Config: &fly.MachineConfig{
 			Schedule:    "24/7",
@@ -441,6 +467,7 @@ func testLaunchInputForUpdateKeepUnmanagedFields(t *testing.T) {
 	li, err := md.launchInputForUpdate(origMachineRaw)
 	require.NoError(t, err)
 	assert.Equal(t, "ab1234567890", li.ID)
+	assert.Equal(t, "renamed-worker", li.Name)
 	assert.Equal(t, "ord", li.Region)
 	assert.Equal(t, "24/7", li.Config.Schedule)
 	assert.Equal(t, true, li.Config.AutoDestroy)
@@ -450,6 +477,7 @@ func testLaunchInputForUpdateKeepUnmanagedFields(t *testing.T) {
 
 	li = md.launchInputForRestart(origMachineRaw)
 	assert.Equal(t, "ab1234567890", li.ID)
+	assert.Equal(t, "renamed-worker", li.Name)
 	assert.Equal(t, "ord", li.Region)
 	assert.Equal(t, "24/7", li.Config.Schedule)
 	assert.Equal(t, true, li.Config.AutoDestroy)
@@ -32,6 +32,7 @@ type DeployManifest struct {
 	RestartOnly           bool                      `json:"restart_only,omitempty"`
 	WaitTimeout           *time.Duration            `json:"wait_timeout,omitempty"`
 	StopSignal            string                    `json:"stop_signal,omitempty"`
+	StopTimeout           *time.Duration            `json:"stop_timeout,omitempty"`
 	LeaseTimeout          *time.Duration            `json:"lease_timeout,omitempty"`
 	ReleaseCmdTimeout     *time.Duration            `json:"release_cmd_timeout,omitempty"`
 	Guest                 *fly.MachineGuest         `json:"guest,omitempty"`
@@ -67,6 +68,7 @@ func NewManifest(AppName string, config *appconfig.Config, args MachineDeploymen
 		RestartOnly:           args.RestartOnly,
 		WaitTimeout:           args.WaitTimeout,
 		StopSignal:            args.StopSignal,
+		StopTimeout:           args.StopTimeout,
 		LeaseTimeout:          args.LeaseTimeout,
 		ReleaseCmdTimeout:     args.ReleaseCmdTimeout,
 		Guest:                 args.Guest,
@@ -478,3 +478,62 @@ func TestUpdateOrCreateMachine(t *testing.T) {
 	assert.Nil(t, lease)
 
 }
+
+func TestBakeCanaries(t *testing.T) {
+	t.Parallel()
+
+	ctx := withQuietIOStreams(context.Background())
+	autostop := fly.MachineAutostopStop
+
+	tests := []struct {
+		name    string
+		machine *fly.Machine
+		checks  []*fly.MachineCheckStatus
+		wantErr string
+	}{
+		{
+			name:    "started and healthy",
+			machine: &fly.Machine{ID: "m1", State: fly.MachineStateStarted, Config: &fly.MachineConfig{}},
+			checks:  []*fly.MachineCheckStatus{{Name: "http", Status: fly.Passing}},
+		},
+		{
+			name:    "stopped",
+			machine: &fly.Machine{ID: "m1", State: fly.MachineStateStopped, Config: &fly.MachineConfig{}},
+			wantErr: "canary machine m1 is stopped after baking",
+		},
+		{
+			name: "stopped by autostop",
+			machine: &fly.Machine{ID: "m1", State: fly.MachineStateStopped, Config: &fly.MachineConfig{
+				Services: []fly.MachineService{{Autostop: &autostop}},
+			}},
+		},
+		{
+			name:    "failing checks",
+			machine: &fly.Machine{ID: "m1", State: fly.MachineStateStarted, Config: &fly.MachineConfig{}},
+			checks:  []*fly.MachineCheckStatus{{Name: "http", Status: fly.Critical}},
+			wantErr: "canary machine m1 has 1 failing health checks",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.machine.Checks = tt.checks
+			md := &machineDeployment{
+				flapsClient: &mock.FlapsClient{
+					GetFunc: func(ctx context.Context, machineID string) (*fly.Machine, error) {
+						return tt.machine, nil
+					},
+				},
+				io:          iostreams.FromContext(ctx),
+				bakeTime:    time.Millisecond,
+				waitTimeout: 10 * time.Millisecond,
+			}
+			err := md.bakeCanaries(ctx, &fly.Machine{ID: "m1"})
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -67,6 +67,7 @@ type blueGreen struct {
 	clearLinesAbove     func(count int)
 	timeout             time.Duration
 	stopSignal          string
+	stopTimeout         time.Duration
 	aborted             chan struct{}
 	healthLock          sync.RWMutex
 	stateLock           sync.RWMutex
@@ -88,6 +89,7 @@ func BlueGreenStrategy(md *machineDeployment, blueMachines []*machineUpdateEntry
 		appConfig:           md.appConfig,
 		timeout:             md.waitTimeout,
 		stopSignal:          md.stopSignal,
+		stopTimeout:         md.stopTimeout,
 		io:                  md.io,
 		colorize:            md.colorize,
 		clearLinesAbove:     md.logClearLinesAbove,
@@ -509,7 +511,7 @@ func (bg *blueGreen) StopBlueMachines(ctx context.Context) error {
 			if bg.isAborted() {
 				return ErrAborted
 			}
-			err := gm.leasableMachine.Stop(ctx, bg.stopSignal)
+			err := gm.leasableMachine.Stop(ctx, bg.stopSignal, bg.stopTimeout)
 			if err != nil {
 				// Just let the user know, it's not a critical error as we are gonna destroy the
 				// machines with force later
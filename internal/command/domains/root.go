@@ -25,7 +25,9 @@ func New() *cobra.Command {
 		short = "Manage domains (deprecated)"
 		long  = `Manage domains
 Notice: this feature is deprecated and no longer supported.
-You can still view existing domains, but registration is no longer possible.`
+You can still view existing domains, but registration is no longer possible.
+
+To set up a custom domain for an app, use 'fly domains setup <hostname>'.`
 	)
 	cmd := command.New("domains", short, long, nil)
 	cmd.Deprecated = "`fly domains` will be removed in a future release"
@@ -35,6 +37,7 @@ You can still view existing domains, but registration is no longer possible.`
 		newDomainsShow(),
 		newDomainsAdd(),
 		newDomainsRegister(),
+		newDomainsSetup(),
 	)
 	cmd.Hidden = true
 	return cmd
@@ -0,0 +1,227 @@
+package domains
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/command/certificates"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyutil"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func newDomainsSetup() *cobra.Command {
+	const (
+		short = "Set up a custom domain for an app"
+		long  = `Set up a custom domain for an application, end to end:
+
+  1. allocates the IPv4 and IPv6 addresses the domain needs, if the app
+     has none: a shared IPv4 address and a dedicated IPv6 one
+  2. requests a certificate for the hostname
+  3. lists the DNS records to create and which ones are configured
+  4. waits for the certificate to be issued and for HTTPS requests to the
+     hostname to reach the app
+
+Running it again for the same hostname picks up where it left off.`
+	)
+	cmd := command.New("setup <hostname>", short, long, runDomainsSetup,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Duration{
+			Name:        "timeout",
+			Description: "How long to wait for the certificate to be issued",
+			Default:     10 * time.Minute,
+		},
+		flag.Bool{
+			Name:        "no-wait",
+			Description: "Only print the DNS records to create, without waiting for the certificate",
+		},
+	)
+	cmd.Args = cobra.ExactArgs(1)
+	return cmd
+}
+
+// dnsRecord is a DNS record a custom domain needs.
+type dnsRecord struct {
+	Type       string
+	Name       string
+	Value      string
+	Configured bool
+}
+
+func runDomainsSetup(ctx context.Context) error {
+	io := iostreams.FromContext(ctx)
+	colorize := io.ColorScheme()
+	apiClient := flyutil.ClientFromContext(ctx)
+	appName := appconfig.NameFromContext(ctx)
+	hostname := strings.ToLower(flag.FirstArg(ctx))
+
+	fmt.Fprintf(io.Out, "%s Checking the IP addresses of %s\n", colorize.Bold("1."), appName)
+	v4, v6, err := ensureDomainIPAddresses(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(io.Out, "%s Requesting a certificate for %s\n", colorize.Bold("2."), hostname)
+	cert, hostcheck, err := apiClient.CheckAppCertificate(ctx, appName, hostname)
+	if err != nil {
+		if cert, hostcheck, err = apiClient.AddCertificate(ctx, appName, hostname); err != nil {
+			return err
+		}
+		fmt.Fprintf(io.Out, "   Certificate requested, using %s\n", cert.CertificateAuthority)
+	} else {
+		fmt.Fprintf(io.Out, "   The certificate already exists, its status is %s\n", cert.ClientStatus)
+	}
+
+	fmt.Fprintf(io.Out, "%s DNS records for %s\n\n", colorize.Bold("3."), hostname)
+	records := domainRecords(hostname, appName, v4, v6, cert, hostcheck)
+	rows := make([][]string, 0, len(records))
+	for _, r := range records {
+		status := colorize.Yellow("missing")
+		if r.Configured {
+			status = colorize.Green("configured")
+		}
+		rows = append(rows, []string{r.Type, r.Name, r.Value, status})
+	}
+	render.Table(io.Out, "", rows, "Type", "Name", "Value", "Status")
+
+	if flag.GetBool(ctx, "no-wait") {
+		fmt.Fprintf(io.Out, "Create the missing records with your DNS provider, then run 'fly domains setup %s' again\n", hostname)
+		return nil
+	}
+
+	fmt.Fprintf(io.Out, "%s Waiting for the certificate, create the missing records with your DNS provider meanwhile\n", colorize.Bold("4."))
+	if err := certificates.WatchCertificate(ctx, hostname, flag.GetDuration(ctx, "timeout"), 10*time.Second); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(io.Out, "\nChecking https://%s reaches %s...\n", hostname, appName)
+	if err := verifyDomainTraffic(ctx, hostname); err != nil {
+		return fmt.Errorf("the certificate is issued but https://%s doesn't reach Fly.io yet, DNS changes may still be propagating: %w", hostname, err)
+	}
+	fmt.Fprintf(io.Out, "%s is set up, https://%s reaches %s\n", colorize.Green("✓"), hostname, appName)
+	return nil
+}
+
+// ensureDomainIPAddresses returns the public IPv4 and IPv6 addresses of an
+// app, allocating the missing ones.
+func ensureDomainIPAddresses(ctx context.Context, appName string) (v4, v6 string, err error) {
+	io := iostreams.FromContext(ctx)
+	apiClient := flyutil.ClientFromContext(ctx)
+
+	ips, err := apiClient.GetIPAddresses(ctx, appName)
+	if err != nil {
+		return "", "", err
+	}
+	for _, ip := range ips {
+		switch {
+		case (ip.Type == "v4" || ip.Type == "shared_v4") && (v4 == "" || ip.Type == "v4"):
+			v4 = ip.Address
+		case ip.Type == "v6" && v6 == "":
+			v6 = ip.Address
+		}
+	}
+
+	if v4 == "" {
+		shared, err := apiClient.AllocateSharedIPAddress(ctx, appName)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to allocate a shared IPv4 address: %w", err)
+		}
+		v4 = shared.String()
+		fmt.Fprintf(io.Out, "   Allocated the shared IPv4 address %s\n", v4)
+	}
+	if v6 == "" {
+		ip, err := apiClient.AllocateIPAddress(ctx, appName, "v6", "", nil, "")
+		if err != nil {
+			return "", "", fmt.Errorf("failed to allocate an IPv6 address: %w", err)
+		}
+		v6 = ip.Address
+		fmt.Fprintf(io.Out, "   Allocated the IPv6 address %s\n", v6)
+	}
+	return v4, v6, nil
+}
+
+// domainRecords lists the DNS records hostname needs to reach the app and get
+// its certificate, and whether they're configured.
+func domainRecords(hostname, appName, v4, v6 string, cert *fly.AppCertificate, hostcheck *fly.HostnameCheck) []dnsRecord {
+	resolves := func(address string, records []string) bool {
+		ip := net.ParseIP(address)
+		for _, r := range append(slices.Clone(records), hostcheck.ResolvedAddresses...) {
+			if ip.Equal(net.ParseIP(r)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var records []dnsRecord
+	if cert.IsApex || cert.IsWildcard {
+		records = append(records,
+			dnsRecord{Type: "A", Name: hostname, Value: v4, Configured: resolves(v4, hostcheck.ARecords)},
+			dnsRecord{Type: "AAAA", Name: hostname, Value: v6, Configured: resolves(v6, hostcheck.AAAARecords)},
+		)
+	} else {
+		target := appName + ".fly.dev"
+		configured := slices.ContainsFunc(hostcheck.CNAMERecords, func(r string) bool {
+			return strings.EqualFold(strings.TrimSuffix(r, "."), target)
+		}) || resolves(v4, hostcheck.ARecords) || resolves(v6, hostcheck.AAAARecords)
+		records = append(records, dnsRecord{Type: "CNAME", Name: hostname, Value: target, Configured: configured})
+	}
+
+	// Wildcards are only validated with the DNS-01 challenge, others also
+	// with it when their traffic goes through a CDN
+	if cert.IsWildcard || (!cert.AcmeALPNConfigured && cert.DNSProvider == certificates.CLOUDFLARE) {
+		records = append(records, dnsRecord{
+			Type:       "CNAME",
+			Name:       cert.DNSValidationHostname,
+			Value:      cert.DNSValidationTarget,
+			Configured: cert.AcmeDNSConfigured,
+		})
+	}
+	return records
+}
+
+// verifyDomainTraffic checks that HTTPS requests to hostname reach the Fly.io
+// proxy, which sets the Fly-Request-Id header, with a valid certificate.
+func verifyDomainTraffic(ctx context.Context, hostname string) error {
+	// Any subdomain of a wildcard reaches the app
+	if strings.HasPrefix(hostname, "*.") {
+		hostname = "www" + strings.TrimPrefix(hostname, "*")
+	}
+
+	client := &http.Client{
+		Timeout: 15 * time.Second,
+		// Redirects of the app are fine, the first response tells
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, "https://"+hostname+"/", http.NoBody)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // skipcq: GO-S2307
+
+	if resp.Header.Get("Fly-Request-Id") == "" {
+		return errors.New("the response doesn't come from the Fly.io proxy")
+	}
+	return nil
+}
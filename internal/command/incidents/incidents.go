@@ -3,14 +3,21 @@ package incidents
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/fly-go/flaps"
 	"github.com/superfly/flyctl/helpers"
+	"github.com/superfly/flyctl/internal/appconfig"
 	"github.com/superfly/flyctl/internal/command"
 	"github.com/superfly/flyctl/internal/command/incidents/hosts"
+	"github.com/superfly/flyctl/internal/command/platform"
 	"github.com/superfly/flyctl/internal/config"
 	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flapsutil"
 	"github.com/superfly/flyctl/internal/incidents"
 	"github.com/superfly/flyctl/internal/render"
 	"github.com/superfly/flyctl/iostreams"
@@ -19,9 +26,27 @@ import (
 func New() *cobra.Command {
 	const (
 		short = "Show incidents"
-		long  = `Show incidents that may be affecting your organization.`
+		long  = `Show the ongoing incidents and maintenances of the platform affecting
+the regions of an app, or all of them with --all or outside of apps.
+
+Incidents and maintenances of regions are shown for apps with machines in
+them, and those of the whole platform, like of deployments or the API, for
+all apps.`
+	)
+	cmd := command.New("incidents", short, long, runIncidents,
+		command.RequireSession,
+		command.LoadAppNameIfPresent,
 	)
-	cmd := command.New("incidents", short, long, nil)
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.JSONOutput(),
+		flag.Bool{
+			Name:        "all",
+			Description: "Show the incidents and maintenances of all regions",
+		},
+	)
+	cmd.Args = cobra.NoArgs
 	cmd.AddCommand(
 		newIncidentsList(),
 		hosts.New(),
@@ -29,6 +54,75 @@ func New() *cobra.Command {
 	return cmd
 }
 
+func runIncidents(ctx context.Context) error {
+	out := iostreams.FromContext(ctx).Out
+	appName := appconfig.NameFromContext(ctx)
+
+	var (
+		notices []incidents.Notice
+		err     error
+		regions []string
+	)
+	if appName == "" || flag.GetBool(ctx, "all") {
+		notices, err = incidents.Notices(ctx)
+	} else {
+		if regions, err = appRegions(ctx, appName); err != nil {
+			return err
+		}
+		notices, err = incidents.AppNotices(ctx, regions)
+	}
+	if err != nil {
+		return err
+	}
+
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(out, notices)
+	}
+
+	if len(notices) == 0 {
+		if regions != nil {
+			fmt.Fprintf(out, "There are no incidents or maintenances affecting %s in %s\n", appName, strings.Join(regions, ", "))
+		} else {
+			fmt.Fprintf(out, "There are no incidents or maintenances\n")
+		}
+		return nil
+	}
+
+	rows := make([][]string, 0, len(notices))
+	for _, n := range notices {
+		affected := "all"
+		if !n.Global() {
+			affected = strings.Join(n.Regions, ", ")
+		}
+		rows = append(rows, []string{n.Kind, n.Name, n.Status, affected, n.StartedAt, n.UpdatedAt})
+	}
+	if err := render.Table(out, "", rows, "Kind", "Name", "Status", "Regions", "Started At", "Last Updated"); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "See %s for details.\n", platform.StatusURL)
+	return nil
+}
+
+// appRegions returns the regions of the machines of the app.
+func appRegions(ctx context.Context, appName string) ([]string, error) {
+	flapsClient, err := flapsutil.NewClientWithOptions(ctx, flaps.NewClientOpts{
+		AppName: appName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	machines, err := flapsClient.ListActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	regions := lo.Uniq(lo.Map(machines, func(m *fly.Machine, _ int) string { return m.Region }))
+	slices.Sort(regions)
+	return regions, nil
+}
+
 func newIncidentsList() *cobra.Command {
 	const (
 		short = "List active incidents."
@@ -2,6 +2,7 @@ package ips
 
 import (
 	"context"
+	"errors"
 
 	"github.com/spf13/cobra"
 	fly "github.com/superfly/fly-go"
@@ -15,7 +16,12 @@ import (
 
 func newAllocatev4() *cobra.Command {
 	const (
-		long  = `Allocates an IPv4 address to the application`
+		long = `Allocates an IPv4 address to the application.
+
+Dedicated addresses are anycast from all regions by default. With --region,
+the address is only announced from that region, and only routes to the
+machines there, e.g. for per-region ingress addresses for compliance or
+DNS-based routing. Shared addresses are always global.`
 		short = `Allocate an IPv4 address`
 	)
 
@@ -40,7 +46,10 @@ func newAllocatev4() *cobra.Command {
 
 func newAllocatev6() *cobra.Command {
 	const (
-		long  = `Allocates an IPv6 address to the application`
+		long = `Allocates an IPv6 address to the application.
+
+Public addresses are anycast from all regions by default. With --region, the
+address is only announced from that region.`
 		short = `Allocate an IPv6 address`
 	)
 
@@ -70,6 +79,9 @@ func newAllocatev6() *cobra.Command {
 func runAllocateIPAddressV4(ctx context.Context) error {
 	addrType := "v4"
 	if flag.GetBool(ctx, "shared") {
+		if flag.GetRegion(ctx) != "" {
+			return errors.New("shared IPv4 addresses are global, --region can only be used for dedicated ones")
+		}
 		addrType = "shared_v4"
 	} else if !flag.GetBool(ctx, "yes") {
 		msg := `Looks like you're accessing a paid feature. Dedicated IPv4 addresses now cost $2/mo.
@@ -133,7 +145,12 @@ func runAllocateIPAddress(ctx context.Context, addrType string, org *fly.Organiz
 		return err
 	}
 
+	machines, err := appMachines(ctx, appName)
+	if err != nil {
+		return err
+	}
+
 	ipAddresses := []fly.IPAddress{*ipAddress}
-	renderListTable(ctx, ipAddresses)
+	renderListTable(ctx, ipAddresses, machines)
 	return nil
 }
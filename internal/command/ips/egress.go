@@ -0,0 +1,210 @@
+package ips
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"github.com/superfly/flyctl/gql"
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyutil"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func newEgress() *cobra.Command {
+	const (
+		long = `Commands for managing the static egress IP addresses of the machines of an
+application, the addresses their outbound connections come from, e.g. to
+allow them through the firewall of a database.
+
+An egress IP address stays with its machine until the machine is destroyed.`
+		short = `Manage static egress IP addresses`
+	)
+
+	cmd := command.New("egress", short, long, nil)
+	cmd.AddCommand(
+		newEgressList(),
+		newEgressAllocate(),
+	)
+	return cmd
+}
+
+func newEgressList() *cobra.Command {
+	const (
+		long  = `Lists the static egress IP addresses of the machines of the application`
+		short = `List static egress IP addresses`
+	)
+
+	cmd := command.New("list", short, long, runEgressList,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Aliases = []string{"ls"}
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.JSONOutput(),
+	)
+	return cmd
+}
+
+func newEgressAllocate() *cobra.Command {
+	const (
+		long = `Allocates static egress IPv4 and IPv6 addresses to the machines of the
+application, all of them or the ones of --region or --machine. Machines that
+already have them keep their addresses.`
+		short = `Allocate static egress IP addresses`
+	)
+
+	cmd := command.New("allocate", short, long, runEgressAllocate,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Region(),
+		flag.StringSlice{
+			Name:        "machine",
+			Description: "Only allocate addresses to these machines",
+		},
+	)
+	return cmd
+}
+
+// egressIP is a static egress IP address of a machine.
+type egressIP struct {
+	MachineID string
+	Region    string
+	Family    string
+	IP        string
+}
+
+type appMachine struct {
+	id     string
+	region string
+}
+
+// appEgress returns the machines of an app and their static egress IP
+// addresses.
+func appEgress(ctx context.Context, appName string) ([]appMachine, []egressIP, error) {
+	client := flyutil.ClientFromContext(ctx).GenqClient()
+
+	resp, err := gql.AgentGetInstances(ctx, client, appName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		machines []appMachine
+		ips      []egressIP
+	)
+	for _, m := range resp.App.Machines.Nodes {
+		if m.State == "destroyed" {
+			continue
+		}
+		machines = append(machines, appMachine{id: m.Id, region: m.Region})
+		for _, ip := range m.Ips.Nodes {
+			// Other than their 6PN address, machines only have egress addresses
+			if ip.Kind == "privatenet" {
+				continue
+			}
+			ips = append(ips, egressIP{MachineID: m.Id, Region: m.Region, Family: ip.Family, IP: ip.Ip})
+		}
+	}
+	return machines, ips, nil
+}
+
+func runEgressList(ctx context.Context) error {
+	out := iostreams.FromContext(ctx).Out
+	appName := appconfig.NameFromContext(ctx)
+
+	_, ips, err := appEgress(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(out, ips)
+	}
+
+	renderEgressTable(ctx, ips)
+	return nil
+}
+
+func runEgressAllocate(ctx context.Context) error {
+	var (
+		io       = iostreams.FromContext(ctx)
+		colorize = io.ColorScheme()
+		appName  = appconfig.NameFromContext(ctx)
+		client   = flyutil.ClientFromContext(ctx).GenqClient()
+		region   = flag.GetRegion(ctx)
+		only     = flag.GetStringSlice(ctx, "machine")
+	)
+
+	machines, current, err := appEgress(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range only {
+		if !lo.ContainsBy(machines, func(m appMachine) bool { return m.id == id }) {
+			return fmt.Errorf("machine %s isn't a machine of %s", id, appName)
+		}
+	}
+	machines = lo.Filter(machines, func(m appMachine, _ int) bool {
+		return (region == "" || m.region == region) && (len(only) == 0 || lo.Contains(only, m.id))
+	})
+	if len(machines) == 0 {
+		return fmt.Errorf("%s has no machines to allocate egress addresses to", appName)
+	}
+
+	_ = `# @genqlient
+	mutation AllocateEgressIPAddress($appId: ID!, $machineId: ID!) {
+		allocateEgressIpAddress(input: {appId: $appId, machineId: $machineId}) {
+			v4
+			v6
+		}
+	}
+	`
+
+	var allocated []egressIP
+	for _, m := range machines {
+		if lo.ContainsBy(current, func(ip egressIP) bool { return ip.MachineID == m.id }) {
+			fmt.Fprintf(io.Out, "Machine %s already has egress addresses\n", colorize.Bold(m.id))
+			continue
+		}
+
+		resp, err := gql.AllocateEgressIPAddress(ctx, client, appName, m.id)
+		if err != nil {
+			return fmt.Errorf("failed to allocate egress addresses to machine %s: %w", m.id, err)
+		}
+		allocated = append(allocated,
+			egressIP{MachineID: m.id, Region: m.region, Family: "v4", IP: resp.AllocateEgressIpAddress.V4},
+			egressIP{MachineID: m.id, Region: m.region, Family: "v6", IP: resp.AllocateEgressIpAddress.V6},
+		)
+	}
+
+	if len(allocated) > 0 {
+		renderEgressTable(ctx, allocated)
+	}
+	return nil
+}
+
+func renderEgressTable(ctx context.Context, ips []egressIP) {
+	rows := make([][]string, 0, len(ips))
+	for _, ip := range ips {
+		rows = append(rows, []string{ip.MachineID, ip.Region, ip.Family, ip.IP})
+	}
+
+	out := iostreams.FromContext(ctx).Out
+	render.Table(out, "", rows, "Machine", "Region", "Version", "IP")
+}
@@ -19,6 +19,7 @@ func New() *cobra.Command {
 		newAllocatev4(),
 		newAllocatev6(),
 		newPrivate(),
+		newEgress(),
 		newRelease(),
 	)
 	return cmd
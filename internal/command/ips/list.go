@@ -16,7 +16,9 @@ import (
 
 func newList() *cobra.Command {
 	const (
-		long  = `Lists the IP addresses allocated to the application`
+		long = `Lists the IP addresses allocated to the application, with their monthly
+cost, the services of the app's machines they route to, and whether they
+route to any.`
 		short = `List allocated IP addresses`
 	)
 
@@ -46,11 +48,16 @@ func runIPAddressesList(ctx context.Context) error {
 		return err
 	}
 
+	machines, err := appMachines(ctx, appName)
+	if err != nil {
+		return err
+	}
+
 	if cfg.JSONOutput {
-		return render.JSON(out, ipAddresses)
+		return render.JSON(out, ipAddressesDetails(ipAddresses, machines))
 	}
 
-	renderListTable(ctx, ipAddresses)
+	renderListTable(ctx, ipAddresses, machines)
 	fmt.Println("Learn more about Fly.io public, private, shared and dedicated IP addresses in our docs: https://fly.io/docs/networking/services/")
 	return nil
 }
@@ -1,19 +1,35 @@
 package ips
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/azazeal/pause"
+	"github.com/inancgumus/screen"
 	"github.com/spf13/cobra"
+	fly "github.com/superfly/fly-go"
 	"github.com/superfly/fly-go/flaps"
 	"github.com/superfly/flyctl/internal/appconfig"
 	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
 	"github.com/superfly/flyctl/internal/flag"
 	"github.com/superfly/flyctl/internal/flapsutil"
+	"github.com/superfly/flyctl/iostreams"
 )
 
 func newPrivate() *cobra.Command {
 	const (
-		long  = `List instances private IP addresses, accessible from within the Fly network`
+		long = `List instances private IP addresses, accessible from within the Fly network.
+
+With --watch, the list is refreshed as machines are created and destroyed,
+and machines that are new, destroyed or whose private address changed since
+the first refresh are flagged, e.g. while debugging service discovery.`
 		short = `List instances private IP addresses`
 	)
 
@@ -26,6 +42,15 @@ func newPrivate() *cobra.Command {
 		flag.App(),
 		flag.AppConfig(),
 		flag.JSONOutput(),
+		flag.Bool{
+			Name:        "watch",
+			Description: "Refresh the list as machines change",
+		},
+		flag.Int{
+			Name:        "rate",
+			Description: "Refresh Rate for --watch",
+			Default:     5,
+		},
 	)
 	return cmd
 }
@@ -40,11 +65,121 @@ func runPrivateIPAddressesList(ctx context.Context) error {
 		return err
 	}
 
+	if flag.GetBool(ctx, "watch") {
+		if config.FromContext(ctx).JSONOutput {
+			return errors.New("--watch and --json are not supported together")
+		}
+		return runPrivateIPAddressesWatch(ctx, flapsClient, appName)
+	}
+
 	machines, _, err := flapsClient.ListFlyAppsMachines(ctx)
 	if err != nil {
 		return err
 	}
-	renderPrivateTableMachines(ctx, machines)
+	renderPrivateTableMachines(iostreams.FromContext(ctx).Out, machines, nil)
 
 	return nil
 }
+
+func runPrivateIPAddressesWatch(ctx context.Context, flapsClient flapsutil.FlapsClient, appName string) (err error) {
+	streams := iostreams.FromContext(ctx)
+	if !streams.IsInteractive() {
+		return errors.New("--watch is not supported for non-interactive sessions")
+	}
+	colorize := streams.ColorScheme()
+
+	sleep := flag.GetInt(ctx, "rate")
+	if sleep < 1 || sleep > 3600 {
+		return errors.New("--rate must be in the [1, 3600] range")
+	}
+
+	var (
+		buf     bytes.Buffer
+		watcher privateIPWatcher
+	)
+	for err == nil {
+		var listed []*fly.Machine
+		if listed, _, err = flapsClient.ListFlyAppsMachines(ctx); err != nil {
+			break
+		}
+
+		buf.Reset()
+		machines, changes := watcher.update(listed)
+		renderPrivateTableMachines(&buf, machines, changes)
+
+		header := fmt.Sprintf("%s %s %s\n\n", colorize.Bold(appName), "at:", colorize.Bold(time.Now().UTC().Format("15:04:05")))
+
+		screen.Clear()
+		screen.MoveTopLeft()
+
+		io.Copy(streams.Out, io.MultiReader(
+			strings.NewReader(header),
+			&buf,
+		))
+
+		pause.For(ctx, time.Duration(sleep)*time.Second)
+	}
+
+	// Interrupted with Ctrl-C
+	if errors.Is(ctx.Err(), context.Canceled) {
+		err = nil
+	}
+
+	return
+}
+
+// privateIPWatcher tracks the machines of an app across refreshes of --watch,
+// to flag the ones created, destroyed or given another 6PN address since the
+// first refresh.
+type privateIPWatcher struct {
+	// first maps the machines of the first refresh to their 6PN address
+	first map[string]string
+	// gone holds the machines destroyed since, still listed as such
+	gone map[string]*fly.Machine
+	// last holds the machines of the previous refresh
+	last []*fly.Machine
+}
+
+// update returns the machines to list, including the destroyed ones, and
+// what changed for each of them.
+func (w *privateIPWatcher) update(machines []*fly.Machine) ([]*fly.Machine, map[string]string) {
+	if w.first == nil {
+		w.first = map[string]string{}
+		w.gone = map[string]*fly.Machine{}
+		for _, m := range machines {
+			w.first[m.ID] = m.PrivateIP
+		}
+	}
+
+	changes := map[string]string{}
+	listed := map[string]bool{}
+	for _, m := range machines {
+		listed[m.ID] = true
+		delete(w.gone, m.ID)
+
+		ip, ok := w.first[m.ID]
+		switch {
+		case !ok:
+			changes[m.ID] = "new"
+		case ip != m.PrivateIP:
+			changes[m.ID] = fmt.Sprintf("6PN changed from %s", ip)
+		}
+	}
+
+	for _, m := range w.last {
+		if !listed[m.ID] {
+			w.gone[m.ID] = &fly.Machine{ID: m.ID, Region: m.Region, State: "destroyed", PrivateIP: m.PrivateIP}
+		}
+	}
+	w.last = machines
+
+	all := append([]*fly.Machine{}, machines...)
+	for _, m := range w.gone {
+		all = append(all, m)
+		changes[m.ID] = "destroyed"
+	}
+	sort.Slice(all[len(machines):], func(i, j int) bool {
+		return all[len(machines)+i].ID < all[len(machines)+j].ID
+	})
+	return all, changes
+}
@@ -2,19 +2,29 @@ package ips
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
+	fly "github.com/superfly/fly-go"
 	"github.com/superfly/flyctl/internal/appconfig"
 	"github.com/superfly/flyctl/internal/command"
 	"github.com/superfly/flyctl/internal/flag"
 	"github.com/superfly/flyctl/internal/flyutil"
+	"github.com/superfly/flyctl/internal/prompt"
 )
 
 func newRelease() *cobra.Command {
 	const (
-		long  = `Releases one or more IP addresses from the application`
+		long = `Releases one or more IP addresses from the application.
+
+Instead of listing addresses, use --all-shared to release the shared IPv4
+addresses, or --unused to release the public and Flycast addresses no
+machine serves: the app's machines have no services, or none in the region
+of a regional address. The addresses are listed before asking for
+confirmation.`
 		short = `Release IP addresses`
 	)
 
@@ -26,9 +36,18 @@ func newRelease() *cobra.Command {
 	flag.Add(cmd,
 		flag.App(),
 		flag.AppConfig(),
+		flag.Yes(),
+		flag.Bool{
+			Name:        "all-shared",
+			Description: "Release all the shared IPv4 addresses of the app",
+		},
+		flag.Bool{
+			Name:        "unused",
+			Description: "Release the addresses of the app that no machine services are exposed on",
+		},
 	)
 
-	cmd.Args = cobra.MinimumNArgs(1)
+	cmd.Args = cobra.ArbitraryArgs
 	return cmd
 }
 
@@ -37,6 +56,16 @@ func runReleaseIPAddress(ctx context.Context) error {
 
 	appName := appconfig.NameFromContext(ctx)
 
+	allShared, unused := flag.GetBool(ctx, "all-shared"), flag.GetBool(ctx, "unused")
+	switch {
+	case (allShared || unused) && len(flag.Args(ctx)) > 0:
+		return errors.New("addresses can't be listed with --all-shared or --unused")
+	case allShared || unused:
+		return runReleaseIPAddressesMatching(ctx, appName, allShared, unused)
+	case len(flag.Args(ctx)) == 0:
+		return errors.New("requires at least one address, --all-shared or --unused")
+	}
+
 	for _, address := range flag.Args(ctx) {
 
 		if ip := net.ParseIP(address); ip == nil {
@@ -52,3 +81,50 @@ func runReleaseIPAddress(ctx context.Context) error {
 
 	return nil
 }
+
+// runReleaseIPAddressesMatching releases the shared and/or unused addresses of
+// an app, once confirmed.
+func runReleaseIPAddressesMatching(ctx context.Context, appName string, allShared, unused bool) error {
+	client := flyutil.ClientFromContext(ctx)
+
+	ipAddresses, err := client.GetIPAddresses(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	machines, err := appMachines(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	toRelease := lo.Filter(ipAddresses, func(ip fly.IPAddress, _ int) bool {
+		_, routable := ipAddressServices(ip, machines)
+		return (allShared && ip.Type == "shared_v4") || (unused && !routable)
+	})
+	if len(toRelease) == 0 {
+		fmt.Printf("No addresses of %s to release\n", appName)
+		return nil
+	}
+
+	renderListTable(ctx, toRelease, machines)
+	if !flag.GetYes(ctx) {
+		switch confirmed, err := prompt.Confirmf(ctx, "Release %d IP addresses from %s?", len(toRelease), appName); {
+		case err == nil:
+			if !confirmed {
+				return nil
+			}
+		case prompt.IsNonInteractive(err):
+			return prompt.NonInteractiveError("yes flag must be specified when not running interactively")
+		default:
+			return err
+		}
+	}
+
+	for _, ip := range toRelease {
+		if err := client.ReleaseIPAddress(ctx, appName, ip.Address); err != nil {
+			return err
+		}
+		fmt.Printf("Released %s from %s\n", ip.Address, appName)
+	}
+	return nil
+}
@@ -2,20 +2,94 @@ package ips
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"net"
+	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/samber/lo"
 	fly "github.com/superfly/fly-go"
+	"github.com/superfly/fly-go/flaps"
+	"github.com/superfly/flyctl/internal/flapsutil"
 	"github.com/superfly/flyctl/internal/format"
 	"github.com/superfly/flyctl/internal/render"
 	"github.com/superfly/flyctl/iostreams"
 )
 
-func renderListTable(ctx context.Context, ipAddresses []fly.IPAddress) {
+// dedicatedIPv4MonthlyCost is the monthly cost of a dedicated IPv4 address, in
+// US dollars. Other addresses are free.
+const dedicatedIPv4MonthlyCost = 2
+
+// ipAddressDetails is an address of an app with what it costs and the
+// services of the app's machines it routes to.
+type ipAddressDetails struct {
+	fly.IPAddress
+	MonthlyCost float64
+	Services    []string
+	Routable    bool
+}
+
+func ipAddressesDetails(ipAddresses []fly.IPAddress, machines []*fly.Machine) []ipAddressDetails {
+	details := make([]ipAddressDetails, 0, len(ipAddresses))
+	for _, ipAddr := range ipAddresses {
+		services, routable := ipAddressServices(ipAddr, machines)
+		d := ipAddressDetails{IPAddress: ipAddr, Services: services, Routable: routable}
+		if ipAddr.Type == "v4" {
+			d.MonthlyCost = dedicatedIPv4MonthlyCost
+		}
+		details = append(details, d)
+	}
+	return details
+}
+
+// ipAddressServices returns the services that machines expose on an address:
+// the ones of all the machines for global addresses, of the machines of its
+// region for regional addresses. Addresses other than public and Flycast ones
+// are always routable.
+func ipAddressServices(ipAddr fly.IPAddress, machines []*fly.Machine) ([]string, bool) {
+	switch ipAddr.Type {
+	case "v4", "shared_v4", "v6", "private_v6":
+	default:
+		return nil, true
+	}
+
+	var services []string
+	for _, m := range machines {
+		if m.Config == nil || (ipScope(ipAddr.Region) == "regional" && m.Region != ipAddr.Region) {
+			continue
+		}
+		for _, service := range m.Config.Services {
+			services = append(services, formatMachineService(service))
+		}
+	}
+	services = lo.Uniq(services)
+	sort.Strings(services)
+	return services, len(services) > 0
+}
+
+// formatMachineService describes the ports of a service and the internal port
+// they map to, e.g. "tcp 80,443 => 8080".
+func formatMachineService(service fly.MachineService) string {
+	ports := lo.FilterMap(service.Ports, func(p fly.MachinePort, _ int) (string, bool) {
+		switch {
+		case p.Port != nil:
+			return strconv.Itoa(*p.Port), true
+		case p.StartPort != nil && p.EndPort != nil:
+			return fmt.Sprintf("%d-%d", *p.StartPort, *p.EndPort), true
+		default:
+			return "", false
+		}
+	})
+	return fmt.Sprintf("%s %s => %d", service.Protocol, strings.Join(ports, ","), service.InternalPort)
+}
+
+func renderListTable(ctx context.Context, ipAddresses []fly.IPAddress, machines []*fly.Machine) {
 	rows := make([][]string, 0, len(ipAddresses))
 
 	var ipType string
-	for _, ipAddr := range ipAddresses {
+	for _, ipAddr := range ipAddressesDetails(ipAddresses, machines) {
 		if strings.HasPrefix(ipAddr.Address, "fdaa") {
 			ipType = "private"
 		} else {
@@ -23,41 +97,83 @@ func renderListTable(ctx context.Context, ipAddresses []fly.IPAddress) {
 		}
 
 		createdAt := format.RelativeTime(ipAddr.CreatedAt)
+		scope := ipScope(ipAddr.Region)
+		cost := "free"
+		if ipAddr.MonthlyCost > 0 {
+			cost = fmt.Sprintf("$%g/mo", ipAddr.MonthlyCost)
+		}
+		services := strings.Join(ipAddr.Services, ", ")
+		routable := "yes"
+		if !ipAddr.Routable {
+			routable = "no"
+		}
 
-		switch {
-		case ipAddr.Type == "v4":
-			rows = append(rows, []string{"v4", ipAddr.Address, "public (dedicated, $2/mo)", ipAddr.Region, createdAt})
-		case ipAddr.Type == "shared_v4":
-			rows = append(rows, []string{"v4", ipAddr.Address, "public (shared)", ipAddr.Region, createdAt})
-		case ipAddr.Type == "v6":
-			rows = append(rows, []string{"v6", ipAddr.Address, "public (dedicated)", ipAddr.Region, createdAt})
-		case ipAddr.Type == "private_v6":
-			rows = append(rows, []string{"v6", ipAddr.Address, "private", ipAddr.Region, createdAt})
-		default:
-			rows = append(rows, []string{ipAddr.Type, ipAddr.Address, ipType, ipAddr.Region, createdAt})
+		version, typ := ipAddr.Type, ipType
+		switch ipAddr.Type {
+		case "v4":
+			version, typ = "v4", "public (dedicated)"
+		case "shared_v4":
+			version, typ = "v4", "public (shared)"
+		case "v6":
+			version, typ = "v6", "public (dedicated)"
+		case "private_v6":
+			version, typ = "v6", "private"
 		}
+		rows = append(rows, []string{version, ipAddr.Address, typ, ipAddr.Region, scope, cost, services, routable, createdAt})
 	}
 
 	out := iostreams.FromContext(ctx).Out
-	render.Table(out, "", rows, "Version", "IP", "Type", "Region", "Created At")
+	render.Table(out, "", rows, "Version", "IP", "Type", "Region", "Scope", "Monthly Cost", "Services", "Routable", "Created At")
 }
 
-func renderPrivateTableMachines(ctx context.Context, machines []*fly.Machine) {
+// ipScope tells whether an address is announced from all regions or only
+// from its region.
+func ipScope(region string) string {
+	if region == "" || region == "global" {
+		return "global"
+	}
+	return "regional"
+}
+
+// renderPrivateTableMachines lists the 6PN addresses of machines. In watch
+// mode, changes notes what changed for each machine since the first refresh.
+func renderPrivateTableMachines(w io.Writer, machines []*fly.Machine, changes map[string]string) {
 	rows := make([][]string, 0, len(machines))
 
 	for _, machine := range machines {
-		rows = append(rows, []string{machine.ID, machine.Region, machine.PrivateIP})
+		if changes == nil {
+			rows = append(rows, []string{machine.ID, machine.Region, machine.PrivateIP})
+			continue
+		}
+		rows = append(rows, []string{machine.ID, machine.Region, machine.State, machine.PrivateIP, changes[machine.ID]})
 	}
 
-	out := iostreams.FromContext(ctx).Out
-	render.Table(out, "", rows, "ID", "Region", "IP")
+	if changes == nil {
+		render.Table(w, "", rows, "ID", "Region", "IP")
+		return
+	}
+	render.Table(w, "", rows, "ID", "Region", "State", "IP", "Change")
 }
 
 func renderSharedTable(ctx context.Context, ip net.IP) {
 	rows := make([][]string, 0, 1)
 
-	rows = append(rows, []string{"v4", ip.String(), "shared", "global"})
+	rows = append(rows, []string{"v4", ip.String(), "shared", "global", ipScope("global")})
 
 	out := iostreams.FromContext(ctx).Out
-	render.Table(out, "", rows, "Version", "IP", "Type", "Region")
+	render.Table(out, "", rows, "Version", "IP", "Type", "Region", "Scope")
+}
+
+// appMachines lists the machines of an app, to tell what its addresses route
+// to.
+func appMachines(ctx context.Context, appName string) ([]*fly.Machine, error) {
+	flapsClient, err := flapsutil.NewClientWithOptions(ctx, flaps.NewClientOpts{
+		AppName: appName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	machines, _, err := flapsClient.ListFlyAppsMachines(ctx)
+	return machines, err
 }
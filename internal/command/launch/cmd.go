@@ -49,6 +49,7 @@ func New() (cmd *cobra.Command) {
 
 		flag.Region(),
 		flag.Org(),
+		flag.Network(),
 		flag.NoDeploy(),
 		flag.AppConfig(),
 		flag.Bool{
@@ -193,12 +193,16 @@ func (state *launchState) createApp(ctx context.Context) (*fly.App, error) {
 	if err != nil {
 		return nil, err
 	}
-	app, err := apiClient.CreateApp(ctx, fly.CreateAppInput{
+	input := fly.CreateAppInput{
 		OrganizationID:  org.ID,
 		Name:            state.Plan.AppName,
 		PreferredRegion: &state.Plan.RegionCode,
 		Machines:        true,
-	})
+	}
+	if v := flag.GetString(ctx, "network"); v != "" {
+		input.Network = fly.StringPointer(v)
+	}
+	app, err := apiClient.CreateApp(ctx, input)
 	if err != nil {
 		return nil, err
 	}
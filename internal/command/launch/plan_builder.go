@@ -446,6 +446,7 @@ func determineBaseAppConfig(ctx context.Context) (*appconfig.Config, bool, error
 	}
 
 	newCfg := appconfig.NewConfig()
+	newCfg.SchemaVersion = appconfig.CurrentSchemaVersion
 	if err := newCfg.SetMachinesPlatform(); err != nil {
 		return nil, false, err
 	}
@@ -3,11 +3,14 @@ package logs
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"time"
 
 	"github.com/azazeal/pause"
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 	"golang.org/x/sync/errgroup"
 
@@ -28,11 +31,42 @@ func New() (cmd *cobra.Command) {
 		long = `View application logs as generated by the application running on
 the Fly platform.
 
-Logs can be filtered to a specific instance using the --instance/-i flag or
-to all instances running in a specific region using the --region/-r flag.
+Logs can be filtered to specific machines using the --machine/-i flag, with
+an ID or comma separated IDs, to the machines of a process group using the
+--process-group/-g flag, or to all machines running in a specific region
+using the --region/-r flag.
+
+Logs can be filtered by their messages with --grep and --grep-v, regular
+expressions that messages have to match, or not match, like
+--grep-v "GET /health". Regions and single machines narrow the logs the
+platform sends, several machines and patterns narrow them as they're received,
+before they're printed.
+
+Logs can also be filtered by the value of a field with --field, like
+--field level=error. Fields are those of log entries, timestamp, region,
+instance, level and message, and those of apps logging JSON objects, with
+dots for nested ones, like --field http.status=500. A field an app logs
+takes precedence over the log entry's own of the same name, so --field
+level=error matches the level the app logged when there's one. Values are
+compared regardless of case and all the filters have to match.
+
+With --json, each log entry is printed as a JSON object on its own line,
+including the fields logged by apps, ready to pipe into jq or log shippers.
 
 By default logs are continually streamed until the command is aborted.
 Use --no-tail to only fetch the logs in the buffer.
+
+Logs can be narrowed to a time range with --since, like --since 2h, or with
+--from and --to. Times are RFC 3339 timestamps, local times like
+"2024-05-01 13:45", or durations ago like 90m. Logs of a range aren't
+streamed. They're filtered from the recent logs the platform buffers, which
+may only cover the last few minutes of busy apps: older logs can't be
+fetched, and ranges that end before the oldest buffered log fail.
+
+Logs of a release, from when it was created until the next one, are shown
+with --release, like --release v42, and logs since a release with
+--since-release. Like other ranges, only the buffered logs of releases are
+shown, so those of older releases can't be.
 `
 		short = "View app logs"
 	)
@@ -44,22 +78,43 @@ Use --no-tail to only fetch the logs in the buffer.
 
 	cmd.Args = cobra.NoArgs
 
+	cmd.AddCommand(newShip())
+
 	flag.Add(cmd,
 		flag.App(),
 		flag.AppConfig(),
 		flag.Region(),
 		flag.JSONOutput(),
-		flag.String{
-			Name:              "machine",
-			Description:       "Filter by machine ID",
-			Aliases:           []string{"instance"},
-			UseAliasShortHand: true,
-		},
+		narrowFlags,
 		flag.Bool{
 			Name:        "no-tail",
 			Shorthand:   "n",
 			Description: "Do not continually stream logs",
 		},
+		flag.Duration{
+			Name:        "since",
+			Description: "Only show logs of this long ago until now, like 2h",
+		},
+		flag.String{
+			Name:        "from",
+			Description: "Only show logs from this time",
+		},
+		flag.String{
+			Name:        "to",
+			Description: "Only show logs until this time",
+		},
+		flag.String{
+			Name:        "release",
+			Description: "Only show logs of a release, until the next one, like v42",
+		},
+		flag.String{
+			Name:        "since-release",
+			Description: "Only show logs since a release, like v42",
+		},
+		flag.StringArray{
+			Name:        "field",
+			Description: "Only show logs with a field of a value, given as name=value. Can be specified multiple times",
+		},
 	)
 	return
 }
@@ -67,34 +122,100 @@ Use --no-tail to only fetch the logs in the buffer.
 func run(ctx context.Context) error {
 	client := flyutil.ClientFromContext(ctx)
 
+	filters, err := parseFieldFilters(flag.GetStringArray(ctx, "field"))
+	if err != nil {
+		return err
+	}
+
 	opts := &logs.LogOptions{
 		AppName:    appconfig.NameFromContext(ctx),
 		RegionCode: config.FromContext(ctx).Region,
-		VMID:       flag.GetString(ctx, "machine"),
 		NoTail:     flag.GetBool(ctx, "no-tail"),
 	}
+	if opts.From, opts.To, err = timeRange(ctx, time.Now()); err != nil {
+		return err
+	}
+	if err := releaseRange(ctx, client, opts); err != nil {
+		return err
+	}
+	if err := narrow(ctx, opts); err != nil {
+		return err
+	}
 
 	var eg *errgroup.Group
 	eg, ctx = errgroup.WithContext(ctx)
 
-	var streams []<-chan logs.LogEntry
-	if opts.NoTail {
-		streams = []<-chan logs.LogEntry{
+	streams := logStreams(ctx, eg, client, opts)
+
+	eg.Go(func() error {
+		return printStreams(ctx, filters, streams...)
+	})
+
+	err = eg.Wait()
+	if v := lo.CoalesceOrEmpty(flag.GetString(ctx, "release"), flag.GetString(ctx, "since-release")); v != "" && errors.Is(err, logs.ErrNotBuffered) {
+		return fmt.Errorf("the logs of release %s are no longer buffered: %w", v, err)
+	}
+	return err
+}
+
+// logStreams returns the streams of logs for opts, run in eg: polling only
+// when the logs aren't tailed, otherwise NATS with polling until it's up.
+func logStreams(ctx context.Context, eg *errgroup.Group, client flyutil.Client, opts *logs.LogOptions) []<-chan logs.LogEntry {
+	if opts.NoTail || opts.HasRange() {
+		return []<-chan logs.LogEntry{
 			poll(ctx, eg, client, opts),
 		}
-	} else {
-		pollingCtx, cancelPolling := context.WithCancel(ctx)
-		streams = []<-chan logs.LogEntry{
-			poll(pollingCtx, eg, client, opts),
-			nats(ctx, eg, client, opts, cancelPolling),
+	}
+
+	pollingCtx, cancelPolling := context.WithCancel(ctx)
+	return []<-chan logs.LogEntry{
+		poll(pollingCtx, eg, client, opts),
+		nats(ctx, eg, client, opts, cancelPolling),
+	}
+}
+
+// timeRange returns the time range of logs given with --since, or --from and
+// --to. Either end of it is zero when it's open.
+func timeRange(ctx context.Context, now time.Time) (from, to time.Time, err error) {
+	if since := flag.GetDuration(ctx, "since"); since > 0 {
+		if flag.IsSpecified(ctx, "from") {
+			return from, to, errors.New("--since can't be used with --from")
 		}
+		from = now.Add(-since)
 	}
 
-	eg.Go(func() error {
-		return printStreams(ctx, streams...)
-	})
+	if v := flag.GetString(ctx, "from"); v != "" {
+		if from, err = parseLogTime(v, now); err != nil {
+			return from, to, fmt.Errorf("invalid --from: %w", err)
+		}
+	}
+	if v := flag.GetString(ctx, "to"); v != "" {
+		if to, err = parseLogTime(v, now); err != nil {
+			return from, to, fmt.Errorf("invalid --to: %w", err)
+		}
+	}
 
-	return eg.Wait()
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return from, to, errors.New("--from must be before --to")
+	}
+	return from, to, nil
+}
+
+// parseLogTime parses an RFC 3339 timestamp, a local time, or a duration
+// before now.
+func parseLogTime(v string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, v, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	if d, err := time.ParseDuration(v); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("%q is neither a timestamp nor a duration", v)
 }
 
 func poll(ctx context.Context, eg *errgroup.Group, client flyutil.Client, opts *logs.LogOptions) <-chan logs.LogEntry {
@@ -146,24 +267,26 @@ func nats(ctx context.Context, eg *errgroup.Group, client flyutil.Client, opts *
 	return c
 }
 
-func printStreams(ctx context.Context, streams ...<-chan logs.LogEntry) error {
+func printStreams(ctx context.Context, filters []fieldFilter, streams ...<-chan logs.LogEntry) error {
 	var eg *errgroup.Group
 	eg, ctx = errgroup.WithContext(ctx)
 
 	out := iostreams.FromContext(ctx).Out
-	json := config.FromContext(ctx).JSONOutput
+	jsonOutput := config.FromContext(ctx).JSONOutput
 
 	for _, stream := range streams {
 		stream := stream
 
 		eg.Go(func() error {
-			return printStream(ctx, out, stream, json)
+			return printStream(ctx, out, stream, filters, jsonOutput)
 		})
 	}
 	return eg.Wait()
 }
 
-func printStream(ctx context.Context, w io.Writer, stream <-chan logs.LogEntry, json bool) error {
+func printStream(ctx context.Context, w io.Writer, stream <-chan logs.LogEntry, filters []fieldFilter, jsonOutput bool) error {
+	enc := json.NewEncoder(w)
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -173,9 +296,14 @@ func printStream(ctx context.Context, w io.Writer, stream <-chan logs.LogEntry,
 				return nil
 			}
 
+			r := newRecord(entry)
+			if !matches(r, filters) {
+				continue
+			}
+
 			var err error
-			if json {
-				err = render.JSON(w, entry)
+			if jsonOutput {
+				err = enc.Encode(r)
 			} else {
 				err = render.LogEntry(w, entry,
 					render.HideAllocID(),
@@ -0,0 +1,97 @@
+package logs
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/superfly/fly-go/flaps"
+
+	"github.com/superfly/flyctl/logs"
+
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flag/flagnames"
+	"github.com/superfly/flyctl/internal/flapsutil"
+)
+
+// narrowFlags are the flags narrowing logs to those of machines, or of
+// messages matching patterns.
+var narrowFlags = flag.Set{
+	flag.String{
+		Name:              "machine",
+		Description:       "Filter by machine ID, or comma separated IDs",
+		Aliases:           []string{"instance"},
+		UseAliasShortHand: true,
+	},
+	flag.ProcessGroup("Filter by the machines of a process group"),
+	flag.String{
+		Name:        "grep",
+		Description: "Only show logs with messages matching a regular expression",
+	},
+	flag.String{
+		Name:        "grep-v",
+		Description: "Only show logs with messages not matching a regular expression",
+	},
+}
+
+// narrow narrows the logs of opts to the machines and the patterns given with
+// narrowFlags.
+func narrow(ctx context.Context, opts *logs.LogOptions) (err error) {
+	if v := flag.GetString(ctx, "grep"); v != "" {
+		if opts.Grep, err = regexp.Compile(v); err != nil {
+			return fmt.Errorf("invalid --grep: %w", err)
+		}
+	}
+	if v := flag.GetString(ctx, "grep-v"); v != "" {
+		if opts.GrepV, err = regexp.Compile(v); err != nil {
+			return fmt.Errorf("invalid --grep-v: %w", err)
+		}
+	}
+
+	ids := lo.Uniq(lo.Compact(lo.Map(strings.Split(flag.GetString(ctx, "machine"), ","), func(id string, _ int) string {
+		return strings.TrimSpace(id)
+	})))
+
+	if group := flag.GetString(ctx, flagnames.ProcessGroup); group != "" {
+		if ids, err = groupMachines(ctx, opts.AppName, group, ids); err != nil {
+			return err
+		}
+	}
+
+	// A single machine is narrowed to by the platform, several by flyctl
+	if len(ids) == 1 {
+		opts.VMID = ids[0]
+	} else {
+		opts.VMIDs = ids
+	}
+	return nil
+}
+
+// groupMachines returns the IDs of the machines of the process group, among
+// ids when there are any.
+func groupMachines(ctx context.Context, appName, group string, ids []string) ([]string, error) {
+	flapsClient, err := flapsutil.NewClientWithOptions(ctx, flaps.NewClientOpts{
+		AppName: appName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not create flaps client: %w", err)
+	}
+
+	machines, err := flapsClient.ListActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list machines: %w", err)
+	}
+
+	var groupIDs []string
+	for _, m := range machines {
+		if m.ProcessGroup() == group && (len(ids) == 0 || lo.Contains(ids, m.ID)) {
+			groupIDs = append(groupIDs, m.ID)
+		}
+	}
+	if len(groupIDs) == 0 {
+		return nil, fmt.Errorf("no machines of %s in process group %s", appName, group)
+	}
+	return groupIDs, nil
+}
@@ -0,0 +1,119 @@
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/superfly/flyctl/logs"
+)
+
+// record is a log entry as printed with --json, one per line. Apps logging
+// JSON objects get their fields in Fields.
+type record struct {
+	Timestamp string         `json:"timestamp"`
+	Region    string         `json:"region"`
+	Instance  string         `json:"instance"`
+	Level     string         `json:"level"`
+	Message   string         `json:"message"`
+	Fields    map[string]any `json:"fields,omitempty"`
+	Meta      logs.Meta      `json:"meta"`
+}
+
+func newRecord(entry logs.LogEntry) record {
+	r := record{
+		Timestamp: entry.Timestamp,
+		Region:    entry.Region,
+		Instance:  entry.Instance,
+		Level:     entry.Level,
+		Message:   entry.Message,
+		Meta:      entry.Meta,
+	}
+
+	if msg := strings.TrimSpace(entry.Message); strings.HasPrefix(msg, "{") {
+		var fields map[string]any
+		if err := json.Unmarshal([]byte(msg), &fields); err == nil {
+			r.Fields = fields
+		}
+	}
+
+	return r
+}
+
+// field returns the value of the named field of the record. Fields the app
+// logged come first, so that an app's own level isn't shadowed by the one of
+// the platform, then come the record's own. Fields of nested objects are
+// named with dots, like http.status.
+func (r record) field(name string) (string, bool) {
+	if v, ok := r.appField(name); ok {
+		return v, true
+	}
+
+	switch name {
+	case "timestamp":
+		return r.Timestamp, true
+	case "region":
+		return r.Region, true
+	case "instance":
+		return r.Instance, true
+	case "level":
+		return r.Level, true
+	case "message":
+		return r.Message, true
+	}
+	return "", false
+}
+
+func (r record) appField(name string) (string, bool) {
+	var v any = r.Fields
+	for _, key := range strings.Split(name, ".") {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return "", false
+		}
+		if v, ok = obj[key]; !ok {
+			return "", false
+		}
+	}
+
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case map[string]any, []any:
+		b, _ := json.Marshal(v)
+		return string(b), true
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+// fieldFilter matches the records with a field of a value, given as
+// name=value.
+type fieldFilter struct {
+	name  string
+	value string
+}
+
+func parseFieldFilters(args []string) ([]fieldFilter, error) {
+	filters := make([]fieldFilter, 0, len(args))
+	for _, arg := range args {
+		name, value, ok := strings.Cut(arg, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid field filter %q, must be name=value", arg)
+		}
+		filters = append(filters, fieldFilter{name: name, value: value})
+	}
+	return filters, nil
+}
+
+// matches reports whether the record matches all the filters, comparing
+// values regardless of case.
+func matches(r record, filters []fieldFilter) bool {
+	for _, f := range filters {
+		v, ok := r.field(f.name)
+		if !ok || !strings.EqualFold(v, f.value) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package logs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	fly "github.com/superfly/fly-go"
+
+	"github.com/superfly/flyctl/logs"
+
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyutil"
+)
+
+// releaseLookback is how many of the latest releases are looked up for
+// --release and --since-release.
+const releaseLookback = 100
+
+// releaseRange narrows opts to the logs of the release given with --release,
+// until the next release, or to those since the one given with
+// --since-release.
+func releaseRange(ctx context.Context, client flyutil.Client, opts *logs.LogOptions) error {
+	during, since := flag.GetString(ctx, "release"), flag.GetString(ctx, "since-release")
+	switch {
+	case during == "" && since == "":
+		return nil
+	case during != "" && since != "":
+		return errors.New("--release can't be used with --since-release")
+	case opts.HasRange():
+		return errors.New("--release and --since-release can't be used with --since, --from or --to")
+	}
+
+	v, name := during, "--release"
+	if since != "" {
+		v, name = since, "--since-release"
+	}
+	version, err := parseReleaseVersion(v)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+
+	releases, err := client.GetAppReleasesMachines(ctx, opts.AppName, "", releaseLookback)
+	if err != nil {
+		return fmt.Errorf("failed retrieving app releases %s: %w", opts.AppName, err)
+	}
+
+	var release, next *fly.Release
+	for i := range releases {
+		switch r := &releases[i]; {
+		case r.Version == version:
+			release = r
+		case r.Version > version && (next == nil || r.Version < next.Version):
+			next = r
+		}
+	}
+	if release == nil {
+		return fmt.Errorf("release v%d of %s not found among its latest %d releases", version, opts.AppName, releaseLookback)
+	}
+
+	opts.From = release.CreatedAt
+	if during != "" && next != nil {
+		opts.To = next.CreatedAt
+	}
+	return nil
+}
+
+// parseReleaseVersion parses a release version, like v42 or 42.
+func parseReleaseVersion(v string) (int, error) {
+	version, err := strconv.Atoi(strings.TrimPrefix(strings.ToLower(v), "v"))
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("%q isn't a release version, like v42", v)
+	}
+	return version, nil
+}
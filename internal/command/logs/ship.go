@@ -0,0 +1,211 @@
+package logs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/superfly/flyctl/iostreams"
+	"github.com/superfly/flyctl/logs"
+
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyutil"
+)
+
+func newShip() (cmd *cobra.Command) {
+	const (
+		long = `Ship the logs of an app to durable storage, as they're generated, until
+the command is aborted. Logs are written as JSON objects, one per line, like
+with 'fly logs --json'.
+
+Destinations are files, rotated once they reach --max-size with --max-files
+of them kept:
+
+  fly logs ship --dest /var/log/my-app.ndjson
+
+or S3 compatible buckets, like Tigris ones, where logs are uploaded as gzipped
+objects under the prefix every --flush-interval:
+
+  fly logs ship --dest s3://my-bucket/logs
+
+Buckets are accessed with the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+AWS_REGION environment variables, and AWS_ENDPOINT_URL_S3 for buckets outside
+of AWS.
+`
+		short = "Ship app logs to files or S3 buckets"
+	)
+
+	cmd = command.New("ship", short, long, runShip,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Region(),
+		narrowFlags,
+		flag.StringArray{
+			Name:        "field",
+			Description: "Only ship logs with a field of a value, given as name=value. Can be specified multiple times",
+		},
+		flag.String{
+			Name:        "dest",
+			Description: "Where to ship logs, a file path or an s3://bucket/prefix URL",
+		},
+		flag.String{
+			Name:        "max-size",
+			Default:     "100MB",
+			Description: "Size of log files at which they're rotated",
+		},
+		flag.Int{
+			Name:        "max-files",
+			Default:     5,
+			Description: "Number of rotated log files to keep",
+		},
+		flag.Duration{
+			Name:        "flush-interval",
+			Default:     time.Minute,
+			Description: "How often logs are written to the destination",
+		},
+	)
+
+	return
+}
+
+// logSink is a destination of shipped logs, written one JSON line at a time.
+// Sinks are safe for concurrent use.
+type logSink interface {
+	Write(line []byte) error
+	Flush(ctx context.Context) error
+	Close(ctx context.Context) error
+}
+
+func runShip(ctx context.Context) error {
+	io := iostreams.FromContext(ctx)
+	client := flyutil.ClientFromContext(ctx)
+	appName := appconfig.NameFromContext(ctx)
+
+	filters, err := parseFieldFilters(flag.GetStringArray(ctx, "field"))
+	if err != nil {
+		return err
+	}
+
+	dest := flag.GetString(ctx, "dest")
+	if dest == "" {
+		return errors.New("a destination is required, given with --dest")
+	}
+	maxSize, err := humanize.ParseBytes(flag.GetString(ctx, "max-size"))
+	if err != nil {
+		return fmt.Errorf("invalid --max-size: %w", err)
+	}
+
+	sink, err := newLogSink(dest, appName, int64(maxSize), flag.GetInt(ctx, "max-files"))
+	if err != nil {
+		return err
+	}
+
+	opts := &logs.LogOptions{
+		AppName:    appName,
+		RegionCode: config.FromContext(ctx).Region,
+	}
+	if err := narrow(ctx, opts); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(io.ErrOut, "Shipping the logs of %s to %s\n", appName, dest)
+
+	eg, ectx := errgroup.WithContext(ctx)
+
+	for _, stream := range logStreams(ectx, eg, client, opts) {
+		eg.Go(func() error {
+			return shipStream(ectx, sink, stream, filters)
+		})
+	}
+
+	eg.Go(func() error {
+		ticker := time.NewTicker(flag.GetDuration(ctx, "flush-interval"))
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ectx.Done():
+				return nil
+			case <-ticker.C:
+				if err := sink.Flush(ectx); err != nil {
+					// Logs stay buffered and are written with the next flush
+					fmt.Fprintf(io.ErrOut, "Failed to write logs to %s: %v\n", dest, err)
+				}
+			}
+		}
+	})
+
+	err = eg.Wait()
+
+	// Write what's left even when interrupted
+	closeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+	if cerr := sink.Close(closeCtx); cerr != nil {
+		return fmt.Errorf("failed to write logs to %s: %w", dest, cerr)
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+func shipStream(ctx context.Context, sink logSink, stream <-chan logs.LogEntry, filters []fieldFilter) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case entry, ok := <-stream:
+			if !ok {
+				return nil
+			}
+
+			r := newRecord(entry)
+			if !matches(r, filters) {
+				continue
+			}
+
+			line, err := json.Marshal(r)
+			if err != nil {
+				return err
+			}
+			if err := sink.Write(append(line, '\n')); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// newLogSink returns the sink for dest, a path or an s3:// URL.
+func newLogSink(dest, appName string, maxSize int64, maxFiles int) (logSink, error) {
+	if !strings.HasPrefix(dest, "s3://") {
+		return newFileSink(strings.TrimPrefix(dest, "file://"), maxSize, maxFiles)
+	}
+
+	u, err := url.Parse(dest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid destination %s: %w", dest, err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid destination %s: no bucket", dest)
+	}
+	return newS3Sink(u.Host, strings.Trim(u.Path, "/"), appName)
+}
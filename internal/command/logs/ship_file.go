@@ -0,0 +1,112 @@
+package logs
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// fileSink writes logs to a file, rotated once it reaches maxSize: the file
+// is renamed with a .1 suffix, the previous .1 to .2, and so on, keeping
+// maxFiles of them.
+type fileSink struct {
+	mu       sync.Mutex
+	path     string
+	maxSize  int64
+	maxFiles int
+
+	f    *os.File
+	w    *bufio.Writer
+	size int64
+}
+
+func newFileSink(path string, maxSize int64, maxFiles int) (*fileSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	s := &fileSink{path: path, maxSize: maxSize, maxFiles: maxFiles}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *fileSink) open() error {
+	f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	inf, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	s.f, s.w, s.size = f, bufio.NewWriter(f), inf.Size()
+	return nil
+}
+
+func (s *fileSink) Write(line []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.maxSize > 0 && s.size > 0 && s.size+int64(len(line)) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			return fmt.Errorf("rotate %s: %w", s.path, err)
+		}
+	}
+
+	n, err := s.w.Write(line)
+	s.size += int64(n)
+	return err
+}
+
+func (s *fileSink) rotate() error {
+	if err := s.w.Flush(); err != nil {
+		return err
+	}
+	if err := s.f.Close(); err != nil {
+		return err
+	}
+
+	if s.maxFiles < 1 {
+		if err := os.Remove(s.path); err != nil {
+			return err
+		}
+		return s.open()
+	}
+
+	for i := s.maxFiles - 1; i >= 1; i-- {
+		err := os.Rename(fmt.Sprintf("%s.%d", s.path, i), fmt.Sprintf("%s.%d", s.path, i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(s.path, s.path+".1"); err != nil {
+		return err
+	}
+	return s.open()
+}
+
+func (s *fileSink) Flush(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.w.Flush()
+}
+
+func (s *fileSink) Close(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.w.Flush(); err != nil {
+		s.f.Close()
+		return err
+	}
+	return s.f.Close()
+}
@@ -0,0 +1,204 @@
+package logs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// s3Sink uploads logs to an S3 compatible bucket, as a gzipped object of the
+// logs written since the previous flush.
+type s3Sink struct {
+	mu  sync.Mutex
+	buf []byte
+
+	bucket   string
+	prefix   string
+	endpoint string
+	region   string
+
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+
+	client *http.Client
+}
+
+func newS3Sink(bucket, prefix, appName string) (*s3Sink, error) {
+	s := &s3Sink{
+		bucket:          bucket,
+		prefix:          path.Join(prefix, appName),
+		endpoint:        os.Getenv("AWS_ENDPOINT_URL_S3"),
+		region:          os.Getenv("AWS_REGION"),
+		accessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		client:          &http.Client{Timeout: time.Minute},
+	}
+
+	if s.accessKeyID == "" || s.secretAccessKey == "" {
+		return nil, errors.New("shipping logs to S3 requires the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables")
+	}
+	if s.region == "" {
+		s.region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if s.region == "" {
+		s.region = "us-east-1"
+	}
+
+	return s, nil
+}
+
+func (s *s3Sink) Write(line []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.buf = append(s.buf, line...)
+	return nil
+}
+
+func (s *s3Sink) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	data := s.buf
+	s.buf = nil
+	s.mu.Unlock()
+
+	if len(data) == 0 {
+		return nil
+	}
+
+	if err := s.upload(ctx, data); err != nil {
+		// Keep the logs for the next flush
+		s.mu.Lock()
+		s.buf = append(data, s.buf...)
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (s *s3Sink) Close(ctx context.Context) error {
+	return s.Flush(ctx)
+}
+
+func (s *s3Sink) upload(ctx context.Context, data []byte) error {
+	var body bytes.Buffer
+	zw := gzip.NewWriter(&body)
+	if _, err := zw.Write(data); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	key := path.Join(s.prefix, now.Format("2006/01/02"), fmt.Sprintf("%s-%x.ndjson.gz", now.Format("150405.000000"), suffix))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key), bytes.NewReader(body.Bytes()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/gzip")
+	s.sign(req, body.Bytes(), now)
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close() // skipcq: GO-S2307
+
+	if res.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("upload of %s failed with status %s: %s", key, res.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// objectURL returns the URL of the object with key: path-style with a custom
+// endpoint, virtual-hosted-style on AWS.
+func (s *s3Sink) objectURL(key string) string {
+	if s.endpoint != "" {
+		return strings.TrimSuffix(s.endpoint, "/") + "/" + s.bucket + "/" + s3EscapePath(key)
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.bucket, s.region, s3EscapePath(key))
+}
+
+// sign signs req with AWS Signature Version 4.
+func (s *s3Sink) sign(req *http.Request, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := fmt.Sprintf("host:%s\nx-amz-content-sha256:%s\nx-amz-date:%s\n", req.URL.Host, payloadHash, amzDate)
+	if s.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.sessionToken)
+		signedHeaders += ";x-amz-security-token"
+		canonicalHeaders += fmt.Sprintf("x-amz-security-token:%s\n", s.sessionToken)
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", date, s.region)
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretAccessKey), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", s.accessKeyID, scope, signedHeaders, signature))
+}
+
+// s3EscapePath escapes an object key the way S3 signs it, everything but
+// unreserved characters and slashes.
+func s3EscapePath(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		switch c := key[i]; {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', strings.IndexByte("-_.~/", c) >= 0:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
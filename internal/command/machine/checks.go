@@ -0,0 +1,141 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/command/ssh"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyerr"
+	"github.com/superfly/flyctl/internal/flyutil"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func newChecks() *cobra.Command {
+	const (
+		short = "Manage the health checks of a machine"
+		long  = short + "\n"
+		usage = "checks <command>"
+	)
+
+	cmd := command.New(usage, short, long, nil)
+
+	cmd.AddCommand(
+		newChecksRun(),
+	)
+
+	return cmd
+}
+
+func newChecksRun() *cobra.Command {
+	const (
+		short = "Run the health checks of a machine now"
+		long  = short + `. The checks configured on the machine are evaluated
+immediately through a WireGuard tunnel to the machine's private address, and
+the full output of each check, such as the response body of HTTP checks, is
+printed. Exits with status 1 if any check fails.
+`
+		usage = "run [<id>]"
+	)
+
+	cmd := command.New(usage, short, long, runMachineChecksRun,
+		command.RequireSession,
+		command.LoadAppNameIfPresent,
+	)
+
+	cmd.Args = cobra.MaximumNArgs(1)
+
+	flag.Add(
+		cmd,
+		flag.App(),
+		flag.AppConfig(),
+		selectFlag,
+		flag.JSONOutput(),
+		flag.StringSlice{
+			Name:        "check",
+			Description: "Only run the checks with these names",
+		},
+	)
+
+	return cmd
+}
+
+func runMachineChecksRun(ctx context.Context) error {
+	var (
+		io       = iostreams.FromContext(ctx)
+		colorize = io.ColorScheme()
+		client   = flyutil.ClientFromContext(ctx)
+		names    = flag.GetStringSlice(ctx, "check")
+	)
+
+	machineID := flag.FirstArg(ctx)
+	haveMachineID := len(flag.Args(ctx)) > 0
+	machine, ctx, err := selectOneMachine(ctx, "", machineID, haveMachineID)
+	if err != nil {
+		return err
+	}
+
+	if machine.State != fly.MachineStateStarted {
+		return fmt.Errorf("machine %s is %s, checks can only be run on started machines", machine.ID, machine.State)
+	}
+
+	checks := mach.ConfiguredChecks(machine)
+	if len(names) > 0 {
+		checks = slices.DeleteFunc(checks, func(c mach.NamedCheck) bool {
+			return !slices.Contains(names, c.Name)
+		})
+	}
+	if len(checks) == 0 {
+		return fmt.Errorf("machine %s has no checks to run", machine.ID)
+	}
+
+	app, err := client.GetAppCompact(ctx, appconfig.NameFromContext(ctx))
+	if err != nil {
+		return fmt.Errorf("could not get app: %w", err)
+	}
+
+	_, dialer, err := ssh.BringUpAgent(ctx, client, app, "", config.FromContext(ctx).JSONOutput)
+	if err != nil {
+		return err
+	}
+
+	results := make([]*mach.CheckResult, 0, len(checks))
+	for _, check := range checks {
+		results = append(results, mach.RunCheck(ctx, dialer.DialContext, machine.PrivateIP, check))
+	}
+
+	failed := slices.ContainsFunc(results, func(r *mach.CheckResult) bool { return !r.Passing })
+
+	if config.FromContext(ctx).JSONOutput {
+		if err := render.JSON(io.Out, results); err != nil {
+			return err
+		}
+	} else {
+		for _, r := range results {
+			status := colorize.Green("passing")
+			if !r.Passing {
+				status = colorize.Red("critical")
+			}
+			fmt.Fprintf(io.Out, "%s (%s %s) %s in %s\n", colorize.Bold(r.Name), r.Type, r.Target, status, r.Duration.Round(time.Millisecond))
+			for _, line := range strings.Split(r.Output, "\n") {
+				fmt.Fprintf(io.Out, "  %s\n", line)
+			}
+			fmt.Fprintln(io.Out)
+		}
+	}
+
+	if failed {
+		return flyerr.ExitCodeError{Code: 1}
+	}
+	return nil
+}
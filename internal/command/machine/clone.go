@@ -14,6 +14,7 @@ import (
 	"github.com/superfly/flyctl/internal/appconfig"
 	"github.com/superfly/flyctl/internal/command"
 	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flag/completion"
 	"github.com/superfly/flyctl/internal/flapsutil"
 	mach "github.com/superfly/flyctl/internal/machine"
 	"github.com/superfly/flyctl/internal/watch"
@@ -23,7 +24,7 @@ import (
 func newClone() *cobra.Command {
 	const (
 		short = "Clone a Fly Machine"
-		long  = "Clone a Fly Machine. The new Machine will be a copy of the specified Machine. If the original Machine has a volume, then a new empty volume will be created and attached to the new Machine."
+		long  = "Clone a Fly Machine. The new Machine will be a copy of the specified Machine. If the original Machine has a volume, then a new empty volume will be created and attached to the new Machine. Use --count and a comma separated --region list to create several clones at once, spread across regions."
 
 		usage = "clone [machine_id]"
 	)
@@ -41,7 +42,17 @@ func newClone() *cobra.Command {
 		flag.App(),
 		flag.AppConfig(),
 		selectFlag,
-		flag.Region(),
+		flag.String{
+			Name:         "region",
+			Shorthand:    "r",
+			Description:  "Comma separated list of target regions for the new Machines. Clones are spread round-robin across the regions.",
+			CompletionFn: completion.CompleteRegions,
+		},
+		flag.Int{
+			Name:        "count",
+			Description: "Number of clones to create",
+			Default:     1,
+		},
 		flag.String{
 			Name:        "name",
 			Description: "Optional name for the new Machine",
@@ -58,6 +69,10 @@ func newClone() *cobra.Command {
 			Name:        "override-cmd",
 			Description: "Set CMD on the new Machine to this value",
 		},
+		flag.Bool{
+			Name:        "fork-volumes",
+			Description: "Fork the source Machine's volumes, copying their data, instead of creating empty volumes",
+		},
 		flag.Bool{
 			Name:        "clear-cmd",
 			Description: "Set empty CMD on the new Machine so it uses default CMD for the image",
@@ -114,18 +129,30 @@ func runMachineClone(ctx context.Context) (err error) {
 		}
 	}
 
-	region := flag.GetString(ctx, "region")
-	if vol != nil && region != "" {
-		if vol.Region != region {
-			return fmt.Errorf("specified region %s but volume is in region %s, use the same region as the volume", colorize.Bold(region), colorize.Bold(vol.Region))
-		}
-	} else if vol != nil && region == "" {
-		region = vol.Region
-	} else if region == "" {
-		region = source.Region
+	regions := lo.Compact(lo.Map(strings.Split(flag.GetString(ctx, "region"), ","), func(r string, _ int) string {
+		return strings.TrimSpace(r)
+	}))
+	count := flag.GetInt(ctx, "count")
+	switch {
+	case count < 1:
+		return fmt.Errorf("--count must be at least 1")
+	case count > 1 && flag.GetString(ctx, "name") != "":
+		return fmt.Errorf("--name can't be used when creating more than one clone")
+	case count > 1 && vol != nil:
+		return fmt.Errorf("--attach-volume can't be used when creating more than one clone")
+	case flag.GetBool(ctx, "fork-volumes") && flag.GetString(ctx, "from-snapshot") != "":
+		return fmt.Errorf("--fork-volumes and --from-snapshot can't be used together")
 	}
 
-	fmt.Fprintf(out, "Cloning Machine %s into region %s\n", colorize.Bold(source.ID), colorize.Bold(region))
+	if vol != nil && len(regions) > 0 {
+		if len(regions) > 1 || vol.Region != regions[0] {
+			return fmt.Errorf("specified region %s but volume is in region %s, use the same region as the volume", colorize.Bold(strings.Join(regions, ",")), colorize.Bold(vol.Region))
+		}
+	} else if vol != nil {
+		regions = []string{vol.Region}
+	} else if len(regions) == 0 {
+		regions = []string{source.Region}
+	}
 
 	targetConfig := helpers.Clone(source.Config)
 
@@ -179,25 +206,114 @@ func runMachineClone(ctx context.Context) (err error) {
 		}
 	}
 
+	// Standby machine
+	if flag.IsSpecified(ctx, "standby-for") {
+		standbys := flag.GetStringSlice(ctx, "standby-for")
+		for idx := range standbys {
+			if standbys[idx] == "source" {
+				standbys[idx] = source.ID
+			}
+		}
+		targetConfig.Standbys = lo.Ternary(len(standbys) > 0, standbys, nil)
+		targetConfig.Env = lo.Assign(targetConfig.Env,
+			map[string]string{"FLY_STANDBY_FOR": strings.Join(standbys, ",")},
+		)
+	}
+
+	var launchedMachines []*fly.Machine
+	for i := 0; i < count; i++ {
+		// Spread the clones round-robin across the requested regions.
+		region := regions[i%len(regions)]
+
+		fmt.Fprintf(out, "Cloning Machine %s into region %s\n", colorize.Bold(source.ID), colorize.Bold(region))
+
+		config := helpers.Clone(targetConfig)
+		if config.Mounts, err = cloneMounts(ctx, source, config, region, volID); err != nil {
+			return err
+		}
+
+		input := fly.LaunchMachineInput{
+			Name:       flag.GetString(ctx, "name"),
+			Region:     region,
+			Config:     config,
+			SkipLaunch: len(config.Standbys) > 0,
+		}
+
+		fmt.Fprintf(out, "Provisioning a new Machine with image %s...\n", source.Config.Image)
+
+		launchedMachine, err := flapsClient.Launch(ctx, input)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "  Machine %s has been created...\n", colorize.Bold(launchedMachine.ID))
+		launchedMachines = append(launchedMachines, launchedMachine)
+	}
+
+	if flag.GetDetach(ctx) {
+		return nil
+	}
+
+	if len(targetConfig.Standbys) == 0 {
+		for _, launchedMachine := range launchedMachines {
+			fmt.Fprintf(out, "  Waiting for Machine %s to start...\n", colorize.Bold(launchedMachine.ID))
+
+			// wait for a machine to be started
+			err = mach.WaitForStartOrStop(ctx, launchedMachine, "start", time.Minute*5)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err = watch.MachinesChecks(ctx, launchedMachines); err != nil {
+			return fmt.Errorf("error while watching health checks: %w", err)
+		}
+	}
+
+	if len(launchedMachines) > 1 {
+		fmt.Fprintf(out, "%d Machines have been successfully cloned!\n", len(launchedMachines))
+	} else {
+		fmt.Fprintf(out, "Machine has been successfully cloned!\n")
+	}
+
+	return
+}
+
+// cloneMounts returns the mounts for a clone of source in region, attaching
+// the existing volume volID if set, and otherwise creating a new volume for
+// every mount of source.
+func cloneMounts(ctx context.Context, source *fly.Machine, targetConfig *fly.MachineConfig, region, volID string) ([]fly.MachineMount, error) {
+	var (
+		io          = iostreams.FromContext(ctx)
+		out         = io.Out
+		colorize    = io.ColorScheme()
+		flapsClient = flapsutil.ClientFromContext(ctx)
+		mounts      []fly.MachineMount
+		err         error
+	)
+
 	for _, mnt := range source.Config.Mounts {
 		var vol *fly.Volume
 		if volID != "" {
 			fmt.Fprintf(out, "Attaching existing volume %s\n", colorize.Bold(volID))
 			vol, err = flapsClient.GetVolume(ctx, volID)
 			if err != nil {
-				return fmt.Errorf("could not get existing volume: %w", err)
+				return nil, fmt.Errorf("could not get existing volume: %w", err)
 			}
 
 			if vol.IsAttached() {
-				return fmt.Errorf("volume %s is already attached to a machine", vol.ID)
+				return nil, fmt.Errorf("volume %s is already attached to a machine", vol.ID)
 			}
 		} else {
-			var snapshotID *string
-			switch snapID := flag.GetString(ctx, "from-snapshot"); snapID {
-			case "last":
+			var snapshotID, sourceVolumeID *string
+			switch snapID := flag.GetString(ctx, "from-snapshot"); {
+			case flag.GetBool(ctx, "fork-volumes"):
+				sourceVolumeID = fly.Pointer(mnt.Volume)
+				fmt.Fprintf(out, "Forking volume %s\n", colorize.Bold(mnt.Volume))
+			case snapID == "last":
 				snapshots, err := flapsClient.GetVolumeSnapshots(ctx, mnt.Volume)
 				if err != nil {
-					return err
+					return nil, err
 				}
 				if len(snapshots) > 0 {
 					snapshot := lo.MaxBy(snapshots, func(i, j fly.VolumeSnapshot) bool { return i.CreatedAt.After(j.CreatedAt) })
@@ -207,7 +323,7 @@ func runMachineClone(ctx context.Context) (err error) {
 					fmt.Fprintf(out, "No snapshot for source volume %s, the new volume will start empty\n", colorize.Bold(mnt.Volume))
 					snapshotID = nil
 				}
-			case "":
+			case snapID == "":
 				fmt.Fprintf(out, "Volume '%s' will start empty\n", colorize.Bold(mnt.Name))
 			default:
 				snapshotID = &snapID
@@ -220,17 +336,18 @@ func runMachineClone(ctx context.Context) (err error) {
 				SizeGb:              &mnt.SizeGb,
 				Encrypted:           &mnt.Encrypted,
 				SnapshotID:          snapshotID,
+				SourceVolumeID:      sourceVolumeID,
 				RequireUniqueZone:   fly.Pointer(flag.GetBool(ctx, "volume-requires-unique-zone")),
 				ComputeRequirements: targetConfig.Guest,
 				ComputeImage:        targetConfig.Image,
 			}
 			vol, err = flapsClient.CreateVolume(ctx, volInput)
 			if err != nil {
-				return err
+				return nil, err
 			}
 		}
 
-		targetConfig.Mounts = []fly.MachineMount{
+		mounts = []fly.MachineMount{
 			{
 				Volume:                 vol.ID,
 				Path:                   mnt.Path,
@@ -241,55 +358,5 @@ func runMachineClone(ctx context.Context) (err error) {
 		}
 	}
 
-	// Standby machine
-	if flag.IsSpecified(ctx, "standby-for") {
-		standbys := flag.GetStringSlice(ctx, "standby-for")
-		for idx := range standbys {
-			if standbys[idx] == "source" {
-				standbys[idx] = source.ID
-			}
-		}
-		targetConfig.Standbys = lo.Ternary(len(standbys) > 0, standbys, nil)
-		targetConfig.Env = lo.Assign(targetConfig.Env,
-			map[string]string{"FLY_STANDBY_FOR": strings.Join(standbys, ",")},
-		)
-	}
-
-	input := fly.LaunchMachineInput{
-		Name:       flag.GetString(ctx, "name"),
-		Region:     region,
-		Config:     targetConfig,
-		SkipLaunch: len(targetConfig.Standbys) > 0,
-	}
-
-	fmt.Fprintf(out, "Provisioning a new Machine with image %s...\n", source.Config.Image)
-
-	launchedMachine, err := flapsClient.Launch(ctx, input)
-	if err != nil {
-		return err
-	}
-
-	fmt.Fprintf(out, "  Machine %s has been created...\n", colorize.Bold(launchedMachine.ID))
-
-	if flag.GetDetach(ctx) {
-		return nil
-	}
-
-	if !input.SkipLaunch {
-		fmt.Fprintf(out, "  Waiting for Machine %s to start...\n", colorize.Bold(launchedMachine.ID))
-
-		// wait for a machine to be started
-		err = mach.WaitForStartOrStop(ctx, launchedMachine, "start", time.Minute*5)
-		if err != nil {
-			return err
-		}
-
-		if err = watch.MachinesChecks(ctx, []*fly.Machine{launchedMachine}); err != nil {
-			return fmt.Errorf("error while watching health checks: %w", err)
-		}
-	}
-
-	fmt.Fprintf(out, "Machine has been successfully cloned!\n")
-
-	return
+	return mounts, nil
 }
@@ -0,0 +1,116 @@
+package machine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/flyctl/helpers"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func newConfig() *cobra.Command {
+	const (
+		short = "Manage machine configs"
+		long  = short + "\n"
+		usage = "config <command>"
+	)
+
+	cmd := command.New(usage, short, long, nil)
+
+	cmd.AddCommand(
+		newConfigExport(),
+	)
+
+	return cmd
+}
+
+func newConfigExport() *cobra.Command {
+	const (
+		short = "Export a machine's config as JSON"
+		long  = short + `. The exported config can be used to create an
+identical machine, in this or another app, with
+"fly machine create --from-config <file>".
+`
+		usage = "export [machine-id]"
+	)
+
+	cmd := command.New(usage, short, long, runConfigExport,
+		command.RequireSession,
+		command.LoadAppNameIfPresent,
+	)
+
+	cmd.Args = cobra.RangeArgs(0, 1)
+
+	flag.Add(
+		cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.String{
+			Name:        "output",
+			Shorthand:   "o",
+			Description: "Write the config to this file instead of stdout",
+		},
+	)
+
+	return cmd
+}
+
+func runConfigExport(ctx context.Context) error {
+	io := iostreams.FromContext(ctx)
+
+	machineID := flag.FirstArg(ctx)
+	haveMachineID := len(flag.Args(ctx)) > 0
+	machine, _, err := selectOneMachine(ctx, "", machineID, haveMachineID)
+	if err != nil {
+		return err
+	}
+
+	if machine.HostStatus != fly.HostStatusOk || machine.Config == nil {
+		return fmt.Errorf("the config of machine %s can't be retrieved as it is on an unreachable host, try again later", machine.ID)
+	}
+
+	config := helpers.Clone(machine.Config)
+	// Pin the exact image the machine is running.
+	config.Image = machine.FullImageRef()
+
+	path := flag.GetString(ctx, "output")
+	if path == "" {
+		return render.JSON(io.Out, config)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("could not create %s: %w", path, err)
+	}
+	defer f.Close() // skipcq: GO-S2307
+
+	if err := render.JSON(f, config); err != nil {
+		return fmt.Errorf("could not write %s: %w", path, err)
+	}
+
+	fmt.Fprintf(io.ErrOut, "Wrote config of machine %s to %s\n", machine.ID, path)
+	return nil
+}
+
+// loadMachineConfigFile reads a machine config previously written by
+// `fly machine config export`.
+func loadMachineConfigFile(path string) (*fly.MachineConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read machine config: %w", err)
+	}
+
+	var config fly.MachineConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("could not parse machine config %s: %w", path, err)
+	}
+
+	return &config, nil
+}
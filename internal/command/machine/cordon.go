@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	fly "github.com/superfly/fly-go"
 	"github.com/superfly/flyctl/internal/command"
 	"github.com/superfly/flyctl/internal/flag"
 	"github.com/superfly/flyctl/internal/flapsutil"
@@ -15,7 +16,11 @@ import (
 func newMachineCordon() *cobra.Command {
 	const (
 		short = "Deactivate all services on a machine"
-		long  = short + "\n"
+		long  = short + `. Cordoned machines stop receiving traffic from the
+Fly Proxy and are skipped by future deployments, so they can be inspected
+without affecting the rest of the app. Use "fly machine uncordon" to bring
+them back into service.
+`
 		usage = "cordon [<id>...]"
 	)
 
@@ -56,10 +61,22 @@ func runMachineCordon(ctx context.Context) (err error) {
 
 	for _, machine := range machines {
 		fmt.Fprintf(io.Out, "Activating cordon on machine %s...\n", machine.ID)
-		if err = flapsClient.Cordon(ctx, machine.ID, machine.LeaseNonce); err != nil {
+		if err = cordonMachine(ctx, flapsClient, machine); err != nil {
 			return err
 		}
 		fmt.Fprintf(io.Out, "done!\n")
 	}
 	return
 }
+
+// cordonMachine cordons a leased machine and marks it as cordoned in its
+// metadata.
+func cordonMachine(ctx context.Context, flapsClient flapsutil.FlapsClient, machine *fly.Machine) error {
+	if err := flapsutil.Cordon(ctx, flapsClient, machine.ID, machine.LeaseNonce); err != nil {
+		return err
+	}
+	if err := flapsClient.SetMetadata(ctx, machine.ID, mach.CordonedMetadataKey, "true"); err != nil {
+		return fmt.Errorf("could not mark machine %s as cordoned: %w", machine.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/fly-go/flaps"
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flapsutil"
+	"github.com/superfly/flyctl/internal/format"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+	"golang.org/x/sync/errgroup"
+)
+
+func newCrashes() *cobra.Command {
+	const (
+		short = "Show the crashes and OOM kills of an app's machines"
+		long  = short + `. Exits that weren't requested, by a stop or
+an update, are counted per machine over --since, along with their exit
+codes, the OOM kills among them and the restarts they caused.
+
+Machines only keep their last few events, so the crashes of busy machines
+may not go back as far as --since; the output says how far back they go.
+`
+		usage = "crashes [app]"
+	)
+
+	cmd := command.New(usage, short, long, runMachineCrashes,
+		command.RequireSession,
+		command.LoadAppNameIfPresent,
+	)
+
+	cmd.Args = cobra.MaximumNArgs(1)
+
+	flag.Add(
+		cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.JSONOutput(),
+		flag.Duration{
+			Name:        "since",
+			Description: "Count the crashes of this long ago until now",
+			Default:     24 * time.Hour,
+		},
+	)
+
+	return cmd
+}
+
+type machineCrashes struct {
+	ID           string         `json:"id"`
+	Region       string         `json:"region"`
+	ProcessGroup string         `json:"process_group"`
+	MemoryMB     int            `json:"memory_mb"`
+	Crashes      int            `json:"crashes"`
+	OOMKills     int            `json:"oom_kills"`
+	ExitCodes    map[string]int `json:"exit_codes"`
+	Restarts     int            `json:"restarts"`
+	LastCrash    *time.Time     `json:"last_crash,omitempty"`
+	// EventsSince is set when the events of the machine don't go back as
+	// far as the counted window.
+	EventsSince *time.Time `json:"events_since,omitempty"`
+}
+
+func runMachineCrashes(ctx context.Context) error {
+	if appName := flag.FirstArg(ctx); appName != "" {
+		ctx = appconfig.WithName(ctx, appName)
+	}
+
+	appName := appconfig.NameFromContext(ctx)
+	if appName == "" {
+		return command.ErrRequireAppName
+	}
+
+	flapsClient, err := flapsutil.NewClientWithOptions(ctx, flaps.NewClientOpts{
+		AppName: appName,
+	})
+	if err != nil {
+		return fmt.Errorf("could not create flaps client: %w", err)
+	}
+
+	window := flag.GetDuration(ctx, "since")
+	since := time.Now().Add(-window)
+
+	// Crashed machines may be stopped, so list them all
+	list, err := flapsClient.List(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	// Listed machines come without their events
+	machines := make([]*fly.Machine, len(list))
+	eg, ectx := errgroup.WithContext(ctx)
+	eg.SetLimit(8)
+	for i, m := range list {
+		eg.Go(func() error {
+			machine, err := flapsClient.Get(ectx, m.ID)
+			if err != nil {
+				return fmt.Errorf("could not get machine %s: %w", m.ID, err)
+			}
+			machines[i] = machine
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return err
+	}
+
+	var (
+		crashes     []*machineCrashes
+		total, ooms int
+		// The machines whose events don't cover the whole window, and the
+		// most recent of their oldest events
+		partial     int
+		eventsSince time.Time
+	)
+	for _, machine := range machines {
+		c := countCrashes(machine, since)
+		if c.EventsSince != nil {
+			partial++
+			if c.EventsSince.After(eventsSince) {
+				eventsSince = *c.EventsSince
+			}
+		}
+		if c.Crashes > 0 {
+			crashes = append(crashes, c)
+			total += c.Crashes
+			ooms += c.OOMKills
+		}
+	}
+	sort.Slice(crashes, func(i, j int) bool {
+		if crashes[i].Crashes != crashes[j].Crashes {
+			return crashes[i].Crashes > crashes[j].Crashes
+		}
+		return crashes[i].ID < crashes[j].ID
+	})
+
+	out := iostreams.FromContext(ctx).Out
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(out, crashes)
+	}
+
+	if total == 0 {
+		fmt.Fprintf(out, "No crashes across the %d machines of %s in the last %s\n", len(machines), appName, formatWindow(window))
+		printPartialEvents(out, partial, eventsSince)
+		return nil
+	}
+
+	rows := make([][]string, 0, len(crashes))
+	for _, c := range crashes {
+		rows = append(rows, []string{
+			c.ID,
+			c.Region,
+			c.ProcessGroup,
+			strconv.Itoa(c.Crashes),
+			strconv.Itoa(c.OOMKills),
+			formatExitCodes(c.ExitCodes),
+			strconv.Itoa(c.Restarts),
+			format.RelativeTime(*c.LastCrash),
+			fmt.Sprintf("%dMB", c.MemoryMB),
+		})
+	}
+	if err := render.Table(out, "", rows, "Machine", "Region", "Process", "Crashes", "OOM Kills", "Exit Codes", "Restarts", "Last Crash", "Memory"); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "%d crashes, %d of them OOM kills, across %d of the %d machines of %s in the last %s\n", total, ooms, len(crashes), len(machines), appName, formatWindow(window))
+	printPartialEvents(out, partial, eventsSince)
+	if ooms > 0 {
+		fmt.Fprintln(out, "Machines killed for running out of memory may need more of it, see 'fly scale memory'.")
+	}
+	return nil
+}
+
+// countCrashes counts the exits of machine since then that weren't requested.
+func countCrashes(machine *fly.Machine, since time.Time) *machineCrashes {
+	c := &machineCrashes{
+		ID:           machine.ID,
+		Region:       machine.Region,
+		ProcessGroup: machine.ProcessGroup(),
+		ExitCodes:    map[string]int{},
+	}
+	if guest := machine.GetConfig().Guest; guest != nil {
+		c.MemoryMB = guest.MemoryMB
+	}
+
+	var (
+		oldest   time.Time
+		launched bool
+	)
+	for _, event := range machine.Events {
+		at := event.Time()
+		if oldest.IsZero() || at.Before(oldest) {
+			oldest = at
+		}
+		if event.Type == "launch" {
+			launched = true
+		}
+
+		if event.Request == nil {
+			continue
+		}
+		if at.Before(since) {
+			continue
+		}
+
+		c.Restarts = max(c.Restarts, event.Request.RestartCount)
+
+		exit := event.Request.ExitEvent
+		if exit == nil || exit.RequestedStop {
+			continue
+		}
+
+		c.Crashes++
+		if exit.OOMKilled {
+			c.OOMKills++
+		}
+		c.ExitCodes[strconv.Itoa(exit.ExitCode)]++
+		if c.LastCrash == nil || at.After(*c.LastCrash) {
+			c.LastCrash = &at
+		}
+	}
+
+	// Without its launch event, the events of the machine were cut off at
+	// the oldest one
+	if !launched && oldest.After(since) {
+		c.EventsSince = &oldest
+	}
+
+	return c
+}
+
+// printPartialEvents tells how far back the events of the machines go when
+// some of them don't cover the whole window.
+func printPartialEvents(out io.Writer, partial int, eventsSince time.Time) {
+	if partial == 0 {
+		return
+	}
+	fmt.Fprintf(out, "Machines only keep their last few events, those of %d machines only go back to %s; earlier crashes aren't counted.\n", partial, format.RelativeTime(eventsSince))
+}
+
+// formatWindow formats durations without their zero units, like 24h rather
+// than 24h0m0s.
+func formatWindow(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// formatExitCodes formats exit codes with their counts, like 1×3, 137×1.
+func formatExitCodes(codes map[string]int) string {
+	keys := make([]string, 0, len(codes))
+	for code := range codes {
+		keys = append(keys, code)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return codes[keys[i]] > codes[keys[j]] || (codes[keys[i]] == codes[keys[j]] && keys[i] < keys[j])
+	})
+
+	parts := make([]string, 0, len(keys))
+	for _, code := range keys {
+		parts = append(parts, fmt.Sprintf("%s×%d", code, codes[code]))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -2,6 +2,7 @@ package machine
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -10,10 +11,12 @@ import (
 	"github.com/superfly/flyctl/internal/appconfig"
 	"github.com/superfly/flyctl/internal/command"
 	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flag/flagnames"
 	"github.com/superfly/flyctl/internal/flapsutil"
 	"github.com/superfly/flyctl/internal/flyutil"
 	mach "github.com/superfly/flyctl/internal/machine"
 	"github.com/superfly/flyctl/internal/prompt"
+	"github.com/superfly/flyctl/internal/render"
 	"github.com/superfly/flyctl/iostreams"
 )
 
@@ -22,6 +25,14 @@ func newDestroy() *cobra.Command {
 		short = "Destroy Fly machines"
 		long  = `Destroy one or more Fly machines.
 This command requires a machine to be in a stopped or suspended state unless the force flag is used.
+
+Use --matching to destroy every machine matching a selector, e.g. to clean up
+machines stopped for more than 30 days:
+
+  fly machine destroy --matching "state=stopped,created_before=30d"
+
+Matching machines are listed before asking for confirmation; use --dry-run to
+only list them.
 `
 		usage = "destroy [flags] ID ID ..."
 	)
@@ -38,6 +49,15 @@ This command requires a machine to be in a stopped or suspended state unless the
 		flag.App(),
 		flag.AppConfig(),
 		selectFlag,
+		flag.Yes(),
+		flag.String{
+			Name:        "matching",
+			Description: `Destroy all machines matching a selector, e.g. "state=stopped,created_before=30d"`,
+		},
+		flag.Bool{
+			Name:        "dry-run",
+			Description: "With --matching, only list the machines that would be destroyed",
+		},
 		flag.Bool{
 			Name:        "force",
 			Shorthand:   "f",
@@ -63,8 +83,23 @@ func runMachineDestroy(ctx context.Context) (err error) {
 
 	var machinesToBeDeleted []*fly.Machine
 	image := strings.TrimSpace(flag.GetString(ctx, "image"))
+	selector := flag.GetString(ctx, "matching")
+
+	if flag.GetBool(ctx, "dry-run") && selector == "" {
+		return errors.New("--dry-run can only be used with --matching")
+	}
 
 	switch {
+	case selector != "":
+		if len(flag.Args(ctx)) > 0 || image != "" || flag.GetBool(ctx, "select") {
+			return errors.New("machine IDs, --image and --select can't be used with --matching")
+		}
+
+		machinesToBeDeleted, err = selectMachinesToDestroy(ctx, selector)
+		if err != nil || machinesToBeDeleted == nil {
+			return err
+		}
+
 	case image != "":
 		machines, err := flapsutil.ClientFromContext(ctx).ListActive(ctx)
 		if err != nil {
@@ -79,16 +114,18 @@ func runMachineDestroy(ctx context.Context) (err error) {
 			}
 		}
 
-		confirmed, err := prompt.Confirm(ctx,
-			fmt.Sprintf("%d Machines (%s) will be destroyed, continue?",
-				len(machinesToBeDeleted),
-				strings.Join(ids, ","),
-			))
-		if err != nil {
-			return err
-		}
-		if !confirmed {
-			return nil
+		if len(machinesToBeDeleted) > 0 && !flag.GetBool(ctx, flagnames.Yes) {
+			confirmed, err := prompt.Confirm(ctx,
+				fmt.Sprintf("%d Machines (%s) will be destroyed, continue?",
+					len(machinesToBeDeleted),
+					strings.Join(ids, ","),
+				))
+			if err != nil {
+				return err
+			}
+			if !confirmed {
+				return nil
+			}
 		}
 
 	case len(flag.Args(ctx)) == 0:
@@ -129,6 +166,63 @@ func runMachineDestroy(ctx context.Context) (err error) {
 	return nil
 }
 
+func selectMachinesToDestroy(ctx context.Context, rawSelector string) ([]*fly.Machine, error) {
+	io := iostreams.FromContext(ctx)
+
+	if appconfig.NameFromContext(ctx) == "" {
+		return nil, errors.New("an app name must be specified to use --matching")
+	}
+
+	selector, err := mach.ParseSelector(rawSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	machines, err := mach.ListActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get a list of machines: %w", err)
+	}
+	machines = selector.Filter(machines)
+
+	if len(machines) == 0 {
+		fmt.Fprintf(io.Out, "No machines match %q\n", rawSelector)
+		return nil, nil
+	}
+
+	rows := make([][]string, 0, len(machines))
+	for _, machine := range machines {
+		rows = append(rows, []string{
+			machine.ID,
+			machine.Name,
+			machine.State,
+			machine.Region,
+			machine.ProcessGroup(),
+			machine.CreatedAt,
+			machine.UpdatedAt,
+		})
+	}
+	title := fmt.Sprintf("%d machines match %q", len(machines), rawSelector)
+	if err := render.Table(io.Out, title, rows, "ID", "Name", "State", "Region", "Process Group", "Created", "Updated"); err != nil {
+		return nil, err
+	}
+
+	if flag.GetBool(ctx, "dry-run") {
+		return nil, nil
+	}
+
+	if !flag.GetBool(ctx, flagnames.Yes) {
+		confirmed, err := prompt.Confirm(ctx, fmt.Sprintf("Destroy these %d machines?", len(machines)))
+		if err != nil {
+			return nil, err
+		}
+		if !confirmed {
+			return nil, nil
+		}
+	}
+
+	return machines, nil
+}
+
 func singleDestroyRun(ctx context.Context, machine *fly.Machine) error {
 	var (
 		out   = iostreams.FromContext(ctx).Out
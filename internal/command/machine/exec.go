@@ -2,14 +2,17 @@ package machine
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"github.com/kballard/go-shellquote"
 	"github.com/spf13/cobra"
 	fly "github.com/superfly/fly-go"
 	"github.com/superfly/flyctl/internal/command"
 	"github.com/superfly/flyctl/internal/config"
 	"github.com/superfly/flyctl/internal/flag"
 	"github.com/superfly/flyctl/internal/flapsutil"
+	"github.com/superfly/flyctl/internal/flyerr"
 	"github.com/superfly/flyctl/internal/render"
 	"github.com/superfly/flyctl/iostreams"
 )
@@ -17,8 +20,11 @@ import (
 func newMachineExec() *cobra.Command {
 	const (
 		short = "Execute a command on a machine"
-		long  = short + "\n"
-		usage = "exec [machine-id] <command>"
+		long  = short + `. The command can be given as a single quoted
+argument, or as a list of arguments following "--". The exit code of the
+command is used as the exit code of flyctl.
+`
+		usage = "exec [machine-id] <command> | exec [machine-id] -- <command> [args...]"
 	)
 
 	cmd := command.New(usage, short, long, runMachineExec,
@@ -38,7 +44,18 @@ func newMachineExec() *cobra.Command {
 		},
 	)
 
-	cmd.Args = cobra.RangeArgs(1, 2)
+	cmd.Args = func(cmd *cobra.Command, args []string) error {
+		if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+			if dash > 1 {
+				return errors.New("only a machine ID may be given before --")
+			}
+			if len(args) == dash {
+				return errors.New("a command is required after --")
+			}
+			return nil
+		}
+		return cobra.RangeArgs(1, 2)(cmd, args)
+	}
 
 	return cmd
 }
@@ -54,7 +71,13 @@ func runMachineExec(ctx context.Context) (err error) {
 		command       string
 	)
 
-	if len(args) == 2 {
+	if dash := flag.FromContext(ctx).ArgsLenAtDash(); dash >= 0 {
+		if dash == 1 {
+			machineID = args[0]
+			haveMachineID = true
+		}
+		command = shellquote.Join(args[dash:]...)
+	} else if len(args) == 2 {
 		machineID = args[0]
 		haveMachineID = true
 		command = args[1]
@@ -81,18 +104,20 @@ func runMachineExec(ctx context.Context) (err error) {
 	}
 
 	if config.JSONOutput {
-		return render.JSON(io.Out, out)
+		if err := render.JSON(io.Out, out); err != nil {
+			return err
+		}
+	} else {
+		if out.StdOut != "" {
+			fmt.Fprint(io.Out, out.StdOut)
+		}
+		if out.StdErr != "" {
+			fmt.Fprint(io.ErrOut, out.StdErr)
+		}
 	}
 
 	if out.ExitCode != 0 {
-		fmt.Fprintf(io.Out, "Exit code: %d\n", out.ExitCode)
-	}
-
-	if out.StdOut != "" {
-		fmt.Fprint(io.Out, out.StdOut)
-	}
-	if out.StdErr != "" {
-		fmt.Fprint(io.ErrOut, out.StdErr)
+		return flyerr.ExitCodeError{Code: int(out.ExitCode)}
 	}
 
 	return
@@ -38,6 +38,16 @@ Machines REST fly.`
 		newMachineCordon(),
 		newMachineUncordon(),
 		newSuspend(),
+		newTop(),
+		newConfig(),
+		newRestartPolicy(),
+		newSchedule(),
+		newRename(),
+		newChecks(),
+		newWait(),
+		newMetadata(),
+		newVolumes(),
+		newCrashes(),
 	)
 
 	return cmd
@@ -0,0 +1,281 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/flyctl/internal/cmdutil"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flapsutil"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func newMetadata() *cobra.Command {
+	const (
+		short = "Manage the metadata of a machine"
+		long  = short + `. Metadata are key/value labels attached to a machine.
+They are kept across deploys and can be targeted by selectors, e.g.
+"fly machine update --matching team=billing". Keys starting with fly_ or fly-
+are reserved for the platform, except those flyctl sets itself, like
+fly_cordoned or fly_schedule_start. Setting fly_cordoned to true or false, or
+unsetting it, cordons or uncordons the machine like "fly machine cordon".
+`
+		usage = "metadata <command>"
+	)
+
+	cmd := command.New(usage, short, long, nil)
+
+	cmd.Aliases = []string{"meta"}
+
+	cmd.AddCommand(
+		newMetadataList(),
+		newMetadataSet(),
+		newMetadataUnset(),
+	)
+
+	return cmd
+}
+
+func newMetadataList() *cobra.Command {
+	const (
+		short = "List the metadata of a machine"
+		long  = short + "\n"
+		usage = "list [<id>]"
+	)
+
+	cmd := command.New(usage, short, long, runMetadataList,
+		command.RequireSession,
+		command.LoadAppNameIfPresent,
+	)
+
+	cmd.Aliases = []string{"ls"}
+	cmd.Args = cobra.MaximumNArgs(1)
+
+	flag.Add(
+		cmd,
+		flag.App(),
+		flag.AppConfig(),
+		selectFlag,
+		flag.JSONOutput(),
+	)
+
+	return cmd
+}
+
+func runMetadataList(ctx context.Context) error {
+	io := iostreams.FromContext(ctx)
+
+	machineID := flag.FirstArg(ctx)
+	haveMachineID := len(flag.Args(ctx)) > 0
+	machine, ctx, err := selectOneMachine(ctx, "", machineID, haveMachineID)
+	if err != nil {
+		return err
+	}
+
+	metadata, err := flapsutil.ClientFromContext(ctx).GetMetadata(ctx, machine.ID)
+	if err != nil {
+		return err
+	}
+
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(io.Out, metadata)
+	}
+
+	rows := make([][]string, 0, len(metadata))
+	for _, key := range sortedKeys(metadata) {
+		rows = append(rows, []string{key, metadata[key]})
+	}
+
+	return render.Table(io.Out, "", rows, "Key", "Value")
+}
+
+func newMetadataSet() *cobra.Command {
+	const (
+		short = "Set metadata on a machine"
+		long  = short + "\n"
+		usage = "set <id> <key=value>..."
+	)
+
+	cmd := command.New(usage, short, long, runMetadataSet,
+		command.RequireSession,
+		command.LoadAppNameIfPresent,
+	)
+
+	cmd.Args = cobra.MinimumNArgs(2)
+
+	flag.Add(
+		cmd,
+		flag.App(),
+		flag.AppConfig(),
+	)
+
+	return cmd
+}
+
+func runMetadataSet(ctx context.Context) error {
+	var (
+		io   = iostreams.FromContext(ctx)
+		args = flag.Args(ctx)
+	)
+
+	metadata, err := cmdutil.ParseKVStringsToMap(args[1:])
+	if err != nil {
+		return err
+	}
+	for key := range metadata {
+		if err := validateMetadataKey(key); err != nil {
+			return err
+		}
+	}
+
+	machine, ctx, err := selectOneMachine(ctx, "", args[0], true)
+	if err != nil {
+		return err
+	}
+	flapsClient := flapsutil.ClientFromContext(ctx)
+
+	for _, key := range sortedKeys(metadata) {
+		if key == mach.CordonedMetadataKey {
+			cordoned, err := strconv.ParseBool(metadata[key])
+			if err != nil {
+				return fmt.Errorf("%s must be true or false", key)
+			}
+			if err := setCordoned(ctx, machine, cordoned); err != nil {
+				return err
+			}
+			fmt.Fprintf(io.Out, "Set %s=%t on machine %s\n", key, cordoned, machine.ID)
+			continue
+		}
+		if err := flapsClient.SetMetadata(ctx, machine.ID, key, metadata[key]); err != nil {
+			return err
+		}
+		fmt.Fprintf(io.Out, "Set %s=%s on machine %s\n", key, metadata[key], machine.ID)
+	}
+
+	return nil
+}
+
+func newMetadataUnset() *cobra.Command {
+	const (
+		short = "Remove metadata from a machine"
+		long  = short + "\n"
+		usage = "unset <id> <key>..."
+	)
+
+	cmd := command.New(usage, short, long, runMetadataUnset,
+		command.RequireSession,
+		command.LoadAppNameIfPresent,
+	)
+
+	cmd.Args = cobra.MinimumNArgs(2)
+
+	flag.Add(
+		cmd,
+		flag.App(),
+		flag.AppConfig(),
+	)
+
+	return cmd
+}
+
+func runMetadataUnset(ctx context.Context) error {
+	var (
+		io   = iostreams.FromContext(ctx)
+		args = flag.Args(ctx)
+		keys = args[1:]
+	)
+
+	for _, key := range keys {
+		if err := validateMetadataKey(key); err != nil {
+			return err
+		}
+	}
+
+	machine, ctx, err := selectOneMachine(ctx, "", args[0], true)
+	if err != nil {
+		return err
+	}
+	flapsClient := flapsutil.ClientFromContext(ctx)
+
+	for _, key := range keys {
+		if machine.GetMetadataByKey(key) == "" {
+			fmt.Fprintf(io.Out, "Machine %s has no metadata key %s\n", machine.ID, key)
+			continue
+		}
+		if key == mach.CordonedMetadataKey {
+			if err := setCordoned(ctx, machine, false); err != nil {
+				return err
+			}
+			fmt.Fprintf(io.Out, "Removed %s from machine %s\n", key, machine.ID)
+			continue
+		}
+		if err := flapsClient.DeleteMetadata(ctx, machine.ID, key); err != nil {
+			return err
+		}
+		fmt.Fprintf(io.Out, "Removed %s from machine %s\n", key, machine.ID)
+	}
+
+	return nil
+}
+
+// flyctlMetadataKeys are the keys with a reserved prefix that flyctl sets
+// itself, so they can be changed like the keys of users.
+var flyctlMetadataKeys = []string{
+	mach.CordonedMetadataKey,
+	scheduleStartMetadataKey,
+	scheduleStopMetadataKey,
+	scheduleTimezoneMetadataKey,
+	schedulerMetadataKey,
+	mach.ScaleSchedulerMetadataKey,
+	mach.ScaleScheduleMetadataKey,
+	mach.AutoscalerMetadataKey,
+	mach.AutoscalePoliciesMetadataKey,
+	mach.AutoscaleDecisionsMetadataKey,
+}
+
+// setCordoned cordons or uncordons machine, so that setting fly_cordoned
+// changes whether the machine gets traffic and not only its marker.
+func setCordoned(ctx context.Context, machine *fly.Machine, cordoned bool) error {
+	machine, release, err := mach.AcquireLease(ctx, machine)
+	defer release()
+	if err != nil {
+		return err
+	}
+
+	flapsClient := flapsutil.ClientFromContext(ctx)
+	if cordoned {
+		return cordonMachine(ctx, flapsClient, machine)
+	}
+	return uncordonMachine(ctx, flapsClient, machine)
+}
+
+func validateMetadataKey(key string) error {
+	switch {
+	case key == "":
+		return fmt.Errorf("metadata keys can't be empty")
+	case slices.Contains(flyctlMetadataKeys, key):
+		return nil
+	case strings.HasPrefix(key, "fly_"), strings.HasPrefix(key, "fly-"):
+		return fmt.Errorf("metadata key %s is reserved for the platform", key)
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,100 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flapsutil"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func newRename() *cobra.Command {
+	const (
+		short = "Rename a machine"
+		long  = short + `. Only the name of the machine is changed, its config is
+left untouched. Started machines are restarted to apply the new name, and
+later deploys keep it.
+`
+		usage = "rename [<id>] <new-name>"
+	)
+
+	cmd := command.New(usage, short, long, runMachineRename,
+		command.RequireSession,
+		command.LoadAppNameIfPresent,
+	)
+
+	cmd.Args = cobra.RangeArgs(1, 2)
+
+	flag.Add(
+		cmd,
+		flag.App(),
+		flag.AppConfig(),
+		selectFlag,
+	)
+
+	return cmd
+}
+
+func runMachineRename(ctx context.Context) (err error) {
+	var (
+		io       = iostreams.FromContext(ctx)
+		colorize = io.ColorScheme()
+		args     = flag.Args(ctx)
+		name     = args[len(args)-1]
+	)
+
+	machineID := ""
+	haveMachineID := len(args) > 1
+	if haveMachineID {
+		machineID = args[0]
+	}
+
+	machine, ctx, err := selectOneMachine(ctx, "", machineID, haveMachineID)
+	if err != nil {
+		return err
+	}
+
+	if machine.Name == name {
+		fmt.Fprintf(io.Out, "Machine %s is already named %s\n", machine.ID, name)
+		return nil
+	}
+
+	machines, err := flapsutil.ClientFromContext(ctx).List(ctx, "")
+	if err != nil {
+		return fmt.Errorf("could not get a list of machines: %w", err)
+	}
+	for _, m := range machines {
+		if m.ID != machine.ID && m.Name == name {
+			return fmt.Errorf("machine %s is already named %s", m.ID, name)
+		}
+	}
+
+	machine, release, err := mach.AcquireLease(ctx, machine)
+	defer release()
+	if err != nil {
+		return err
+	}
+
+	if machine.HostStatus != fly.HostStatusOk {
+		return fmt.Errorf("machine %s is on an unreachable host, try again later", machine.ID)
+	}
+
+	oldName := machine.Name
+	input := &fly.LaunchMachineInput{
+		Name:       name,
+		Region:     machine.Region,
+		Config:     mach.CloneConfig(machine.Config),
+		SkipLaunch: machine.State != fly.MachineStateStarted,
+	}
+	if err := mach.Update(ctx, machine, input); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(io.Out, "Machine %s renamed from %s to %s\n", colorize.Bold(machine.ID), oldName, colorize.Bold(name))
+	return nil
+}
@@ -38,14 +38,14 @@ func newRestart() *cobra.Command {
 			Shorthand:   "s",
 			Description: "Signal to stop the machine with (default: SIGINT)",
 		},
-
 		flag.Int{
-			Name:        "time",
-			Description: "Seconds to wait before killing the machine",
+			Name:        "timeout",
+			Description: "Seconds to wait for the machine to stop gracefully before killing it",
+			Aliases:     []string{"time"},
 		},
 		flag.Bool{
 			Name:        "force",
-			Description: "Force stop the machine(s)",
+			Description: "Force stop the machine(s) immediately, without a graceful shutdown",
 		},
 		flag.Bool{
 			Name:        "skip-health-checks",
@@ -60,9 +60,13 @@ func newRestart() *cobra.Command {
 func runMachineRestart(ctx context.Context) error {
 	var (
 		args    = flag.Args(ctx)
-		timeout = flag.GetInt(ctx, "time")
+		timeout = flag.GetInt(ctx, "timeout")
 	)
 
+	if err := checkStopTimeout(ctx); err != nil {
+		return err
+	}
+
 	// Resolve flags
 	input := &fly.RestartMachineInput{
 		Timeout:          time.Duration(timeout) * time.Second,
@@ -0,0 +1,146 @@
+package machine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/flag"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func newRestartPolicy() *cobra.Command {
+	const (
+		short = "Change the restart policy of one or more machines"
+		long  = short + `. Only the restart policy of the machine config is
+changed, the rest of the config is left untouched. Started machines are
+restarted to apply the new policy.
+`
+		usage = "restart-policy [<id>...]"
+	)
+
+	cmd := command.New(usage, short, long, runMachineRestartPolicy,
+		command.RequireSession,
+		command.LoadAppNameIfPresent,
+	)
+
+	cmd.Args = cobra.ArbitraryArgs
+
+	flag.Add(
+		cmd,
+		flag.App(),
+		flag.AppConfig(),
+		selectFlag,
+		flag.String{
+			Name:        "policy",
+			Description: "The restart policy. Options include 'no', 'always', and 'on-failure'.",
+		},
+		flag.Int{
+			Name:        "max-retries",
+			Description: "With the 'on-failure' policy, the maximum number of times to restart the machine before letting it stop",
+		},
+	)
+
+	return cmd
+}
+
+func parseRestartPolicy(policy string) (fly.MachineRestartPolicy, error) {
+	switch policy {
+	case "no":
+		return fly.MachineRestartPolicyNo, nil
+	case "always":
+		return fly.MachineRestartPolicyAlways, nil
+	case "on-failure", "on-fail":
+		return fly.MachineRestartPolicyOnFailure, nil
+	default:
+		return "", fmt.Errorf("invalid restart policy %q, options include 'no', 'always', and 'on-failure'", policy)
+	}
+}
+
+func runMachineRestartPolicy(ctx context.Context) (err error) {
+	var (
+		io         = iostreams.FromContext(ctx)
+		colorize   = io.ColorScheme()
+		args       = flag.Args(ctx)
+		maxRetries = flag.GetInt(ctx, "max-retries")
+	)
+
+	if !flag.IsSpecified(ctx, "policy") && !flag.IsSpecified(ctx, "max-retries") {
+		return errors.New("at least one of --policy or --max-retries must be specified")
+	}
+	if maxRetries < 0 {
+		return errors.New("--max-retries can't be negative")
+	}
+
+	var policy fly.MachineRestartPolicy
+	if flag.IsSpecified(ctx, "policy") {
+		if policy, err = parseRestartPolicy(flag.GetString(ctx, "policy")); err != nil {
+			return err
+		}
+	}
+
+	machines, ctx, err := selectManyMachines(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	machines, release, err := mach.AcquireLeases(ctx, machines)
+	defer release()
+	if err != nil {
+		return err
+	}
+
+	for _, machine := range machines {
+		if machine.HostStatus != fly.HostStatusOk {
+			return fmt.Errorf("machine %s is on an unreachable host, try again later", machine.ID)
+		}
+
+		restart := fly.MachineRestart{}
+		if machine.Config.Restart != nil {
+			restart = *machine.Config.Restart
+		}
+		if policy != "" {
+			restart.Policy = policy
+		}
+		if flag.IsSpecified(ctx, "max-retries") {
+			restart.MaxRetries = maxRetries
+		}
+		if restart.Policy != fly.MachineRestartPolicyOnFailure {
+			if flag.IsSpecified(ctx, "max-retries") {
+				return fmt.Errorf("--max-retries can only be used with the 'on-failure' policy, machine %s has policy '%s'", machine.ID, restart.Policy)
+			}
+			restart.MaxRetries = 0
+		}
+
+		config := mach.CloneConfig(machine.Config)
+		config.Restart = &restart
+
+		input := &fly.LaunchMachineInput{
+			Name:       machine.Name,
+			Region:     machine.Region,
+			Config:     config,
+			SkipLaunch: machine.State != fly.MachineStateStarted,
+		}
+		if err := mach.Update(ctx, machine, input); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(io.Out, "Restart policy of machine %s set to %s\n", colorize.Bold(machine.ID), formatRestartPolicy(&restart))
+	}
+
+	return nil
+}
+
+func formatRestartPolicy(restart *fly.MachineRestart) string {
+	if restart == nil || restart.Policy == "" {
+		return "default"
+	}
+	if restart.Policy == fly.MachineRestartPolicyOnFailure && restart.MaxRetries > 0 {
+		return fmt.Sprintf("%s (max retries: %d)", restart.Policy, restart.MaxRetries)
+	}
+	return string(restart.Policy)
+}
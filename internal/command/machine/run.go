@@ -255,7 +255,12 @@ func newRun() *cobra.Command {
 func newCreate() *cobra.Command {
 	const (
 		short = "Create, but don't start, a machine"
-		long  = short + "\n"
+		long  = short + `. Use --from-config to create the machine from a
+config written by "fly machine config export", in which case the image
+argument is optional. Flags given alongside --from-config override the
+values in the file. Its volume mounts and reserved fly_ metadata, like the
+process group and release, aren't used.
+`
 
 		usage = "create <image> [command]"
 	)
@@ -269,9 +274,18 @@ func newCreate() *cobra.Command {
 		cmd,
 		runOrCreateFlags,
 		sharedFlags,
+		flag.String{
+			Name:        "from-config",
+			Description: "Path to a machine config JSON file, as written by 'fly machine config export'",
+		},
 	)
 
-	cmd.Args = cobra.MinimumNArgs(1)
+	cmd.Args = func(cmd *cobra.Command, args []string) error {
+		if cmd.Flags().Changed("from-config") {
+			return nil
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	}
 
 	return cmd
 }
@@ -361,6 +375,34 @@ func runMachineRun(ctx context.Context) error {
 		},
 	}
 
+	imageOrPath := flag.FirstArg(ctx)
+
+	if path := flag.GetString(ctx, "from-config"); path != "" {
+		machineConf, err = loadMachineConfigFile(path)
+		if err != nil {
+			return err
+		}
+		if destroy {
+			machineConf.AutoDestroy = true
+		}
+		// Volumes belong to the app and region they were created in, so
+		// they can't be carried over. Use --volume to attach one.
+		if len(machineConf.Mounts) > 0 {
+			fmt.Fprintf(io.ErrOut, "Skipping %d volume mount(s) from %s, use --volume to attach a volume\n", len(machineConf.Mounts), path)
+			machineConf.Mounts = nil
+		}
+		// The reserved keys tie a machine to the app's process groups and
+		// releases, deploys would adopt a copy that kept them
+		for key := range machineConf.Metadata {
+			if strings.HasPrefix(key, "fly_") || strings.HasPrefix(key, "fly-") {
+				delete(machineConf.Metadata, key)
+			}
+		}
+		if imageOrPath == "" {
+			imageOrPath = machineConf.Image
+		}
+	}
+
 	input := fly.LaunchMachineInput{
 		Name:   flag.GetString(ctx, "name"),
 		Region: flag.GetString(ctx, "region"),
@@ -376,7 +418,6 @@ func runMachineRun(ctx context.Context) error {
 	}
 	ctx = flapsutil.NewContextWithClient(ctx, flapsClient)
 
-	imageOrPath := flag.FirstArg(ctx)
 	if imageOrPath == "" && shell {
 		imageOrPath = "ubuntu"
 	} else if imageOrPath == "" {
@@ -0,0 +1,560 @@
+package machine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flapsutil"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+const (
+	scheduleStartMetadataKey    = "fly_schedule_start"
+	scheduleStopMetadataKey     = "fly_schedule_stop"
+	scheduleTimezoneMetadataKey = "fly_schedule_timezone"
+	schedulerMetadataKey        = "fly_schedule_scheduler"
+
+	defaultSchedulerImage = "flyio/flyctl:latest"
+)
+
+func newSchedule() *cobra.Command {
+	const (
+		short = "Manage start and stop schedules for machines"
+		long  = short + `. Schedules are cron expressions stored in the
+machine's metadata, e.g. stop staging workers overnight with:
+
+  fly machine schedule set --process-group worker --start "0 8 * * 1-5" --stop "0 20 * * 1-5"
+
+Schedules are applied by "fly machine schedule reconcile", which can be run
+from CI or from a scheduler machine created with
+"fly machine schedule install".
+`
+		usage = "schedule <command>"
+	)
+
+	cmd := command.New(usage, short, long, nil)
+
+	cmd.AddCommand(
+		newScheduleSet(),
+		newScheduleShow(),
+		newScheduleClear(),
+		newScheduleReconcile(),
+		newScheduleInstall(),
+	)
+
+	return cmd
+}
+
+var scheduleTargetFlags = flag.Set{
+	flag.App(),
+	flag.AppConfig(),
+	selectFlag,
+	flag.ProcessGroup("Act on all machines in this process group"),
+}
+
+func newScheduleSet() *cobra.Command {
+	const (
+		short = "Set the start and stop schedule of machines"
+		long  = short + `. Both schedules are five field cron expressions
+(minute hour day-of-month month day-of-week) evaluated in the given timezone.
+`
+		usage = "set [<id>...]"
+	)
+
+	cmd := command.New(usage, short, long, runScheduleSet,
+		command.RequireSession,
+		command.LoadAppNameIfPresent,
+	)
+
+	cmd.Args = cobra.ArbitraryArgs
+
+	flag.Add(
+		cmd,
+		scheduleTargetFlags,
+		flag.String{
+			Name:        "start",
+			Description: `Cron expression for when machines should be started, e.g. "0 8 * * 1-5"`,
+		},
+		flag.String{
+			Name:        "stop",
+			Description: `Cron expression for when machines should be stopped, e.g. "0 20 * * 1-5"`,
+		},
+		flag.String{
+			Name:        "timezone",
+			Description: "Timezone the schedules are evaluated in, e.g. America/New_York",
+			Default:     "UTC",
+		},
+	)
+
+	return cmd
+}
+
+func runScheduleSet(ctx context.Context) error {
+	var (
+		io       = iostreams.FromContext(ctx)
+		start    = flag.GetString(ctx, "start")
+		stop     = flag.GetString(ctx, "stop")
+		timezone = flag.GetString(ctx, "timezone")
+	)
+
+	if start == "" && stop == "" {
+		return errors.New("at least one of --start or --stop must be specified")
+	}
+	for _, expr := range []string{start, stop} {
+		if expr == "" {
+			continue
+		}
+		if _, err := mach.ParseCron(expr); err != nil {
+			return err
+		}
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return fmt.Errorf("invalid timezone %q: %w", timezone, err)
+	}
+
+	machines, ctx, err := selectScheduleMachines(ctx)
+	if err != nil {
+		return err
+	}
+	flapsClient := flapsutil.ClientFromContext(ctx)
+
+	metadata := map[string]string{
+		scheduleStartMetadataKey:    start,
+		scheduleStopMetadataKey:     stop,
+		scheduleTimezoneMetadataKey: timezone,
+	}
+
+	for _, machine := range machines {
+		for key, value := range metadata {
+			if value == "" {
+				err = flapsClient.DeleteMetadata(ctx, machine.ID, key)
+			} else {
+				err = flapsClient.SetMetadata(ctx, machine.ID, key, value)
+			}
+			if err != nil {
+				return fmt.Errorf("could not set schedule of machine %s: %w", machine.ID, err)
+			}
+		}
+		fmt.Fprintf(io.Out, "Schedule of machine %s set\n", machine.ID)
+	}
+
+	return nil
+}
+
+func newScheduleShow() *cobra.Command {
+	const (
+		short = "Show the schedules of an app's machines"
+		long  = short + "\n"
+		usage = "show"
+	)
+
+	cmd := command.New(usage, short, long, runScheduleShow,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Aliases = []string{"list", "ls"}
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(
+		cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.JSONOutput(),
+	)
+
+	return cmd
+}
+
+type machineSchedule struct {
+	ID           string `json:"id"`
+	ProcessGroup string `json:"process_group"`
+	Region       string `json:"region"`
+	State        string `json:"state"`
+	Start        string `json:"start,omitempty"`
+	Stop         string `json:"stop,omitempty"`
+	Timezone     string `json:"timezone"`
+	DesiredState string `json:"desired_state,omitempty"`
+}
+
+func runScheduleShow(ctx context.Context) error {
+	io := iostreams.FromContext(ctx)
+
+	ctx, err := buildContextFromAppName(ctx, appconfig.NameFromContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	machines, err := listScheduledMachines(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	schedules := make([]machineSchedule, 0, len(machines))
+	for _, machine := range machines {
+		desired, err := desiredScheduledState(machine, now)
+		if err != nil {
+			return err
+		}
+		schedules = append(schedules, machineSchedule{
+			ID:           machine.ID,
+			ProcessGroup: machine.ProcessGroup(),
+			Region:       machine.Region,
+			State:        machine.State,
+			Start:        machine.GetMetadataByKey(scheduleStartMetadataKey),
+			Stop:         machine.GetMetadataByKey(scheduleStopMetadataKey),
+			Timezone:     scheduleTimezone(machine),
+			DesiredState: desired,
+		})
+	}
+
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(io.Out, schedules)
+	}
+
+	if len(schedules) == 0 {
+		fmt.Fprintf(io.Out, "No machines of app %s have a schedule\n", appconfig.NameFromContext(ctx))
+		return nil
+	}
+
+	rows := make([][]string, 0, len(schedules))
+	for _, s := range schedules {
+		rows = append(rows, []string{s.ID, s.ProcessGroup, s.Region, s.State, s.Start, s.Stop, s.Timezone, s.DesiredState})
+	}
+
+	return render.Table(io.Out, "", rows, "ID", "Process Group", "Region", "State", "Start", "Stop", "Timezone", "Desired State")
+}
+
+func newScheduleClear() *cobra.Command {
+	const (
+		short = "Remove the start and stop schedule of machines"
+		long  = short + "\n"
+		usage = "clear [<id>...]"
+	)
+
+	cmd := command.New(usage, short, long, runScheduleClear,
+		command.RequireSession,
+		command.LoadAppNameIfPresent,
+	)
+
+	cmd.Args = cobra.ArbitraryArgs
+
+	flag.Add(cmd, scheduleTargetFlags)
+
+	return cmd
+}
+
+func runScheduleClear(ctx context.Context) error {
+	io := iostreams.FromContext(ctx)
+
+	machines, ctx, err := selectScheduleMachines(ctx)
+	if err != nil {
+		return err
+	}
+	flapsClient := flapsutil.ClientFromContext(ctx)
+
+	for _, machine := range machines {
+		for _, key := range []string{scheduleStartMetadataKey, scheduleStopMetadataKey, scheduleTimezoneMetadataKey} {
+			if machine.GetMetadataByKey(key) == "" {
+				continue
+			}
+			if err := flapsClient.DeleteMetadata(ctx, machine.ID, key); err != nil {
+				return fmt.Errorf("could not clear schedule of machine %s: %w", machine.ID, err)
+			}
+		}
+		fmt.Fprintf(io.Out, "Schedule of machine %s cleared\n", machine.ID)
+	}
+
+	return nil
+}
+
+func newScheduleReconcile() *cobra.Command {
+	const (
+		short = "Start and stop machines according to their schedules"
+		long  = short + `. Every machine with a schedule is moved to the state
+of the most recent start or stop window.
+`
+		usage = "reconcile"
+	)
+
+	cmd := command.New(usage, short, long, runScheduleReconcile,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(
+		cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Bool{
+			Name:        "dry-run",
+			Description: "Only show which machines would be started or stopped",
+		},
+	)
+
+	return cmd
+}
+
+func runScheduleReconcile(ctx context.Context) error {
+	var (
+		io     = iostreams.FromContext(ctx)
+		dryRun = flag.GetBool(ctx, "dry-run")
+	)
+
+	ctx, err := buildContextFromAppName(ctx, appconfig.NameFromContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	machines, err := listScheduledMachines(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	changed := 0
+	for _, machine := range machines {
+		desired, err := desiredScheduledState(machine, now)
+		if err != nil {
+			return err
+		}
+
+		var action string
+		switch {
+		case desired == fly.MachineStateStarted && (machine.State == fly.MachineStateStopped || machine.State == "suspended"):
+			action = "start"
+		case desired == fly.MachineStateStopped && machine.State == fly.MachineStateStarted:
+			action = "stop"
+		default:
+			continue
+		}
+
+		changed++
+		if dryRun {
+			fmt.Fprintf(io.Out, "Would %s machine %s\n", action, machine.ID)
+			continue
+		}
+
+		if err := reconcileScheduledMachine(ctx, machine, action); err != nil {
+			return err
+		}
+	}
+
+	if changed == 0 {
+		fmt.Fprintf(io.Out, "All scheduled machines are in their desired state\n")
+	}
+
+	return nil
+}
+
+func reconcileScheduledMachine(ctx context.Context, machine *fly.Machine, action string) error {
+	io := iostreams.FromContext(ctx)
+
+	machine, release, err := mach.AcquireLease(ctx, machine)
+	defer release()
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "start":
+		if err := Start(ctx, machine); err != nil {
+			return err
+		}
+		fmt.Fprintf(io.Out, "%s has been started\n", machine.ID)
+	case "stop":
+		if err := Stop(ctx, machine, "", 0); err != nil {
+			return err
+		}
+		fmt.Fprintf(io.Out, "%s has been stopped\n", machine.ID)
+	}
+
+	return nil
+}
+
+func newScheduleInstall() *cobra.Command {
+	const (
+		short = "Create a scheduler machine that applies machine schedules"
+		long  = short + `. The scheduler machine runs
+"fly machine schedule reconcile" every hour, so schedules should start and
+stop machines on the hour. It authenticates with the FLY_API_TOKEN secret of
+the app, which can be set with:
+
+  fly secrets set FLY_API_TOKEN="$(fly tokens create deploy)"
+`
+		usage = "install"
+	)
+
+	cmd := command.New(usage, short, long, runScheduleInstall,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(
+		cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Region(),
+		flag.String{
+			Name:        "image",
+			Description: "The flyctl image the scheduler machine runs",
+			Default:     defaultSchedulerImage,
+		},
+	)
+
+	return cmd
+}
+
+func runScheduleInstall(ctx context.Context) error {
+	var (
+		io       = iostreams.FromContext(ctx)
+		colorize = io.ColorScheme()
+		appName  = appconfig.NameFromContext(ctx)
+	)
+
+	ctx, err := buildContextFromAppName(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	existing, err := mach.FindController(ctx, schedulerMetadataKey)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("app %s already has a scheduler machine %s", appName, existing.ID)
+	}
+
+	machine, err := mach.LaunchController(ctx, appName, mach.ControllerSpec{
+		Name:     "fly-machine-scheduler",
+		Region:   flag.GetRegion(ctx),
+		Image:    flag.GetString(ctx, "image"),
+		Schedule: "hourly",
+		Cmd:      []string{"machine", "schedule", "reconcile", "--app", appName},
+		Restart:  fly.MachineRestartPolicyNo,
+		Metadata: map[string]string{
+			schedulerMetadataKey: "true",
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(io.Out, "Scheduler machine %s created, it will reconcile machine schedules every hour\n", colorize.Bold(machine.ID))
+	return nil
+}
+
+func selectScheduleMachines(ctx context.Context) ([]*fly.Machine, context.Context, error) {
+	var (
+		args  = flag.Args(ctx)
+		group = flag.GetProcessGroup(ctx)
+	)
+
+	if group == "" {
+		return selectManyMachines(ctx, args)
+	}
+
+	if len(args) > 0 {
+		return nil, nil, errors.New("machine IDs can't be used with --process-group")
+	}
+	appName := appconfig.NameFromContext(ctx)
+	if appName == "" {
+		return nil, nil, errors.New("an app name must be specified to use --process-group")
+	}
+
+	ctx, err := buildContextFromAppName(ctx, appName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	machines, err := mach.ListActive(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not get a list of machines: %w", err)
+	}
+	machines = lo.Filter(machines, func(m *fly.Machine, _ int) bool {
+		return m.ProcessGroup() == group && !isSchedulerMachine(m)
+	})
+	if len(machines) == 0 {
+		return nil, nil, fmt.Errorf("no machines found in process group %s", group)
+	}
+
+	return machines, ctx, nil
+}
+
+func listScheduledMachines(ctx context.Context) ([]*fly.Machine, error) {
+	machines, err := mach.ListActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get a list of machines: %w", err)
+	}
+
+	return lo.Filter(machines, func(m *fly.Machine, _ int) bool {
+		return m.GetMetadataByKey(scheduleStartMetadataKey) != "" || m.GetMetadataByKey(scheduleStopMetadataKey) != ""
+	}), nil
+}
+
+func isSchedulerMachine(m *fly.Machine) bool {
+	return m.GetMetadataByKey(schedulerMetadataKey) == "true"
+}
+
+func scheduleTimezone(m *fly.Machine) string {
+	if tz := m.GetMetadataByKey(scheduleTimezoneMetadataKey); tz != "" {
+		return tz
+	}
+	return "UTC"
+}
+
+// desiredScheduledState returns the state m should be in at now according
+// to its schedules, or an empty string if its schedules haven't fired yet.
+func desiredScheduledState(m *fly.Machine, now time.Time) (string, error) {
+	loc, err := time.LoadLocation(scheduleTimezone(m))
+	if err != nil {
+		return "", fmt.Errorf("invalid schedule timezone on machine %s: %w", m.ID, err)
+	}
+	now = now.In(loc)
+
+	var (
+		lastStart, lastStop time.Time
+		haveStart, haveStop bool
+	)
+
+	if expr := m.GetMetadataByKey(scheduleStartMetadataKey); expr != "" {
+		schedule, err := mach.ParseCron(expr)
+		if err != nil {
+			return "", fmt.Errorf("invalid start schedule on machine %s: %w", m.ID, err)
+		}
+		lastStart, haveStart = schedule.Prev(now, mach.CronLookback)
+	}
+
+	if expr := m.GetMetadataByKey(scheduleStopMetadataKey); expr != "" {
+		schedule, err := mach.ParseCron(expr)
+		if err != nil {
+			return "", fmt.Errorf("invalid stop schedule on machine %s: %w", m.ID, err)
+		}
+		lastStop, haveStop = schedule.Prev(now, mach.CronLookback)
+	}
+
+	switch {
+	case haveStart && (!haveStop || lastStart.After(lastStop)):
+		return fly.MachineStateStarted, nil
+	case haveStop:
+		return fly.MachineStateStopped, nil
+	default:
+		return "", nil
+	}
+}
@@ -2,6 +2,7 @@ package machine
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -18,7 +19,10 @@ import (
 func newStop() *cobra.Command {
 	const (
 		short = "Stop one or more Fly machines"
-		long  = short + "\n"
+		long  = short + `. Machines are sent --signal and given --timeout seconds
+to shut down gracefully before being killed. Use --force to kill them
+immediately instead.
+`
 
 		usage = "stop [<id>...]"
 	)
@@ -44,6 +48,11 @@ func newStop() *cobra.Command {
 			Name:        "timeout",
 			Description: "Seconds to wait before sending SIGKILL to the machine",
 		},
+		flag.Bool{
+			Name:        "force",
+			Shorthand:   "f",
+			Description: "Kill the machine immediately, without a graceful shutdown",
+		},
 		flag.Duration{
 			Name:        "wait-timeout",
 			Shorthand:   "w",
@@ -63,6 +72,16 @@ func runMachineStop(ctx context.Context) (err error) {
 		timeout = flag.GetInt(ctx, "timeout")
 	)
 
+	if err := checkStopTimeout(ctx); err != nil {
+		return err
+	}
+	if flag.GetBool(ctx, "force") {
+		if flag.IsSpecified(ctx, "signal") || flag.IsSpecified(ctx, "timeout") {
+			return errors.New("--signal and --timeout can't be used with --force")
+		}
+		signal = "SIGKILL"
+	}
+
 	machines, ctx, err := selectManyMachines(ctx, args)
 	if err != nil {
 		return err
@@ -85,6 +104,15 @@ func runMachineStop(ctx context.Context) (err error) {
 	return
 }
 
+// checkStopTimeout validates the graceful shutdown window shared by stop and
+// restart.
+func checkStopTimeout(ctx context.Context) error {
+	if flag.GetInt(ctx, "timeout") < 0 {
+		return errors.New("--timeout can't be negative")
+	}
+	return nil
+}
+
 func Stop(ctx context.Context, machine *fly.Machine, signal string, timeout int) (err error) {
 	machineStopInput := fly.StopMachineInput{
 		ID:     machine.ID,
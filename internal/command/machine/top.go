@@ -0,0 +1,286 @@
+package machine
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/azazeal/pause"
+	"github.com/dustin/go-humanize"
+	"github.com/inancgumus/screen"
+	"github.com/spf13/cobra"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/fly-go/flaps"
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flapsutil"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+	"golang.org/x/sync/errgroup"
+)
+
+func newTop() *cobra.Command {
+	const (
+		short = "Show resource usage of an app's machines"
+		long  = short + `. CPU and memory usage are summed over the processes
+running on each started machine, and rootfs usage is read from the machine's
+root filesystem.
+`
+		usage = "top [app]"
+	)
+
+	cmd := command.New(usage, short, long, runMachineTop,
+		command.RequireSession,
+		command.LoadAppNameIfPresent,
+	)
+
+	cmd.Args = cobra.MaximumNArgs(1)
+
+	flag.Add(
+		cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.JSONOutput(),
+		flag.Bool{
+			Name:        "watch",
+			Description: "Refresh resource usage",
+		},
+		flag.Int{
+			Name:        "rate",
+			Description: "Refresh rate in seconds for --watch",
+			Default:     5,
+		},
+	)
+
+	return cmd
+}
+
+type machineUsage struct {
+	ID               string `json:"id"`
+	Name             string `json:"name"`
+	Region           string `json:"region"`
+	State            string `json:"state"`
+	ProcessGroup     string `json:"process_group"`
+	Processes        int    `json:"processes"`
+	CPU              uint64 `json:"cpu"`
+	MemoryUsedBytes  uint64 `json:"memory_used_bytes"`
+	MemoryTotalBytes uint64 `json:"memory_total_bytes"`
+	RootfsUsedBytes  uint64 `json:"rootfs_used_bytes"`
+	RootfsTotalBytes uint64 `json:"rootfs_total_bytes"`
+	// Error is why the usage of the machine couldn't be read, its row
+	// showing it rather than the whole command failing.
+	Error string `json:"error,omitempty"`
+}
+
+func runMachineTop(ctx context.Context) error {
+	if appName := flag.FirstArg(ctx); appName != "" {
+		ctx = appconfig.WithName(ctx, appName)
+	}
+
+	appName := appconfig.NameFromContext(ctx)
+	if appName == "" {
+		return command.ErrRequireAppName
+	}
+
+	flapsClient, err := flapsutil.NewClientWithOptions(ctx, flaps.NewClientOpts{
+		AppName: appName,
+	})
+	if err != nil {
+		return fmt.Errorf("could not create flaps client: %w", err)
+	}
+	ctx = flapsutil.NewContextWithClient(ctx, flapsClient)
+
+	watch := flag.GetBool(ctx, "watch")
+	if watch && config.FromContext(ctx).JSONOutput {
+		return errors.New("--watch and --json are not supported together")
+	}
+
+	if !watch {
+		return renderMachineTop(ctx, iostreams.FromContext(ctx).Out)
+	}
+
+	return watchMachineTop(ctx)
+}
+
+func watchMachineTop(ctx context.Context) (err error) {
+	streams := iostreams.FromContext(ctx)
+	if !streams.IsInteractive() {
+		return errors.New("--watch is not supported for non-interactive sessions")
+	}
+	colorize := streams.ColorScheme()
+
+	rate := flag.GetInt(ctx, "rate")
+	if rate < 1 || rate > 3600 {
+		return errors.New("--rate must be in the [1, 3600] range")
+	}
+
+	appName := appconfig.NameFromContext(ctx)
+
+	var buf bytes.Buffer
+	for ctx.Err() == nil {
+		buf.Reset()
+
+		// A failure to list the machines is shown until the next refresh
+		// rather than ending the watch
+		if renderErr := renderMachineTop(ctx, &buf); renderErr != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			buf.Reset()
+			fmt.Fprintf(&buf, "%s %v\n", colorize.Red("Error:"), renderErr)
+		}
+
+		header := fmt.Sprintf("%s %s %s\n\n", colorize.Bold(appName), "at:", colorize.Bold(time.Now().UTC().Format("15:04:05")))
+
+		screen.Clear()
+		screen.MoveTopLeft()
+
+		io.Copy(streams.Out, io.MultiReader(
+			strings.NewReader(header),
+			&buf,
+		))
+
+		pause.For(ctx, time.Duration(rate)*time.Second)
+	}
+
+	// Interrupted with Ctrl-C
+	if err = ctx.Err(); errors.Is(err, context.Canceled) {
+		err = nil
+	}
+
+	return
+}
+
+func renderMachineTop(ctx context.Context, out io.Writer) error {
+	usages, err := collectMachineUsage(ctx)
+	if err != nil {
+		return err
+	}
+
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(out, usages)
+	}
+
+	rows := make([][]string, 0, len(usages))
+	for _, u := range usages {
+		row := []string{u.ID, u.Name, u.Region, u.State, u.ProcessGroup}
+		switch {
+		case u.Error != "":
+			row = append(row, "error: "+u.Error, "", "", "")
+		case u.State != fly.MachineStateStarted:
+			row = append(row, "", "", "", "")
+		default:
+			row = append(row,
+				strconv.Itoa(u.Processes),
+				fmt.Sprintf("%d%%", u.CPU),
+				formatUsage(u.MemoryUsedBytes, u.MemoryTotalBytes),
+				formatUsage(u.RootfsUsedBytes, u.RootfsTotalBytes),
+			)
+		}
+		rows = append(rows, row)
+	}
+
+	return render.Table(out, "", rows, "ID", "Name", "Region", "State", "Process Group", "Procs", "CPU", "Memory", "Rootfs")
+}
+
+func collectMachineUsage(ctx context.Context) ([]*machineUsage, error) {
+	flapsClient := flapsutil.ClientFromContext(ctx)
+
+	machines, err := flapsClient.List(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("could not get a list of machines: %w", err)
+	}
+
+	var (
+		usages = make([]*machineUsage, 0, len(machines))
+		mu     sync.Mutex
+	)
+
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.SetLimit(8)
+
+	for _, m := range machines {
+		if m.IsReleaseCommandMachine() || m.IsFlyAppsConsole() {
+			continue
+		}
+
+		eg.Go(func() error {
+			usage, err := getMachineUsage(ctx, flapsClient, m)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			usages = append(usages, usage)
+			return nil
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(usages, func(i, j int) bool {
+		return usages[i].ID < usages[j].ID
+	})
+
+	return usages, nil
+}
+
+func getMachineUsage(ctx context.Context, flapsClient flapsutil.FlapsClient, m *fly.Machine) (*machineUsage, error) {
+	usage := &machineUsage{
+		ID:           m.ID,
+		Name:         m.Name,
+		Region:       m.Region,
+		State:        m.State,
+		ProcessGroup: m.ProcessGroup(),
+	}
+
+	if m.Config != nil && m.Config.Guest != nil {
+		usage.MemoryTotalBytes = uint64(m.Config.Guest.MemoryMB) * 1024 * 1024
+	}
+
+	if m.State != fly.MachineStateStarted {
+		return usage, nil
+	}
+
+	processes, err := flapsClient.GetProcesses(ctx, m.ID)
+	if err != nil {
+		usage.Error = err.Error()
+		return usage, nil
+	}
+
+	usage.Processes = len(processes)
+	for _, p := range processes {
+		usage.CPU += p.Cpu
+		usage.MemoryUsedBytes += p.Rss
+	}
+
+	// Not every image ships df, so rootfs usage is best effort.
+	if used, total, err := mach.DiskUsage(ctx, m, "/"); err == nil {
+		usage.RootfsUsedBytes, usage.RootfsTotalBytes = used, total
+	}
+
+	return usage, nil
+}
+
+func formatUsage(used, total uint64) string {
+	switch {
+	case total == 0 && used == 0:
+		return "-"
+	case total == 0:
+		return humanize.IBytes(used)
+	default:
+		return fmt.Sprintf("%s / %s (%d%%)", humanize.IBytes(used), humanize.IBytes(total), used*100/total)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	fly "github.com/superfly/fly-go"
 	"github.com/superfly/flyctl/internal/command"
 	"github.com/superfly/flyctl/internal/flag"
 	"github.com/superfly/flyctl/internal/flapsutil"
@@ -15,7 +16,9 @@ import (
 func newMachineUncordon() *cobra.Command {
 	const (
 		short = "Reactivate all services on a machine"
-		long  = short + "\n"
+		long  = short + `. Uncordoned machines receive traffic from the Fly
+Proxy again and are included in future deployments.
+`
 		usage = "uncordon [<id>...]"
 	)
 
@@ -56,10 +59,21 @@ func runMachineUncordon(ctx context.Context) (err error) {
 
 	for _, machine := range machines {
 		fmt.Fprintf(io.Out, "Deactivating cordon on machine %s...\n", machine.ID)
-		if err = flapsClient.Uncordon(ctx, machine.ID, machine.LeaseNonce); err != nil {
+		if err = uncordonMachine(ctx, flapsClient, machine); err != nil {
 			return err
 		}
 		fmt.Fprintf(io.Out, "done!\n")
 	}
 	return
 }
+
+// uncordonMachine uncordons a leased machine and clears its cordon marker.
+func uncordonMachine(ctx context.Context, flapsClient flapsutil.FlapsClient, machine *fly.Machine) error {
+	if err := flapsutil.Uncordon(ctx, flapsClient, machine.ID, machine.LeaseNonce); err != nil {
+		return err
+	}
+	if err := flapsClient.DeleteMetadata(ctx, machine.ID, mach.CordonedMetadataKey); err != nil {
+		return fmt.Errorf("could not clear cordon marker on machine %s: %w", machine.ID, err)
+	}
+	return nil
+}
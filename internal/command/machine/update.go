@@ -3,6 +3,7 @@ package machine
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -13,15 +14,29 @@ import (
 	"github.com/superfly/flyctl/internal/appconfig"
 	"github.com/superfly/flyctl/internal/command"
 	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flapsutil"
 	"github.com/superfly/flyctl/internal/flyerr"
 	mach "github.com/superfly/flyctl/internal/machine"
 	"github.com/superfly/flyctl/internal/watch"
 )
 
+const (
+	bulkUpdateLeaseTimeout      = 13 * time.Second
+	bulkUpdateLeaseDelayBetween = (bulkUpdateLeaseTimeout - 1*time.Second) / 3
+)
+
 func newUpdate() *cobra.Command {
 	const (
 		short = "Update a machine"
-		long  = short + "\n"
+		long  = short + `.
+
+Use --matching to update every machine matching a selector instead, e.g.
+--matching "process_group=worker,region=iad". Selector keys can be id, name,
+state, region, process_group, image, created_before, created_after,
+updated_before, updated_after or any metadata key. Matching machines are
+updated one at a time, waiting for each to become healthy before moving on
+to the next.
+`
 
 		usage = "update [machine_id]"
 	)
@@ -35,8 +50,12 @@ func newUpdate() *cobra.Command {
 		cmd,
 		flag.Image(),
 		sharedFlags,
-		flag.Yes(),
 		selectFlag,
+		flag.Yes(),
+		flag.String{
+			Name:        "matching",
+			Description: `Update all machines matching a selector, e.g. "process_group=worker,region=iad"`,
+		},
 		flag.Bool{
 			Name:        "skip-start",
 			Description: "Updates machine without starting it.",
@@ -82,6 +101,14 @@ func runUpdate(ctx context.Context) (err error) {
 
 	machineID := flag.FirstArg(ctx)
 	haveMachineID := len(flag.Args(ctx)) > 0
+
+	if selector := flag.GetString(ctx, "matching"); selector != "" {
+		if haveMachineID || flag.GetBool(ctx, "select") {
+			return errors.New("machine IDs and --select can't be used with --matching")
+		}
+		return runBulkUpdate(ctx, selector)
+	}
+
 	machine, ctx, err := selectOneMachine(ctx, "", machineID, haveMachineID)
 	if err != nil {
 		return err
@@ -172,3 +199,145 @@ func runUpdate(ctx context.Context) (err error) {
 
 	return nil
 }
+
+func runBulkUpdate(ctx context.Context, rawSelector string) error {
+	var (
+		io       = iostreams.FromContext(ctx)
+		colorize = io.ColorScheme()
+		appName  = appconfig.NameFromContext(ctx)
+
+		autoConfirm      = flag.GetBool(ctx, "yes")
+		skipHealthChecks = flag.GetBool(ctx, "skip-health-checks")
+		skipStart        = flag.GetBool(ctx, "skip-start")
+		waitTimeout      = time.Duration(flag.GetInt(ctx, "wait-timeout")) * time.Second
+	)
+
+	if appName == "" {
+		return errors.New("an app name must be specified to use --matching")
+	}
+	if flag.GetString(ctx, flag.Dockerfile().Name) != "" {
+		return errors.New("--dockerfile can't be used with --matching, push an image and use --image instead")
+	}
+
+	selector, err := mach.ParseSelector(rawSelector)
+	if err != nil {
+		return err
+	}
+
+	ctx, err = buildContextFromAppName(ctx, appName)
+	if err != nil {
+		return err
+	}
+	flapsClient := flapsutil.ClientFromContext(ctx)
+
+	machines, err := mach.ListActive(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get a list of machines: %w", err)
+	}
+	machines = selector.Filter(machines)
+	if len(machines) == 0 {
+		return fmt.Errorf("no machines in app %s match %q", appName, rawSelector)
+	}
+
+	for _, m := range machines {
+		if m.HostStatus != fly.HostStatusOk {
+			return fmt.Errorf("machine %s is on an unreachable host, try again later", m.ID)
+		}
+	}
+
+	fmt.Fprintf(io.Out, "%d machine(s) match %q:\n", len(machines), rawSelector)
+	for _, m := range machines {
+		fmt.Fprintf(io.Out, "  %s (%s, region %s)\n", colorize.Bold(m.ID), m.State, m.Region)
+	}
+
+	// Compute every config up front so that nothing is applied if any of
+	// them are invalid.
+	configs := make(map[string]*fly.MachineConfig, len(machines))
+	for _, m := range machines {
+		machineConf, err := determineMachineConfig(ctx, &determineMachineConfigInput{
+			initialMachineConf: *m.Config,
+			appName:            appName,
+			imageOrPath:        flag.GetString(ctx, "image"),
+			region:             m.Region,
+			updating:           true,
+		})
+		if err != nil {
+			return err
+		}
+
+		if mp := flag.GetString(ctx, "mount-point"); mp != "" {
+			if len(machineConf.Mounts) != 1 {
+				return fmt.Errorf("machine %s doesn't have a volume attached", m.ID)
+			}
+			machineConf.Mounts[0].Path = mp
+		}
+
+		configs[m.ID] = machineConf
+	}
+
+	if !autoConfirm {
+		first := machines[0]
+		msg := fmt.Sprintf("Configuration changes to be applied to %d machine(s), shown for %s (%s):\n", len(machines), colorize.Bold(first.ID), colorize.Bold(first.Name))
+		confirmed, err := mach.ConfirmConfigChanges(ctx, first, *configs[first.ID], msg)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Fprintf(io.Out, "No changes to apply\n")
+			return nil
+		}
+	}
+
+	machineSet := mach.NewMachineSet(flapsClient, io, machines, true)
+	if err := machineSet.AcquireLeases(ctx, bulkUpdateLeaseTimeout); err != nil {
+		return err
+	}
+	defer machineSet.ReleaseLeases(ctx) // skipcq: GO-S2307
+	machineSet.StartBackgroundLeaseRefresh(ctx, bulkUpdateLeaseTimeout, bulkUpdateLeaseDelayBetween)
+
+	for _, lm := range machineSet.GetMachines() {
+		m := lm.Machine()
+		machineConf := configs[m.ID]
+
+		fmt.Fprintf(io.Out, "Updating machine %s\n", colorize.Bold(m.ID))
+		input := fly.LaunchMachineInput{
+			Name:             m.Name,
+			Region:           m.Region,
+			Config:           machineConf,
+			SkipLaunch:       len(machineConf.Standbys) > 0 || skipStart,
+			SkipHealthChecks: skipHealthChecks,
+			Timeout:          flag.GetInt(ctx, "wait-timeout"),
+		}
+		if err := lm.Update(ctx, input); err != nil {
+			return fmt.Errorf("could not update machine %s: %w", m.ID, err)
+		}
+
+		if input.SkipLaunch || machineConf.Schedule != "" {
+			continue
+		}
+
+		if err := lm.WaitForState(ctx, fly.MachineStateStarted, waitTimeout, false); err != nil {
+			var timeoutErr mach.WaitTimeoutErr
+			if errors.As(err, &timeoutErr) {
+				return flyerr.GenericErr{
+					Err:      timeoutErr.Error(),
+					Descript: timeoutErr.Description(),
+					Suggest:  "Try increasing the --wait-timeout",
+				}
+			}
+			return err
+		}
+
+		if !skipHealthChecks {
+			if err := lm.WaitForHealthchecksToPass(ctx, waitTimeout); err != nil {
+				return fmt.Errorf("failed to wait for health checks of machine %s to pass: %w", m.ID, err)
+			}
+		}
+
+		fmt.Fprintf(io.Out, "Machine %s updated successfully!\n", colorize.Bold(m.ID))
+	}
+
+	fmt.Fprintf(io.Out, "\nUpdated %d machine(s) matching %q\n", len(machines), rawSelector)
+
+	return nil
+}
@@ -0,0 +1,213 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flapsutil"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func newVolumes() *cobra.Command {
+	const (
+		short = "Attach or detach the volume of a stopped machine"
+		long  = short + `. Moving a volume between machines this way keeps
+both machines, instead of destroying and recreating them. The machine has to
+be stopped, and stays stopped once the volume is attached or detached.
+`
+		usage = "volumes <command>"
+	)
+
+	cmd := command.New(usage, short, long, nil)
+
+	cmd.Aliases = []string{"volume", "vol"}
+
+	cmd.AddCommand(
+		newVolumesAttach(),
+		newVolumesDetach(),
+	)
+
+	return cmd
+}
+
+func newVolumesAttach() *cobra.Command {
+	const (
+		short = "Attach a volume to a stopped machine"
+		long  = short + `. The volume has to be unattached and in the region of
+the machine, and the machine can't have another volume mounted.
+`
+		usage = "attach <machine-id> <volume-id>"
+	)
+
+	cmd := command.New(usage, short, long, runVolumesAttach,
+		command.RequireSession,
+		command.LoadAppNameIfPresent,
+	)
+
+	cmd.Args = cobra.ExactArgs(2)
+
+	flag.Add(
+		cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.String{
+			Name:        "path",
+			Description: "The path to mount the volume at",
+			Default:     "/data",
+		},
+	)
+
+	return cmd
+}
+
+func newVolumesDetach() *cobra.Command {
+	const (
+		short = "Detach a volume from a stopped machine"
+		long  = short + `. The volume is left in place and can then be attached
+to another machine in the same region.
+`
+		usage = "detach <machine-id> [<volume-id>]"
+	)
+
+	cmd := command.New(usage, short, long, runVolumesDetach,
+		command.RequireSession,
+		command.LoadAppNameIfPresent,
+	)
+
+	cmd.Args = cobra.RangeArgs(1, 2)
+
+	flag.Add(
+		cmd,
+		flag.App(),
+		flag.AppConfig(),
+	)
+
+	return cmd
+}
+
+func runVolumesAttach(ctx context.Context) error {
+	var (
+		io    = iostreams.FromContext(ctx)
+		args  = flag.Args(ctx)
+		volID = args[1]
+		path  = flag.GetString(ctx, "path")
+	)
+
+	if path == "" {
+		return fmt.Errorf("--path can't be empty")
+	}
+
+	machine, ctx, err := leaseStoppedMachine(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	defer machine.release()
+
+	if len(machine.Config.Mounts) > 0 {
+		return fmt.Errorf("machine %s already has volume %s attached, detach it first", machine.ID, machine.Config.Mounts[0].Volume)
+	}
+
+	vol, err := flapsutil.ClientFromContext(ctx).GetVolume(ctx, volID)
+	if err != nil {
+		return fmt.Errorf("failed to get volume: %w", err)
+	}
+	switch {
+	case vol.IsAttached():
+		return fmt.Errorf("volume %s is attached to machine %s, detach it first", vol.ID, *vol.AttachedMachine)
+	case vol.Region != machine.Region:
+		return fmt.Errorf("volume %s is in region %s but machine %s is in %s", vol.ID, vol.Region, machine.ID, machine.Region)
+	}
+
+	config := mach.CloneConfig(machine.Config)
+	config.Mounts = []fly.MachineMount{{
+		Volume: vol.ID,
+		Name:   vol.Name,
+		Path:   path,
+	}}
+	if err := updateStoppedMachine(ctx, machine.Machine, config); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(io.Out, "Volume %s attached to machine %s at %s\n", vol.ID, machine.ID, path)
+	return nil
+}
+
+func runVolumesDetach(ctx context.Context) error {
+	var (
+		io   = iostreams.FromContext(ctx)
+		args = flag.Args(ctx)
+	)
+
+	machine, ctx, err := leaseStoppedMachine(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	defer machine.release()
+
+	if len(machine.Config.Mounts) == 0 {
+		return fmt.Errorf("machine %s has no volume attached", machine.ID)
+	}
+	volID := machine.Config.Mounts[0].Volume
+	if len(args) > 1 {
+		volID = args[1]
+	}
+
+	config := mach.CloneConfig(machine.Config)
+	config.Mounts = lo.Reject(config.Mounts, func(m fly.MachineMount, _ int) bool { return m.Volume == volID })
+	if len(config.Mounts) == len(machine.Config.Mounts) {
+		return fmt.Errorf("machine %s doesn't have volume %s attached", machine.ID, volID)
+	}
+	if err := updateStoppedMachine(ctx, machine.Machine, config); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(io.Out, "Volume %s detached from machine %s\n", volID, machine.ID)
+	return nil
+}
+
+type leasedMachine struct {
+	*fly.Machine
+	release func()
+}
+
+// leaseStoppedMachine selects the machine and leases it, as long as it is
+// stopped: mounts can't change on a running machine.
+func leaseStoppedMachine(ctx context.Context, machineID string) (*leasedMachine, context.Context, error) {
+	machine, ctx, err := selectOneMachine(ctx, "", machineID, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	machine, release, err := mach.AcquireLease(ctx, machine)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+
+	switch {
+	case machine.HostStatus != fly.HostStatusOk:
+		release()
+		return nil, nil, fmt.Errorf("machine %s is on an unreachable host, try again later", machine.ID)
+	case machine.State != fly.MachineStateStopped:
+		release()
+		return nil, nil, fmt.Errorf("machine %s is %s, stop it first with 'fly machine stop %s'", machine.ID, machine.State, machine.ID)
+	}
+
+	return &leasedMachine{Machine: machine, release: release}, ctx, nil
+}
+
+func updateStoppedMachine(ctx context.Context, machine *fly.Machine, config *fly.MachineConfig) error {
+	input := &fly.LaunchMachineInput{
+		Name:       machine.Name,
+		Region:     machine.Region,
+		Config:     config,
+		SkipLaunch: true,
+	}
+	return mach.Update(ctx, machine, input)
+}
@@ -0,0 +1,138 @@
+package machine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flapsutil"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func newWait() *cobra.Command {
+	const (
+		short = "Wait for a machine to reach a state or get an event"
+		long  = short + `. Either wait for the machine to reach a state:
+
+  fly machine wait <id> --state stopped --timeout 2m
+
+or for an event, optionally only one that happened after another event:
+
+  fly machine wait <id> --event exit --after start
+
+Without --after, an event that already happened satisfies the wait.
+`
+		usage = "wait [<id>]"
+	)
+
+	cmd := command.New(usage, short, long, runMachineWait,
+		command.RequireSession,
+		command.LoadAppNameIfPresent,
+	)
+
+	cmd.Args = cobra.MaximumNArgs(1)
+
+	flag.Add(
+		cmd,
+		flag.App(),
+		flag.AppConfig(),
+		selectFlag,
+		flag.JSONOutput(),
+		flag.String{
+			Name:        "state",
+			Description: "The state to wait for, e.g. started, stopped, suspended or destroyed",
+		},
+		flag.String{
+			Name:        "event",
+			Description: "The event type to wait for, e.g. start, exit or launch",
+		},
+		flag.String{
+			Name:        "after",
+			Description: "With --event, only consider events that happened after the latest event of this type",
+		},
+		flag.Duration{
+			Name:        "timeout",
+			Description: "How long to wait, 0 waits forever",
+			Default:     5 * time.Minute,
+		},
+	)
+
+	return cmd
+}
+
+func runMachineWait(ctx context.Context) error {
+	var (
+		io        = iostreams.FromContext(ctx)
+		state     = flag.GetString(ctx, "state")
+		eventType = flag.GetString(ctx, "event")
+		after     = flag.GetString(ctx, "after")
+		timeout   = flag.GetDuration(ctx, "timeout")
+	)
+
+	switch {
+	case state == "" && eventType == "":
+		return errors.New("one of --state or --event must be specified")
+	case state != "" && eventType != "":
+		return errors.New("--state and --event can't be used together")
+	case after != "" && eventType == "":
+		return errors.New("--after can only be used with --event")
+	case timeout < 0:
+		return errors.New("--timeout can't be negative")
+	}
+
+	machineID := flag.FirstArg(ctx)
+	haveMachineID := len(flag.Args(ctx)) > 0
+	machine, ctx, err := selectOneMachine(ctx, "", machineID, haveMachineID)
+	if err != nil {
+		return err
+	}
+
+	lm := mach.NewLeasableMachine(flapsutil.ClientFromContext(ctx), io, machine, false)
+
+	if state != "" {
+		if err := lm.WaitForState(ctx, state, timeout, true); err != nil {
+			return err
+		}
+		if config.FromContext(ctx).JSONOutput {
+			return render.JSON(io.Out, map[string]string{"id": machine.ID, "state": state})
+		}
+		fmt.Fprintf(io.Out, "Machine %s is %s\n", machine.ID, state)
+		return nil
+	}
+
+	var event *fly.MachineEvent
+	if after != "" {
+		event, err = lm.WaitForEventTypeAfterType(ctx, eventType, after, timeout, true)
+	} else {
+		event, err = lm.WaitForEventType(ctx, eventType, timeout, true)
+	}
+	if err != nil {
+		return err
+	}
+	if event == nil {
+		// Interrupted with Ctrl-C
+		return nil
+	}
+
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(io.Out, event)
+	}
+
+	fmt.Fprintf(io.Out, "Machine %s got %s event at %s", machine.ID, event.Type, event.Time().UTC().Format(time.RFC3339))
+	if event.Request != nil {
+		if code, err := event.Request.GetExitCode(); err == nil {
+			fmt.Fprintf(io.Out, " with exit code %d", code)
+		}
+	}
+	fmt.Fprintln(io.Out)
+
+	return nil
+}
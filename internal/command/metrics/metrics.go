@@ -12,16 +12,21 @@ import (
 
 func New() (cmd *cobra.Command) {
 	const (
-		short = "Commands that handle sending any metrics to flyctl-metrics"
-		long  = short + "\n"
+		short = "Query the metrics of apps"
+		long  = `Query the metrics of apps from the Prometheus of their organization, with
+PromQL queries or prebuilt views of their CPU, memory and HTTP usage.
+`
 		usage = "metrics <command>"
 	)
 
 	cmd = command.New(usage, short, long, nil)
-	cmd.Hidden = true
 
 	cmd.AddCommand(
 		newSend(),
+		newQuery(),
+		newView("cpu", "Show the CPU usage of the machines of an app", cpuView),
+		newView("memory", "Show the memory usage of the machines of an app", memoryView),
+		newView("http", "Show the HTTP responses of an app by status", httpView),
 	)
 
 	return
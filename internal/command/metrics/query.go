@@ -0,0 +1,208 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/command/orgs"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flag/flagnames"
+	"github.com/superfly/flyctl/internal/flyutil"
+	"github.com/superfly/flyctl/internal/prometheus"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func newQuery() (cmd *cobra.Command) {
+	const (
+		long = `Run a PromQL query against the Prometheus of an organization, the one of
+the app when there's one, and show its series.
+
+Without --range, the current value of each series is shown. With --range,
+each series is shown as a sparkline over that long ago until now, along with
+its minimum, average, maximum and last values:
+
+  fly metrics query 'fly_instance_memory_mem_available{app="my-app"}' --range 1h
+`
+		short = "Run a PromQL query"
+		usage = "query <promql>"
+	)
+
+	cmd = command.New(usage, short, long, runQuery,
+		command.RequireSession,
+		command.LoadAppNameIfPresent,
+	)
+
+	cmd.Args = cobra.ExactArgs(1)
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Org(),
+		flag.JSONOutput(),
+	)
+	flag.Add(cmd, rangeFlags(0)...)
+
+	return
+}
+
+// rangeFlags are the flags of the time range of queries, defaulting to
+// defaultRange.
+func rangeFlags(defaultRange time.Duration) []flag.Flag {
+	return []flag.Flag{
+		flag.Duration{
+			Name:        "range",
+			Default:     defaultRange,
+			Description: "Show the series over this long ago until now, like 1h",
+		},
+		flag.Duration{
+			Name:        "step",
+			Description: "Interval between the samples of a range, by default a sixtieth of it",
+		},
+	}
+}
+
+func runQuery(ctx context.Context) error {
+	orgSlug, err := queryOrg(ctx)
+	if err != nil {
+		return err
+	}
+
+	return showQuery(ctx, orgSlug, flag.FirstArg(ctx), seriesLabels, formatValue)
+}
+
+// queryOrg returns the organization to query: the one of the app unless
+// --org is given.
+func queryOrg(ctx context.Context) (string, error) {
+	if appName := appconfig.NameFromContext(ctx); appName != "" && !flag.IsSpecified(ctx, flagnames.Org) {
+		app, err := flyutil.ClientFromContext(ctx).GetAppCompact(ctx, appName)
+		if err != nil {
+			return "", fmt.Errorf("failed to get app: %w", err)
+		}
+		return app.Organization.Slug, nil
+	}
+
+	org, err := orgs.OrgFromFlagOrSelect(ctx)
+	if err != nil {
+		return "", err
+	}
+	return org.Slug, nil
+}
+
+// showQuery runs query, over the range of the flags when there's one, and
+// renders its series named with label and values formatted with format.
+func showQuery(ctx context.Context, orgSlug, query string, label func(map[string]string) string, format func(float64) string) error {
+	out := iostreams.FromContext(ctx).Out
+
+	var (
+		series []prometheus.Series
+		err    error
+	)
+	rng := flag.GetDuration(ctx, "range")
+	if rng > 0 {
+		step := flag.GetDuration(ctx, "step")
+		if step <= 0 {
+			step = max(rng/60, 15*time.Second)
+		}
+		end := time.Now()
+		series, err = prometheus.QueryRange(ctx, orgSlug, query, end.Add(-rng), end, step)
+	} else {
+		series, err = prometheus.Query(ctx, orgSlug, query)
+	}
+	if err != nil {
+		return err
+	}
+
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(out, series)
+	}
+	if len(series) == 0 {
+		fmt.Fprintln(out, "No series found")
+		return nil
+	}
+
+	slices.SortFunc(series, func(a, b prometheus.Series) int {
+		return strings.Compare(label(a.Metric), label(b.Metric))
+	})
+
+	return renderSeries(out, series, rng > 0, label, format)
+}
+
+func renderSeries(w io.Writer, series []prometheus.Series, ranged bool, label func(map[string]string) string, format func(float64) string) error {
+	var rows [][]string
+	for _, s := range series {
+		last, ok := s.Last()
+		if !ok {
+			continue
+		}
+
+		if !ranged {
+			rows = append(rows, []string{label(s.Metric), format(last)})
+			continue
+		}
+
+		low, high, sum := math.Inf(1), math.Inf(-1), 0.0
+		for _, sample := range s.Samples {
+			low, high, sum = math.Min(low, sample.Value), math.Max(high, sample.Value), sum+sample.Value
+		}
+		rows = append(rows, []string{
+			label(s.Metric),
+			sparkline(s.Samples, low, high),
+			format(low),
+			format(sum / float64(len(s.Samples))),
+			format(high),
+			format(last),
+		})
+	}
+
+	if !ranged {
+		return render.Table(w, "", rows, "Series", "Value")
+	}
+	return render.Table(w, "", rows, "Series", "Trend", "Min", "Avg", "Max", "Last")
+}
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws the samples, ranging from low to high, with a block each.
+func sparkline(samples []prometheus.Sample, low, high float64) string {
+	var b strings.Builder
+	for _, sample := range samples {
+		i := len(sparks) / 2
+		if high > low {
+			i = int((sample.Value - low) / (high - low) * float64(len(sparks)-1))
+		}
+		b.WriteRune(sparks[i])
+	}
+	return b.String()
+}
+
+// seriesLabels names series after their labels, like {app="a", region="b"}.
+func seriesLabels(metric map[string]string) string {
+	name := metric["__name__"]
+
+	keys := lo.Keys(metric)
+	slices.Sort(keys)
+
+	var labels []string
+	for _, k := range keys {
+		if k != "__name__" {
+			labels = append(labels, fmt.Sprintf("%s=%q", k, metric[k]))
+		}
+	}
+	return name + "{" + strings.Join(labels, ", ") + "}"
+}
+
+func formatValue(v float64) string {
+	return fmt.Sprintf("%.4g", v)
+}
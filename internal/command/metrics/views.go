@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyutil"
+)
+
+// view is a prebuilt query of the metrics of an app.
+type view struct {
+	query  func(appName string) string
+	label  func(metric map[string]string) string
+	format func(float64) string
+}
+
+var (
+	// fly_instance_cpu counts centiseconds spent in each mode
+	cpuView = view{
+		query: func(appName string) string {
+			return fmt.Sprintf(`sum by (instance, region) (rate(fly_instance_cpu{app=%q,mode!="idle"}[1m])) / 100`, appName)
+		},
+		label: instanceLabel,
+		format: func(v float64) string {
+			return fmt.Sprintf("%.2f cores", v)
+		},
+	}
+
+	memoryView = view{
+		query: func(appName string) string {
+			return fmt.Sprintf(`fly_instance_memory_mem_total{app=%[1]q} - fly_instance_memory_mem_available{app=%[1]q}`, appName)
+		},
+		label: instanceLabel,
+		format: func(v float64) string {
+			return humanize.IBytes(uint64(max(v, 0)))
+		},
+	}
+
+	httpView = view{
+		query: func(appName string) string {
+			return fmt.Sprintf(`sum by (status) (rate(fly_edge_http_responses_count{app=%q}[1m]))`, appName)
+		},
+		label: func(metric map[string]string) string {
+			return "HTTP " + metric["status"]
+		},
+		format: func(v float64) string {
+			return fmt.Sprintf("%.2f/s", v)
+		},
+	}
+)
+
+func newView(name, short string, v view) (cmd *cobra.Command) {
+	long := short + `, as a sparkline over --range with the minimum,
+average, maximum and last values, or the current values with --range 0.
+`
+
+	cmd = command.New(name, short, long, func(ctx context.Context) error {
+		return runView(ctx, v)
+	},
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.JSONOutput(),
+	)
+	flag.Add(cmd, rangeFlags(time.Hour)...)
+
+	return
+}
+
+func runView(ctx context.Context, v view) error {
+	appName := appconfig.NameFromContext(ctx)
+
+	app, err := flyutil.ClientFromContext(ctx).GetAppCompact(ctx, appName)
+	if err != nil {
+		return fmt.Errorf("failed to get app: %w", err)
+	}
+
+	return showQuery(ctx, app.Organization.Slug, v.query(app.Name), v.label, v.format)
+}
+
+// instanceLabel names series after the machine and region they're of.
+func instanceLabel(metric map[string]string) string {
+	if region := metric["region"]; region != "" {
+		return fmt.Sprintf("%s (%s)", metric["instance"], region)
+	}
+	return metric["instance"]
+}
@@ -0,0 +1,212 @@
+// Package networks implements the networks command chain.
+package networks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/command/orgs"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyutil"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+// defaultNetwork is the name of the private network of the apps created
+// without --network.
+const defaultNetwork = "default"
+
+// New initializes and returns a new networks Command.
+func New() *cobra.Command {
+	const (
+		long = `Commands for inspecting the private networks (6PN) of an organization. Apps
+only reach the apps of their own network over private networking.
+
+Apps are on the default network of their organization, unless they are
+created with --network, e.g. 'fly apps create --network staging', which
+creates the network with its first app.`
+		short = "Inspect the private networks of an organization"
+	)
+
+	cmd := command.New("networks", short, long, nil)
+	cmd.Aliases = []string{"network"}
+	cmd.AddCommand(
+		newList(),
+		newShow(),
+	)
+	return cmd
+}
+
+func newList() *cobra.Command {
+	const (
+		long  = `List the private networks of an organization and the apps attached to each`
+		short = `List private networks`
+	)
+
+	cmd := command.New("list", short, long, runList,
+		command.RequireSession,
+	)
+	cmd.Aliases = []string{"ls"}
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.Org(),
+		flag.JSONOutput(),
+	)
+	return cmd
+}
+
+func newShow() *cobra.Command {
+	const (
+		long  = `Show the apps attached to a private network of an organization`
+		short = `Show the apps of a private network`
+	)
+
+	cmd := command.New("show <network>", short, long, runShow,
+		command.RequireSession,
+	)
+	cmd.Args = cobra.ExactArgs(1)
+
+	flag.Add(cmd,
+		flag.Org(),
+		flag.JSONOutput(),
+	)
+	return cmd
+}
+
+// network is a private network and the names of its apps.
+type network struct {
+	Name string
+	Apps []string
+}
+
+func runList(ctx context.Context) error {
+	out := iostreams.FromContext(ctx).Out
+
+	networks, err := orgNetworks(ctx)
+	if err != nil {
+		return err
+	}
+
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(out, networks)
+	}
+
+	rows := make([][]string, 0, len(networks))
+	for _, n := range networks {
+		rows = append(rows, []string{n.Name, fmt.Sprint(len(n.Apps)), strings.Join(n.Apps, ", ")})
+	}
+	return render.Table(out, "", rows, "Name", "App Count", "Apps")
+}
+
+func runShow(ctx context.Context) error {
+	out := iostreams.FromContext(ctx).Out
+	name := flag.FirstArg(ctx)
+
+	networks, err := orgNetworks(ctx)
+	if err != nil {
+		return err
+	}
+
+	n, ok := lo.Find(networks, func(n network) bool { return n.Name == name })
+	if !ok {
+		return fmt.Errorf("no apps are attached to a network named %s", name)
+	}
+
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(out, n)
+	}
+
+	rows := lo.Map(n.Apps, func(app string, _ int) []string { return []string{app} })
+	return render.Table(out, fmt.Sprintf("Network %s", n.Name), rows, "App")
+}
+
+// orgNetworks returns the networks of the apps of the selected organization.
+func orgNetworks(ctx context.Context) ([]network, error) {
+	org, err := orgs.OrgFromFlagOrSelect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	apps, err := appsWithNetworks(ctx, org.ID)
+	if err != nil {
+		return nil, err
+	}
+	return groupByNetwork(apps), nil
+}
+
+// appsWithNetworks lists the apps of an organization with their network,
+// which the apps queries of the API client leave out.
+func appsWithNetworks(ctx context.Context, orgID string) ([]fly.App, error) {
+	client := flyutil.ClientFromContext(ctx)
+
+	const query = `
+		query($org: ID, $after: String) {
+			apps(first: 200, after: $after, organizationId: $org) {
+				pageInfo {
+					hasNextPage
+					endCursor
+				}
+				nodes {
+					name
+					network
+				}
+			}
+		}
+	`
+
+	var (
+		apps   []fly.App
+		cursor string
+	)
+	for {
+		req := client.NewRequest(query)
+		req.Var("org", orgID)
+		if cursor != "" {
+			req.Var("after", cursor)
+		}
+
+		data, err := client.RunWithContext(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		apps = append(apps, data.Apps.Nodes...)
+
+		if !data.Apps.PageInfo.HasNextPage {
+			return apps, nil
+		}
+		cursor = data.Apps.PageInfo.EndCursor
+	}
+}
+
+func groupByNetwork(apps []fly.App) []network {
+	byName := map[string][]string{}
+	for _, app := range apps {
+		name := app.Network
+		if name == "" {
+			name = defaultNetwork
+		}
+		byName[name] = append(byName[name], app.Name)
+	}
+
+	networks := make([]network, 0, len(byName))
+	for name, apps := range byName {
+		sort.Strings(apps)
+		networks = append(networks, network{Name: name, Apps: apps})
+	}
+	sort.Slice(networks, func(i, j int) bool {
+		// The default network first
+		if (networks[i].Name == defaultNetwork) != (networks[j].Name == defaultNetwork) {
+			return networks[i].Name == defaultNetwork
+		}
+		return networks[i].Name < networks[j].Name
+	})
+	return networks
+}
@@ -14,13 +14,10 @@ import (
 	"github.com/superfly/flyctl/internal/config"
 	"github.com/superfly/flyctl/internal/flag"
 	"github.com/superfly/flyctl/internal/flyutil"
+	"github.com/superfly/flyctl/internal/machine"
 	"github.com/superfly/flyctl/internal/render"
 )
 
-// Hardcoded list of regions with GPUs
-// TODO: fetch this list from the graphql endpoint once it is there
-var gpuRegions = []string{"iad", "sjc", "syd", "ams"}
-
 func newRegions() (cmd *cobra.Command) {
 	const (
 		long = `View a list of regions where Fly has edges and/or datacenters
@@ -54,6 +51,7 @@ func runRegions(ctx context.Context) error {
 	}
 
 	var rows [][]string
+	gpuRegions := machine.GPURegions("")
 	for _, region := range regions {
 		gateway := ""
 		if region.GatewayAvailable {
@@ -0,0 +1,298 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/fly-go/flaps"
+	"github.com/superfly/flyctl/agent"
+	"github.com/superfly/flyctl/flypg"
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/command/apps"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flapsutil"
+	"github.com/superfly/flyctl/internal/flyutil"
+	"github.com/superfly/flyctl/internal/prompt"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func newCopy() *cobra.Command {
+	const (
+		short = "Copy the databases of a Postgres cluster to a new cluster of a later major version"
+		long  = short + `. A cluster of the new version is created next
+to the current one, with as many machines of the same size and volumes of the
+same size, in the region of the leader, and the databases of the current
+cluster are dumped and restored to it once.
+
+This is a one-time copy, not an in-place upgrade: there is no replication
+between the clusters and no switchover. Writes to the current cluster while
+its databases are copied aren't copied, so stop the apps writing to it
+beforehand. Only the presence of every database in the new cluster is
+checked, not their contents; check that the data you rely on was copied, then
+attach the apps to the new cluster with 'fly postgres attach' and destroy the
+current one once it's unused. Barman machines aren't part of the new cluster.
+`
+		usage = "copy"
+	)
+
+	cmd := command.New(usage, short, long, runCopy,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Yes(),
+		flag.Int{
+			Name:        "to",
+			Description: "The major version of Postgres of the new cluster, like 16",
+		},
+		flag.String{
+			Name:        "name",
+			Description: "The name of the new cluster, the name of the current one with the version by default",
+		},
+		flag.String{
+			Name:        "password",
+			Description: "The password of the postgres user of the current cluster, also used for the new one",
+		},
+		flag.String{
+			Name:        "image",
+			Description: "Path to public image containing custom migration process",
+		},
+	)
+
+	return cmd
+}
+
+func runCopy(ctx context.Context) (err error) {
+	var (
+		io      = iostreams.FromContext(ctx)
+		client  = flyutil.ClientFromContext(ctx)
+		appName = appconfig.NameFromContext(ctx)
+		to      = flag.GetInt(ctx, "to")
+	)
+
+	if to == 0 {
+		return errors.New("the version of the new cluster is required, given with --to")
+	}
+
+	app, err := client.GetAppCompact(ctx, appName)
+	if err != nil {
+		return fmt.Errorf("failed to resolve app: %w", err)
+	}
+	if !app.IsPostgresApp() {
+		return fmt.Errorf("app %s is not a Postgres app", appName)
+	}
+
+	ctx, err = apps.BuildContext(ctx, app)
+	if err != nil {
+		return err
+	}
+	flapsClient := flapsutil.ClientFromContext(ctx)
+
+	machines, err := flapsClient.ListActive(ctx)
+	if err != nil {
+		return fmt.Errorf("could not retrieve machines: %w", err)
+	}
+	// Barman machines back up the cluster, they aren't Postgres nodes
+	machines = lo.Filter(machines, func(m *fly.Machine, _ int) bool {
+		return m.GetConfig().Metadata["fly-barman"] != "true"
+	})
+	if len(machines) == 0 {
+		return fmt.Errorf("no machines are available on this app %s", appName)
+	}
+	if !IsFlex(machines[0]) {
+		return errors.New("copies are only supported for flex clusters")
+	}
+	leader, _ := machinesNodeRoles(ctx, machines)
+	if leader == nil {
+		return fmt.Errorf("no active leader found")
+	}
+
+	from, err := majorVersion(leader.ImageRef.Tag)
+	if err != nil {
+		return err
+	}
+	if to <= from {
+		return fmt.Errorf("%s runs Postgres %d, it can only be copied to a later major version", appName, from)
+	}
+
+	mounts := leader.GetConfig().Mounts
+	if len(mounts) == 0 {
+		return fmt.Errorf("the leader %s has no volume", leader.ID)
+	}
+	volume, err := flapsClient.GetVolume(ctx, mounts[0].Volume)
+	if err != nil {
+		return fmt.Errorf("failed to resolve the volume of the leader %s: %w", leader.ID, err)
+	}
+
+	guest := leader.GetConfig().Guest
+	if guest == nil {
+		return fmt.Errorf("the leader %s has no guest configuration", leader.ID)
+	}
+	vmSize, err := resolveVMSize(ctx, guest.ToSize())
+	if err != nil {
+		return err
+	}
+
+	newName := flag.GetString(ctx, "name")
+	if newName == "" {
+		newName = fmt.Sprintf("%s-pg%d", appName, to)
+	}
+
+	password := flag.GetString(ctx, "password")
+	if password == "" {
+		if err := prompt.Password(ctx, &password, fmt.Sprintf("Password of the postgres user of %s:", appName), true); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(io.Out, "Copying %s from Postgres %d to %d creates %s, a cluster of %d %s machines with %dGB volumes in %s, and copies the databases of %s to it.\n",
+		appName, from, to, newName, len(machines), vmSize.Name, volume.SizeGb, leader.Region, appName)
+
+	if !flag.GetYes(ctx) {
+		switch confirmed, err := prompt.Confirm(ctx, "Continue?"); {
+		case err == nil:
+			if !confirmed {
+				return nil
+			}
+		case prompt.IsNonInteractive(err):
+			return prompt.NonInteractiveError("--yes flag must be specified when not running interactively")
+		default:
+			return err
+		}
+	}
+
+	org, err := client.GetOrganizationBySlug(ctx, app.Organization.Slug)
+	if err != nil {
+		return fmt.Errorf("failed to resolve organization %s: %w", app.Organization.Slug, err)
+	}
+
+	err = CreateCluster(ctx, org, &fly.Region{Code: leader.Region}, &ClusterParams{
+		PostgresConfiguration: PostgresConfiguration{
+			Name:               newName,
+			ImageRef:           fmt.Sprintf("flyio/postgres-flex:%d", to),
+			InitialClusterSize: len(machines),
+			VMSize:             vmSize.Name,
+			DiskGb:             volume.SizeGb,
+		},
+		Password: password,
+		Manager:  flypg.ReplicationManager,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", newName, err)
+	}
+
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("%w\n%s was created but isn't a complete copy of %s, destroy it with 'fly apps destroy %s' before trying again", err, newName, appName, newName)
+		}
+	}()
+
+	newApp, err := client.GetAppCompact(ctx, newName)
+	if err != nil {
+		return fmt.Errorf("failed to resolve app: %w", err)
+	}
+	newLeader, err := clusterLeader(ctx, newApp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(io.Out, "Copying the databases of %s to %s\n", appName, newName)
+
+	err = importDatabase(ctx, &importParams{
+		App:       newApp,
+		LeaderID:  newLeader.ID,
+		SourceURI: fmt.Sprintf("postgres://postgres:%s@%s.internal:5432", url.QueryEscape(password), appName),
+		Region:    leader.Region,
+		VMSize:    vmSize,
+		Image:     flag.GetString(ctx, "image"),
+		Command:   "migrate -no-owner=true -create=true -clean=false -data-only=false",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to copy the databases of %s to %s: %w", appName, newName, err)
+	}
+
+	dialer := agent.DialerFromContext(ctx)
+	missing, err := missingDatabases(ctx,
+		flypg.NewFromInstance(leader.PrivateIP, dialer),
+		flypg.NewFromInstance(newLeader.PrivateIP, dialer),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to verify the databases of %s: %w", newName, err)
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("the databases %s of %s are missing from %s", strings.Join(missing, ", "), appName, newName)
+	}
+
+	fmt.Fprintf(io.Out, "\n%s runs Postgres %d and has every database of %s, check that their data was copied.\n", newName, to, appName)
+	fmt.Fprintf(io.Out, "Attach your apps to it with 'fly postgres attach %s --app <app>', then detach them from %s and destroy it once unused.\n", newName, appName)
+	return nil
+}
+
+// majorVersion returns the major version of Postgres of an image tag, like
+// 15 of 15.6.
+func majorVersion(tag string) (int, error) {
+	major, _, _ := strings.Cut(strings.TrimPrefix(tag, "v"), ".")
+	version, err := strconv.Atoi(major)
+	if err != nil {
+		return 0, fmt.Errorf("could not determine the Postgres version of image tag %q", tag)
+	}
+	return version, nil
+}
+
+// clusterLeader returns the leader of the cluster of app.
+func clusterLeader(ctx context.Context, app *fly.AppCompact) (*fly.Machine, error) {
+	flapsClient, err := flapsutil.NewClientWithOptions(ctx, flaps.NewClientOpts{
+		AppCompact: app,
+		AppName:    app.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	machines, err := flapsClient.ListActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve machines: %w", err)
+	}
+	leader, _ := machinesNodeRoles(ctx, machines)
+	if leader == nil {
+		return nil, fmt.Errorf("no active leader found on %s", app.Name)
+	}
+	return leader, nil
+}
+
+// missingDatabases returns the databases of the source cluster that the target
+// one doesn't have.
+func missingDatabases(ctx context.Context, source, target *flypg.Client) ([]string, error) {
+	sourceDatabases, err := source.ListDatabases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	targetDatabases, err := target.ListDatabases(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := lo.Map(targetDatabases, func(db flypg.PostgresDatabase, _ int) string { return db.Name })
+
+	var missing []string
+	for _, db := range sourceDatabases {
+		if !slices.Contains(names, db.Name) {
+			missing = append(missing, db.Name)
+		}
+	}
+	return missing, nil
+}
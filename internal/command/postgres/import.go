@@ -134,9 +134,42 @@ func runImport(ctx context.Context) error {
 		return err
 	}
 
+	return importDatabase(ctx, &importParams{
+		App:       app,
+		LeaderID:  machineID,
+		SourceURI: sourceURI,
+		Region:    region.Code,
+		VMSize:    vmSize,
+		Image:     imageRef,
+		Command:   resolveImportCommand(ctx),
+	})
+}
+
+// importParams are the parameters of an import of a database into the
+// cluster of App.
+type importParams struct {
+	App       *fly.AppCompact
+	LeaderID  string
+	SourceURI string
+	Region    string
+	VMSize    *fly.VMSize
+	// Image is of the migration machine, the latest importer when empty.
+	Image   string
+	Command string
+}
+
+// importDatabase imports the database of SourceURI into the cluster of App,
+// with the migration process run on an ephemeral machine.
+func importDatabase(ctx context.Context, params *importParams) error {
+	var (
+		client = flyutil.ClientFromContext(ctx)
+		app    = params.App
+		err    error
+	)
+
 	// Set sourceURI as a secret
 	_, err = client.SetSecrets(ctx, app.Name, map[string]string{
-		"SOURCE_DATABASE_URI": sourceURI,
+		"SOURCE_DATABASE_URI": params.SourceURI,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to set secrets: %s", err)
@@ -150,12 +183,12 @@ func runImport(ctx context.Context) error {
 	machineConfig := &fly.MachineConfig{
 		Env: map[string]string{
 			"POSTGRES_PASSWORD": "pass",
-			"PG_MACHINE_ID":     machineID,
+			"PG_MACHINE_ID":     params.LeaderID,
 		},
 		Guest: &fly.MachineGuest{
-			CPUKind:  vmSize.CPUClass,
-			CPUs:     int(vmSize.CPUCores),
-			MemoryMB: vmSize.MemoryMB,
+			CPUKind:  params.VMSize.CPUClass,
+			CPUs:     int(params.VMSize.CPUCores),
+			MemoryMB: params.VMSize.MemoryMB,
 		},
 		DNS: &fly.DNSConfig{
 			SkipRegistration: true,
@@ -167,6 +200,7 @@ func runImport(ctx context.Context) error {
 	}
 
 	// If a custom migration image is not specified, resolve latest managed image.
+	imageRef := params.Image
 	if imageRef == "" {
 		imageRef, err = client.GetLatestImageTag(ctx, "flyio/postgres-importer", nil)
 		if err != nil {
@@ -177,7 +211,7 @@ func runImport(ctx context.Context) error {
 
 	ephemeralInput := &mach.EphemeralInput{
 		LaunchInput: fly.LaunchMachineInput{
-			Region: region.Code,
+			Region: params.Region,
 			Config: machineConfig,
 		},
 		What: "to run the import process",
@@ -197,7 +231,7 @@ func runImport(ctx context.Context) error {
 		Dialer:   agent.DialerFromContext(ctx),
 		App:      app.Name,
 		Username: ssh.DefaultSshUsername,
-		Cmd:      resolveImportCommand(ctx),
+		Cmd:      params.Command,
 		Stdin:    os.Stdin,
 		Stdout:   ioutils.NewWriteCloserWrapper(colorable.NewColorableStdout(), func() error { return nil }),
 		Stderr:   ioutils.NewWriteCloserWrapper(colorable.NewColorableStderr(), func() error { return nil }),
@@ -44,6 +44,7 @@ func New() *cobra.Command {
 		newImport(),
 		newEvents(),
 		newBarman(),
+		newCopy(),
 	)
 
 	return cmd
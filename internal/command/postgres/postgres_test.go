@@ -25,3 +25,19 @@ func TestIsFlex(t *testing.T) {
 		},
 	}))
 }
+
+func TestMajorVersion(t *testing.T) {
+	for tag, want := range map[string]int{
+		"15":     15,
+		"15.6":   15,
+		"v16.2":  16,
+		"16.2-1": 16,
+	} {
+		got, err := majorVersion(tag)
+		assert.NoError(t, err, tag)
+		assert.Equal(t, want, got, tag)
+	}
+
+	_, err := majorVersion("latest")
+	assert.Error(t, err)
+}
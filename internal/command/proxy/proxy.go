@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -22,14 +24,45 @@ import (
 func New() *cobra.Command {
 	var (
 		long = strings.Trim(`Proxies connections to a Fly Machine through a WireGuard tunnel. By default,
-connects to the first Machine address returned by an internal DNS query on the app.`, "\n")
+connects to the first Machine address returned by an internal DNS query on the app.
+
+Several ports can be proxied at once over the same tunnel, e.g.
+
+  fly proxy 5432:5432 6379:6379 -a infra
+
+By default, only this host can connect to the proxy. With --bind, other
+hosts of the network can too, and with --unix, whoever can access the socket
+file, e.g. containers it's mounted in.
+
+When a connection fails to reach the Machine, the WireGuard tunnel is
+re-established, starting the agent again if needed, and the connection
+retried, while the proxy keeps listening; --no-reconnect disables this.
+Connections open when the tunnel dies are closed either way.
+
+With --socks5, the whole private network of the organization is proxied
+instead, through a SOCKS5 proxy that resolves .internal names, e.g.
+
+  fly proxy --socks5 :1080 -o personal
+  curl --socks5-hostname localhost:1080 http://my-app.internal:8080
+
+With --orgs, it proxies the private networks of several organizations at once
+over their own tunnels of the agent: .internal names go to the first
+organization they resolve in, and private IPv6 addresses to the one they
+belong to.
+
+  fly proxy --socks5 :1080 --orgs personal,client-a,client-b`, "\n")
 		short = `Proxies connections to a Fly Machine.`
 	)
 
-	cmd := command.New("proxy <local:remote> [remote_host]", short, long, run,
+	cmd := command.New("proxy <local:remote>... [remote_host]", short, long, run,
 		command.RequireSession, command.LoadAppNameIfPresent)
 
-	cmd.Args = cobra.RangeArgs(1, 2)
+	cmd.Args = func(cmd *cobra.Command, args []string) error {
+		if socks, _ := cmd.Flags().GetString("socks5"); socks != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	}
 
 	flag.Add(cmd,
 		flag.App(),
@@ -50,7 +83,24 @@ connects to the first Machine address returned by an internal DNS query on the a
 			Name:        flagnames.BindAddr,
 			Shorthand:   "b",
 			Default:     "127.0.0.1",
-			Description: "Local address to bind to",
+			Description: "Local address to bind to, like 0.0.0.0 to share the proxy with other hosts",
+			Aliases:     []string{"bind"},
+		},
+		flag.String{
+			Name:        "unix",
+			Description: "Listen on this unix socket path instead of a local port, e.g. to share the proxy with containers",
+		},
+		flag.String{
+			Name:        "socks5",
+			Description: "Run a SOCKS5 proxy to the private network of the organization on this address, like :1080, instead of proxying ports",
+		},
+		flag.StringSlice{
+			Name:        "orgs",
+			Description: "With --socks5, also proxy the private networks of these organizations, comma separated",
+		},
+		flag.Bool{
+			Name:        "no-reconnect",
+			Description: "Don't re-establish the tunnel when connections fail to reach the Machine",
 		},
 		flag.Bool{
 			Name:        "watch-stdin",
@@ -68,7 +118,46 @@ func run(ctx context.Context) (err error) {
 
 	orgSlug := flag.GetOrg(ctx)
 
-	args := flag.Args(ctx)
+	socksAddr := flag.GetString(ctx, "socks5")
+	var (
+		mappings   [][]string
+		remoteHost string
+	)
+	if socksAddr != "" {
+		if flag.GetString(ctx, "unix") != "" || flag.GetBool(ctx, "select") {
+			return errors.New("--socks5 can't be used with --unix or --select")
+		}
+		host, port, err := net.SplitHostPort(socksAddr)
+		if err != nil {
+			return fmt.Errorf("invalid SOCKS5 address %s, expected [host]:port: %w", socksAddr, err)
+		}
+		if host == "" {
+			host = flag.GetBindAddr(ctx)
+		}
+		socksAddr = net.JoinHostPort(host, port)
+		warnProxyExposure(ctx, host)
+
+		if orgs := flag.GetStringSlice(ctx, "orgs"); orgSlug == "" && appName == "" && len(orgs) > 0 {
+			orgSlug = orgs[0]
+		}
+	} else {
+		if len(flag.GetStringSlice(ctx, "orgs")) > 0 {
+			return errors.New("--orgs can only be used with --socks5")
+		}
+		mappings, remoteHost, err = parsePortMappings(flag.Args(ctx))
+		if err != nil {
+			return err
+		}
+		if socket := flag.GetString(ctx, "unix"); socket != "" {
+			if len(mappings) > 1 {
+				return errors.New("--unix can only be used with a single port mapping")
+			}
+			// The local port of the mapping is the remote one when it's alone
+			ports := mappings[0]
+			mappings[0] = []string{socket, ports[len(ports)-1]}
+		}
+		warnProxyExposure(ctx, flag.GetBindAddr(ctx))
+	}
 	promptInstance := flag.GetBool(ctx, "select")
 
 	if promptInstance && appName == "" {
@@ -120,29 +209,94 @@ func run(ctx context.Context) (err error) {
 		return err
 	}
 
-	ports := strings.Split(args[0], ":")
-
 	params := &proxy.ConnectParams{
 		BindAddr:         flag.GetBindAddr(ctx),
-		Ports:            ports,
 		AppName:          appName,
 		OrganizationSlug: orgSlug,
 		Dialer:           dialer,
 		PromptInstance:   promptInstance,
 		Network:          *network,
+		NoReconnect:      flag.GetBool(ctx, "no-reconnect"),
+	}
+
+	if flag.GetBool(ctx, "watch-stdin") {
+		ctx = watchStdinAndAbortOnClose(ctx)
+	}
+
+	if socksAddr != "" {
+		dialers := []proxy.OrgDialer{{Org: orgSlug, Network: *network, Dialer: dialer}}
+		for _, org := range flag.GetStringSlice(ctx, "orgs") {
+			if org == orgSlug {
+				continue
+			}
+			if _, err := client.GetOrganizationBySlug(ctx, org); err != nil {
+				return err
+			}
+			d, err := agentclient.ConnectToTunnel(ctx, org, "", flag.GetBool(ctx, "quiet"))
+			if err != nil {
+				return err
+			}
+			dialers = append(dialers, proxy.OrgDialer{Org: org, Dialer: d})
+		}
+
+		orgs := make([]string, 0, len(dialers))
+		for _, d := range dialers {
+			orgs = append(orgs, d.Org)
+		}
+		return proxy.ConnectSOCKS5(ctx, socksAddr, orgs, proxy.RouteDial(agentclient, dialers))
 	}
+	params.Ports = mappings[0]
 
-	if len(args) > 1 {
-		params.RemoteHost = args[1]
+	if remoteHost != "" {
+		params.RemoteHost = remoteHost
 	} else {
 		params.RemoteHost = fmt.Sprintf("%s.internal", appName)
 	}
 
-	if flag.GetBool(ctx, "watch-stdin") {
-		ctx = watchStdinAndAbortOnClose(ctx)
+	if len(mappings) == 1 {
+		return proxy.Connect(ctx, params)
+	}
+	return proxy.ConnectAll(ctx, params, mappings)
+}
+
+// warnProxyExposure warns when the proxy, bound to bindAddr, accepts
+// connections from other hosts than this one, or from whoever can access its
+// unix socket.
+func warnProxyExposure(ctx context.Context, bindAddr string) {
+	io := iostreams.FromContext(ctx)
+	colorize := io.ColorScheme()
+
+	if ip := net.ParseIP(bindAddr); bindAddr != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		fmt.Fprintln(io.ErrOut, colorize.WarningIcon(),
+			colorize.Yellow(fmt.Sprintf("The proxy listens on %s: any host that can reach it can connect to your private network services through it", bindAddr)))
 	}
+	if socket := flag.GetString(ctx, "unix"); socket != "" {
+		fmt.Fprintln(io.ErrOut, colorize.WarningIcon(),
+			colorize.Yellow(fmt.Sprintf("Anyone who can access %s can connect to your private network services through it", socket)))
+	}
+}
 
-	return proxy.Connect(ctx, params)
+// parsePortMappings splits the arguments of the command into port mappings,
+// local[:remote] with local a port or a unix socket path, and the optional
+// remote host after them.
+func parsePortMappings(args []string) (mappings [][]string, remoteHost string, err error) {
+	for i, arg := range args {
+		ports := strings.Split(arg, ":")
+		_, remoteErr := strconv.Atoi(ports[len(ports)-1])
+		isMapping := len(ports) <= 2 && (remoteErr == nil || (len(ports) == 1 && strings.Contains(arg, "/")))
+
+		switch {
+		case isMapping:
+			mappings = append(mappings, ports)
+		case i == 0:
+			return nil, "", fmt.Errorf("invalid port mapping %s, expected <local:remote>", arg)
+		case i == len(args)-1:
+			remoteHost = arg
+		default:
+			return nil, "", fmt.Errorf("invalid port mapping %s, the remote host goes after the port mappings", arg)
+		}
+	}
+	return mappings, remoteHost, nil
 }
 
 // Asynchronously watches stdin and abort when it closes.
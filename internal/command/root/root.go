@@ -42,6 +42,7 @@ import (
 	"github.com/superfly/flyctl/internal/command/metrics"
 	"github.com/superfly/flyctl/internal/command/move"
 	"github.com/superfly/flyctl/internal/command/mysql"
+	"github.com/superfly/flyctl/internal/command/networks"
 	"github.com/superfly/flyctl/internal/command/open"
 	"github.com/superfly/flyctl/internal/command/orgs"
 	"github.com/superfly/flyctl/internal/command/ping"
@@ -63,6 +64,7 @@ import (
 	"github.com/superfly/flyctl/internal/command/suspend"
 	"github.com/superfly/flyctl/internal/command/synthetics"
 	"github.com/superfly/flyctl/internal/command/tokens"
+	"github.com/superfly/flyctl/internal/command/trace"
 	"github.com/superfly/flyctl/internal/command/version"
 	"github.com/superfly/flyctl/internal/command/volumes"
 	"github.com/superfly/flyctl/internal/command/wireguard"
@@ -115,6 +117,7 @@ func New() *cobra.Command {
 		group(proxy.New(), "upkeep"),
 		group(postgres.New(), "dbs_and_extensions"),
 		group(ips.New(), "configuring"),
+		group(networks.New(), "configuring"),
 		group(secrets.New(), "configuring"),
 		group(ssh.New(), "upkeep"),
 		group(ssh.NewSFTP(), "upkeep"),
@@ -127,6 +130,7 @@ func New() *cobra.Command {
 		group(services.New(), "upkeep"),
 		group(config.New(), "configuring"),
 		group(scale.New(), "configuring"),
+		group(scale.NewAutoscale(), "configuring"),
 		group(tokens.New(), "acl"),
 		group(extensions.New(), "dbs_and_extensions"),
 		group(consul.New(), "dbs_and_extensions"),
@@ -136,7 +140,8 @@ func New() *cobra.Command {
 		group(console.New(), "upkeep"),
 		settings.New(),
 		group(storage.New(), "dbs_and_extensions"),
-		metrics.New(),
+		group(metrics.New(), "upkeep"),
+		group(trace.New(), "upkeep"),
 		synthetics.New(),
 		curl.New(),       // TODO: deprecate
 		domains.New(),    // TODO: deprecate
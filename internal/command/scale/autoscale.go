@@ -0,0 +1,623 @@
+package scale
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/azazeal/pause"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flapsutil"
+	"github.com/superfly/flyctl/internal/flyutil"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+const (
+	// autoscaleTolerance is how far the load can drift from the target before
+	// the machine count changes, to avoid flapping around the target.
+	autoscaleTolerance = 0.1
+	// autoscaleLoadWindow is the window the load of a group is averaged over.
+	autoscaleLoadWindow = 5 * time.Minute
+	// maxAutoscaleDecisions bounds the decisions kept in the autoscaler's
+	// metadata.
+	maxAutoscaleDecisions = 20
+)
+
+var autoscaleMetrics = []string{"cpu", "memory"}
+
+// autoscalePolicy keeps the average load of a process group around Target
+// percent of its guests' CPU or memory, within Min and Max machines.
+type autoscalePolicy struct {
+	ProcessGroup string `json:"process_group"`
+	Metric       string `json:"metric"`
+	Target       int    `json:"target"`
+	Min          int    `json:"min"`
+	Max          int    `json:"max"`
+}
+
+type autoscaleDecision struct {
+	Time         time.Time `json:"time"`
+	ProcessGroup string    `json:"process_group"`
+	Metric       string    `json:"metric"`
+	Load         float64   `json:"load"`
+	From         int       `json:"from"`
+	To           int       `json:"to"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// NewAutoscale returns the autoscale command, which is registered at the top
+// level next to scale.
+func NewAutoscale() *cobra.Command {
+	const (
+		short = "Configure metrics-driven autoscaling"
+		long  = short + `. Autoscaling policies adjust the machine count of a
+process group to keep its average CPU or memory usage around a target, e.g.
+
+  fly autoscale policy set --process-group web --metric cpu --target 70 --min 2 --max 10
+
+Unlike autostop and autostart, which only start and stop existing machines,
+policies create and destroy machines. They are applied every minute by an
+autoscaler machine created with the first policy, which authenticates with the
+FLY_API_TOKEN secret of the app:
+
+  fly secrets set FLY_API_TOKEN="$(fly tokens create deploy)"
+`
+		usage = "autoscale <command>"
+	)
+
+	cmd := command.New(usage, short, long, nil)
+
+	cmd.AddCommand(
+		newAutoscalePolicy(),
+		newAutoscaleReconcile(),
+	)
+
+	return cmd
+}
+
+func newAutoscalePolicy() *cobra.Command {
+	const (
+		short = "Manage autoscaling policies"
+		long  = short + "\n"
+		usage = "policy <command>"
+	)
+
+	cmd := command.New(usage, short, long, nil)
+
+	cmd.AddCommand(
+		newAutoscalePolicySet(),
+		newAutoscalePolicyShow(),
+		newAutoscalePolicyUnset(),
+	)
+
+	return cmd
+}
+
+func newAutoscalePolicySet() *cobra.Command {
+	const (
+		short = "Set the autoscaling policy of a process group"
+		long  = short + `. The target is a percentage of the CPUs or memory of
+the group's guests, averaged over its started machines for the last five
+minutes.
+`
+		usage = "set"
+	)
+
+	cmd := command.New(usage, short, long, runAutoscalePolicySet,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.ProcessGroup("The process group to autoscale"),
+		flag.Region(),
+		flag.String{
+			Name:        "metric",
+			Description: "The metric to scale on: cpu or memory",
+			Default:     "cpu",
+		},
+		flag.Int{
+			Name:        "target",
+			Description: "Target average usage in percent",
+			Default:     70,
+		},
+		flag.Int{
+			Name:        "min",
+			Description: "Minimum number of machines",
+			Default:     1,
+		},
+		flag.Int{
+			Name:        "max",
+			Description: "Maximum number of machines",
+			Default:     3,
+		},
+		flag.String{
+			Name:        "image",
+			Description: "The flyctl image the autoscaler machine runs, when it is created",
+			Default:     defaultScaleSchedulerImage,
+		},
+	)
+
+	return cmd
+}
+
+func runAutoscalePolicySet(ctx context.Context) error {
+	io := iostreams.FromContext(ctx)
+	appName := appconfig.NameFromContext(ctx)
+
+	ctx, err := controllerContext(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	appConfig, err := appconfig.FromRemoteApp(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	policy := autoscalePolicy{
+		ProcessGroup: flag.GetProcessGroup(ctx),
+		Metric:       flag.GetString(ctx, "metric"),
+		Target:       flag.GetInt(ctx, "target"),
+		Min:          flag.GetInt(ctx, "min"),
+		Max:          flag.GetInt(ctx, "max"),
+	}
+	if policy.ProcessGroup == "" {
+		policy.ProcessGroup = appConfig.DefaultProcessName()
+	}
+	if !slices.Contains(appConfig.ProcessNames(), policy.ProcessGroup) {
+		return fmt.Errorf("unknown process group '%s', valid names are %s", policy.ProcessGroup, appConfig.FormatProcessNames())
+	}
+	if err := policy.validate(); err != nil {
+		return err
+	}
+
+	autoscaler, policies, _, err := getAutoscaler(ctx)
+	if err != nil {
+		return err
+	}
+	policies = append(lo.Reject(policies, func(p autoscalePolicy, _ int) bool {
+		return p.ProcessGroup == policy.ProcessGroup
+	}), policy)
+
+	if autoscaler == nil {
+		autoscaler, err = launchAutoscaler(ctx, appName, policies)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(io.Out, "Created autoscaler machine %s\n", autoscaler.ID)
+	} else if err := setControllerMetadata(ctx, autoscaler, mach.AutoscalePoliciesMetadataKey, policies); err != nil {
+		return fmt.Errorf("could not save autoscaling policies: %w", err)
+	}
+
+	fmt.Fprintf(io.Out, "Group '%s' will scale between %d and %d machines to keep %s usage around %d%%\n",
+		policy.ProcessGroup, policy.Min, policy.Max, policy.Metric, policy.Target)
+	return nil
+}
+
+func newAutoscalePolicyShow() *cobra.Command {
+	const (
+		short = "Show autoscaling policies and recent scaling decisions"
+		long  = short + "\n"
+		usage = "show"
+	)
+
+	cmd := command.New(usage, short, long, runAutoscalePolicyShow,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Aliases = []string{"list", "ls"}
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.JSONOutput(),
+	)
+
+	return cmd
+}
+
+func runAutoscalePolicyShow(ctx context.Context) error {
+	io := iostreams.FromContext(ctx)
+	appName := appconfig.NameFromContext(ctx)
+
+	ctx, err := controllerContext(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	_, policies, decisions, err := getAutoscaler(ctx)
+	if err != nil {
+		return err
+	}
+
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(io.Out, map[string]any{
+			"policies":  append([]autoscalePolicy{}, policies...),
+			"decisions": append([]autoscaleDecision{}, decisions...),
+		})
+	}
+
+	if len(policies) == 0 {
+		fmt.Fprintf(io.Out, "App %s has no autoscaling policies\n", appName)
+		return nil
+	}
+
+	rows := make([][]string, 0, len(policies))
+	for _, p := range policies {
+		rows = append(rows, []string{p.ProcessGroup, p.Metric, fmt.Sprintf("%d%%", p.Target), strconv.Itoa(p.Min), strconv.Itoa(p.Max)})
+	}
+	if err := render.Table(io.Out, "Policies", rows, "Process Group", "Metric", "Target", "Min", "Max"); err != nil {
+		return err
+	}
+
+	if len(decisions) == 0 {
+		fmt.Fprintf(io.Out, "No scaling decisions yet\n")
+		return nil
+	}
+
+	rows = make([][]string, 0, len(decisions))
+	for i := len(decisions) - 1; i >= 0; i-- {
+		d := decisions[i]
+		rows = append(rows, []string{
+			d.Time.Format(time.RFC3339),
+			d.ProcessGroup,
+			fmt.Sprintf("%s %.0f%%", d.Metric, d.Load),
+			fmt.Sprintf("%d -> %d", d.From, d.To),
+			d.Error,
+		})
+	}
+	return render.Table(io.Out, "Recent decisions", rows, "Time", "Process Group", "Load", "Machines", "Error")
+}
+
+func newAutoscalePolicyUnset() *cobra.Command {
+	const (
+		short = "Remove the autoscaling policy of a process group"
+		long  = short + `. The autoscaler machine is destroyed along with the
+last policy.
+`
+		usage = "unset"
+	)
+
+	cmd := command.New(usage, short, long, runAutoscalePolicyUnset,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.ProcessGroup("The process group to stop autoscaling"),
+	)
+
+	return cmd
+}
+
+func runAutoscalePolicyUnset(ctx context.Context) error {
+	io := iostreams.FromContext(ctx)
+	appName := appconfig.NameFromContext(ctx)
+
+	ctx, err := controllerContext(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	autoscaler, policies, _, err := getAutoscaler(ctx)
+	if err != nil {
+		return err
+	}
+
+	group := flag.GetProcessGroup(ctx)
+	if group == "" {
+		appConfig, err := appconfig.FromRemoteApp(ctx, appName)
+		if err != nil {
+			return err
+		}
+		group = appConfig.DefaultProcessName()
+	}
+
+	remaining := lo.Reject(policies, func(p autoscalePolicy, _ int) bool {
+		return p.ProcessGroup == group
+	})
+	if len(remaining) == len(policies) {
+		return fmt.Errorf("process group '%s' has no autoscaling policy", group)
+	}
+
+	if len(remaining) == 0 {
+		if err := destroyControllerMachine(ctx, autoscaler); err != nil {
+			return err
+		}
+		fmt.Fprintf(io.Out, "Removed the autoscaling policy of group '%s' and destroyed autoscaler machine %s\n", group, autoscaler.ID)
+		return nil
+	}
+
+	if err := setControllerMetadata(ctx, autoscaler, mach.AutoscalePoliciesMetadataKey, remaining); err != nil {
+		return fmt.Errorf("could not save autoscaling policies: %w", err)
+	}
+	fmt.Fprintf(io.Out, "Removed the autoscaling policy of group '%s'\n", group)
+	return nil
+}
+
+func newAutoscaleReconcile() *cobra.Command {
+	const (
+		short = "Scale process groups according to their autoscaling policies"
+		long  = short + `. This is what the autoscaler machine runs; it can also
+be run by hand, e.g. with --dry-run to see what the autoscaler would do.
+`
+		usage = "reconcile"
+	)
+
+	cmd := command.New(usage, short, long, runAutoscaleReconcile,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Yes(),
+		flag.Bool{
+			Name:        "dry-run",
+			Description: "Only show which groups would be scaled",
+		},
+		flag.Duration{
+			Name:        "interval",
+			Description: "Keep reconciling at this interval instead of exiting",
+		},
+	)
+
+	return cmd
+}
+
+func runAutoscaleReconcile(ctx context.Context) error {
+	io := iostreams.FromContext(ctx)
+	appName := appconfig.NameFromContext(ctx)
+	interval := flag.GetDuration(ctx, "interval")
+
+	ctx, err := controllerContext(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	for {
+		err := reconcileAutoscale(ctx, appName)
+		if interval <= 0 {
+			return err
+		}
+		if err != nil {
+			fmt.Fprintf(io.ErrOut, "Autoscaling failed: %v\n", err)
+		}
+
+		pause.For(ctx, interval)
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+func reconcileAutoscale(ctx context.Context, appName string) error {
+	var (
+		io     = iostreams.FromContext(ctx)
+		dryRun = flag.GetBool(ctx, "dry-run")
+	)
+
+	autoscaler, policies, decisions, err := getAutoscaler(ctx)
+	if err != nil {
+		return err
+	}
+	if len(policies) == 0 {
+		fmt.Fprintf(io.Out, "App %s has no autoscaling policies\n", appName)
+		return nil
+	}
+
+	machines, _, err := flapsutil.ClientFromContext(ctx).ListFlyAppsMachines(ctx)
+	if err != nil {
+		return err
+	}
+	machineGroups := lo.GroupBy(machines, func(m *fly.Machine) string {
+		return m.ProcessGroup()
+	})
+
+	appConfig, err := appconfig.FromRemoteApp(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	loads := make(map[string]map[string]float64)
+	for _, metric := range lo.Uniq(lo.Map(policies, func(p autoscalePolicy, _ int) string { return p.Metric })) {
+		loads[metric], err = fetchGroupLoad(ctx, appName, machines, metric)
+		if err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	var newDecisions []autoscaleDecision
+	for _, policy := range policies {
+		current := len(machineGroups[policy.ProcessGroup])
+		load, hasLoad := loads[policy.Metric][policy.ProcessGroup]
+
+		desired := autoscaleDesiredCount(policy, current, load, hasLoad)
+		if desired == current {
+			continue
+		}
+
+		fmt.Fprintf(io.Out, "Group '%s' %s usage is %.0f%% for a target of %d%%, scaling from %d to %d machines\n",
+			policy.ProcessGroup, policy.Metric, load, policy.Target, current, desired)
+		if dryRun {
+			continue
+		}
+
+		decision := autoscaleDecision{
+			Time:         now,
+			ProcessGroup: policy.ProcessGroup,
+			Metric:       policy.Metric,
+			Load:         math.Round(load),
+			From:         current,
+			To:           desired,
+		}
+		groups := groupCounts{policy.ProcessGroup: {absolute: desired}}
+		if err := runMachinesScaleCount(ctx, appName, appConfig, groups, -1, balanceDefault); err != nil {
+			decision.Error = err.Error()
+			fmt.Fprintf(io.ErrOut, "Failed to scale group '%s': %v\n", policy.ProcessGroup, err)
+		}
+		newDecisions = append(newDecisions, decision)
+	}
+
+	if len(newDecisions) == 0 {
+		if !dryRun {
+			fmt.Fprintf(io.Out, "All autoscaled groups are within their targets\n")
+		}
+		return nil
+	}
+
+	decisions = append(decisions, newDecisions...)
+	if len(decisions) > maxAutoscaleDecisions {
+		decisions = decisions[len(decisions)-maxAutoscaleDecisions:]
+	}
+	if err := setControllerMetadata(ctx, autoscaler, mach.AutoscaleDecisionsMetadataKey, decisions); err != nil {
+		return fmt.Errorf("could not record scaling decisions: %w", err)
+	}
+	return nil
+}
+
+// getAutoscaler returns the autoscaler machine of the app along with the
+// policies and decisions it holds. The machine is nil when the app has no
+// policies.
+func getAutoscaler(ctx context.Context) (*fly.Machine, []autoscalePolicy, []autoscaleDecision, error) {
+	autoscaler, err := mach.FindController(ctx, mach.AutoscalerMetadataKey)
+	if err != nil || autoscaler == nil {
+		return nil, nil, nil, err
+	}
+
+	var (
+		policies  []autoscalePolicy
+		decisions []autoscaleDecision
+	)
+	if err := getControllerMetadata(autoscaler, mach.AutoscalePoliciesMetadataKey, &policies); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := getControllerMetadata(autoscaler, mach.AutoscaleDecisionsMetadataKey, &decisions); err != nil {
+		return nil, nil, nil, err
+	}
+
+	return autoscaler, policies, decisions, nil
+}
+
+func launchAutoscaler(ctx context.Context, appName string, policies []autoscalePolicy) (*fly.Machine, error) {
+	autoscaler, err := launchControllerMachine(ctx, appName, mach.ControllerSpec{
+		Name:    "fly-autoscaler",
+		Cmd:     []string{"autoscale", "reconcile", "--app", appName, "--yes", "--interval", "1m"},
+		Restart: fly.MachineRestartPolicyAlways,
+		Metadata: map[string]string{
+			mach.AutoscalerMetadataKey: "true",
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setControllerMetadata(ctx, autoscaler, mach.AutoscalePoliciesMetadataKey, policies); err != nil {
+		return nil, fmt.Errorf("could not save autoscaling policies: %w", err)
+	}
+	return autoscaler, nil
+}
+
+func (p autoscalePolicy) validate() error {
+	switch {
+	case !slices.Contains(autoscaleMetrics, p.Metric):
+		return fmt.Errorf("invalid metric %q, options are %v", p.Metric, autoscaleMetrics)
+	case p.Target < 1 || p.Target > 100:
+		return errors.New("--target must be a percentage between 1 and 100")
+	case p.Min < 0:
+		return errors.New("--min can't be negative")
+	case p.Max < 1:
+		return errors.New("--max must be at least 1")
+	case p.Min > p.Max:
+		return fmt.Errorf("--min (%d) can't be greater than --max (%d)", p.Min, p.Max)
+	}
+	return nil
+}
+
+// autoscaleDesiredCount returns how many machines the group of policy should
+// have so its load gets close to the target, proportionally to how far off the
+// current load is. Without load data, e.g. with no started machines, the count
+// is only kept within bounds.
+func autoscaleDesiredCount(policy autoscalePolicy, current int, load float64, hasLoad bool) int {
+	desired := current
+	if hasLoad && current > 0 {
+		ratio := load / float64(policy.Target)
+		if math.Abs(ratio-1) > autoscaleTolerance {
+			desired = int(math.Ceil(float64(current) * ratio))
+		}
+	}
+	return min(max(desired, policy.Min), policy.Max)
+}
+
+// fetchGroupLoad returns the average usage, in percent of the guest, of the
+// started machines of each group over the last few minutes.
+func fetchGroupLoad(ctx context.Context, appName string, machines []*fly.Machine, metric string) (map[string]float64, error) {
+	app, err := flyutil.ClientFromContext(ctx).GetAppCompact(ctx, appName)
+	if err != nil {
+		return nil, err
+	}
+
+	rng := fmt.Sprintf("%ds", int(autoscaleLoadWindow.Seconds()))
+	var query string
+	switch metric {
+	case "cpu":
+		// fly_instance_cpu counts centiseconds spent in each mode
+		query = fmt.Sprintf(`avg_over_time((sum by (instance) (rate(fly_instance_cpu{app=%q,mode!="idle"}[1m])) / 100)[%s:1m])`, appName, rng)
+	case "memory":
+		query = fmt.Sprintf(`avg_over_time((1 - fly_instance_memory_mem_available{app=%[1]q} / fly_instance_memory_mem_total{app=%[1]q})[%[2]s:1m])`, appName, rng)
+	default:
+		return nil, fmt.Errorf("unknown metric %q", metric)
+	}
+
+	values, err := queryPrometheus(ctx, app.Organization.Slug, query)
+	if err != nil {
+		return nil, err
+	}
+
+	perGroup := make(map[string][]float64)
+	for _, m := range machines {
+		v, ok := values[m.ID]
+		if !ok || m.State != fly.MachineStateStarted {
+			continue
+		}
+		if metric == "cpu" {
+			cpus := 1
+			if m.Config != nil && m.Config.Guest != nil && m.Config.Guest.CPUs > 0 {
+				cpus = m.Config.Guest.CPUs
+			}
+			v /= float64(cpus)
+		}
+		perGroup[m.ProcessGroup()] = append(perGroup[m.ProcessGroup()], v*100)
+	}
+
+	return lo.MapValues(perGroup, func(loads []float64, _ string) float64 {
+		return lo.Sum(loads) / float64(len(loads))
+	}), nil
+}
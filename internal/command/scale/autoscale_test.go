@@ -0,0 +1,49 @@
+package scale
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_autoscaleDesiredCount(t *testing.T) {
+	policy := autoscalePolicy{ProcessGroup: "web", Metric: "cpu", Target: 70, Min: 2, Max: 10}
+
+	testcases := []struct {
+		name    string
+		current int
+		load    float64
+		hasLoad bool
+		want    int
+	}{
+		{name: "within tolerance", current: 4, load: 75, hasLoad: true, want: 4},
+		{name: "scale out", current: 4, load: 140, hasLoad: true, want: 8},
+		{name: "scale in", current: 6, load: 35, hasLoad: true, want: 3},
+		{name: "capped at max", current: 8, load: 200, hasLoad: true, want: 10},
+		{name: "floored at min", current: 3, load: 5, hasLoad: true, want: 2},
+		{name: "no load data", current: 1, hasLoad: false, want: 2},
+		{name: "no machines", current: 0, load: 0, hasLoad: false, want: 2},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, autoscaleDesiredCount(policy, tc.current, tc.load, tc.hasLoad))
+		})
+	}
+}
+
+func Test_autoscalePolicy_validate(t *testing.T) {
+	valid := autoscalePolicy{ProcessGroup: "web", Metric: "memory", Target: 80, Min: 0, Max: 3}
+	assert.NoError(t, valid.validate())
+
+	for _, p := range []autoscalePolicy{
+		{Metric: "disk", Target: 70, Min: 1, Max: 3},
+		{Metric: "cpu", Target: 0, Min: 1, Max: 3},
+		{Metric: "cpu", Target: 101, Min: 1, Max: 3},
+		{Metric: "cpu", Target: 70, Min: -1, Max: 3},
+		{Metric: "cpu", Target: 70, Min: 0, Max: 0},
+		{Metric: "cpu", Target: 70, Min: 4, Max: 3},
+	} {
+		assert.Error(t, p.validate(), "%+v", p)
+	}
+}
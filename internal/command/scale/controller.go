@@ -0,0 +1,61 @@
+package scale
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/fly-go/flaps"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flapsutil"
+	mach "github.com/superfly/flyctl/internal/machine"
+)
+
+// Controller machines of scale keep their configuration as JSON in their own
+// metadata, so it lives with the app rather than on a workstation.
+
+func controllerContext(ctx context.Context, appName string) (context.Context, error) {
+	flapsClient, err := flapsutil.NewClientWithOptions(ctx, flaps.NewClientOpts{
+		AppName: appName,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return flapsutil.NewContextWithClient(ctx, flapsClient), nil
+}
+
+func getControllerMetadata(controller *fly.Machine, key string, v any) error {
+	raw := controller.GetMetadataByKey(key)
+	if raw == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(raw), v); err != nil {
+		return fmt.Errorf("invalid %s on machine %s: %w", key, controller.ID, err)
+	}
+	return nil
+}
+
+func setControllerMetadata(ctx context.Context, controller *fly.Machine, key string, v any) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return flapsutil.ClientFromContext(ctx).SetMetadata(ctx, controller.ID, key, string(raw))
+}
+
+// launchControllerMachine launches a controller machine in the region and
+// with the image given by the flags.
+func launchControllerMachine(ctx context.Context, appName string, spec mach.ControllerSpec) (*fly.Machine, error) {
+	spec.Region = flag.GetRegion(ctx)
+	spec.Image = flag.GetString(ctx, "image")
+	return mach.LaunchController(ctx, appName, spec)
+}
+
+func destroyControllerMachine(ctx context.Context, controller *fly.Machine) error {
+	input := fly.RemoveMachineInput{ID: controller.ID, Kill: true}
+	if err := flapsutil.ClientFromContext(ctx).Destroy(ctx, input, ""); err != nil {
+		return fmt.Errorf("could not destroy machine %s: %w", controller.ID, err)
+	}
+	return nil
+}
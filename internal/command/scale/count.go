@@ -24,6 +24,14 @@ func newScaleCount() *cobra.Command {
 		short = "Change an app's VM count to the given value"
 		long  = `Change an app's VM count to the given value.
 
+New machines of a group with [mounts] are attached to the group's unattached
+volumes first. Volumes are created for the remaining ones after confirmation,
+with the size given by --volume-size and restored from --from-snapshot if set.
+
+Machines are destroyed right away when scaling down. With --drain-timeout they
+are first cordoned so the proxy stops sending them requests, and destroyed
+once their in-flight connections are done or the timeout expires.
+
 For pricing, see https://fly.io/docs/about/pricing/`
 	)
 	cmd := command.New("count [count]", short, long, runScaleCount,
@@ -37,9 +45,13 @@ For pricing, see https://fly.io/docs/about/pricing/`
 		flag.Yes(),
 		flag.ProcessGroup("The process group to scale"),
 		flag.Int{Name: "max-per-region", Description: "Max number of VMs per region", Default: -1},
+		flag.String{Name: "balance", Description: "How to place machines across regions: 'spread' evens them out, 'pack' fills the fewest regions, 'copy-existing' keeps the current distribution"},
 		flag.String{Name: "region", Shorthand: "r", Description: "Comma separated list of regions to act on. Defaults to all regions where there is at least one machine running for the app", CompletionFn: completion.CompleteRegions},
 		flag.Bool{Name: "with-new-volumes", Description: "New machines each get a new volumes even if there are unattached volumes available"},
 		flag.String{Name: "from-snapshot", Description: "New volumes are restored from snapshot, use 'last' for most recent snapshot. The default is an empty volume"},
+		flag.Int{Name: "volume-size", Description: "Size in GB of new volumes. The default is the size of the group's existing volumes"},
+		flag.Bool{Name: "dry-run", Description: "Print the machines that would be created and destroyed without changing anything"},
+		flag.Duration{Name: "drain-timeout", Description: "When scaling down, how long to wait for the connections of cordoned machines to finish before destroying them, e.g. 30s or 5m"},
 		flag.VMSizeFlags,
 		flag.Env(),
 	)
@@ -90,7 +102,12 @@ func runScaleCount(ctx context.Context) error {
 
 	maxPerRegion := flag.GetInt(ctx, "max-per-region")
 
-	return runMachinesScaleCount(ctx, appName, appConfig, groups, maxPerRegion)
+	balance, err := parseBalanceStrategy(flag.GetString(ctx, "balance"))
+	if err != nil {
+		return err
+	}
+
+	return runMachinesScaleCount(ctx, appName, appConfig, groups, maxPerRegion, balance)
 }
 
 type groupCount struct{ absolute, relative int }
@@ -0,0 +1,225 @@
+package scale
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+	fly "github.com/superfly/fly-go"
+)
+
+// balanceStrategy decides in which regions machines are added or removed
+// when scaling a group.
+type balanceStrategy string
+
+const (
+	// balanceDefault adds machines round robin in region order and removes
+	// them in reverse region order.
+	balanceDefault balanceStrategy = ""
+	// balanceSpread evens out the number of machines across regions.
+	balanceSpread balanceStrategy = "spread"
+	// balancePack fills the fewest regions possible.
+	balancePack balanceStrategy = "pack"
+	// balanceCopyExisting keeps the current proportion of machines in each
+	// region.
+	balanceCopyExisting balanceStrategy = "copy-existing"
+)
+
+var balanceStrategies = []balanceStrategy{balanceSpread, balancePack, balanceCopyExisting}
+
+func parseBalanceStrategy(s string) (balanceStrategy, error) {
+	if s == "" {
+		return balanceDefault, nil
+	}
+	for _, b := range balanceStrategies {
+		if string(b) == s {
+			return b, nil
+		}
+	}
+
+	names := make([]string, 0, len(balanceStrategies))
+	for _, b := range balanceStrategies {
+		names = append(names, string(b))
+	}
+	return "", fmt.Errorf("invalid balance strategy %q, options are %s", s, strings.Join(names, ", "))
+}
+
+// balanceGroupCounts returns how many machines to add or remove in each of
+// regions so the group has expectedTotal machines placed per balance.
+func balanceGroupCounts(balance balanceStrategy, expectedTotal int, current map[string]int, regions []string, maxPerRegion int) (map[string]int, error) {
+	switch balance {
+	case balanceSpread, balancePack:
+		return convergeGroupCountsBy(balance, expectedTotal, current, regions, maxPerRegion)
+	case balanceCopyExisting:
+		return copyExistingGroupCounts(expectedTotal, current, regions, maxPerRegion)
+	default:
+		return convergeGroupCounts(expectedTotal, current, regions, maxPerRegion)
+	}
+}
+
+// convergeGroupCountsBy adds or removes one machine at a time, picking the
+// region with the fewest machines to add to and the most to remove from when
+// spreading, and the opposite when packing. Ties go to the earliest region
+// when adding and the latest when removing, since the primary region tends to
+// be listed first.
+func convergeGroupCountsBy(balance balanceStrategy, expectedTotal int, current map[string]int, regions []string, maxPerRegion int) (map[string]int, error) {
+	if len(regions) == 0 {
+		regions = sortedRegions(current)
+	}
+
+	if maxPerRegion >= 0 && len(regions)*maxPerRegion < expectedTotal {
+		return nil, MaxPerRegionError
+	}
+
+	counts := make(map[string]int, len(regions))
+	total := 0
+	for _, region := range regions {
+		counts[region] = current[region]
+		if maxPerRegion >= 0 && counts[region] > maxPerRegion {
+			counts[region] = maxPerRegion
+		}
+		total += counts[region]
+	}
+
+	// better reports whether a is a better pick than b for the next machine
+	better := func(a, b int, adding bool) bool {
+		if (balance == balanceSpread) == adding {
+			return a < b
+		}
+		return a > b
+	}
+
+	for ; total < expectedTotal; total++ {
+		pick := ""
+		for _, region := range regions {
+			if maxPerRegion >= 0 && counts[region] >= maxPerRegion {
+				continue
+			}
+			if pick == "" || better(counts[region], counts[pick], true) {
+				pick = region
+			}
+		}
+		counts[pick]++
+	}
+
+	for ; total > expectedTotal; total-- {
+		pick := ""
+		for i := len(regions) - 1; i >= 0; i-- {
+			region := regions[i]
+			if counts[region] == 0 {
+				continue
+			}
+			if pick == "" || better(counts[region], counts[pick], false) {
+				pick = region
+			}
+		}
+		counts[pick]--
+	}
+
+	return countDiffs(counts, current), nil
+}
+
+// copyExistingGroupCounts keeps the proportion of machines in each region,
+// rounding with the largest remainder. Groups without machines in any of
+// regions are spread instead.
+func copyExistingGroupCounts(expectedTotal int, current map[string]int, regions []string, maxPerRegion int) (map[string]int, error) {
+	if len(regions) == 0 {
+		regions = sortedRegions(current)
+	}
+
+	currentTotal := 0
+	for _, region := range regions {
+		currentTotal += current[region]
+	}
+	if currentTotal == 0 {
+		return convergeGroupCountsBy(balanceSpread, expectedTotal, current, regions, maxPerRegion)
+	}
+
+	type share struct {
+		region    string
+		remainder int
+	}
+
+	counts := make(map[string]int, len(regions))
+	shares := make([]share, 0, len(regions))
+	assigned := 0
+	for _, region := range regions {
+		scaled := current[region] * expectedTotal
+		counts[region] = scaled / currentTotal
+		assigned += counts[region]
+		shares = append(shares, share{region: region, remainder: scaled % currentTotal})
+	}
+
+	sort.SliceStable(shares, func(i, j int) bool {
+		return shares[i].remainder > shares[j].remainder
+	})
+	for i := 0; assigned < expectedTotal; i++ {
+		counts[shares[i].region]++
+		assigned++
+	}
+
+	if maxPerRegion >= 0 {
+		for _, region := range regions {
+			if counts[region] > maxPerRegion {
+				return nil, fmt.Errorf("copying the current distribution needs %d machines in region %s, more than the maximum of %d per region", counts[region], region, maxPerRegion)
+			}
+		}
+	}
+
+	return countDiffs(counts, current), nil
+}
+
+// placementSummary describes how many machines each changed group ends up
+// with per region once actions are applied, e.g. "app: iad=2 ord=1".
+func placementSummary(machines []*fly.Machine, actions []*planItem) []string {
+	totals := make(map[string]map[string]int)
+	for _, action := range actions {
+		if totals[action.GroupName] == nil {
+			totals[action.GroupName] = make(map[string]int)
+		}
+		totals[action.GroupName][action.Region] += action.Delta
+	}
+	for _, m := range machines {
+		if counts, ok := totals[m.ProcessGroup()]; ok {
+			counts[m.Region]++
+		}
+	}
+
+	groups := lo.Keys(totals)
+	sort.Strings(groups)
+
+	lines := make([]string, 0, len(groups))
+	for _, group := range groups {
+		parts := []string{}
+		for _, region := range sortedRegions(totals[group]) {
+			if totals[group][region] > 0 {
+				parts = append(parts, fmt.Sprintf("%s=%d", region, totals[group][region]))
+			}
+		}
+		if len(parts) == 0 {
+			parts = append(parts, "no machines")
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", group, strings.Join(parts, " ")))
+	}
+	return lines
+}
+
+func countDiffs(counts, current map[string]int) map[string]int {
+	diffs := make(map[string]int)
+	for region, count := range counts {
+		if delta := count - current[region]; delta != 0 {
+			diffs[region] = delta
+		}
+	}
+	return diffs
+}
+
+func sortedRegions(counts map[string]int) []string {
+	regions := make([]string, 0, len(counts))
+	for region := range counts {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	return regions
+}
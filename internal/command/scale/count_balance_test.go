@@ -0,0 +1,135 @@
+package scale
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_balanceGroupCounts(t *testing.T) {
+	testcases := []struct {
+		name          string
+		balance       balanceStrategy
+		want          map[string]int
+		expectedTotal int
+		current       map[string]int
+		regions       []string
+		maxPerRegion  int
+	}{
+		{
+			name:          "Spread evens out unbalanced regions",
+			balance:       balanceSpread,
+			want:          map[string]int{"iad": 3},
+			current:       map[string]int{"scl": 3},
+			expectedTotal: 6,
+			regions:       []string{"scl", "iad"},
+			maxPerRegion:  -1,
+		},
+		{
+			name:          "Spread removes from the largest regions",
+			balance:       balanceSpread,
+			want:          map[string]int{"scl": -3},
+			current:       map[string]int{"scl": 5, "iad": 2},
+			expectedTotal: 4,
+			regions:       []string{"scl", "iad"},
+			maxPerRegion:  -1,
+		},
+		{
+			name:          "Spread respects max per region",
+			balance:       balanceSpread,
+			want:          map[string]int{"scl": 2, "iad": 2, "ord": 1},
+			expectedTotal: 5,
+			regions:       []string{"scl", "iad", "ord"},
+			maxPerRegion:  2,
+		},
+		{
+			name:          "Pack fills the first region",
+			balance:       balancePack,
+			want:          map[string]int{"scl": 4},
+			expectedTotal: 4,
+			regions:       []string{"scl", "iad"},
+			maxPerRegion:  -1,
+		},
+		{
+			name:          "Pack adds to the largest region",
+			balance:       balancePack,
+			want:          map[string]int{"iad": 2},
+			current:       map[string]int{"scl": 1, "iad": 2},
+			expectedTotal: 5,
+			regions:       []string{"scl", "iad"},
+			maxPerRegion:  -1,
+		},
+		{
+			name:          "Pack overflows into the next region at max per region",
+			balance:       balancePack,
+			want:          map[string]int{"scl": 3, "iad": 2},
+			expectedTotal: 5,
+			regions:       []string{"scl", "iad"},
+			maxPerRegion:  3,
+		},
+		{
+			name:          "Pack empties the smallest regions first",
+			balance:       balancePack,
+			want:          map[string]int{"iad": -1, "scl": -1},
+			current:       map[string]int{"scl": 3, "iad": 1},
+			expectedTotal: 2,
+			regions:       []string{"scl", "iad"},
+			maxPerRegion:  -1,
+		},
+		{
+			name:          "Copy existing keeps proportions",
+			balance:       balanceCopyExisting,
+			want:          map[string]int{"scl": 2, "iad": 1},
+			current:       map[string]int{"scl": 2, "iad": 1},
+			expectedTotal: 6,
+			regions:       []string{"scl", "iad", "ord"},
+			maxPerRegion:  -1,
+		},
+		{
+			name:          "Copy existing rounds by largest remainder",
+			balance:       balanceCopyExisting,
+			want:          map[string]int{"scl": 1, "iad": 1},
+			current:       map[string]int{"scl": 2, "iad": 1},
+			expectedTotal: 5,
+			regions:       []string{"scl", "iad"},
+			maxPerRegion:  -1,
+		},
+		{
+			name:          "Copy existing spreads groups without machines",
+			balance:       balanceCopyExisting,
+			want:          map[string]int{"scl": 2, "iad": 1},
+			expectedTotal: 3,
+			regions:       []string{"scl", "iad"},
+			maxPerRegion:  -1,
+		},
+		{
+			name:          "Default strategy",
+			balance:       balanceDefault,
+			want:          map[string]int{"scl": 1},
+			current:       map[string]int{"scl": 1, "iad": 1},
+			expectedTotal: 3,
+			regions:       []string{"scl", "iad"},
+			maxPerRegion:  -1,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := balanceGroupCounts(tc.balance, tc.expectedTotal, tc.current, tc.regions, tc.maxPerRegion)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func Test_balanceGroupCounts_errors(t *testing.T) {
+	_, err := balanceGroupCounts(balanceSpread, 10, nil, []string{"scl", "mia"}, 1)
+	assert.Equal(t, MaxPerRegionError, err)
+
+	_, err = balanceGroupCounts(balanceCopyExisting, 4, map[string]int{"scl": 3, "iad": 1}, []string{"scl", "iad"}, 2)
+	assert.Error(t, err)
+
+	_, err = parseBalanceStrategy("random")
+	assert.Error(t, err)
+}
@@ -0,0 +1,136 @@
+package scale
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/samber/lo"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/flyctl/internal/flapsutil"
+	"github.com/superfly/flyctl/internal/flyutil"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+// drainPollInterval is how often the connections of draining machines are
+// checked. The proxy metrics are scraped every 15s or so, so polling faster
+// doesn't help.
+const drainPollInterval = 10 * time.Second
+
+// drainLeaseTTL is the lease TTL, in seconds, kept on draining machines. The
+// leases are refreshed on every poll so they outlive long drain timeouts.
+const drainLeaseTTL = 120
+
+// drainMachines cordons machines so the proxy stops sending them new requests,
+// then waits until their in-flight connections are done or timeout is reached.
+// The machines are left for the caller to destroy, and the cordoned ones are
+// returned, also on errors, for the caller to uncordon those it doesn't
+// destroy, see uncordonMachines.
+func drainMachines(ctx context.Context, appName string, machines []*fly.Machine, timeout time.Duration) (cordoned []*fly.Machine, err error) {
+	io := iostreams.FromContext(ctx)
+	flapsClient := flapsutil.ClientFromContext(ctx)
+
+	// Machines on unreachable hosts can't be cordoned nor serve requests
+	machines = lo.Filter(machines, func(m *fly.Machine, _ int) bool {
+		return m.HostStatus == fly.HostStatusOk
+	})
+	if len(machines) == 0 {
+		return nil, nil
+	}
+
+	for _, m := range machines {
+		if err := flapsClient.Cordon(ctx, m.ID, m.LeaseNonce); err != nil {
+			return cordoned, fmt.Errorf("could not cordon machine %s: %w", m.ID, err)
+		}
+		cordoned = append(cordoned, m)
+		fmt.Fprintf(io.Out, "  Cordoned %s group:%s region:%s\n", m.ID, m.ProcessGroup(), m.Region)
+	}
+
+	app, err := flyutil.ClientFromContext(ctx).GetAppCompact(ctx, appName)
+	if err != nil {
+		return cordoned, err
+	}
+	query := fmt.Sprintf(`sum by (instance) (fly_app_concurrency{app=%q})`, appName)
+
+	fmt.Fprintf(io.Out, "Waiting up to %s for in-flight connections to finish\n", timeout)
+	deadline := time.Now().Add(timeout)
+	// Once the connections can't be checked, the drain timeout is waited out
+	// while still refreshing the leases
+	waitOut := false
+	for {
+		// Give the proxy metrics time to catch up with the cordon before
+		// the first check
+		next := time.Now().Add(drainPollInterval)
+		if next.After(deadline) {
+			next = deadline
+		}
+		if err := sleepUntil(ctx, next); err != nil {
+			return cordoned, err
+		}
+
+		for _, m := range machines {
+			if _, err := flapsClient.RefreshLease(ctx, m.ID, fly.IntPointer(drainLeaseTTL), m.LeaseNonce); err != nil {
+				return cordoned, fmt.Errorf("could not refresh lease on machine %s: %w", m.ID, err)
+			}
+		}
+
+		if waitOut {
+			if !time.Now().Before(deadline) {
+				return cordoned, nil
+			}
+			continue
+		}
+
+		connections, err := queryPrometheus(ctx, app.Organization.Slug, query)
+		if err != nil {
+			fmt.Fprintf(io.ErrOut, "Could not check in-flight connections, waiting out the drain timeout: %v\n", err)
+			waitOut = true
+			continue
+		}
+
+		busy := lo.Filter(machines, func(m *fly.Machine, _ int) bool {
+			return connections[m.ID] > 0
+		})
+		if len(busy) == 0 {
+			fmt.Fprintf(io.Out, "All connections drained\n")
+			return cordoned, nil
+		}
+		if !time.Now().Before(deadline) {
+			open := lo.SumBy(busy, func(m *fly.Machine) float64 { return connections[m.ID] })
+			fmt.Fprintf(io.ErrOut, "Drain timeout reached with %.0f connections still open on %d machines\n", open, len(busy))
+			return cordoned, nil
+		}
+		fmt.Fprintf(io.Out, "  %d machines still have connections open\n", len(busy))
+	}
+}
+
+// uncordonMachines uncordons the machines left cordoned by drainMachines that
+// weren't destroyed, so the proxy routes requests to them again. It uses a
+// context of its own, as ctx may be canceled by then.
+func uncordonMachines(ctx context.Context, machines []*fly.Machine) {
+	io := iostreams.FromContext(ctx)
+	flapsClient := flapsutil.ClientFromContext(ctx)
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+
+	for _, m := range machines {
+		if err := flapsClient.Uncordon(ctx, m.ID, m.LeaseNonce); err != nil {
+			fmt.Fprintf(io.ErrOut, "Could not uncordon machine %s, uncordon it with 'fly machine uncordon %s': %v\n", m.ID, m.ID, err)
+			continue
+		}
+		fmt.Fprintf(io.Out, "  Uncordoned %s group:%s region:%s\n", m.ID, m.ProcessGroup(), m.Region)
+	}
+}
+
+func sleepUntil(ctx context.Context, t time.Time) error {
+	timer := time.NewTimer(time.Until(t))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
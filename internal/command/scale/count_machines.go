@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/samber/lo"
 	"github.com/sourcegraph/conc/pool"
@@ -20,7 +21,7 @@ import (
 	"github.com/superfly/flyctl/iostreams"
 )
 
-func runMachinesScaleCount(ctx context.Context, appName string, appConfig *appconfig.Config, expectedGroupCounts groupCounts, maxPerRegion int) error {
+func runMachinesScaleCount(ctx context.Context, appName string, appConfig *appconfig.Config, expectedGroupCounts groupCounts, maxPerRegion int, balance balanceStrategy) error {
 	io := iostreams.FromContext(ctx)
 	flapsClient := flapsutil.ClientFromContext(ctx)
 	ctx = appconfig.WithConfig(ctx, appConfig)
@@ -64,8 +65,11 @@ func runMachinesScaleCount(ctx context.Context, appName string, appConfig *appco
 
 	defaults := newDefaults(appConfig, latestCompleteRelease, machines, volumes,
 		flag.GetString(ctx, "from-snapshot"), flag.GetBool(ctx, "with-new-volumes"), defaultGuest)
+	if flag.IsSpecified(ctx, "volume-size") {
+		defaults.volumeSize = flag.GetInt(ctx, "volume-size")
+	}
 
-	actions, err := computeActions(machines, expectedGroupCounts, regions, maxPerRegion, defaults)
+	actions, err := computeActions(machines, expectedGroupCounts, regions, maxPerRegion, balance, defaults)
 	if err != nil {
 		return err
 	}
@@ -103,6 +107,46 @@ func runMachinesScaleCount(ctx context.Context, appName string, appConfig *appco
 		case volumesToCreate > 0:
 			fmt.Fprintf(io.Out, "%+4d volumes  for group '%s' in region '%s'\n", volumesToCreate, action.GroupName, action.Region)
 		}
+		if cvr := action.CreateVolumeRequest; volumesToCreate > 0 {
+			fmt.Fprintf(io.Out, "     new volumes are named '%s' with %dGB", cvr.Name, *cvr.SizeGb)
+			if cvr.SnapshotID != nil {
+				fmt.Fprintf(io.Out, " restored from snapshot %s", *cvr.SnapshotID)
+			}
+			fmt.Fprintln(io.Out)
+		}
+	}
+
+	dryRun := flag.GetBool(ctx, "dry-run")
+	if volumesToCreate := lo.SumBy(actions, (*planItem).VolumesDelta); volumesToCreate > 0 && !flag.GetYes(ctx) && !dryRun {
+		switch confirmed, err := prompt.Confirmf(ctx, "Create %d new volumes? If not, only machines that can use an unattached volume are created", volumesToCreate); {
+		case err == nil:
+			if !confirmed {
+				actions = withoutNewVolumes(actions)
+				if len(actions) == 0 {
+					fmt.Fprintf(io.Out, "No machines can be created without new volumes\n")
+					return nil
+				}
+			}
+		case prompt.IsNonInteractive(err):
+			return prompt.NonInteractiveError("--yes flag must be specified when not running interactively")
+		default:
+			return err
+		}
+	}
+
+	if balance != balanceDefault {
+		fmt.Fprintf(io.Out, "Resulting placement with the '%s' strategy:\n", balance)
+		for _, line := range placementSummary(machines, actions) {
+			fmt.Fprintf(io.Out, "  %s\n", line)
+		}
+	}
+
+	if dryRun {
+		fmt.Fprintf(io.Out, "Dry run, no changes made. The plan would:\n")
+		for _, line := range dryRunPlan(actions) {
+			fmt.Fprintf(io.Out, "  %s\n", line)
+		}
+		return nil
 	}
 
 	if !flag.GetYes(ctx) {
@@ -142,6 +186,11 @@ func runMachinesScaleCount(ctx context.Context, appName string, appConfig *appco
 		WithMaxGoroutines(maxConcurrentActions).
 		WithContext(ctx)
 
+	// With a drain timeout the machines to remove are only destroyed once the
+	// new ones are up and the old ones have finished their requests
+	drainTimeout := flag.GetDuration(ctx, "drain-timeout")
+	var toDrain []*fly.Machine
+
 	fmt.Fprintf(io.Out, "Executing scale plan\n")
 	for _, action := range actions {
 		action := action
@@ -164,22 +213,61 @@ func runMachinesScaleCount(ctx context.Context, appName string, appConfig *appco
 					return nil
 				})
 			}
+		case action.Delta < 0 && drainTimeout > 0:
+			toDrain = append(toDrain, action.Machines[:-action.Delta]...)
 		case action.Delta < 0:
 			for i := 0; i > action.Delta; i-- {
 				updatePool.Go(func(ctx context.Context) error {
-					m := action.Machines[-i]
-					err := destroyMachine(ctx, m)
-					if err != nil {
-						return err
-					}
-					fmt.Fprintf(io.Out, "  Destroyed %s group:%s region:%s size:%s\n", m.ID, action.GroupName, action.Region, m.Config.Guest.ToSize())
-					return nil
+					return destroyScaledMachine(ctx, action.Machines[-i])
 				})
 			}
 		}
 	}
 
-	return updatePool.Wait()
+	if err := updatePool.Wait(); err != nil || len(toDrain) == 0 {
+		return err
+	}
+
+	// Machines left cordoned by a failed or interrupted drain would stop
+	// getting requests for good
+	var (
+		destroyedMu sync.Mutex
+		destroyed   = map[string]bool{}
+	)
+	cordoned, err := drainMachines(ctx, appName, toDrain, drainTimeout)
+	defer func() {
+		uncordonMachines(ctx, lo.Filter(cordoned, func(m *fly.Machine, _ int) bool {
+			return !destroyed[m.ID]
+		}))
+	}()
+	if err != nil {
+		return err
+	}
+
+	destroyPool := pool.New().
+		WithErrors().
+		WithMaxGoroutines(5).
+		WithContext(ctx)
+	for _, m := range toDrain {
+		destroyPool.Go(func(ctx context.Context) error {
+			if err := destroyScaledMachine(ctx, m); err != nil {
+				return err
+			}
+			destroyedMu.Lock()
+			destroyed[m.ID] = true
+			destroyedMu.Unlock()
+			return nil
+		})
+	}
+	return destroyPool.Wait()
+}
+
+func destroyScaledMachine(ctx context.Context, m *fly.Machine) error {
+	if err := destroyMachine(ctx, m); err != nil {
+		return err
+	}
+	fmt.Fprintf(iostreams.FromContext(ctx).Out, "  Destroyed %s group:%s region:%s size:%s\n", m.ID, m.ProcessGroup(), m.Region, m.Config.Guest.ToSize())
+	return nil
 }
 
 func launchMachine(ctx context.Context, action *planItem, idx int) (*fly.Machine, error) {
@@ -220,6 +308,63 @@ func launchMachine(ctx context.Context, action *planItem, idx int) (*fly.Machine
 	return flapsClient.Launch(ctx, input)
 }
 
+// dryRunPlan lists every machine actions would create or destroy, along with
+// its guest and volume.
+func dryRunPlan(actions []*planItem) []string {
+	var lines []string
+	for _, action := range actions {
+		for i := 0; i < action.Delta; i++ {
+			config := action.LaunchMachineInput.Config
+			line := fmt.Sprintf("create machine group:%s region:%s size:%s cpus:%d memory:%dMB",
+				action.GroupName, action.Region, config.Guest.ToSize(), config.Guest.CPUs, config.Guest.MemoryMB)
+
+			if len(config.Mounts) > 0 {
+				mount := config.Mounts[0]
+				switch {
+				case i < len(action.Volumes):
+					line += fmt.Sprintf(" volume:%s (existing) at %s", action.Volumes[i].ID, mount.Path)
+				case action.CreateVolumeRequest != nil:
+					cvr := action.CreateVolumeRequest
+					line += fmt.Sprintf(" volume:%s (new", cvr.Name)
+					if cvr.SizeGb != nil {
+						line += fmt.Sprintf(", %dGB", *cvr.SizeGb)
+					}
+					if cvr.SnapshotID != nil {
+						line += fmt.Sprintf(", from snapshot %s", *cvr.SnapshotID)
+					}
+					line += fmt.Sprintf(") at %s", mount.Path)
+				default:
+					line += fmt.Sprintf(" volume:none available for %s", mount.Path)
+				}
+			}
+			lines = append(lines, line)
+		}
+
+		for _, m := range action.Machines[:max(-action.Delta, 0)] {
+			line := fmt.Sprintf("destroy machine %s group:%s region:%s size:%s state:%s",
+				m.ID, action.GroupName, action.Region, m.Config.Guest.ToSize(), m.State)
+			if len(m.Config.Mounts) > 0 {
+				line += fmt.Sprintf(" volume:%s (kept, left unattached)", m.Config.Mounts[0].Volume)
+			}
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// withoutNewVolumes trims the machines to create to the unattached volumes
+// available for them, dropping the actions left with nothing to do.
+func withoutNewVolumes(actions []*planItem) []*planItem {
+	return lo.FilterMap(actions, func(action *planItem, _ int) (*planItem, bool) {
+		if action.CreateVolumeRequest == nil {
+			return action, true
+		}
+		action.Delta = len(action.Volumes)
+		action.CreateVolumeRequest = nil
+		return action, action.Delta > 0
+	})
+}
+
 func destroyMachine(ctx context.Context, machine *fly.Machine) error {
 	flapsClient := flapsutil.ClientFromContext(ctx)
 	input := fly.RemoveMachineInput{
@@ -262,7 +407,7 @@ func (pi *planItem) MachineSize() string {
 	return ""
 }
 
-func computeActions(machines []*fly.Machine, expectedGroupCounts groupCounts, regions []string, maxPerRegion int, defaults *defaultValues) ([]*planItem, error) {
+func computeActions(machines []*fly.Machine, expectedGroupCounts groupCounts, regions []string, maxPerRegion int, balance balanceStrategy, defaults *defaultValues) ([]*planItem, error) {
 	actions := make([]*planItem, 0)
 	seenGroups := make(map[string]bool)
 	machineGroups := lo.GroupBy(machines, func(m *fly.Machine) string {
@@ -292,7 +437,7 @@ func computeActions(machines []*fly.Machine, expectedGroupCounts groupCounts, re
 			return k, len(v)
 		})
 
-		regionDiffs, err := convergeGroupCounts(expected, currentPerRegionCount, regions, maxPerRegion)
+		regionDiffs, err := balanceGroupCounts(balance, expected, currentPerRegionCount, regions, maxPerRegion)
 		if err != nil {
 			return nil, err
 		}
@@ -326,7 +471,7 @@ func computeActions(machines []*fly.Machine, expectedGroupCounts groupCounts, re
 			return nil, err
 		}
 
-		regionDiffs, err := convergeGroupCounts(expected, nil, regions, maxPerRegion)
+		regionDiffs, err := balanceGroupCounts(balance, expected, nil, regions, maxPerRegion)
 		if err != nil {
 			return nil, err
 		}
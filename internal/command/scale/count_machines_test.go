@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	fly "github.com/superfly/fly-go"
 )
 
 func Test_convergeGroupCounts(t *testing.T) {
@@ -137,3 +138,69 @@ func Test_convergeGroupCounts_maxPerRegion(t *testing.T) {
 		})
 	}
 }
+
+func Test_withoutNewVolumes(t *testing.T) {
+	vol := &fly.Volume{ID: "vol_1"}
+	actions := []*planItem{
+		{GroupName: "app", Region: "iad", Delta: 3, Volumes: []*fly.Volume{vol}, CreateVolumeRequest: &fly.CreateVolumeRequest{}},
+		{GroupName: "app", Region: "ord", Delta: 2, CreateVolumeRequest: &fly.CreateVolumeRequest{}},
+		{GroupName: "worker", Region: "iad", Delta: 1},
+		{GroupName: "worker", Region: "ord", Delta: -1},
+	}
+
+	got := withoutNewVolumes(actions)
+	assert.Len(t, got, 3)
+	assert.Equal(t, 1, got[0].Delta)
+	assert.Nil(t, got[0].CreateVolumeRequest)
+	assert.Equal(t, "worker", got[1].GroupName)
+	assert.Equal(t, -1, got[2].Delta)
+}
+
+func Test_CreateVolumeRequest_size(t *testing.T) {
+	mConfig := &fly.MachineConfig{Mounts: []fly.MachineMount{{Name: "data", SizeGb: 3}}}
+
+	d := &defaultValues{volsize: 1}
+	assert.Equal(t, 3, *d.CreateVolumeRequest(mConfig, "iad", 1).SizeGb)
+
+	d.volumeSize = 10
+	assert.Equal(t, 10, *d.CreateVolumeRequest(mConfig, "iad", 1).SizeGb)
+	assert.Equal(t, 3, mConfig.Mounts[0].SizeGb)
+
+	d = &defaultValues{volsize: 1, volsizeByName: map[string]int{"data": 5}}
+	mConfig.Mounts[0].SizeGb = 0
+	assert.Equal(t, 5, *d.CreateVolumeRequest(mConfig, "iad", 1).SizeGb)
+
+	assert.Nil(t, d.CreateVolumeRequest(mConfig, "iad", 0))
+}
+
+func Test_dryRunPlan(t *testing.T) {
+	guest := &fly.MachineGuest{}
+	guest.SetSize("shared-cpu-1x")
+	config := &fly.MachineConfig{Guest: guest, Mounts: []fly.MachineMount{{Path: "/data"}}}
+
+	actions := []*planItem{
+		{
+			GroupName:           "app",
+			Region:              "iad",
+			Delta:               2,
+			LaunchMachineInput:  &fly.LaunchMachineInput{Config: config},
+			Volumes:             []*fly.Volume{{ID: "vol_1"}},
+			CreateVolumeRequest: &fly.CreateVolumeRequest{Name: "data", SizeGb: fly.Pointer(3)},
+		},
+		{
+			GroupName: "app",
+			Region:    "ord",
+			Delta:     -1,
+			Machines: []*fly.Machine{
+				{ID: "m1", State: "started", Config: &fly.MachineConfig{Guest: guest, Mounts: []fly.MachineMount{{Volume: "vol_2"}}}},
+				{ID: "m2", State: "started", Config: &fly.MachineConfig{Guest: guest}},
+			},
+		},
+	}
+
+	assert.Equal(t, []string{
+		"create machine group:app region:iad size:shared-cpu-1x cpus:1 memory:256MB volume:vol_1 (existing) at /data",
+		"create machine group:app region:iad size:shared-cpu-1x cpus:1 memory:256MB volume:data (new, 3GB) at /data",
+		"destroy machine m1 group:app region:ord size:shared-cpu-1x state:started volume:vol_2 (kept, left unattached)",
+	}, dryRunPlan(actions))
+}
@@ -15,6 +15,7 @@ type defaultValues struct {
 	guestPerGroup   map[string]*fly.MachineGuest
 	volsize         int
 	volsizeByName   map[string]int
+	volumeSize      int
 	releaseId       string
 	releaseVersion  string
 	appConfig       *appconfig.Config
@@ -123,6 +124,12 @@ func (d *defaultValues) CreateVolumeRequest(mConfig *fly.MachineConfig, region s
 		return nil
 	}
 	mount := mConfig.Mounts[0]
+	switch {
+	case d.volumeSize > 0:
+		mount.SizeGb = d.volumeSize
+	case mount.SizeGb == 0:
+		mount.SizeGb = lo.ValueOr(d.volsizeByName, mount.Name, d.volsize)
+	}
 	return &fly.CreateVolumeRequest{
 		Name:                mount.Name,
 		Region:              region,
@@ -3,16 +3,20 @@ package scale
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/samber/lo"
 	fly "github.com/superfly/fly-go"
 	"github.com/superfly/fly-go/flaps"
 	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/flag"
 	"github.com/superfly/flyctl/internal/flapsutil"
 	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/prompt"
+	"github.com/superfly/flyctl/iostreams"
 )
 
-func v2ScaleVM(ctx context.Context, appName, group, sizeName string, memoryMB int) (*fly.VMSize, error) {
+func v2ScaleVM(ctx context.Context, appName, group string, vm groupVM) (*fly.MachineGuest, error) {
 	flapsClient, err := flapsutil.NewClientWithOptions(ctx, flaps.NewClientOpts{
 		AppName: appName,
 	})
@@ -21,8 +25,8 @@ func v2ScaleVM(ctx context.Context, appName, group, sizeName string, memoryMB in
 	}
 	ctx = flapsutil.NewContextWithClient(ctx, flapsClient)
 
-	// Quickly validate sizeName before any network call
-	if err := (&fly.MachineGuest{}).SetSize(sizeName); err != nil && sizeName != "" {
+	// Quickly validate the size before any network call
+	if err := (&fly.MachineGuest{}).SetSize(vm.size); err != nil && vm.size != "" {
 		return nil, err
 	}
 
@@ -44,6 +48,12 @@ func v2ScaleVM(ctx context.Context, appName, group, sizeName string, memoryMB in
 	if len(machines) == 0 {
 		return nil, fmt.Errorf("No active machines in process group '%s', check `fly status` output", group)
 	}
+	if err := checkGroupVM(group, machines, vm); err != nil {
+		return nil, err
+	}
+	if confirmed, err := confirmGPUKindChanges(ctx, gpuKindChanges(machines, vm)); err != nil || !confirmed {
+		return nil, err
+	}
 
 	machines, releaseFunc, err := mach.AcquireLeases(ctx, machines)
 	defer releaseFunc()
@@ -52,31 +62,196 @@ func v2ScaleVM(ctx context.Context, appName, group, sizeName string, memoryMB in
 	}
 
 	for _, machine := range machines {
-		if sizeName != "" {
-			machine.Config.Guest.SetSize(sizeName)
-		}
-		if memoryMB > 0 {
-			machine.Config.Guest.MemoryMB = memoryMB
+		if err := updateMachineGuest(ctx, machine, vm); err != nil {
+			return nil, err
 		}
+	}
+
+	return machines[0].Config.Guest, nil
+}
+
+// v2ScaleVMGroups applies a size and memory per process group. Every group is
+// checked and all of the machines leased before the first one is updated, so a
+// typo in a group name doesn't leave the app half scaled.
+func v2ScaleVMGroups(ctx context.Context, appName string, groups groupVMs) (map[string]*fly.VMSize, error) {
+	flapsClient, err := flapsutil.NewClientWithOptions(ctx, flaps.NewClientOpts{
+		AppName: appName,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ctx = flapsutil.NewContextWithClient(ctx, flapsClient)
+
+	appConfig, err := appconfig.FromRemoteApp(ctx, appName)
+	if err != nil {
+		return nil, err
+	}
+	processNames := appConfig.ProcessNames()
+
+	machines, err := mach.ListActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	machineGroups := lo.GroupBy(machines, func(m *fly.Machine) string {
+		return m.ProcessGroup()
+	})
 
-		input := &fly.LaunchMachineInput{
-			Name:   machine.Name,
-			Region: machine.Region,
-			Config: machine.Config,
+	var toUpdate []*fly.Machine
+	replaced := 0
+	for _, group := range groups.names() {
+		if !lo.Contains(processNames, group) {
+			return nil, fmt.Errorf("unknown process group '%s', valid names are %s", group, appConfig.FormatProcessNames())
 		}
-		if err := mach.Update(ctx, machine, input); err != nil {
+		if len(machineGroups[group]) == 0 {
+			return nil, fmt.Errorf("No active machines in process group '%s', check `fly status` output", group)
+		}
+		if err := checkGroupVM(group, machineGroups[group], groups[group]); err != nil {
+			return nil, err
+		}
+		replaced += gpuKindChanges(machineGroups[group], groups[group])
+		toUpdate = append(toUpdate, machineGroups[group]...)
+	}
+	if confirmed, err := confirmGPUKindChanges(ctx, replaced); err != nil || !confirmed {
+		return nil, err
+	}
+
+	toUpdate, releaseFunc, err := mach.AcquireLeases(ctx, toUpdate)
+	defer releaseFunc()
+	if err != nil {
+		return nil, err
+	}
+
+	sizes := make(map[string]*fly.VMSize, len(groups))
+	for _, machine := range toUpdate {
+		group := machine.ProcessGroup()
+		vm := groups[group]
+		if err := updateMachineGuest(ctx, machine, vm); err != nil {
 			return nil, err
 		}
+		if _, ok := sizes[group]; !ok {
+			sizes[group] = guestVMSize(machine.Config.Guest)
+		}
+	}
+
+	return sizes, nil
+}
+
+// checkGroupVM makes sure vm can be applied to every machine of group before
+// any of them is touched: the regions must offer the resulting GPU kind, and
+// machines moving to another GPU kind can't have a volume since they get
+// replaced on a different host.
+func checkGroupVM(group string, machines []*fly.Machine, vm groupVM) error {
+	var gpuKind string
+	var regions []string
+	for _, machine := range machines {
+		guest := *machine.Config.Guest
+		if err := vm.apply(&guest); err != nil {
+			return fmt.Errorf("process group '%s': %w", group, err)
+		}
+		if guest.GPUKind == machine.Config.Guest.GPUKind {
+			continue
+		}
+		if len(machine.Config.Mounts) > 0 {
+			return fmt.Errorf("machine %s of process group '%s' has a volume attached and can't change its GPU kind, the volume is tied to its current host", machine.ID, group)
+		}
+		if guest.GPUKind != "" {
+			gpuKind = guest.GPUKind
+			regions = append(regions, machine.Region)
+		}
+	}
+
+	if len(regions) == 0 {
+		return nil
+	}
+	if err := mach.ValidateGPURegions(gpuKind, regions); err != nil {
+		return fmt.Errorf("process group '%s': %w", group, err)
+	}
+	return nil
+}
+
+// gpuKindChanges returns how many of machines vm moves to another GPU kind,
+// which replaces them, see updateMachineGuest.
+func gpuKindChanges(machines []*fly.Machine, vm groupVM) int {
+	return lo.CountBy(machines, func(m *fly.Machine) bool {
+		guest := *m.Config.Guest
+		return vm.apply(&guest) == nil && guest.GPUKind != m.Config.Guest.GPUKind
+	})
+}
+
+// confirmGPUKindChanges asks before replacing n machines to change their GPU
+// kind, since their ephemeral disks go away with them. It reports whether to
+// go on.
+func confirmGPUKindChanges(ctx context.Context, n int) (bool, error) {
+	if n == 0 || flag.GetYes(ctx) {
+		return true, nil
+	}
+	switch confirmed, err := prompt.Confirmf(ctx, "Changing the GPU kind replaces %d machines, losing the data on their ephemeral disks. Continue?", n); {
+	case err == nil:
+		return confirmed, nil
+	case prompt.IsNonInteractive(err):
+		return false, prompt.NonInteractiveError("--yes flag must be specified when not running interactively")
+	default:
+		return false, err
+	}
+}
+
+// gpuReplaceTimeout bounds how long the replacement of a machine changing its
+// GPU kind may take to start and pass its checks.
+const gpuReplaceTimeout = 5 * time.Minute
+
+// updateMachineGuest applies vm to machine. Machines changing their GPU kind
+// need another type of host, so a machine with the same name and config is
+// launched, and the old one is only destroyed once the new one is started and
+// healthy.
+func updateMachineGuest(ctx context.Context, machine *fly.Machine, vm groupVM) error {
+	gpuKind := machine.Config.Guest.GPUKind
+	if err := vm.apply(machine.Config.Guest); err != nil {
+		return err
 	}
 
-	// Return fly.VMSize to remain compatible with v1 scale app signature
-	size := &fly.VMSize{
-		Name:     machines[0].Config.Guest.ToSize(),
-		MemoryMB: machines[0].Config.Guest.MemoryMB,
-		CPUCores: float32(machines[0].Config.Guest.CPUs),
+	input := &fly.LaunchMachineInput{
+		Name:   machine.Name,
+		Region: machine.Region,
+		Config: machine.Config,
+	}
+	if machine.Config.Guest.GPUKind == gpuKind {
+		return mach.Update(ctx, machine, input)
 	}
 
-	return size, nil
+	io := iostreams.FromContext(ctx)
+	flapsClient := flapsutil.ClientFromContext(ctx)
+
+	fmt.Fprintf(io.Out, "Replacing machine %s to change its GPU kind\n", machine.ID)
+	newMachine, err := flapsClient.Launch(ctx, *input)
+	if err != nil {
+		return fmt.Errorf("could not launch the replacement of machine %s, which was kept: %w", machine.ID, err)
+	}
+
+	lm := mach.NewLeasableMachine(flapsClient, io, newMachine, false)
+	if err := lm.WaitForState(ctx, fly.MachineStateStarted, gpuReplaceTimeout, false); err != nil {
+		return fmt.Errorf("%w\nmachine %s was kept, destroy its replacement %s before trying again", err, machine.ID, newMachine.ID)
+	}
+	if err := lm.WaitForHealthchecksToPass(ctx, gpuReplaceTimeout); err != nil {
+		return fmt.Errorf("%w\nmachine %s was kept, destroy its replacement %s before trying again", err, machine.ID, newMachine.ID)
+	}
+
+	err = flapsClient.Destroy(ctx, fly.RemoveMachineInput{ID: machine.ID, Kill: true}, machine.LeaseNonce)
+	if err != nil {
+		return fmt.Errorf("machine %s was replaced by %s but could not be destroyed: %w", machine.ID, newMachine.ID, err)
+	}
+	// The lease went away with the machine
+	machine.LeaseNonce = ""
+	fmt.Fprintf(io.Out, "  Machine %s replaced by %s\n", machine.ID, newMachine.ID)
+	return nil
+}
+
+// guestVMSize returns a fly.VMSize to remain compatible with v1 scale app signature
+func guestVMSize(guest *fly.MachineGuest) *fly.VMSize {
+	return &fly.VMSize{
+		Name:     guest.ToSize(),
+		MemoryMB: guest.MemoryMB,
+		CPUCores: float32(guest.CPUs),
+	}
 }
 
 func listMachinesWithGroup(ctx context.Context, group string) ([]*fly.Machine, error) {
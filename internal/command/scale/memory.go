@@ -36,5 +36,5 @@ func runScaleMemory(ctx context.Context) error {
 		return err
 	}
 
-	return scaleVertically(ctx, group, "", memoryMB)
+	return scaleVertically(ctx, group, groupVM{memoryMB: memoryMB})
 }
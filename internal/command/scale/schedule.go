@@ -0,0 +1,511 @@
+package scale
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flapsutil"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+const (
+	defaultScaleSchedulerImage = "flyio/flyctl:latest"
+)
+
+// scaleRule sets process groups to a machine count whenever Cron fires.
+type scaleRule struct {
+	Cron     string         `json:"cron"`
+	Timezone string         `json:"timezone"`
+	Counts   map[string]int `json:"counts"`
+}
+
+func newScaleSchedule() *cobra.Command {
+	const (
+		short = "Manage time-based scaling rules"
+		long  = short + `. Each rule sets the machine count of process groups
+when a five field cron expression fires, e.g. to run more web machines during
+office hours:
+
+  fly scale schedule add "0 8 * * 1-5" web=6
+  fly scale schedule add "0 20 * * 1-5" web=2
+
+Rules are stored on a scheduler machine, created with the first rule, that runs
+"fly scale schedule reconcile" every hour, so rules should fire on the hour.
+Reconcile scales every group to the count of its most recent rule. The
+scheduler authenticates with the FLY_API_TOKEN secret of the app, which can be
+set with:
+
+  fly secrets set FLY_API_TOKEN="$(fly tokens create deploy)"
+`
+		usage = "schedule <command>"
+	)
+
+	cmd := command.New(usage, short, long, nil)
+
+	cmd.AddCommand(
+		newScaleScheduleAdd(),
+		newScaleScheduleList(),
+		newScaleScheduleRemove(),
+		newScaleScheduleReconcile(),
+		newScaleScheduleClear(),
+	)
+
+	return cmd
+}
+
+func newScaleScheduleAdd() *cobra.Command {
+	const (
+		short = "Add a scaling rule"
+		long  = short + `. The machine count is set either for the group given
+with --process-group, or per group with group=count pairs.
+`
+		usage = "add <cron> <count | group=count...>"
+	)
+
+	cmd := command.New(usage, short, long, runScaleScheduleAdd,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.MinimumNArgs(2)
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.ProcessGroup("The process group to scale"),
+		flag.Region(),
+		flag.String{
+			Name:        "timezone",
+			Description: "Timezone the rule is evaluated in, e.g. America/New_York",
+			Default:     "UTC",
+		},
+		flag.String{
+			Name:        "image",
+			Description: "The flyctl image the scheduler machine runs, when it is created",
+			Default:     defaultScaleSchedulerImage,
+		},
+	)
+
+	return cmd
+}
+
+func runScaleScheduleAdd(ctx context.Context) error {
+	var (
+		io      = iostreams.FromContext(ctx)
+		args    = flag.Args(ctx)
+		appName = appconfig.NameFromContext(ctx)
+	)
+
+	rule, err := parseScaleRule(args[0], flag.GetString(ctx, "timezone"), args[1:], flag.GetProcessGroup(ctx))
+	if err != nil {
+		return err
+	}
+
+	ctx, err = controllerContext(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	appConfig, err := appconfig.FromRemoteApp(ctx, appName)
+	if err != nil {
+		return err
+	}
+	processNames := appConfig.ProcessNames()
+	for group := range rule.Counts {
+		if !slices.Contains(processNames, group) {
+			return fmt.Errorf("unknown process group '%s', valid names are %s", group, appConfig.FormatProcessNames())
+		}
+	}
+
+	scheduler, rules, err := getScaleScheduler(ctx)
+	if err != nil {
+		return err
+	}
+	rules = append(rules, rule)
+
+	if scheduler == nil {
+		scheduler, err = launchScaleScheduler(ctx, appName, rules)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(io.Out, "Created scheduler machine %s\n", scheduler.ID)
+	} else if err := saveScaleRules(ctx, scheduler, rules); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(io.Out, "Added rule %d: %s\n", len(rules), formatScaleRule(rule))
+	return nil
+}
+
+func newScaleScheduleList() *cobra.Command {
+	const (
+		short = "List the scaling rules of an app"
+		long  = short + "\n"
+		usage = "list"
+	)
+
+	cmd := command.New(usage, short, long, runScaleScheduleList,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Aliases = []string{"ls", "show"}
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.JSONOutput(),
+	)
+
+	return cmd
+}
+
+func runScaleScheduleList(ctx context.Context) error {
+	io := iostreams.FromContext(ctx)
+	appName := appconfig.NameFromContext(ctx)
+
+	ctx, err := controllerContext(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	_, rules, err := getScaleScheduler(ctx)
+	if err != nil {
+		return err
+	}
+
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(io.Out, rules)
+	}
+
+	if len(rules) == 0 {
+		fmt.Fprintf(io.Out, "App %s has no scaling rules\n", appName)
+		return nil
+	}
+
+	rows := make([][]string, 0, len(rules))
+	for i, rule := range rules {
+		rows = append(rows, []string{strconv.Itoa(i + 1), rule.Cron, rule.Timezone, formatRuleCounts(rule.Counts)})
+	}
+
+	return render.Table(io.Out, "", rows, "#", "Cron", "Timezone", "Counts")
+}
+
+func newScaleScheduleRemove() *cobra.Command {
+	const (
+		short = "Remove a scaling rule"
+		long  = short + `. Rules are referenced by the number shown by
+"fly scale schedule list".
+`
+		usage = "remove <number>"
+	)
+
+	cmd := command.New(usage, short, long, runScaleScheduleRemove,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Aliases = []string{"rm"}
+	cmd.Args = cobra.ExactArgs(1)
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+	)
+
+	return cmd
+}
+
+func runScaleScheduleRemove(ctx context.Context) error {
+	io := iostreams.FromContext(ctx)
+
+	ctx, err := controllerContext(ctx, appconfig.NameFromContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	scheduler, rules, err := getScaleScheduler(ctx)
+	if err != nil {
+		return err
+	}
+
+	n, err := strconv.Atoi(flag.FirstArg(ctx))
+	if err != nil || n < 1 || n > len(rules) {
+		return fmt.Errorf("invalid rule number %q, the app has %d rules", flag.FirstArg(ctx), len(rules))
+	}
+
+	removed := rules[n-1]
+	rules = slices.Delete(rules, n-1, n)
+	if err := saveScaleRules(ctx, scheduler, rules); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(io.Out, "Removed rule %d: %s\n", n, formatScaleRule(removed))
+	return nil
+}
+
+func newScaleScheduleReconcile() *cobra.Command {
+	const (
+		short = "Scale process groups according to the scaling rules"
+		long  = short + `. Every group mentioned by a rule is scaled to the
+count of its most recent rule.
+`
+		usage = "reconcile"
+	)
+
+	cmd := command.New(usage, short, long, runScaleScheduleReconcile,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Yes(),
+		flag.Bool{
+			Name:        "dry-run",
+			Description: "Only show which groups would be scaled",
+		},
+	)
+
+	return cmd
+}
+
+func runScaleScheduleReconcile(ctx context.Context) error {
+	io := iostreams.FromContext(ctx)
+	appName := appconfig.NameFromContext(ctx)
+
+	ctx, err := controllerContext(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	_, rules, err := getScaleScheduler(ctx)
+	if err != nil {
+		return err
+	}
+
+	desired, err := desiredScheduledCounts(rules, time.Now())
+	if err != nil {
+		return err
+	}
+
+	machines, _, err := flapsutil.ClientFromContext(ctx).ListFlyAppsMachines(ctx)
+	if err != nil {
+		return err
+	}
+	current := lo.CountValuesBy(machines, func(m *fly.Machine) string {
+		return m.ProcessGroup()
+	})
+
+	appConfig, err := appconfig.FromRemoteApp(ctx, appName)
+	if err != nil {
+		return err
+	}
+	processNames := appConfig.ProcessNames()
+
+	groups := make(groupCounts)
+	for _, group := range lo.Keys(desired) {
+		if !slices.Contains(processNames, group) {
+			fmt.Fprintf(io.ErrOut, "Skipping unknown process group '%s'\n", group)
+			continue
+		}
+		if current[group] != desired[group] {
+			groups[group] = groupCount{absolute: desired[group]}
+		}
+	}
+
+	if len(groups) == 0 {
+		fmt.Fprintf(io.Out, "All scheduled groups are at their desired count\n")
+		return nil
+	}
+
+	if flag.GetBool(ctx, "dry-run") {
+		names := lo.Keys(groups)
+		slices.Sort(names)
+		for _, group := range names {
+			fmt.Fprintf(io.Out, "Would scale group '%s' from %d to %d machines\n", group, current[group], desired[group])
+		}
+		return nil
+	}
+
+	return runMachinesScaleCount(ctx, appName, appConfig, groups, -1, balanceDefault)
+}
+
+func newScaleScheduleClear() *cobra.Command {
+	const (
+		short = "Remove all scaling rules and the scheduler machine"
+		long  = short + "\n"
+		usage = "clear"
+	)
+
+	cmd := command.New(usage, short, long, runScaleScheduleClear,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+	)
+
+	return cmd
+}
+
+func runScaleScheduleClear(ctx context.Context) error {
+	io := iostreams.FromContext(ctx)
+
+	ctx, err := controllerContext(ctx, appconfig.NameFromContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	scheduler, _, err := getScaleScheduler(ctx)
+	if err != nil {
+		return err
+	}
+	if scheduler == nil {
+		fmt.Fprintf(io.Out, "App has no scaling rules\n")
+		return nil
+	}
+
+	if err := destroyControllerMachine(ctx, scheduler); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(io.Out, "Removed all scaling rules and destroyed scheduler machine %s\n", scheduler.ID)
+	return nil
+}
+
+// getScaleScheduler returns the scheduler machine of the app and the rules it
+// holds. The machine is nil when the app has no rules yet.
+func getScaleScheduler(ctx context.Context) (*fly.Machine, []scaleRule, error) {
+	scheduler, err := mach.FindController(ctx, mach.ScaleSchedulerMetadataKey)
+	if err != nil || scheduler == nil {
+		return nil, nil, err
+	}
+
+	var rules []scaleRule
+	if err := getControllerMetadata(scheduler, mach.ScaleScheduleMetadataKey, &rules); err != nil {
+		return nil, nil, err
+	}
+
+	return scheduler, rules, nil
+}
+
+func saveScaleRules(ctx context.Context, scheduler *fly.Machine, rules []scaleRule) error {
+	if err := setControllerMetadata(ctx, scheduler, mach.ScaleScheduleMetadataKey, rules); err != nil {
+		return fmt.Errorf("could not save scaling rules: %w", err)
+	}
+	return nil
+}
+
+func launchScaleScheduler(ctx context.Context, appName string, rules []scaleRule) (*fly.Machine, error) {
+	raw, err := json.Marshal(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	return launchControllerMachine(ctx, appName, mach.ControllerSpec{
+		Name:     "fly-scale-scheduler",
+		Schedule: "hourly",
+		Cmd:      []string{"scale", "schedule", "reconcile", "--app", appName, "--yes"},
+		Restart:  fly.MachineRestartPolicyNo,
+		Metadata: map[string]string{
+			mach.ScaleSchedulerMetadataKey: "true",
+			mach.ScaleScheduleMetadataKey:  string(raw),
+		},
+	})
+}
+
+func parseScaleRule(cron, timezone string, args []string, defaultGroup string) (scaleRule, error) {
+	if _, err := mach.ParseCron(cron); err != nil {
+		return scaleRule{}, err
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return scaleRule{}, fmt.Errorf("invalid timezone %q: %w", timezone, err)
+	}
+
+	if defaultGroup == "" {
+		defaultGroup = fly.MachineProcessGroupApp
+	}
+	groups, err := parseGroupCounts(args, defaultGroup)
+	if err != nil {
+		return scaleRule{}, err
+	}
+
+	counts := make(map[string]int, len(groups))
+	for group, count := range groups {
+		if count.relative != 0 {
+			return scaleRule{}, errors.New("scaling rules only take absolute counts")
+		}
+		counts[group] = count.absolute
+	}
+
+	return scaleRule{Cron: cron, Timezone: timezone, Counts: counts}, nil
+}
+
+// desiredScheduledCounts returns the machine count of each group according
+// to the most recent rule mentioning it at now. Groups whose rules haven't
+// fired within the lookback are left out.
+func desiredScheduledCounts(rules []scaleRule, now time.Time) (map[string]int, error) {
+	desired := make(map[string]int)
+	fired := make(map[string]time.Time)
+
+	for i, rule := range rules {
+		loc, err := time.LoadLocation(rule.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone in rule %d: %w", i+1, err)
+		}
+		schedule, err := mach.ParseCron(rule.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule %d: %w", i+1, err)
+		}
+
+		last, ok := schedule.Prev(now.In(loc), mach.CronLookback)
+		if !ok {
+			continue
+		}
+		for group, count := range rule.Counts {
+			// Later rules win ties so that the last added rule takes effect
+			if prev, seen := fired[group]; !seen || !last.Before(prev) {
+				fired[group] = last
+				desired[group] = count
+			}
+		}
+	}
+
+	return desired, nil
+}
+
+func formatScaleRule(rule scaleRule) string {
+	return fmt.Sprintf("%q (%s) %s", rule.Cron, rule.Timezone, formatRuleCounts(rule.Counts))
+}
+
+func formatRuleCounts(counts map[string]int) string {
+	groups := lo.Keys(counts)
+	slices.Sort(groups)
+	return strings.Join(lo.Map(groups, func(group string, _ int) string {
+		return fmt.Sprintf("%s=%d", group, counts[group])
+	}), " ")
+}
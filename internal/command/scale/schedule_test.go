@@ -0,0 +1,65 @@
+package scale
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_desiredScheduledCounts(t *testing.T) {
+	rules := []scaleRule{
+		{Cron: "0 8 * * 1-5", Timezone: "UTC", Counts: map[string]int{"web": 6}},
+		{Cron: "0 20 * * 1-5", Timezone: "UTC", Counts: map[string]int{"web": 2, "worker": 1}},
+	}
+
+	// Wednesday
+	got, err := desiredScheduledCounts(rules, time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"web": 6, "worker": 1}, got)
+
+	got, err = desiredScheduledCounts(rules, time.Date(2024, 5, 15, 21, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"web": 2, "worker": 1}, got)
+
+	// Saturday keeps Friday evening's counts
+	got, err = desiredScheduledCounts(rules, time.Date(2024, 5, 18, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"web": 2, "worker": 1}, got)
+}
+
+func Test_desiredScheduledCounts_timezone(t *testing.T) {
+	rules := []scaleRule{
+		{Cron: "0 8 * * *", Timezone: "America/New_York", Counts: map[string]int{"web": 4}},
+		{Cron: "0 20 * * *", Timezone: "UTC", Counts: map[string]int{"web": 1}},
+	}
+
+	// 08:00 in New York is 12:00 UTC in May
+	got, err := desiredScheduledCounts(rules, time.Date(2024, 5, 15, 11, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"web": 1}, got)
+
+	got, err = desiredScheduledCounts(rules, time.Date(2024, 5, 15, 13, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"web": 4}, got)
+}
+
+func Test_parseScaleRule(t *testing.T) {
+	rule, err := parseScaleRule("0 8 * * 1-5", "UTC", []string{"web=6", "worker=2"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, scaleRule{Cron: "0 8 * * 1-5", Timezone: "UTC", Counts: map[string]int{"web": 6, "worker": 2}}, rule)
+
+	rule, err = parseScaleRule("0 8 * * *", "UTC", []string{"3"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"app": 3}, rule.Counts)
+
+	_, err = parseScaleRule("0 8 * *", "UTC", []string{"3"}, "")
+	assert.Error(t, err)
+
+	_, err = parseScaleRule("0 8 * * *", "Mars/Olympus", []string{"3"}, "")
+	assert.Error(t, err)
+
+	_, err = parseScaleRule("0 8 * * *", "UTC", []string{"web=+2"}, "")
+	assert.Error(t, err)
+}
@@ -1,6 +1,8 @@
 package scale
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
 	"github.com/superfly/flyctl/internal/command"
 	"github.com/superfly/flyctl/internal/flag"
@@ -9,7 +11,11 @@ import (
 func newScaleShow() *cobra.Command {
 	const (
 		short = "Show current resources"
-		long  = `Show current VM size and counts`
+		long  = `Show current VM size and counts.
+
+With --json, the recent p95 CPU and memory usage of each process group is
+included as well, taken from the busiest machine of the group over --window.
+Use --recommend to get right-sizing suggestions based on that usage.`
 	)
 	cmd := command.New("show", short, long, runMachinesScaleShow,
 		command.RequireSession,
@@ -20,6 +26,15 @@ func newScaleShow() *cobra.Command {
 		flag.App(),
 		flag.AppConfig(),
 		flag.JSONOutput(),
+		flag.Bool{
+			Name:        "recommend",
+			Description: "Suggest VM sizes based on recent CPU and memory usage",
+		},
+		flag.Duration{
+			Name:        "window",
+			Description: "How far back to look at CPU and memory usage",
+			Default:     24 * time.Hour,
+		},
 	)
 	return cmd
 }
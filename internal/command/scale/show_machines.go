@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/samber/lo"
 	fly "github.com/superfly/fly-go"
@@ -52,14 +53,38 @@ func runMachinesScaleShow(ctx context.Context) error {
 		return machines[0].Config.Guest
 	})
 
-	if flag.GetBool(ctx, "json") {
+	jsonOutput := flag.GetBool(ctx, "json")
+	recommend := flag.GetBool(ctx, "recommend")
+
+	var utilization map[string]*groupUtilization
+	if jsonOutput || recommend {
+		window := flag.GetDuration(ctx, "window")
+		if window < time.Minute {
+			return fmt.Errorf("--window must be at least one minute")
+		}
+		utilization, err = fetchUtilization(ctx, appName, machines, window)
+		if err != nil {
+			fmt.Fprintf(io.ErrOut, "Warning: could not get CPU and memory usage: %v\n", err)
+		}
+	}
+
+	recommendations := lo.MapValues(representativeGuests, func(guest *fly.MachineGuest, name string) []string {
+		if !recommend {
+			return nil
+		}
+		return recommendGuest(name, guest, utilization[name])
+	})
+
+	if jsonOutput {
 		type groupData struct {
-			Process string
-			Count   int
-			CPUKind string
-			CPUs    int
-			Memory  int
-			Regions map[string]int
+			Process         string
+			Count           int
+			CPUKind         string
+			CPUs            int
+			Memory          int
+			Regions         map[string]int
+			Utilization     *groupUtilization `json:",omitempty"`
+			Recommendations []string          `json:",omitempty"`
 		}
 		groups := lo.FilterMap(groupNames, func(name string, _ int) (res groupData, ok bool) {
 			machines := machineGroups[name]
@@ -76,6 +101,8 @@ func runMachinesScaleShow(ctx context.Context) error {
 				Regions: lo.CountValues(lo.Map(machines, func(m *fly.Machine, _ int) string {
 					return m.Region
 				})),
+				Utilization:     utilization[name],
+				Recommendations: recommendations[name],
 			}, true
 		})
 
@@ -104,6 +131,23 @@ func runMachinesScaleShow(ctx context.Context) error {
 	fmt.Fprintf(io.Out, "VM Resources for app: %s\n\n", appName)
 	render.Table(io.Out, "Groups", rows, "Name", "Count", "Kind", "CPUs", "Memory", "Regions")
 
+	if recommend {
+		var lines []string
+		for _, groupName := range groupNames {
+			lines = append(lines, recommendations[groupName]...)
+		}
+		switch {
+		case utilization == nil:
+		case len(lines) == 0:
+			fmt.Fprintf(io.Out, "\nNo sizing changes recommended based on the last %s\n", flag.GetDuration(ctx, "window"))
+		default:
+			fmt.Fprintf(io.Out, "\nRecommendations based on the last %s:\n", flag.GetDuration(ctx, "window"))
+			for _, line := range lines {
+				fmt.Fprintf(io.Out, "  %s\n", line)
+			}
+		}
+	}
+
 	return nil
 }
 
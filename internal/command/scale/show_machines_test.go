@@ -20,3 +20,22 @@ func Test_formatRegions(t *testing.T) {
 		"fra(3),mia,scl(2)",
 	)
 }
+
+func Test_recommendGuest(t *testing.T) {
+	shared1x := &fly.MachineGuest{CPUKind: "shared", CPUs: 1, MemoryMB: 1024}
+
+	assert.Equal(t,
+		[]string{"worker group p95 memory 180MiB of 1024MiB, consider 256MiB"},
+		recommendGuest("worker", shared1x, &groupUtilization{MemoryMBP95: 180, CPUCoresP95: 0.3}),
+	)
+	assert.Equal(t,
+		[]string{"web group p95 memory 980MiB of 1024MiB, consider 1280MiB", "web group p95 CPU 0.95 of 1 cores, consider 2 CPUs"},
+		recommendGuest("web", shared1x, &groupUtilization{MemoryMBP95: 980, CPUCoresP95: 0.95}),
+	)
+	assert.Empty(t, recommendGuest("web", shared1x, &groupUtilization{MemoryMBP95: 700, CPUCoresP95: 0.5}))
+	assert.Equal(t,
+		[]string{"app group p95 CPU 0.20 of 4 cores, consider 1 CPUs"},
+		recommendGuest("app", &fly.MachineGuest{CPUKind: "performance", CPUs: 4, MemoryMB: 8192}, &groupUtilization{MemoryMBP95: 6000, CPUCoresP95: 0.2}),
+	)
+	assert.Nil(t, recommendGuest("app", shared1x, nil))
+}
@@ -0,0 +1,123 @@
+package scale
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/flyctl/internal/flyutil"
+	"github.com/superfly/flyctl/internal/prometheus"
+)
+
+// groupUtilization is the p95 usage of a process group over a window, taken
+// from its busiest machine.
+type groupUtilization struct {
+	Window      string
+	Machines    int
+	CPUCoresP95 float64
+	MemoryMBP95 int
+}
+
+// fetchUtilization queries the org's Prometheus for the p95 CPU and memory
+// usage of each machine of appName over window, and folds them per group.
+func fetchUtilization(ctx context.Context, appName string, machines []*fly.Machine, window time.Duration) (map[string]*groupUtilization, error) {
+	app, err := flyutil.ClientFromContext(ctx).GetAppCompact(ctx, appName)
+	if err != nil {
+		return nil, err
+	}
+
+	rng := fmt.Sprintf("%ds", int(window.Seconds()))
+	// fly_instance_cpu counts centiseconds spent in each mode
+	cpuQuery := fmt.Sprintf(`quantile_over_time(0.95, (sum by (instance) (rate(fly_instance_cpu{app=%q,mode!="idle"}[1m])) / 100)[%s:1m])`, appName, rng)
+	memQuery := fmt.Sprintf(`quantile_over_time(0.95, (fly_instance_memory_mem_total{app=%[1]q} - fly_instance_memory_mem_available{app=%[1]q})[%[2]s:1m])`, appName, rng)
+
+	cpu, err := queryPrometheus(ctx, app.Organization.Slug, cpuQuery)
+	if err != nil {
+		return nil, err
+	}
+	mem, err := queryPrometheus(ctx, app.Organization.Slug, memQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	utilization := make(map[string]*groupUtilization)
+	for _, m := range machines {
+		cores, hasCPU := cpu[m.ID]
+		memBytes, hasMem := mem[m.ID]
+		if !hasCPU && !hasMem {
+			continue
+		}
+
+		u, ok := utilization[m.ProcessGroup()]
+		if !ok {
+			u = &groupUtilization{Window: window.String()}
+			utilization[m.ProcessGroup()] = u
+		}
+		u.Machines++
+		u.CPUCoresP95 = math.Max(u.CPUCoresP95, math.Round(cores*100)/100)
+		u.MemoryMBP95 = max(u.MemoryMBP95, int(memBytes/(1024*1024)))
+	}
+
+	return utilization, nil
+}
+
+// queryPrometheus runs an instant query and returns the value of each series
+// keyed by its instance label, which is the machine ID.
+func queryPrometheus(ctx context.Context, orgSlug, query string) (map[string]float64, error) {
+	series, err := prometheus.Query(ctx, orgSlug, query)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]float64, len(series))
+	for _, s := range series {
+		if v, ok := s.Last(); ok {
+			values[s.Metric["instance"]] = v
+		}
+	}
+	return values, nil
+}
+
+// recommendGuest suggests right-sizing a group's guest from its utilization.
+// Memory gets 25% headroom over the p95, and CPUs are considered busy above
+// 80% and idle below 10% of the allocation.
+func recommendGuest(group string, guest *fly.MachineGuest, u *groupUtilization) []string {
+	if guest == nil || u == nil {
+		return nil
+	}
+
+	var recommendations []string
+
+	minPerCPU, maxPerCPU := fly.MIN_MEMORY_MB_PER_SHARED_CPU, fly.MAX_MEMORY_MB_PER_SHARED_CPU
+	if guest.CPUKind == "performance" {
+		minPerCPU, maxPerCPU = fly.MIN_MEMORY_MB_PER_CPU, fly.MAX_MEMORY_MB_PER_CPU
+	}
+	const increment = 256
+	target := int(math.Ceil(float64(u.MemoryMBP95)*1.25/increment)) * increment
+	target = min(max(target, minPerCPU*guest.CPUs), maxPerCPU*guest.CPUs)
+
+	switch {
+	case target > guest.MemoryMB && float64(u.MemoryMBP95) > 0.9*float64(guest.MemoryMB):
+		recommendations = append(recommendations, fmt.Sprintf("%s group p95 memory %dMiB of %dMiB, consider %dMiB", group, u.MemoryMBP95, guest.MemoryMB, target))
+	case target < guest.MemoryMB:
+		recommendations = append(recommendations, fmt.Sprintf("%s group p95 memory %dMiB of %dMiB, consider %dMiB", group, u.MemoryMBP95, guest.MemoryMB, target))
+	}
+
+	if guest.CPUs > 0 {
+		busy := u.CPUCoresP95 / float64(guest.CPUs)
+		switch {
+		case busy > 0.8:
+			cpus := int(math.Ceil(u.CPUCoresP95 / 0.6))
+			recommendations = append(recommendations, fmt.Sprintf("%s group p95 CPU %.2f of %d cores, consider %d CPUs", group, u.CPUCoresP95, guest.CPUs, cpus))
+		case busy < 0.1 && guest.CPUs > 1:
+			cpus := max(1, int(math.Ceil(u.CPUCoresP95/0.5)))
+			if cpus < guest.CPUs {
+				recommendations = append(recommendations, fmt.Sprintf("%s group p95 CPU %.2f of %d cores, consider %d CPUs", group, u.CPUCoresP95, guest.CPUs, cpus))
+			}
+		}
+	}
+
+	return recommendations
+}
@@ -3,9 +3,14 @@ package scale
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 
+	"github.com/docker/go-units"
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 	fly "github.com/superfly/fly-go"
+	"github.com/superfly/flyctl/helpers"
 	"github.com/superfly/flyctl/internal/appconfig"
 	"github.com/superfly/flyctl/internal/command"
 	"github.com/superfly/flyctl/internal/flag"
@@ -22,42 +27,114 @@ For a full list of supported sizes use the command 'flyctl platform vm-sizes'
 Memory size can be set with --memory=number-of-MB
 e.g. flyctl scale vm shared-cpu-1x --memory=2048
 
+Apps with several process groups can size each group in one go by passing
+group=size pairs, and group=MB pairs to --memory:
+e.g. flyctl scale vm web=shared-cpu-2x worker=performance-1x --memory web=1024,worker=4096
+
+All the groups are validated and their machines leased before any of them is
+updated.
+
+GPUs can be attached with --vm-gpu-kind and --vm-gpus, or removed with
+--vm-gpu-kind=none:
+e.g. flyctl scale vm performance-8x --vm-gpu-kind a100-40gb --vm-gpus 1
+
+Every region the process group runs in must offer the GPU kind. Changing the
+GPU kind moves machines to a different type of host, so they are replaced
+instead of being updated in place: each replacement is launched, and the old
+machine, with the data of its ephemeral disk, is destroyed once the new one is
+healthy. This is confirmed first, unless --yes is set.
+
 For pricing, see https://fly.io/docs/about/pricing/`
 	)
-	cmd := command.New("vm [size]", short, long, runScaleVM,
+	cmd := command.New("vm [size | group=size...]", short, long, runScaleVM,
 		command.RequireSession,
 		command.RequireAppName,
 	)
-	cmd.Args = cobra.ExactArgs(1)
+	cmd.Args = cobra.MinimumNArgs(1)
 	flag.Add(cmd,
 		flag.App(),
 		flag.AppConfig(),
-		flag.Int{
+		flag.String{
 			Name:        "vm-memory",
-			Description: "Memory in MB for the VM",
-			Default:     0,
+			Description: "Memory in MB for the VM, or comma separated group=MB pairs",
 			Aliases:     []string{"memory"},
 		},
+		flag.String{
+			Name:        "vm-gpu-kind",
+			Description: "The GPU model to attach, or 'none' to remove GPUs",
+			Aliases:     []string{"gpu-kind"},
+		},
+		flag.Int{
+			Name:        "vm-gpus",
+			Description: "Number of GPUs, defaults to 1 when --vm-gpu-kind is set",
+			Aliases:     []string{"gpus"},
+		},
 		flag.ProcessGroup("The process group to apply the VM size to"),
+		flag.Yes(),
 	)
 	return cmd
 }
 
 func runScaleVM(ctx context.Context) error {
-	sizeName := flag.FirstArg(ctx)
-	memoryMB := flag.GetInt(ctx, "vm-memory")
+	args := flag.Args(ctx)
+	rawMemory := flag.GetString(ctx, "vm-memory")
 	group := flag.GetProcessGroup(ctx)
-	return scaleVertically(ctx, group, sizeName, memoryMB)
+
+	if len(args) == 1 && !strings.Contains(args[0], "=") && !strings.Contains(rawMemory, "=") {
+		memoryMB, err := parseMemoryMB(rawMemory)
+		if err != nil {
+			return err
+		}
+		vm := groupVM{size: args[0], memoryMB: memoryMB}
+		if err := parseGPUFlags(ctx, &vm); err != nil {
+			return err
+		}
+		return scaleVertically(ctx, group, vm)
+	}
+
+	if group != "" {
+		return fmt.Errorf("--process-group can't be used with group=size arguments")
+	}
+	if flag.IsSpecified(ctx, "vm-gpu-kind") || flag.IsSpecified(ctx, "vm-gpus") {
+		return fmt.Errorf("--vm-gpu-kind and --vm-gpus can't be used with group=size arguments, scale each group on its own")
+	}
+
+	groups, err := parseGroupVMs(args, rawMemory)
+	if err != nil {
+		return err
+	}
+	return scaleGroupsVertically(ctx, groups)
+}
+
+func parseGPUFlags(ctx context.Context, vm *groupVM) error {
+	if flag.IsSpecified(ctx, "vm-gpu-kind") {
+		kind, err := flag.ParseGPUKind(flag.GetString(ctx, "vm-gpu-kind"))
+		if err != nil {
+			return err
+		}
+		vm.gpuKind = kind
+	}
+	if flag.IsSpecified(ctx, "vm-gpus") {
+		vm.gpus = flag.GetInt(ctx, "vm-gpus")
+		switch {
+		case vm.gpus <= 0:
+			return fmt.Errorf("--vm-gpus must be greater than zero, got: %d", vm.gpus)
+		case vm.gpuKind == "none":
+			return fmt.Errorf("--vm-gpus can't be used with --vm-gpu-kind=none")
+		}
+	}
+	return nil
 }
 
-func scaleVertically(ctx context.Context, group, sizeName string, memoryMB int) error {
+func scaleVertically(ctx context.Context, group string, vm groupVM) error {
 	io := iostreams.FromContext(ctx)
 	appName := appconfig.NameFromContext(ctx)
 
-	size, err := v2ScaleVM(ctx, appName, group, sizeName, memoryMB)
-	if err != nil {
+	guest, err := v2ScaleVM(ctx, appName, group, vm)
+	if err != nil || guest == nil {
 		return err
 	}
+	size := guestVMSize(guest)
 
 	if group == "" {
 		fmt.Fprintf(io.Out, "Scaled VM Type to '%s'\n", size.Name)
@@ -67,9 +144,148 @@ func scaleVertically(ctx context.Context, group, sizeName string, memoryMB int)
 
 	fmt.Fprintf(io.Out, "%15s: %s\n", "CPU Cores", formatCores(*size))
 	fmt.Fprintf(io.Out, "%15s: %s\n", "Memory", formatMemory(*size))
+	if guest.GPUs > 0 {
+		fmt.Fprintf(io.Out, "%15s: %d x %s\n", "GPUs", guest.GPUs, guest.GPUKind)
+	}
 	return nil
 }
 
+func scaleGroupsVertically(ctx context.Context, groups groupVMs) error {
+	io := iostreams.FromContext(ctx)
+	appName := appconfig.NameFromContext(ctx)
+
+	sizes, err := v2ScaleVMGroups(ctx, appName, groups)
+	if err != nil || sizes == nil {
+		return err
+	}
+
+	for _, group := range groups.names() {
+		size := sizes[group]
+		fmt.Fprintf(io.Out, "Scaled VM Type for '%s' to '%s'\n", group, size.Name)
+		fmt.Fprintf(io.Out, "%15s: %s\n", "CPU Cores", formatCores(*size))
+		fmt.Fprintf(io.Out, "%15s: %s\n", "Memory", formatMemory(*size))
+	}
+	return nil
+}
+
+// groupVM is the size, memory and GPUs to apply to the machines of a process
+// group. Empty values leave the current setting untouched.
+type groupVM struct {
+	size     string
+	memoryMB int
+	gpuKind  string
+	gpus     int
+}
+
+// apply sets vm on guest. The size preset goes first since it resets memory
+// and GPUs.
+func (vm groupVM) apply(guest *fly.MachineGuest) error {
+	if vm.size != "" {
+		if err := guest.SetSize(vm.size); err != nil {
+			return err
+		}
+	}
+	if vm.memoryMB > 0 {
+		guest.MemoryMB = vm.memoryMB
+	}
+
+	switch vm.gpuKind {
+	case "":
+	case "none":
+		guest.GPUKind = ""
+		guest.GPUs = 0
+	default:
+		guest.GPUKind = vm.gpuKind
+		if guest.GPUs == 0 {
+			guest.GPUs = 1
+		}
+	}
+
+	if vm.gpus > 0 {
+		if guest.GPUKind == "" {
+			return fmt.Errorf("--vm-gpus requires a GPU model, pass --vm-gpu-kind or a GPU size")
+		}
+		guest.GPUs = vm.gpus
+	}
+	return nil
+}
+
+type groupVMs map[string]groupVM
+
+func (g groupVMs) names() []string {
+	names := lo.Keys(g)
+	sort.Strings(names)
+	return names
+}
+
+// parseGroupVMs parses "group=size" arguments along with a --memory value that
+// is either a single size applied to every group or "group=MB" pairs.
+func parseGroupVMs(args []string, rawMemory string) (groupVMs, error) {
+	groups := make(groupVMs)
+
+	for _, arg := range args {
+		group, size, ok := strings.Cut(arg, "=")
+		if !ok || group == "" || size == "" {
+			return nil, fmt.Errorf("'%s' is not a valid group=size option", arg)
+		}
+		if _, dup := groups[group]; dup {
+			return nil, fmt.Errorf("process group '%s' is given more than once", group)
+		}
+		if err := (&fly.MachineGuest{}).SetSize(size); err != nil {
+			return nil, fmt.Errorf("invalid size for process group '%s': %w", group, err)
+		}
+		groups[group] = groupVM{size: size}
+	}
+
+	if !strings.Contains(rawMemory, "=") {
+		memoryMB, err := parseMemoryMB(rawMemory)
+		if err != nil {
+			return nil, err
+		}
+		for group, vm := range groups {
+			vm.memoryMB = memoryMB
+			groups[group] = vm
+		}
+		return groups, nil
+	}
+
+	seen := make(map[string]bool)
+	for _, pair := range strings.Split(rawMemory, ",") {
+		group, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || group == "" {
+			return nil, fmt.Errorf("'%s' is not a valid group=MB memory option", pair)
+		}
+		if seen[group] {
+			return nil, fmt.Errorf("memory for process group '%s' is given more than once", group)
+		}
+		seen[group] = true
+
+		memoryMB, err := parseMemoryMB(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid memory for process group '%s': %w", group, err)
+		}
+		vm := groups[group]
+		vm.memoryMB = memoryMB
+		groups[group] = vm
+	}
+
+	return groups, nil
+}
+
+func parseMemoryMB(raw string) (int, error) {
+	if raw == "" {
+		return 0, nil
+	}
+	memoryMB, err := helpers.ParseSize(raw, units.RAMInBytes, units.MiB)
+	switch {
+	case err != nil:
+		return 0, err
+	case memoryMB <= 0:
+		return 0, fmt.Errorf("memory must be greater than zero, got: %s", raw)
+	}
+	return memoryMB, nil
+}
+
 func formatCores(size fly.VMSize) string {
 	if size.CPUCores < 1.0 {
 		return fmt.Sprintf("%.2f", size.CPUCores)
@@ -0,0 +1,138 @@
+package scale
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	fly "github.com/superfly/fly-go"
+)
+
+func Test_parseGroupVMs(t *testing.T) {
+	testcases := []struct {
+		name      string
+		args      []string
+		rawMemory string
+		want      groupVMs
+	}{
+		{
+			name: "sizes only",
+			args: []string{"web=shared-cpu-2x", "worker=performance-1x"},
+			want: groupVMs{
+				"web":    {size: "shared-cpu-2x"},
+				"worker": {size: "performance-1x"},
+			},
+		},
+		{
+			name:      "memory per group",
+			args:      []string{"web=shared-cpu-2x", "worker=performance-1x"},
+			rawMemory: "web=1024,worker=4gb",
+			want: groupVMs{
+				"web":    {size: "shared-cpu-2x", memoryMB: 1024},
+				"worker": {size: "performance-1x", memoryMB: 4096},
+			},
+		},
+		{
+			name:      "memory for every group",
+			args:      []string{"web=shared-cpu-2x", "worker=performance-1x"},
+			rawMemory: "2048",
+			want: groupVMs{
+				"web":    {size: "shared-cpu-2x", memoryMB: 2048},
+				"worker": {size: "performance-1x", memoryMB: 2048},
+			},
+		},
+		{
+			name:      "memory for a group without a size",
+			args:      []string{"web=shared-cpu-2x"},
+			rawMemory: "worker=512",
+			want: groupVMs{
+				"web":    {size: "shared-cpu-2x"},
+				"worker": {memoryMB: 512},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseGroupVMs(tc.args, tc.rawMemory)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func Test_parseGroupVMs_errors(t *testing.T) {
+	testcases := []struct {
+		name      string
+		args      []string
+		rawMemory string
+	}{
+		{name: "missing group", args: []string{"shared-cpu-2x"}},
+		{name: "unknown size", args: []string{"web=huge"}},
+		{name: "duplicate group", args: []string{"web=shared-cpu-1x", "web=shared-cpu-2x"}},
+		{name: "bad memory pair", args: []string{"web=shared-cpu-1x"}, rawMemory: "web=1024,4096"},
+		{name: "zero memory", args: []string{"web=shared-cpu-1x"}, rawMemory: "web=0"},
+		{name: "duplicate memory", args: []string{"web=shared-cpu-1x"}, rawMemory: "web=512,web=1024"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseGroupVMs(tc.args, tc.rawMemory)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func Test_groupVM_apply(t *testing.T) {
+	guest := &fly.MachineGuest{}
+	require.NoError(t, guest.SetSize("performance-8x"))
+
+	require.NoError(t, groupVM{gpuKind: "a100-pcie-40gb"}.apply(guest))
+	assert.Equal(t, "a100-pcie-40gb", guest.GPUKind)
+	assert.Equal(t, 1, guest.GPUs)
+
+	require.NoError(t, groupVM{gpus: 2}.apply(guest))
+	assert.Equal(t, 2, guest.GPUs)
+
+	require.NoError(t, groupVM{gpuKind: "none"}.apply(guest))
+	assert.Equal(t, "", guest.GPUKind)
+	assert.Equal(t, 0, guest.GPUs)
+
+	assert.Error(t, groupVM{gpus: 1}.apply(guest))
+}
+
+func Test_checkGroupVM(t *testing.T) {
+	newMachine := func(id, region, gpuKind string, mounts ...fly.MachineMount) *fly.Machine {
+		guest := &fly.MachineGuest{}
+		guest.SetSize("performance-8x")
+		guest.GPUKind = gpuKind
+		return &fly.Machine{ID: id, Region: region, Config: &fly.MachineConfig{Guest: guest, Mounts: mounts}}
+	}
+
+	machines := []*fly.Machine{newMachine("m1", "ord", ""), newMachine("m2", "ord", "")}
+	assert.NoError(t, checkGroupVM("app", machines, groupVM{gpuKind: "a100-pcie-40gb"}))
+	assert.NoError(t, checkGroupVM("app", machines, groupVM{memoryMB: 32768}))
+
+	machines = append(machines, newMachine("m3", "cdg", ""))
+	assert.ErrorContains(t, checkGroupVM("app", machines, groupVM{size: "a100-40gb"}), "isn't available in cdg")
+
+	machines = []*fly.Machine{newMachine("m1", "ord", "", fly.MachineMount{Volume: "vol_1", Path: "/data"})}
+	assert.ErrorContains(t, checkGroupVM("app", machines, groupVM{gpuKind: "l40s"}), "has a volume attached")
+
+	machines = []*fly.Machine{newMachine("m1", "ord", "l40s", fly.MachineMount{Volume: "vol_1", Path: "/data"})}
+	assert.NoError(t, checkGroupVM("app", machines, groupVM{gpus: 2}))
+}
+
+func Test_gpuKindChanges(t *testing.T) {
+	newMachine := func(gpuKind string) *fly.Machine {
+		guest := &fly.MachineGuest{}
+		guest.SetSize("performance-8x")
+		guest.GPUKind = gpuKind
+		return &fly.Machine{Config: &fly.MachineConfig{Guest: guest}}
+	}
+
+	machines := []*fly.Machine{newMachine(""), newMachine("l40s"), newMachine("a100-pcie-40gb")}
+	assert.Equal(t, 2, gpuKindChanges(machines, groupVM{gpuKind: "l40s"}))
+	assert.Equal(t, 2, gpuKindChanges(machines, groupVM{gpuKind: "none"}))
+	assert.Equal(t, 0, gpuKindChanges(machines, groupVM{memoryMB: 65536}))
+}
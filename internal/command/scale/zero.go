@@ -0,0 +1,233 @@
+package scale
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/fly-go/flaps"
+	"github.com/superfly/flyctl/helpers"
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flapsutil"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/prompt"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+// suspendMaxMemoryMB is the largest guest that can be suspended, bigger ones
+// are stopped instead.
+const suspendMaxMemoryMB = 2048
+
+func newScaleZero() *cobra.Command {
+	const (
+		short = "Let a process group scale to zero when idle"
+		long  = `Let the machines of a process group stop when they receive no traffic,
+and optionally start again on incoming requests.
+
+This sets auto_stop_machines, auto_start_machines and min_machines_running on
+the group's services, both on its running machines and in fly.toml so the next
+deploy keeps them. Services shared with other groups are changed in fly.toml
+for those groups too, but their machines only get the change on the next
+deploy.
+
+Machines are stopped by default, or suspended with --suspend which resumes
+them faster. The command ends with the expected cold start profile of the
+group.
+
+e.g. flyctl scale zero --process-group web --wake-on-request`
+	)
+	cmd := command.New("zero", short, long, runScaleZero,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+	cmd.Args = cobra.NoArgs
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Yes(),
+		flag.ProcessGroup("The process group to scale to zero"),
+		flag.Bool{Name: "wake-on-request", Description: "Start machines again when requests come in"},
+		flag.Bool{Name: "suspend", Description: "Suspend idle machines instead of stopping them"},
+		flag.Int{Name: "min-machines-running", Description: "Number of machines kept running in the primary region", Default: 0},
+	)
+	return cmd
+}
+
+func runScaleZero(ctx context.Context) error {
+	io := iostreams.FromContext(ctx)
+	appName := appconfig.NameFromContext(ctx)
+
+	autostop := fly.MachineAutostopStop
+	if flag.GetBool(ctx, "suspend") {
+		autostop = fly.MachineAutostopSuspend
+	}
+	autostart := flag.GetBool(ctx, "wake-on-request")
+	minRunning := flag.GetInt(ctx, "min-machines-running")
+	if minRunning < 0 {
+		return fmt.Errorf("--min-machines-running must be zero or more, got: %d", minRunning)
+	}
+
+	flapsClient, err := flapsutil.NewClientWithOptions(ctx, flaps.NewClientOpts{
+		AppName: appName,
+	})
+	if err != nil {
+		return err
+	}
+	ctx = flapsutil.NewContextWithClient(ctx, flapsClient)
+
+	appConfig, err := appconfig.FromRemoteApp(ctx, appName)
+	if err != nil {
+		return err
+	}
+	group := flag.GetProcessGroup(ctx)
+	if group == "" {
+		group = appConfig.DefaultProcessName()
+	}
+	if !lo.Contains(appConfig.ProcessNames(), group) {
+		return fmt.Errorf("unknown process group '%s', valid names are %s", group, appConfig.FormatProcessNames())
+	}
+	if appConfig.SetAutostop(group, autostop, autostart, minRunning) == 0 {
+		return fmt.Errorf("process group '%s' has no services, only machines behind the Fly proxy can be stopped when idle", group)
+	}
+
+	machines, err := listMachinesWithGroup(ctx, group)
+	if err != nil {
+		return err
+	}
+	machines = lo.Filter(machines, func(m *fly.Machine, _ int) bool {
+		return len(m.Config.Services) > 0
+	})
+
+	fmt.Fprintf(io.Out, "Setting auto_stop_machines=%s auto_start_machines=%t min_machines_running=%d on %d machines of group '%s'\n",
+		autostop, autostart, minRunning, len(machines), group)
+
+	if !flag.GetYes(ctx) {
+		switch confirmed, err := prompt.Confirmf(ctx, "Update the machines of group %s?", group); {
+		case err == nil:
+			if !confirmed {
+				return nil
+			}
+		case prompt.IsNonInteractive(err):
+			return prompt.NonInteractiveError("--yes flag must be specified when not running interactively")
+		default:
+			return err
+		}
+	}
+
+	machines, releaseFunc, err := mach.AcquireLeases(ctx, machines)
+	defer releaseFunc()
+	if err != nil {
+		return err
+	}
+
+	for _, m := range machines {
+		for i := range m.Config.Services {
+			m.Config.Services[i].Autostop = fly.Pointer(autostop)
+			m.Config.Services[i].Autostart = fly.Pointer(autostart)
+			m.Config.Services[i].MinMachinesRunning = fly.Pointer(minRunning)
+		}
+		input := &fly.LaunchMachineInput{
+			Name:   m.Name,
+			Region: m.Region,
+			Config: m.Config,
+		}
+		if err := mach.Update(ctx, m, input); err != nil {
+			return err
+		}
+	}
+
+	if err := saveAutostop(ctx, appName, group, autostop, autostart, minRunning); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(io.Out)
+	for _, line := range coldStartProfile(group, machines, autostop, autostart, minRunning, appConfig.PrimaryRegion) {
+		fmt.Fprintln(io.Out, line)
+	}
+	return nil
+}
+
+// saveAutostop writes the settings to the local fly.toml of appName, if there
+// is one, so they survive the next deploy. The file keeps its comments and
+// formatting.
+func saveAutostop(ctx context.Context, appName, group string, autostop fly.MachineAutostop, autostart bool, minRunning int) error {
+	io := iostreams.FromContext(ctx)
+
+	cfg := appconfig.ConfigFromContext(ctx)
+	if cfg == nil || cfg.AppName != appName {
+		fmt.Fprintf(io.ErrOut, "No fly.toml found for %s, add these settings to the services of group '%s' or the next deploy reverts them\n", appName, group)
+		return nil
+	}
+
+	path := cfg.ConfigFilePath()
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	buf, changed, err := cfg.SetConfigFileAutostop(path, buf, group, autostop, autostart, minRunning)
+	if err != nil {
+		return fmt.Errorf("could not save the settings to %s: %w", path, err)
+	}
+	if changed == 0 {
+		fmt.Fprintf(io.ErrOut, "%s has no services for group '%s', add these settings to them or the next deploy reverts them\n", helpers.PathRelativeToCWD(path), group)
+		return nil
+	}
+	if err := os.WriteFile(path, buf, info.Mode()); err != nil {
+		return err
+	}
+	fmt.Fprintf(io.Out, "Updated %s\n", helpers.PathRelativeToCWD(path))
+	return nil
+}
+
+// coldStartProfile describes how the machines of group behave once idle and
+// what a request hitting a stopped group should expect.
+func coldStartProfile(group string, machines []*fly.Machine, autostop fly.MachineAutostop, autostart bool, minRunning int, primaryRegion string) []string {
+	lines := []string{fmt.Sprintf("Cold start profile for group '%s':", group)}
+	add := func(label, format string, args ...any) {
+		lines = append(lines, fmt.Sprintf("  %-16s %s", label+":", fmt.Sprintf(format, args...)))
+	}
+
+	suspendable := lo.CountBy(machines, func(m *fly.Machine) bool {
+		return m.Config.Guest != nil && m.Config.Guest.MemoryMB <= suspendMaxMemoryMB && m.Config.Guest.GPUKind == ""
+	})
+
+	switch {
+	case autostop == fly.MachineAutostopSuspend && suspendable == len(machines):
+		add("Idle machines", "suspended, resuming from a memory snapshot usually takes a few hundred milliseconds")
+	case autostop == fly.MachineAutostopSuspend:
+		add("Idle machines", "suspended, except %d machines with GPUs or more than %d MB of memory which are stopped", len(machines)-suspendable, suspendMaxMemoryMB)
+	default:
+		add("Idle machines", "stopped, starting one takes a machine boot plus your app's own startup time")
+	}
+
+	if autostart {
+		add("Woken by", "requests through the Fly proxy, the first one waits for the machine to start")
+	} else {
+		add("Woken by", "nothing, run 'fly machine start' or pass --wake-on-request")
+	}
+
+	switch {
+	case minRunning == 0:
+		add("Always running", "none, every region can scale to zero")
+	case primaryRegion != "":
+		add("Always running", "%d machines in the primary region %s", minRunning, primaryRegion)
+	default:
+		add("Always running", "%d machines in the primary region", minRunning)
+	}
+
+	sizes := lo.Uniq(lo.Map(machines, func(m *fly.Machine, _ int) string {
+		return m.Config.Guest.ToSize()
+	}))
+	add("Machines", "%d (%s)", len(machines), strings.Join(sizes, ", "))
+	return lines
+}
@@ -0,0 +1,36 @@
+package scale
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	fly "github.com/superfly/fly-go"
+)
+
+func Test_coldStartProfile(t *testing.T) {
+	newMachine := func(size string) *fly.Machine {
+		guest := &fly.MachineGuest{}
+		guest.SetSize(size)
+		return &fly.Machine{Config: &fly.MachineConfig{Guest: guest}}
+	}
+	machines := []*fly.Machine{newMachine("shared-cpu-1x"), newMachine("shared-cpu-1x")}
+
+	lines := coldStartProfile("web", machines, fly.MachineAutostopSuspend, true, 1, "iad")
+	assert.Equal(t, []string{
+		"Cold start profile for group 'web':",
+		"  Idle machines:   suspended, resuming from a memory snapshot usually takes a few hundred milliseconds",
+		"  Woken by:        requests through the Fly proxy, the first one waits for the machine to start",
+		"  Always running:  1 machines in the primary region iad",
+		"  Machines:        2 (shared-cpu-1x)",
+	}, lines)
+
+	machines = append(machines, newMachine("performance-4x"))
+	lines = coldStartProfile("web", machines, fly.MachineAutostopSuspend, false, 0, "iad")
+	assert.Contains(t, lines[1], "except 1 machines with GPUs or more than 2048 MB")
+	assert.Contains(t, lines[2], "nothing")
+	assert.Contains(t, lines[3], "none")
+	assert.Equal(t, "  Machines:        3 (shared-cpu-1x, performance-4x)", lines[4])
+
+	lines = coldStartProfile("web", machines, fly.MachineAutostopStop, true, 0, "")
+	assert.Contains(t, lines[1], "stopped")
+}
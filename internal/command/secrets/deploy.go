@@ -12,12 +12,22 @@ import (
 	"github.com/superfly/flyctl/internal/flapsutil"
 	"github.com/superfly/flyctl/internal/flyerr"
 	"github.com/superfly/flyctl/internal/flyutil"
+	"github.com/superfly/flyctl/internal/machine"
 )
 
 func newDeploy() (cmd *cobra.Command) {
 	const (
-		long  = `Deploy staged secrets for an application`
-		short = long
+		short = `Deploy staged secrets for an application`
+		long  = short + `
+
+Use --select to first deploy them to the machines matching a selector, to
+verify the change before deploying it to all the machines of the app:
+
+  fly secrets set --stage DATABASE_URL=...
+  fly secrets deploy --select "region=iad"
+  fly secrets deploy
+
+Machines that restart or are updated in between get the new secrets too.`
 		usage = "deploy [flags]"
 	)
 
@@ -27,6 +37,10 @@ func newDeploy() (cmd *cobra.Command) {
 		flag.App(),
 		flag.AppConfig(),
 		flag.Detach(),
+		flag.String{
+			Name:        "select",
+			Description: `Only deploy the secrets to the machines matching a selector, e.g. "region=iad" or "process_group=worker"`,
+		},
 	)
 
 	return cmd
@@ -63,5 +77,12 @@ func runDeploy(ctx context.Context) (err error) {
 		}
 	}
 
-	return DeploySecrets(ctx, app, false, flag.GetBool(ctx, "detach"))
+	var selector machine.Selector
+	if s := flag.GetString(ctx, "select"); s != "" {
+		if selector, err = machine.ParseSelector(s); err != nil {
+			return err
+		}
+	}
+
+	return deploySecrets(ctx, app, false, flag.GetBool(ctx, "detach"), selector)
 }
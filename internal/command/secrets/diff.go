@@ -0,0 +1,162 @@
+package secrets
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyerr"
+	"github.com/superfly/flyctl/internal/flyutil"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func newDiff() (cmd *cobra.Command) {
+	const (
+		long = `Compare the secrets of an application with a local file, without revealing
+any value: the digests of the secrets set on the application are compared
+with the values of the file, a dotenv file of NAME=VALUE pairs or, with a
+.json extension, a JSON object.
+
+Secrets of the file not set on the application are shown with +, the ones
+with another value with ~ and the ones set on the application but missing
+from the file with -. Exits with status 1 if there are any differences.`
+		short = "Compare the secrets of an application with a dotenv or JSON file"
+		usage = "diff <file> [flags]"
+	)
+
+	cmd = command.New(usage, short, long, runDiff, command.RequireSession, command.RequireAppName)
+	cmd.Args = cobra.ExactArgs(1)
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.JSONOutput(),
+	)
+
+	return cmd
+}
+
+// secretsDiff lists the names of the secrets a local file adds, changes and
+// removes compared with the ones set on an app.
+type secretsDiff struct {
+	Added   []string `json:"added"`
+	Changed []string `json:"changed"`
+	Removed []string `json:"removed"`
+}
+
+func (d secretsDiff) empty() bool {
+	return len(d.Added) == 0 && len(d.Changed) == 0 && len(d.Removed) == 0
+}
+
+func runDiff(ctx context.Context) error {
+	streams := iostreams.FromContext(ctx)
+	colorize := streams.ColorScheme()
+	client := flyutil.ClientFromContext(ctx)
+	appName := appconfig.NameFromContext(ctx)
+	path := flag.FirstArg(ctx)
+
+	local, err := readSecretsFile(path)
+	if err != nil {
+		return err
+	}
+
+	current, err := client.GetAppSecrets(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	diff := diffSecrets(local, current)
+	if config.FromContext(ctx).JSONOutput {
+		if err := render.JSON(streams.Out, diff); err != nil {
+			return err
+		}
+	} else if diff.empty() {
+		fmt.Fprintf(streams.Out, "The secrets of %s match %s\n", appName, path)
+	} else {
+		fmt.Fprintf(streams.Out, "Secrets of %s differing from %s:\n", appName, path)
+		for _, name := range diff.Added {
+			fmt.Fprintln(streams.Out, colorize.Green("  + "+name))
+		}
+		for _, name := range diff.Changed {
+			fmt.Fprintln(streams.Out, colorize.Yellow("  ~ "+name))
+		}
+		for _, name := range diff.Removed {
+			fmt.Fprintln(streams.Out, colorize.Red("  - "+name))
+		}
+	}
+
+	if !diff.empty() {
+		return flyerr.ExitCodeError{Code: 1}
+	}
+	return nil
+}
+
+// readSecretsFile reads the secrets of a dotenv file, or of a JSON object for
+// files with a .json extension.
+func readSecretsFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var data map[string]any
+		if err := json.NewDecoder(f).Decode(&data); err != nil {
+			return nil, fmt.Errorf("Failed to parse secrets from %s, expected a JSON object: %w", path, err)
+		}
+		return stringValues(data), nil
+	}
+
+	secrets, err := parseDotenv(f)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse secrets from %s: %w", path, err)
+	}
+	return secrets, nil
+}
+
+func diffSecrets(local map[string]string, current []fly.Secret) secretsDiff {
+	var diff secretsDiff
+
+	digests := map[string]string{}
+	for _, s := range current {
+		digests[s.Name] = s.Digest
+		if _, ok := local[s.Name]; !ok {
+			diff.Removed = append(diff.Removed, s.Name)
+		}
+	}
+	for name, value := range local {
+		digest, ok := digests[name]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, name)
+		case !digestMatches(digest, value):
+			diff.Changed = append(diff.Changed, name)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Changed)
+	sort.Strings(diff.Removed)
+	return diff
+}
+
+// digestMatches tells whether digest, a truncated hex SHA-256 of the value of
+// a secret, is the one of value.
+func digestMatches(digest, value string) bool {
+	sum := sha256.Sum256([]byte(value))
+	return digest != "" && strings.HasPrefix(hex.EncodeToString(sum[:]), strings.ToLower(digest))
+}
@@ -0,0 +1,36 @@
+package secrets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	fly "github.com/superfly/fly-go"
+)
+
+func Test_diff_secrets(t *testing.T) {
+	digest := func(value string) string {
+		sum := sha256.Sum256([]byte(value))
+		return hex.EncodeToString(sum[:])[:16]
+	}
+
+	current := []fly.Secret{
+		{Name: "SAME", Digest: digest("same")},
+		{Name: "CHANGED", Digest: digest("old")},
+		{Name: "REMOVED", Digest: digest("gone")},
+	}
+	local := map[string]string{
+		"SAME":    "same",
+		"CHANGED": "new",
+		"ADDED":   "added",
+	}
+
+	assert.Equal(t, secretsDiff{
+		Added:   []string{"ADDED"},
+		Changed: []string{"CHANGED"},
+		Removed: []string{"REMOVED"},
+	}, diffSecrets(local, current))
+
+	assert.True(t, diffSecrets(map[string]string{"SAME": "same"}, current[:1]).empty())
+}
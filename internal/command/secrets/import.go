@@ -5,18 +5,36 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
 
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
+	fly "github.com/superfly/fly-go"
 	"github.com/superfly/flyctl/internal/appconfig"
 	"github.com/superfly/flyctl/internal/command"
 	"github.com/superfly/flyctl/internal/flag"
 	"github.com/superfly/flyctl/internal/flyutil"
+	"github.com/superfly/flyctl/internal/prompt"
+	"github.com/superfly/flyctl/iostreams"
 )
 
 func newImport() (cmd *cobra.Command) {
 	const (
-		long  = `Set one or more encrypted secrets for an application. Values are read from stdin as NAME=VALUE pairs`
-		short = `Set secrets as NAME=VALUE pairs from stdin`
+		long = `Set one or more encrypted secrets for an application. Values are read from stdin
+as NAME=VALUE pairs, one per line, where values are taken as they are and
+"""...""" values may span lines.
+
+With --dotenv, they are read from a file in the dotenv format instead:
+
+  export NAME=value      # export and comments after unquoted values are ignored
+  NAME='literal value'   # no escapes in single quotes
+  NAME="line\nline"      # \n, \t, \" and \\ escapes in double quotes
+  NAME="first line
+  second line"           # quoted values may span lines, like """...""" ones
+
+The names of the secrets to set are shown, and confirmed when running
+interactively, before all of them are set in a single release.`
+		short = `Set secrets as NAME=VALUE pairs from stdin or a dotenv file`
 		usage = "import [flags]"
 	)
 
@@ -24,12 +42,18 @@ func newImport() (cmd *cobra.Command) {
 
 	flag.Add(cmd,
 		sharedFlags,
+		flag.Yes(),
+		flag.String{
+			Name:        "dotenv",
+			Description: "Read the secrets from this dotenv file, like .env.production, instead of stdin",
+		},
 	)
 
 	return cmd
 }
 
 func runImport(ctx context.Context) (err error) {
+	streams := iostreams.FromContext(ctx)
 	client := flyutil.ClientFromContext(ctx)
 	appName := appconfig.NameFromContext(ctx)
 	app, err := client.GetAppCompact(ctx, appName)
@@ -37,13 +61,62 @@ func runImport(ctx context.Context) (err error) {
 		return
 	}
 
-	secrets, err := parseSecrets(os.Stdin)
+	source := "stdin"
+	var secrets map[string]string
+	if path := flag.GetString(ctx, "dotenv"); path != "" {
+		f, openErr := os.Open(path)
+		if openErr != nil {
+			return openErr
+		}
+		defer f.Close()
+		source = path
+		secrets, err = parseDotenv(f)
+	} else {
+		secrets, err = parseSecrets(os.Stdin)
+	}
 	if err != nil {
-		return fmt.Errorf("Failed to parse secrets from stdin: %w", err)
+		return fmt.Errorf("Failed to parse secrets from %s: %w", source, err)
 	}
 	if len(secrets) < 1 {
 		return errors.New("requires at least one SECRET=VALUE pair")
 	}
 
+	current, err := client.GetAppSecrets(ctx, appName)
+	if err != nil {
+		return err
+	}
+	printSecretsPreview(streams, appName, secrets, current)
+
+	// Secrets piped in leave no stdin to confirm with
+	if streams.IsInteractive() && !flag.GetYes(ctx) {
+		switch confirmed, err := prompt.Confirmf(ctx, "Set %d secrets on %s?", len(secrets), appName); {
+		case err == nil:
+			if !confirmed {
+				return nil
+			}
+		case prompt.IsNonInteractive(err):
+			return prompt.NonInteractiveError("--yes flag must be specified when not running interactively")
+		default:
+			return err
+		}
+	}
+
 	return SetSecretsAndDeploy(ctx, app, secrets, flag.GetBool(ctx, "stage"), flag.GetBool(ctx, "detach"))
 }
+
+// printSecretsPreview lists the names of secrets, marking the ones already set
+// on the app, which are replaced.
+func printSecretsPreview(streams *iostreams.IOStreams, appName string, secrets map[string]string, current []fly.Secret) {
+	colorize := streams.ColorScheme()
+	names := lo.Keys(secrets)
+	sort.Strings(names)
+
+	fmt.Fprintf(streams.Out, "Secrets to set on %s:\n", appName)
+	for _, name := range names {
+		if lo.ContainsBy(current, func(s fly.Secret) bool { return s.Name == name }) {
+			fmt.Fprintln(streams.Out, colorize.Yellow("  ~ "+name))
+		} else {
+			fmt.Fprintln(streams.Out, colorize.Green("  + "+name))
+		}
+	}
+}
@@ -2,8 +2,15 @@ package secrets
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
 
 	"github.com/spf13/cobra"
+	fly "github.com/superfly/fly-go"
 	"github.com/superfly/flyctl/internal/appconfig"
 	"github.com/superfly/flyctl/internal/command"
 	"github.com/superfly/flyctl/internal/config"
@@ -18,7 +25,11 @@ func newList() (cmd *cobra.Command) {
 	const (
 		long = `List the secrets available to the application. It shows each secret's
 name, a digest of its value and the time the secret was last set. The
-actual value of the secret is only available to the application.`
+actual value of the secret is only available to the application.
+
+Setting a secret replaces it, so the time it was last set is also the time
+it was last rotated. Use --stale-after to only list the secrets that haven't
+been rotated for a while, e.g. --stale-after 90d.`
 		short = `List application secret names, digests and creation times`
 		usage = "list [flags]"
 	)
@@ -31,6 +42,10 @@ actual value of the secret is only available to the application.`
 		flag.App(),
 		flag.AppConfig(),
 		flag.JSONOutput(),
+		flag.String{
+			Name:        "stale-after",
+			Description: "Only list the secrets last set longer ago than this age, e.g. 90d, 2w or 12h",
+		},
 	)
 
 	return cmd
@@ -47,6 +62,14 @@ func runList(ctx context.Context) (err error) {
 		return err
 	}
 
+	if staleAfter := flag.GetString(ctx, "stale-after"); staleAfter != "" {
+		age, err := parseSecretAge(staleAfter)
+		if err != nil {
+			return fmt.Errorf("invalid --stale-after: %w", err)
+		}
+		secrets = staleSecrets(secrets, time.Now().Add(-age))
+	}
+
 	var rows [][]string
 
 	for _, secret := range secrets {
@@ -68,3 +91,26 @@ func runList(ctx context.Context) (err error) {
 		return render.Table(out, "", rows, headers...)
 	}
 }
+
+// staleSecrets returns the secrets last set before threshold.
+func staleSecrets(secrets []fly.Secret, threshold time.Time) []fly.Secret {
+	return lo.Filter(secrets, func(s fly.Secret, _ int) bool {
+		return s.CreatedAt.Before(threshold)
+	})
+}
+
+// parseSecretAge parses an age like 90d, 2w or 12h.
+func parseSecretAge(value string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(value, suffix); ok {
+			if count, err := strconv.Atoi(n); err == nil && count >= 0 {
+				return time.Duration(count) * unit, nil
+			}
+		}
+	}
+
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("%q isn't an age such as 90d, 2w or 12h", value)
+}
@@ -0,0 +1,38 @@
+package secrets
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	fly "github.com/superfly/fly-go"
+)
+
+func Test_parse_secret_age(t *testing.T) {
+	for value, expected := range map[string]time.Duration{
+		"90d": 90 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"12h": 12 * time.Hour,
+	} {
+		age, err := parseSecretAge(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, expected, age, value)
+	}
+
+	for _, value := range []string{"", "-3d", "soon"} {
+		_, err := parseSecretAge(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func Test_stale_secrets(t *testing.T) {
+	now := time.Now()
+	secrets := []fly.Secret{
+		{Name: "OLD", CreatedAt: now.Add(-100 * 24 * time.Hour)},
+		{Name: "NEW", CreatedAt: now.Add(-time.Hour)},
+	}
+
+	stale := staleSecrets(secrets, now.Add(-90*24*time.Hour))
+	assert.Equal(t, []fly.Secret{secrets[0]}, stale)
+}
@@ -12,6 +12,9 @@ const (
 	parserStateMultiline  = iota
 )
 
+// parseSecrets reads NAME=VALUE pairs as 'fly secrets import' always has from
+// stdin: values are taken as they are, except for a pair of surrounding double
+// quotes, and only """ quotes span lines.
 func parseSecrets(reader io.Reader) (map[string]string, error) {
 	secrets := map[string]string{}
 	scanner := bufio.NewScanner(reader)
@@ -64,3 +67,107 @@ func parseSecrets(reader io.Reader) (map[string]string, error) {
 
 	return secrets, nil
 }
+
+// parseDotenv reads NAME=VALUE pairs in the dotenv format. Values may be
+// single quoted, taken literally, double quoted, with escapes like \n, or
+// triple double quoted, and quoted values may span lines. Lines may start with
+// export, and comments start with #, also after unquoted values.
+func parseDotenv(reader io.Reader) (map[string]string, error) {
+	buf, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.ReplaceAll(string(buf), "\r\n", "\n"), "\n")
+
+	secrets := map[string]string{}
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimLeft(lines[i], " \t")
+		// Skip comments and empty lines
+		if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("Secrets must be provided as NAME=VALUE pairs (%s is invalid)", lines[i])
+		}
+		value = strings.TrimLeft(value, " \t")
+
+		var quote string
+		switch {
+		case strings.HasPrefix(value, `"""`):
+			quote = `"""`
+		case strings.HasPrefix(value, `"`):
+			quote = `"`
+		case strings.HasPrefix(value, `'`):
+			quote = `'`
+		default:
+			value, _, _ = strings.Cut(value, " #")
+			secrets[key] = strings.TrimSpace(value)
+			continue
+		}
+
+		// The value ends at the closing quote, on this line or a following one
+		value = value[len(quote):]
+		start := i
+		for {
+			if end := closingQuote(value, quote); end >= 0 {
+				value = value[:end]
+				break
+			}
+			if i++; i == len(lines) {
+				return nil, fmt.Errorf("%s has an unterminated quoted value starting on line %d", key, start+1)
+			}
+			value += "\n" + lines[i]
+		}
+		if quote == `"` {
+			value = unescapeDoubleQuoted(value)
+		}
+		secrets[key] = value
+	}
+
+	return secrets, nil
+}
+
+// closingQuote returns the index of quote in s, skipping escaped double
+// quotes, or -1.
+func closingQuote(s, quote string) int {
+	if quote != `"` {
+		return strings.Index(s, quote)
+	}
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+func unescapeDoubleQuoted(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case '"', '\\', '$':
+			b.WriteByte(s[i])
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
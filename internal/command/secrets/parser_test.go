@@ -90,3 +90,58 @@ func Test_parse_with_double_quotes(t *testing.T) {
 		"FOO": "BAR BAZ",
 	}, secrets)
 }
+
+func Test_parse_legacy(t *testing.T) {
+	// Plain stdin import keeps values as they are, only dotenv files get
+	// comments, trimming, escapes and multi-line quotes
+	reader := strings.NewReader(`FOO=a # not a comment
+SPACED= keep spaces 
+ESCAPES="line\nline \"quoted\""
+SINGLE='unterminated
+UNBALANCED="unterminated
+NEXT=value
+`)
+	secrets, err := parseSecrets(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"FOO":        "a # not a comment",
+		"SPACED":     " keep spaces ",
+		"ESCAPES":    `line\nline \"quoted\"`,
+		"SINGLE":     "'unterminated",
+		"UNBALANCED": `"unterminated`,
+		"NEXT":       "value",
+	}, secrets)
+}
+
+func Test_parse_dotenv(t *testing.T) {
+	reader := strings.NewReader(`
+export DATABASE_URL=postgres://db:5432/app # primary
+  SPACED = value with spaces  
+SINGLE='no $escapes\n here'
+DOUBLE="line one\nline \"two\""
+HASH="not # a comment"
+PEM="-----BEGIN KEY-----
+abc
+-----END KEY-----"
+EMPTY=
+`)
+	secrets, err := parseDotenv(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"DATABASE_URL": "postgres://db:5432/app",
+		"SPACED":       "value with spaces",
+		"SINGLE":       `no $escapes\n here`,
+		"DOUBLE":       "line one\nline \"two\"",
+		"HASH":         "not # a comment",
+		"PEM":          "-----BEGIN KEY-----\nabc\n-----END KEY-----",
+		"EMPTY":        "",
+	}, secrets)
+}
+
+func Test_parse_unterminated(t *testing.T) {
+	_, err := parseDotenv(strings.NewReader("FOO=\"BAR\nBAZ=QUX\n"))
+	assert.ErrorContains(t, err, "FOO has an unterminated quoted value starting on line 1")
+
+	_, err = parseDotenv(strings.NewReader("NOT A PAIR\n"))
+	assert.Error(t, err)
+}
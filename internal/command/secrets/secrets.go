@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 	fly "github.com/superfly/fly-go"
 	"github.com/superfly/fly-go/flaps"
@@ -12,6 +13,7 @@ import (
 	"github.com/superfly/flyctl/internal/command/deploy"
 	"github.com/superfly/flyctl/internal/flag"
 	"github.com/superfly/flyctl/internal/flapsutil"
+	"github.com/superfly/flyctl/internal/machine"
 	"github.com/superfly/flyctl/internal/sentry"
 	"github.com/superfly/flyctl/iostreams"
 )
@@ -43,6 +45,8 @@ func New() *cobra.Command {
 		newSet(),
 		newUnset(),
 		newImport(),
+		newSync(),
+		newDiff(),
 		newDeploy(),
 	)
 
@@ -50,6 +54,12 @@ func New() *cobra.Command {
 }
 
 func DeploySecrets(ctx context.Context, app *fly.AppCompact, stage bool, detach bool) error {
+	return deploySecrets(ctx, app, stage, detach, nil)
+}
+
+// deploySecrets restarts the machines of the app for them to get its secrets,
+// only the ones matching selector if it isn't nil.
+func deploySecrets(ctx context.Context, app *fly.AppCompact, stage bool, detach bool, selector machine.Selector) error {
 	out := iostreams.FromContext(ctx).Out
 
 	if stage {
@@ -76,6 +86,16 @@ func DeploySecrets(ctx context.Context, app *fly.AppCompact, stage bool, detach
 		return nil
 	}
 
+	var onlyMachines map[string]bool
+	if selector != nil {
+		selected := selector.Filter(machines)
+		if len(selected) == 0 {
+			return fmt.Errorf("no machines of %s match the selector", app.Name)
+		}
+		onlyMachines = lo.SliceToMap(selected, func(m *fly.Machine) (string, bool) { return m.ID, true })
+		fmt.Fprintf(out, "Deploying secrets to %d of the %d machines of %s\n", len(selected), len(machines), app.Name)
+	}
+
 	// It would be confusing for setting secrets to deploy the current fly.toml file.
 	// Instead, we always grab the currently deployed app config
 	cfg, err := appconfig.FromRemoteApp(ctx, app.Name)
@@ -88,6 +108,7 @@ func DeploySecrets(ctx context.Context, app *fly.AppCompact, stage bool, detach
 		AppCompact:       app,
 		RestartOnly:      true,
 		SkipHealthChecks: detach,
+		OnlyMachines:     onlyMachines,
 	})
 	if err != nil {
 		sentry.CaptureExceptionWithAppInfo(ctx, err, "secrets", app)
@@ -2,8 +2,10 @@ package secrets
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 	fly "github.com/superfly/fly-go"
@@ -17,8 +19,12 @@ import (
 
 func newSet() (cmd *cobra.Command) {
 	const (
-		long  = `Set one or more encrypted secrets for an application`
-		short = long
+		short = `Set one or more encrypted secrets for an application`
+		long  = short + `
+
+With --file, a secret is set to the base64 encoded content of a local file,
+the encoding [[files]] with a secret_name expect, to write it to the machines
+as a file, like a TLS key or a JSON credentials file.`
 		usage = "set [flags] NAME=VALUE NAME=VALUE ..."
 	)
 
@@ -26,10 +32,12 @@ func newSet() (cmd *cobra.Command) {
 
 	flag.Add(cmd,
 		sharedFlags,
+		flag.StringArray{
+			Name:        "file",
+			Description: "Set a secret to the base64 encoded content of a local file, as NAME=PATH. Can be specified multiple times.",
+		},
 	)
 
-	cmd.Args = cobra.MinimumNArgs(1)
-
 	return cmd
 }
 
@@ -46,6 +54,18 @@ func runSet(ctx context.Context) (err error) {
 		return fmt.Errorf("could not parse secrets: %w", err)
 	}
 
+	files, err := cmdutil.ParseKVStringsToMap(flag.GetStringArray(ctx, "file"))
+	if err != nil {
+		return fmt.Errorf("could not parse --file: %w", err)
+	}
+	for k, path := range files {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("could not read file %s for '%s': %w", path, k, err)
+		}
+		secrets[k] = base64.StdEncoding.EncodeToString(content)
+	}
+
 	for k, v := range secrets {
 		if v == "-" {
 			if !helpers.HasPipedStdin() {
@@ -0,0 +1,278 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyutil"
+	"github.com/superfly/flyctl/internal/prompt"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func newSync() (cmd *cobra.Command) {
+	const (
+		long = `Set the secrets of an application from an external secrets manager, read
+with its CLI, which must be installed and logged in:
+
+  vault://<mount>/<path>         a HashiCorp Vault KV secret (vault kv get)
+  aws://<secret id>[?region=<r>] an AWS Secrets Manager secret holding a JSON
+                                 object of key/value pairs (aws secretsmanager)
+  op://<vault>/<item>            the fields of a 1Password item (op item get)
+
+Every key of the source is set as a secret of the same name, unless a
+mapping file is given with --mapping. It holds a NAME=KEY line for every
+secret to set, NAME being the name of the secret and KEY the key of the
+source, and only those secrets are set. Secrets missing from the source are
+left untouched.`
+		short = "Set secrets from HashiCorp Vault, AWS Secrets Manager or 1Password"
+		usage = "sync --from <source> [flags]"
+	)
+
+	cmd = command.New(usage, short, long, runSync, command.RequireSession, command.RequireAppName)
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		sharedFlags,
+		flag.Yes(),
+		flag.String{
+			Name:        "from",
+			Description: "Source of the secrets, like vault://secret/myapp, aws://myapp/production or op://Production/myapp",
+		},
+		flag.String{
+			Name:        "mapping",
+			Description: "File of NAME=KEY lines, setting each secret NAME from the key KEY of the source",
+		},
+		flag.Bool{
+			Name:        "dry-run",
+			Description: "Only show the secrets that would be set",
+		},
+	)
+
+	return cmd
+}
+
+func runSync(ctx context.Context) error {
+	streams := iostreams.FromContext(ctx)
+	client := flyutil.ClientFromContext(ctx)
+	appName := appconfig.NameFromContext(ctx)
+
+	from := flag.GetString(ctx, "from")
+	if from == "" {
+		return errors.New("--from must be specified")
+	}
+	source, err := url.Parse(from)
+	if err != nil {
+		return fmt.Errorf("invalid source %s: %w", from, err)
+	}
+
+	values, err := fetchExternalSecrets(ctx, source)
+	if err != nil {
+		return err
+	}
+
+	var mapping map[string]string
+	if path := flag.GetString(ctx, "mapping"); path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if mapping, err = parseDotenv(f); err != nil {
+			return fmt.Errorf("Failed to parse the mapping file %s: %w", path, err)
+		}
+	}
+
+	secrets, err := mapExternalSecrets(values, mapping)
+	if err != nil {
+		return fmt.Errorf("%s: %w", from, err)
+	}
+	if len(secrets) < 1 {
+		return fmt.Errorf("%s has no secrets to set", from)
+	}
+
+	app, err := client.GetAppCompact(ctx, appName)
+	if err != nil {
+		return err
+	}
+	current, err := client.GetAppSecrets(ctx, appName)
+	if err != nil {
+		return err
+	}
+	printSecretsPreview(streams, appName, secrets, current)
+
+	if flag.GetBool(ctx, "dry-run") {
+		return nil
+	}
+	if !flag.GetYes(ctx) {
+		switch confirmed, err := prompt.Confirmf(ctx, "Set %d secrets on %s?", len(secrets), appName); {
+		case err == nil:
+			if !confirmed {
+				return nil
+			}
+		case prompt.IsNonInteractive(err):
+			return prompt.NonInteractiveError("--yes flag must be specified when not running interactively")
+		default:
+			return err
+		}
+	}
+
+	return SetSecretsAndDeploy(ctx, app, secrets, flag.GetBool(ctx, "stage"), flag.GetBool(ctx, "detach"))
+}
+
+// mapExternalSecrets returns the secrets to set from the values of a source:
+// all of them, or the ones mapping, from secret names to keys of values, asks
+// for.
+func mapExternalSecrets(values, mapping map[string]string) (map[string]string, error) {
+	if mapping == nil {
+		return values, nil
+	}
+
+	secrets := map[string]string{}
+	var missing []string
+	for name, key := range mapping {
+		value, ok := values[key]
+		if !ok {
+			missing = append(missing, key)
+			continue
+		}
+		secrets[name] = value
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("keys %s of the mapping aren't in the source", strings.Join(missing, ", "))
+	}
+	return secrets, nil
+}
+
+// fetchExternalSecrets reads the key/value pairs of source with the CLI of its
+// secrets manager.
+func fetchExternalSecrets(ctx context.Context, source *url.URL) (map[string]string, error) {
+	target := strings.Trim(path.Join(source.Host, source.Path), "/")
+	if target == "" {
+		return nil, fmt.Errorf("%s doesn't name a secret", source)
+	}
+
+	switch source.Scheme {
+	case "vault":
+		out, err := runSecretsCLI(ctx, "vault", "kv", "get", "-format=json", target)
+		if err != nil {
+			return nil, err
+		}
+		return parseVaultSecret(out)
+	case "aws":
+		args := []string{"secretsmanager", "get-secret-value", "--secret-id", target, "--query", "SecretString", "--output", "text"}
+		if region := source.Query().Get("region"); region != "" {
+			args = append(args, "--region", region)
+		}
+		out, err := runSecretsCLI(ctx, "aws", args...)
+		if err != nil {
+			return nil, err
+		}
+		return parseAWSSecretString(out)
+	case "op":
+		vault, item, ok := strings.Cut(target, "/")
+		if !ok {
+			return nil, fmt.Errorf("%s must be op://<vault>/<item>", source)
+		}
+		out, err := runSecretsCLI(ctx, "op", "item", "get", item, "--vault", vault, "--format", "json")
+		if err != nil {
+			return nil, err
+		}
+		return parseOnePasswordItem(out)
+	default:
+		return nil, fmt.Errorf("unsupported source %s, use vault://, aws:// or op://", source)
+	}
+}
+
+func runSecretsCLI(ctx context.Context, name string, args ...string) ([]byte, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return nil, fmt.Errorf("the %s CLI is required to read the secrets, install it and log in: %w", name, err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s %s failed: %w: %s", name, strings.Join(args[:2], " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// parseVaultSecret reads the output of vault kv get -format=json, the data of
+// KV version 2 secrets being nested with their metadata.
+func parseVaultSecret(out []byte) (map[string]string, error) {
+	var secret struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(out, &secret); err != nil {
+		return nil, fmt.Errorf("unexpected vault output: %w", err)
+	}
+
+	data := secret.Data
+	if nested, ok := data["data"].(map[string]any); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+	return stringValues(data), nil
+}
+
+// parseAWSSecretString reads a SecretString holding a JSON object.
+func parseAWSSecretString(out []byte) (map[string]string, error) {
+	var data map[string]any
+	if err := json.Unmarshal(bytes.TrimSpace(out), &data); err != nil {
+		return nil, fmt.Errorf("the secret must hold a JSON object of key/value pairs: %w", err)
+	}
+	return stringValues(data), nil
+}
+
+// parseOnePasswordItem reads the output of op item get --format json, keying
+// the fields with a value by their label.
+func parseOnePasswordItem(out []byte) (map[string]string, error) {
+	var item struct {
+		Fields []struct {
+			Label string  `json:"label"`
+			Value *string `json:"value"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal(out, &item); err != nil {
+		return nil, fmt.Errorf("unexpected op output: %w", err)
+	}
+
+	values := map[string]string{}
+	for _, field := range item.Fields {
+		if field.Label != "" && field.Value != nil {
+			values[field.Label] = *field.Value
+		}
+	}
+	return values, nil
+}
+
+func stringValues(data map[string]any) map[string]string {
+	values := map[string]string{}
+	for k, v := range data {
+		switch v := v.(type) {
+		case string:
+			values[k] = v
+		case nil:
+		default:
+			buf, _ := json.Marshal(v)
+			values[k] = string(buf)
+		}
+	}
+	return values
+}
@@ -0,0 +1,54 @@
+package secrets
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parse_vault_secret(t *testing.T) {
+	// KV version 2
+	values, err := parseVaultSecret([]byte(`{"data": {"data": {"DATABASE_URL": "postgres://db", "PORT": 5432}, "metadata": {"version": 3}}}`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"DATABASE_URL": "postgres://db", "PORT": "5432"}, values)
+
+	// KV version 1
+	values, err = parseVaultSecret([]byte(`{"data": {"API_KEY": "abc"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"API_KEY": "abc"}, values)
+}
+
+func Test_parse_aws_secret_string(t *testing.T) {
+	values, err := parseAWSSecretString([]byte("{\"username\":\"app\",\"password\":\"s3cr3t\"}\n"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"username": "app", "password": "s3cr3t"}, values)
+
+	_, err = parseAWSSecretString([]byte("plain text\n"))
+	assert.Error(t, err)
+}
+
+func Test_parse_onepassword_item(t *testing.T) {
+	values, err := parseOnePasswordItem([]byte(`{"fields": [
+		{"id": "username", "label": "username", "value": "app"},
+		{"id": "notesPlain", "label": "notesPlain"},
+		{"id": "abc", "label": "STRIPE_KEY", "value": "sk_live"}
+	]}`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"username": "app", "STRIPE_KEY": "sk_live"}, values)
+}
+
+func Test_map_external_secrets(t *testing.T) {
+	values := map[string]string{"username": "app", "password": "s3cr3t"}
+
+	secrets, err := mapExternalSecrets(values, nil)
+	require.NoError(t, err)
+	assert.Equal(t, values, secrets)
+
+	secrets, err = mapExternalSecrets(values, map[string]string{"DB_PASSWORD": "password"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"DB_PASSWORD": "s3cr3t"}, secrets)
+
+	_, err = mapExternalSecrets(values, map[string]string{"DB_HOST": "host"})
+	assert.ErrorContains(t, err, "keys host of the mapping aren't in the source")
+}
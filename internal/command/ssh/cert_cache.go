@@ -0,0 +1,114 @@
+package ssh
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/state"
+	"github.com/superfly/flyctl/terminal"
+)
+
+// persistEnv sets how long SSH certificates are reused, like --persist, for
+// scripts running many commands.
+const persistEnv = "FLY_SSH_PERSIST"
+
+// cachedCertificate is an SSH certificate kept between invocations of
+// flyctl, with its private key.
+type cachedCertificate struct {
+	Certificate string
+	PrivateKey  []byte
+	Expires     time.Time
+}
+
+// sshPersist returns how long SSH certificates are reused, from --persist or
+// FLY_SSH_PERSIST. Zero means they aren't.
+func sshPersist(ctx context.Context) time.Duration {
+	if d := flag.GetDuration(ctx, "persist"); d > 0 {
+		return d
+	}
+	if d, err := time.ParseDuration(os.Getenv(persistEnv)); err == nil && d > 0 {
+		return d
+	}
+	return 0
+}
+
+// sshCertificate returns an SSH certificate for user on the apps of org. With
+// persistence, certificates are cached in the config directory and reused by
+// the following connections, which then skip issuing one.
+func sshCertificate(ctx context.Context, org fly.OrganizationImpl, appNames []string, user string) (*fly.IssuedCertificate, ed25519.PrivateKey, error) {
+	persist := sshPersist(ctx)
+	if persist == 0 {
+		return singleUseSSHCertificate(ctx, org, appNames, user, 1)
+	}
+
+	path := certCachePath(ctx, org, appNames, user)
+	if cached, err := readCachedCertificate(path); err == nil && time.Now().Before(cached.Expires) {
+		terminal.Debugf("Reusing SSH certificate from %s\n", path)
+		return &fly.IssuedCertificate{Certificate: cached.Certificate}, ed25519.PrivateKey(cached.PrivateKey), nil
+	}
+
+	// Certificates are issued for whole hours, so they outlive the cache
+	hours := min(int((persist+time.Hour-1)/time.Hour), 72)
+	icert, priv, err := singleUseSSHCertificate(ctx, org, appNames, user, hours)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cached := cachedCertificate{
+		Certificate: icert.Certificate,
+		PrivateKey:  priv,
+		Expires:     time.Now().Add(min(persist, time.Duration(hours)*time.Hour)),
+	}
+	if err := writeCachedCertificate(path, cached); err != nil {
+		terminal.Debugf("Failed to cache SSH certificate: %v\n", err)
+	}
+
+	return icert, priv, nil
+}
+
+// certCachePath returns where the certificate for user on the apps of org is
+// cached.
+func certCachePath(ctx context.Context, org fly.OrganizationImpl, appNames []string, user string) string {
+	key := sha256.Sum256([]byte(strings.Join(append([]string{org.GetSlug(), user}, appNames...), "\x00")))
+	return filepath.Join(state.ConfigDirectory(ctx), "ssh", "certs", hex.EncodeToString(key[:16])+".json")
+}
+
+func readCachedCertificate(path string) (*cachedCertificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cached cachedCertificate
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil, err
+	}
+	return &cached, nil
+}
+
+func writeCachedCertificate(path string, cached cachedCertificate) error {
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	// The private key is as sensitive as the certificate is long-lived
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
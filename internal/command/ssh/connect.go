@@ -62,7 +62,7 @@ type ConnectParams struct {
 func Connect(p *ConnectParams, addr string) (*ssh.Client, error) {
 	terminal.Debugf("Fetching certificate for %s\n", addr)
 
-	cert, pk, err := singleUseSSHCertificate(p.Ctx, p.Org, p.AppNames, p.Username)
+	cert, pk, err := sshCertificate(p.Ctx, p.Org, p.AppNames, p.Username)
 	if err != nil {
 		return nil, fmt.Errorf("create ssh certificate: %w (if you haven't created a key for your org yet, try `flyctl ssh issue`)", err)
 	}
@@ -101,9 +101,8 @@ func Connect(p *ConnectParams, addr string) (*ssh.Client, error) {
 	return sshClient, nil
 }
 
-func singleUseSSHCertificate(ctx context.Context, org fly.OrganizationImpl, appNames []string, user string) (*fly.IssuedCertificate, ed25519.PrivateKey, error) {
+func singleUseSSHCertificate(ctx context.Context, org fly.OrganizationImpl, appNames []string, user string, hours int) (*fly.IssuedCertificate, ed25519.PrivateKey, error) {
 	client := flyutil.ClientFromContext(ctx)
-	hours := 1
 
 	pub, priv, err := ed25519.GenerateKey(nil)
 	if err != nil {
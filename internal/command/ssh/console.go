@@ -3,7 +3,9 @@ package ssh
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"os/user"
 	"runtime"
 	"time"
 
@@ -42,7 +44,7 @@ func stdArgsSSH(cmd *cobra.Command) {
 		flag.String{
 			Name:        "machine",
 			Default:     "",
-			Description: "Run the console in the existing machine with the specified ID",
+			Description: "Run the console in the existing machine with the specified ID or name",
 		},
 		flag.Bool{
 			Name:        "select",
@@ -72,6 +74,10 @@ func stdArgsSSH(cmd *cobra.Command) {
 			Default:     DefaultSshUsername,
 		},
 		flag.ProcessGroup(""),
+		flag.Duration{
+			Name:        "persist",
+			Description: "Reuse the SSH certificate of this command in the following ones for this long, like 10m, to speed up scripts. Also set with " + persistEnv,
+		},
 	)
 }
 
@@ -98,8 +104,18 @@ func lookupAddress(ctx context.Context, cli *agent.Client, dialer agent.Dialer,
 
 func newConsole() *cobra.Command {
 	const (
-		long  = `Connect to a running instance of the current app.`
-		short = long
+		long = `Connect to a running instance of the current app.
+
+The instance is picked with --machine, by ID or name, or interactively with
+--select; --region and --process-group narrow down the instances to pick from.
+
+With --all, the command given with --command runs on every started instance,
+narrowed down the same way, with its output prefixed with the machine ID:
+
+  fly ssh console --all -C "sh -c 'rm -rf /tmp/cache/*'"
+
+The exit status is the highest one of the command on the instances.`
+		short = "Connect to a running instance of the current app."
 		usage = "console"
 	)
 
@@ -109,6 +125,17 @@ func newConsole() *cobra.Command {
 
 	stdArgsSSH(cmd)
 
+	flag.Add(cmd,
+		flag.String{
+			Name:        "log-file",
+			Description: "Record the input and output of the session in this file, for audits. Set " + sessionLogDirEnv + " to record every session in a directory",
+		},
+		flag.Bool{
+			Name:        "all",
+			Description: "Run the command on every started machine, filtered with --region and --process-group",
+		},
+	)
+
 	return cmd
 }
 
@@ -155,6 +182,10 @@ func runConsole(ctx context.Context) error {
 		return err
 	}
 
+	if flag.GetBool(ctx, "all") {
+		return runConsoleAll(ctx, app, dialer)
+	}
+
 	addr, err := lookupAddress(ctx, agentclient, dialer, app, true)
 	if err != nil {
 		return err
@@ -185,7 +216,35 @@ func runConsole(ctx context.Context) error {
 		return err
 	}
 
-	if err := Console(ctx, sshc, cmd, allocPTY); err != nil {
+	var rec *sessionRecorder
+	started := time.Now()
+	if path := sessionLogPath(flag.GetString(ctx, "log-file"), app.Name, addr, started); path != "" {
+		localUser := os.Getenv("USER")
+		if u, err := user.Current(); err == nil {
+			localUser = u.Username
+		}
+
+		rec, err = newSessionRecorder(path, [][2]string{
+			{"started", started.UTC().Format(time.RFC3339)},
+			{"org", app.Organization.Slug},
+			{"app", app.Name},
+			{"machine", flag.GetString(ctx, "machine")},
+			{"address", addr},
+			{"user", params.Username},
+			{"local user", localUser},
+			{"command", cmd},
+		})
+		if err != nil {
+			return err
+		}
+		defer rec.Close() // skipcq: GO-S2307
+
+		if !quiet(ctx) {
+			fmt.Fprintf(iostreams.FromContext(ctx).ErrOut, "Recording the session in %s\n", path)
+		}
+	}
+
+	if err := console(ctx, sshc, cmd, allocPTY, rec); err != nil {
 		captureError(ctx, err, app)
 		return err
 	}
@@ -194,6 +253,12 @@ func runConsole(ctx context.Context) error {
 }
 
 func Console(ctx context.Context, sshClient *ssh.Client, cmd string, allocPTY bool) error {
+	return console(ctx, sshClient, cmd, allocPTY, nil)
+}
+
+// console runs cmd, or a shell, over sshClient attached to the terminal,
+// recording the session with rec when it's set.
+func console(ctx context.Context, sshClient *ssh.Client, cmd string, allocPTY bool, rec *sessionRecorder) error {
 	currentStdin, currentStdout, currentStderr, err := setupConsole()
 	defer func() error {
 		if err := cleanupConsole(currentStdin, currentStdout, currentStderr); err != nil {
@@ -214,6 +279,12 @@ func Console(ctx context.Context, sshClient *ssh.Client, cmd string, allocPTY bo
 		TermEnv:  determineTermEnv(),
 	}
 
+	if rec != nil {
+		sessIO.Stdin = recordedStdin{file: os.Stdin, r: rec}
+		sessIO.Stdout = ioutils.NewWriteCloserWrapper(io.MultiWriter(colorable.NewColorableStdout(), rec.writer("out")), func() error { return nil })
+		sessIO.Stderr = ioutils.NewWriteCloserWrapper(io.MultiWriter(colorable.NewColorableStderr(), rec.writer("err")), func() error { return nil })
+	}
+
 	if err := sshClient.Shell(ctx, sessIO, cmd); err != nil {
 		return errors.Wrap(err, "ssh shell")
 	}
@@ -231,47 +302,22 @@ func addrForMachines(ctx context.Context, app *fly.AppCompact, console bool) (ad
 		return "", err
 	}
 
-	machines, err := flapsClient.ListActive(ctx)
+	machines, err := startedMachines(ctx, flapsClient, app)
 	if err != nil {
 		return "", err
 	}
 
-	machines = lo.Filter(machines, func(m *fly.Machine, _ int) bool {
-		return m.State == "started"
-	})
-
-	if len(machines) < 1 {
-		return "", fmt.Errorf("app %s has no started VMs.\nIt may be unhealthy or not have been deployed yet.\nTry the following command to verify:\n\nfly status", app.Name)
-	}
-
-	if region := flag.GetRegion(ctx); region != "" {
-		machines = lo.Filter(machines, func(m *fly.Machine, _ int) bool {
-			return m.Region == region
-		})
-		if len(machines) < 1 {
-			return "", fmt.Errorf("app %s has no VMs in region %s", app.Name, region)
-		}
-	}
-
-	if group := flag.GetProcessGroup(ctx); group != "" {
-		machines = lo.Filter(machines, func(m *fly.Machine, _ int) bool {
-			return m.ProcessGroup() == group
-		})
-		if len(machines) < 1 {
-			return "", fmt.Errorf("app %s has no VMs in process group %s", app.Name, group)
-		}
-	}
-
 	var namesWithRegion []string
-	machineID := flag.GetString(ctx, "machine")
 	var selectedMachine *fly.Machine
 	multipleGroups := len(lo.UniqBy(machines, func(m *fly.Machine) string { return m.ProcessGroup() })) > 1
 
-	for _, machine := range machines {
-		if machine.ID == machineID {
-			selectedMachine = machine
+	if machineID := flag.GetString(ctx, "machine"); machineID != "" {
+		if selectedMachine, err = machineByIDOrName(machines, machineID); err != nil {
+			return "", fmt.Errorf("app %s: %w", app.Name, err)
 		}
+	}
 
+	for _, machine := range machines {
 		nameWithRegion := fmt.Sprintf("%s: %s %s %s", machine.Region, machine.ID, machine.PrivateIP, machine.Name)
 
 		role := ""
@@ -343,6 +389,65 @@ func addrForMachines(ctx context.Context, app *fly.AppCompact, console bool) (ad
 	return selectedMachine.PrivateIP, nil
 }
 
+// startedMachines lists the started machines of app, filtered by the --region
+// and --process-group flags.
+func startedMachines(ctx context.Context, flapsClient flapsutil.FlapsClient, app *fly.AppCompact) ([]*fly.Machine, error) {
+	machines, err := flapsClient.ListActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	machines = lo.Filter(machines, func(m *fly.Machine, _ int) bool {
+		return m.State == "started"
+	})
+
+	if len(machines) < 1 {
+		return nil, fmt.Errorf("app %s has no started VMs.\nIt may be unhealthy or not have been deployed yet.\nTry the following command to verify:\n\nfly status", app.Name)
+	}
+
+	if region := flag.GetRegion(ctx); region != "" {
+		machines = lo.Filter(machines, func(m *fly.Machine, _ int) bool {
+			return m.Region == region
+		})
+		if len(machines) < 1 {
+			return nil, fmt.Errorf("app %s has no VMs in region %s", app.Name, region)
+		}
+	}
+
+	if group := flag.GetProcessGroup(ctx); group != "" {
+		machines = lo.Filter(machines, func(m *fly.Machine, _ int) bool {
+			return m.ProcessGroup() == group
+		})
+		if len(machines) < 1 {
+			return nil, fmt.Errorf("app %s has no VMs in process group %s", app.Name, group)
+		}
+	}
+
+	return machines, nil
+}
+
+// machineByIDOrName finds a machine by its ID or, failing that, by its name,
+// which has to be unique.
+func machineByIDOrName(machines []*fly.Machine, idOrName string) (*fly.Machine, error) {
+	for _, machine := range machines {
+		if machine.ID == idOrName {
+			return machine, nil
+		}
+	}
+
+	named := lo.Filter(machines, func(m *fly.Machine, _ int) bool {
+		return m.Name == idOrName
+	})
+	switch len(named) {
+	case 0:
+		return nil, fmt.Errorf("no started VM with ID or name %s", idOrName)
+	case 1:
+		return named[0], nil
+	default:
+		return nil, fmt.Errorf("%d VMs are named %s, use the ID of one of them", len(named), idOrName)
+	}
+}
+
 const defaultTermEnv = "xterm"
 
 func determineTermEnv() string {
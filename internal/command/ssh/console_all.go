@@ -0,0 +1,179 @@
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/fly-go/flaps"
+	"github.com/superfly/flyctl/agent"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flapsutil"
+	"github.com/superfly/flyctl/internal/flyerr"
+	"github.com/superfly/flyctl/iostreams"
+	cryptossh "golang.org/x/crypto/ssh"
+	"golang.org/x/sync/errgroup"
+)
+
+// consoleAllConcurrency is how many machines run the command of
+// fly ssh console --all at once.
+const consoleAllConcurrency = 8
+
+// runConsoleAll runs the --command on every started machine of app, prefixing
+// their output with the machine ID, and exits with the highest exit status.
+func runConsoleAll(ctx context.Context, app *fly.AppCompact, dialer agent.Dialer) error {
+	io := iostreams.FromContext(ctx)
+
+	cmd := flag.GetString(ctx, "command")
+	switch {
+	case cmd == "":
+		return errors.New("--all requires a command, given with --command")
+	case flag.IsSpecified(ctx, "machine"), flag.GetBool(ctx, "select"), flag.IsSpecified(ctx, "address"):
+		return errors.New("--all can't be used with --machine, -s/--select or --address")
+	case flag.GetBool(ctx, "pty"):
+		return errors.New("--all can't be used with --pty")
+	}
+
+	flapsClient, err := flapsutil.NewClientWithOptions(ctx, flaps.NewClientOpts{
+		AppCompact: app,
+		AppName:    app.Name,
+	})
+	if err != nil {
+		return err
+	}
+
+	machines, err := startedMachines(ctx, flapsClient, app)
+	if err != nil {
+		return err
+	}
+
+	var (
+		mu       sync.Mutex
+		failed   int
+		exitCode int
+	)
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(consoleAllConcurrency)
+
+	for _, machine := range machines {
+		g.Go(func() error {
+			prefix := machine.ID + ": "
+			stdout := &prefixWriter{w: io.Out, mu: &mu, prefix: prefix}
+			stderr := &prefixWriter{w: io.ErrOut, mu: &mu, prefix: prefix}
+
+			code, err := runOnMachine(ctx, app, dialer, machine, cmd, stdout, stderr)
+			stdout.Flush()
+			stderr.Flush()
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			switch {
+			case err != nil:
+				fmt.Fprintf(io.ErrOut, "%s%v\n", prefix, err)
+				captureError(ctx, err, app)
+				code = 1
+			case code != 0:
+				fmt.Fprintf(io.ErrOut, "%sexit status %d\n", prefix, code)
+			}
+			if code != 0 {
+				failed++
+				exitCode = max(exitCode, code)
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	if failed > 0 {
+		fmt.Fprintf(io.ErrOut, "The command failed on %d of %d machines\n", failed, len(machines))
+		return flyerr.ExitCodeError{Code: exitCode}
+	}
+	return nil
+}
+
+// runOnMachine runs cmd on the machine, returning its exit status.
+func runOnMachine(ctx context.Context, app *fly.AppCompact, dialer agent.Dialer, machine *fly.Machine, cmd string, stdout, stderr io.Writer) (int, error) {
+	params := &ConnectParams{
+		Ctx:            ctx,
+		Org:            app.Organization,
+		Dialer:         dialer,
+		Username:       flag.GetString(ctx, "user"),
+		DisableSpinner: true,
+		AppNames:       []string{app.Name},
+	}
+	sshc, err := Connect(params, machine.PrivateIP)
+	if err != nil {
+		return 0, err
+	}
+	defer sshc.Close() // skipcq: GO-S2307
+
+	sess, err := sshc.Client.NewSession()
+	if err != nil {
+		return 0, err
+	}
+	defer sess.Close() // skipcq: GO-S2307
+
+	// Unlike pipes, the session waits for these writers to be done when the
+	// command exits
+	sess.Stdout = stdout
+	sess.Stderr = stderr
+
+	err = sess.Run(cmd)
+
+	var exitErr *cryptossh.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitStatus(), nil
+	}
+	return 0, err
+}
+
+// prefixWriter writes the lines written to it to w, each with prefix. Whole
+// lines are written under mu, so lines of several writers don't interleave.
+type prefixWriter struct {
+	w      io.Writer
+	mu     *sync.Mutex
+	prefix string
+	buf    []byte
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+
+	i := bytes.LastIndexByte(p.buf, '\n')
+	if i < 0 {
+		return len(b), nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, line := range bytes.SplitAfter(p.buf[:i+1], []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		if _, err := fmt.Fprintf(p.w, "%s%s", p.prefix, line); err != nil {
+			return 0, err
+		}
+	}
+	p.buf = p.buf[i+1:]
+	return len(b), nil
+}
+
+// Flush writes what's left of a last line without a newline.
+func (p *prefixWriter) Flush() {
+	if len(p.buf) == 0 {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fmt.Fprintf(p.w, "%s%s\n", p.prefix, p.buf)
+	p.buf = nil
+}
@@ -0,0 +1,209 @@
+package ssh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/sftp"
+	"github.com/spf13/cobra"
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/flag"
+)
+
+func newCopy() *cobra.Command {
+	const (
+		long = `Copy files between this host and a VM of an app over SFTP, like scp.
+
+Paths on the VM are written [machine-id]:path, like with 'fly sftp', and can
+contain wildcards, quoted so the local shell doesn't expand them:
+
+  fly ssh copy 148ed193b0e389:/var/data/file ./file
+  fly ssh copy ':/var/log/*.log' ./logs/ --app my-app
+  fly ssh copy -r ./assets :/srv/assets
+
+Without a machine ID, the VM is selected with the same flags as
+'fly ssh console'.`
+		short = "Copy files between this host and a VM"
+		usage = "copy <source>... <destination>"
+	)
+
+	cmd := command.New(usage, short, long, runCopy, command.RequireSession, command.LoadAppNameIfPresent)
+
+	cmd.Args = cobra.MinimumNArgs(2)
+
+	stdArgsSSH(cmd)
+
+	flag.Add(cmd,
+		flag.Bool{
+			Name:        "recursive",
+			Shorthand:   "r",
+			Description: "Copy directories recursively",
+		},
+	)
+
+	return cmd
+}
+
+// copyPath is a source or destination of fly ssh copy.
+type copyPath struct {
+	machine string
+	path    string
+	remote  bool
+}
+
+// parseCopyPath parses a local path or a remote [machine-id]:path one.
+// Windows drive letters aren't machines.
+func parseCopyPath(arg string) copyPath {
+	machine, p, ok := strings.Cut(arg, ":")
+	if !ok || len(machine) == 1 || strings.ContainsAny(machine, `/\`) {
+		return copyPath{path: arg}
+	}
+	if p == "" {
+		p = "."
+	}
+	return copyPath{machine: machine, path: p, remote: true}
+}
+
+func runCopy(ctx context.Context) error {
+	args := flag.Args(ctx)
+
+	dst := parseCopyPath(args[len(args)-1])
+	srcs := make([]copyPath, 0, len(args)-1)
+	machine := dst.machine
+	for _, arg := range args[:len(args)-1] {
+		src := parseCopyPath(arg)
+		if src.remote == dst.remote {
+			return errors.New("copy either from a VM to this host or from this host to a VM")
+		}
+		if src.machine != "" {
+			if machine != "" && src.machine != machine {
+				return fmt.Errorf("all the remote paths must be on the same machine, got %s and %s", machine, src.machine)
+			}
+			machine = src.machine
+		}
+		srcs = append(srcs, src)
+	}
+
+	if machine != "" {
+		if flag.IsSpecified(ctx, "machine") && flag.GetString(ctx, "machine") != machine {
+			return fmt.Errorf("machine %s conflicts with --machine", machine)
+		}
+		if err := flag.SetString(ctx, "machine", machine); err != nil {
+			return err
+		}
+	}
+	if appconfig.NameFromContext(ctx) == "" {
+		return command.ErrRequireAppName
+	}
+
+	ftp, err := newSFTPConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer ftp.Close()
+
+	recursive := flag.GetBool(ctx, "recursive")
+	t := newDirTransfer(ctx)
+
+	if dst.remote {
+		var sources []string
+		for _, src := range srcs {
+			matches, err := filepath.Glob(src.path)
+			if err != nil {
+				return err
+			}
+			if len(matches) == 0 {
+				return fmt.Errorf("%s: no such file or directory", src.path)
+			}
+			sources = append(sources, matches...)
+		}
+
+		// Like scp, sources go into the destination when it's a directory
+		inf, err := ftp.Stat(dst.path)
+		intoDir := len(sources) > 1 || strings.HasSuffix(dst.path, "/") || (err == nil && inf.IsDir())
+
+		for _, source := range sources {
+			target := dst.path
+			if intoDir {
+				target = path.Join(dst.path, filepath.Base(source))
+			}
+
+			if err := copyToRemote(ctx, t, ftp, source, target, intoDir, recursive); err != nil {
+				return fmt.Errorf("copy %s -> %s: %w", source, target, err)
+			}
+		}
+	} else {
+		var sources []string
+		for _, src := range srcs {
+			matches, err := ftp.Glob(src.path)
+			if err != nil {
+				return err
+			}
+			if len(matches) == 0 {
+				return fmt.Errorf("%s:%s: no such file or directory", appconfig.NameFromContext(ctx), src.path)
+			}
+			sources = append(sources, matches...)
+		}
+
+		inf, err := os.Stat(dst.path)
+		intoDir := len(sources) > 1 || strings.HasSuffix(dst.path, string(filepath.Separator)) || strings.HasSuffix(dst.path, "/") || (err == nil && inf.IsDir())
+
+		for _, source := range sources {
+			target := dst.path
+			if intoDir {
+				target = filepath.Join(dst.path, path.Base(source))
+			}
+
+			if err := copyFromRemote(ctx, t, ftp, source, target, intoDir, recursive); err != nil {
+				return fmt.Errorf("copy %s -> %s: %w", source, target, err)
+			}
+		}
+	}
+
+	t.summary()
+	return nil
+}
+
+func copyToRemote(ctx context.Context, t *dirTransfer, ftp *sftp.Client, source, target string, intoDir, recursive bool) error {
+	inf, err := os.Stat(source)
+	switch {
+	case err != nil:
+		return err
+	case inf.IsDir() && !recursive:
+		return fmt.Errorf("%s is a directory, copy it with --recursive", source)
+	case inf.IsDir():
+		return t.putDir(ctx, ftp, source, target)
+	}
+
+	if intoDir {
+		if err := ftp.MkdirAll(path.Dir(target)); err != nil {
+			return err
+		}
+	}
+	return t.upload(ftp, source, target, inf)
+}
+
+func copyFromRemote(ctx context.Context, t *dirTransfer, ftp *sftp.Client, source, target string, intoDir, recursive bool) error {
+	inf, err := ftp.Stat(source)
+	switch {
+	case err != nil:
+		return err
+	case inf.IsDir() && !recursive:
+		return fmt.Errorf("%s is a directory, copy it with --recursive", source)
+	case inf.IsDir():
+		return t.getDir(ctx, ftp, source, target)
+	}
+
+	if intoDir {
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+	}
+	return t.download(ftp, source, target, inf)
+}
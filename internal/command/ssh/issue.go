@@ -12,6 +12,7 @@ import (
 	"net/mail"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/ejcx/sshcert"
 	"github.com/spf13/cobra"
@@ -31,7 +32,11 @@ func newIssue() *cobra.Command {
 	const (
 		long = `Issue a new SSH credential. With -agent, populate credential
 into SSH agent. With -hour, set the number of hours (1-72) for credential
-validity.`
+validity.
+
+For third-party tooling and CI, certificates can be scoped to principals with
+--principal and to apps with --apps, and made short-lived with --valid-for.
+Credentials loaded into the SSH agent are removed from it when they expire.`
 		short = `Issue a new SSH credential`
 		usage = "issue [org] [path]"
 	)
@@ -45,20 +50,33 @@ validity.`
 		flag.StringSlice{
 			Name:        "username",
 			Shorthand:   "u",
-			Description: "Unix usernames the SSH cert can authenticate as",
+			Description: "Unix usernames, or principals, the SSH cert can authenticate as",
 			Default:     []string{DefaultSshUsername, "fly"},
+			Aliases:     []string{"principal"},
+		},
+		flag.StringSlice{
+			Name:        "apps",
+			Description: "Apps the SSH cert is restricted to, instead of all the apps of the organization",
 		},
 		flag.Int{
 			Name:        "hours",
 			Default:     24,
 			Description: "Expiration, in hours (<72)",
 		},
+		flag.Duration{
+			Name:        "valid-for",
+			Description: "Validity of the SSH cert, like 2h, rounded up to the hour (1h-72h); overrides --hours",
+		},
 
 		flag.Bool{
 			Name:        "agent",
 			Default:     false,
 			Description: "Add key to SSH agent",
 		},
+		flag.Bool{
+			Name:        "agent-confirm",
+			Description: "With --agent, make the SSH agent ask for confirmation before each use of the key",
+		},
 		flag.Bool{
 			Name:        "dotssh",
 			Shorthand:   "d",
@@ -132,6 +150,10 @@ func runSSHIssue(ctx context.Context) (err error) {
 	}
 
 	hours := flag.GetInt(ctx, "hours")
+	if validFor := flag.GetDuration(ctx, "valid-for"); validFor != 0 {
+		// The API issues certificates valid for whole hours
+		hours = int((validFor + time.Hour - 1) / time.Hour)
+	}
 	if hours < 1 || hours > 72 {
 		return fmt.Errorf("Invalid expiration time (1-72 hours)\n")
 	}
@@ -141,14 +163,15 @@ func runSSHIssue(ctx context.Context) (err error) {
 		return err
 	}
 
-	icert, err := client.IssueSSHCertificate(ctx, org, principals, nil, &hours, pub)
+	icert, err := client.IssueSSHCertificate(ctx, org, principals, flag.GetStringSlice(ctx, "apps"), &hours, pub)
 	if err != nil {
 		return err
 	}
 
 	doAgent := flag.GetBool(ctx, "agent")
 	if doAgent {
-		if err = populateAgent(icert, priv); err != nil {
+		lifetime := time.Duration(hours) * time.Hour
+		if err = populateAgent(icert, priv, lifetime, flag.GetBool(ctx, "agent-confirm")); err != nil {
 			return err
 		}
 
@@ -288,7 +311,9 @@ func MarshalED25519PrivateKey(key ed25519.PrivateKey, comment string) []byte {
 	})
 }
 
-func populateAgent(icert *fly.IssuedCertificate, priv ed25519.PrivateKey) error {
+// populateAgent adds the key and its certificate to the SSH agent, which
+// removes them after lifetime, and asks before each use with confirm.
+func populateAgent(icert *fly.IssuedCertificate, priv ed25519.PrivateKey, lifetime time.Duration, confirm bool) error {
 	acon, err := net.Dial("unix", os.Getenv("SSH_AUTH_SOCK"))
 	if err != nil {
 		return fmt.Errorf("can't connect to SSH agent: %w", err)
@@ -302,8 +327,10 @@ func populateAgent(icert *fly.IssuedCertificate, priv ed25519.PrivateKey) error
 	}
 
 	if err = ssha.Add(agent.AddedKey{
-		PrivateKey:  priv,
-		Certificate: cert.(*ssh.Certificate),
+		PrivateKey:       priv,
+		Certificate:      cert.(*ssh.Certificate),
+		LifetimeSecs:     uint32(lifetime.Seconds()),
+		ConfirmBeforeUse: confirm,
 	}); err != nil {
 		return fmt.Errorf("ssh-agent failure: %w", err)
 	}
@@ -0,0 +1,128 @@
+package ssh
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/superfly/flyctl/internal/buildinfo"
+)
+
+// sessionLogDirEnv is the directory where every console session is logged
+// when set, so a policy can require sessions to be recorded without relying
+// on --log-file.
+const sessionLogDirEnv = "FLY_SSH_SESSION_LOG_DIR"
+
+// sessionRecorder records the input and output of a console session in a
+// log file, each chunk on a line with its time and stream, quoted so control
+// sequences don't alter the log.
+type sessionRecorder struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+// sessionLogPath returns the path of the log of a session from the
+// --log-file flag or the log directory of the environment, if any.
+func sessionLogPath(logFile, app, addr string, started time.Time) string {
+	if logFile != "" {
+		return logFile
+	}
+	if dir := os.Getenv(sessionLogDirEnv); dir != "" {
+		name := fmt.Sprintf("%s-%s-%s.log", app, addr, started.UTC().Format("20060102T150405Z"))
+		return filepath.Join(dir, safeFileName(name))
+	}
+	return ""
+}
+
+// safeFileName replaces the characters of name that aren't valid in file
+// names everywhere, like the colons of IPv6 addresses on Windows.
+func safeFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '-'
+		}
+	}, name)
+}
+
+// newSessionRecorder creates or appends to the log at path, starting with
+// header, pairs of keys and values.
+func newSessionRecorder(path string, header [][2]string) (*sessionRecorder, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed opening session log: %w", err)
+	}
+
+	fmt.Fprintf(f, "# fly ssh console session log\n")
+	fmt.Fprintf(f, "# %-10s %s\n", "flyctl:", buildinfo.Version())
+	for _, kv := range header {
+		if kv[1] != "" {
+			fmt.Fprintf(f, "# %-10s %s\n", kv[0]+":", kv[1])
+		}
+	}
+
+	return &sessionRecorder{f: f}, nil
+}
+
+func (r *sessionRecorder) record(stream string, b []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fmt.Fprintf(r.f, "%s %s %q\n", time.Now().UTC().Format(time.RFC3339Nano), stream, b)
+}
+
+// Close records the end of the session and closes the log.
+func (r *sessionRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fmt.Fprintf(r.f, "# %-10s %s\n", "ended:", time.Now().UTC().Format(time.RFC3339))
+	return r.f.Close()
+}
+
+// writer returns a writer recording what's written to it as stream.
+func (r *sessionRecorder) writer(stream string) io.Writer {
+	return recordWriter{r: r, stream: stream}
+}
+
+type recordWriter struct {
+	r      *sessionRecorder
+	stream string
+}
+
+func (w recordWriter) Write(b []byte) (int, error) {
+	w.r.record(w.stream, b)
+	return len(b), nil
+}
+
+// recordedStdin records what's read from stdin, keeping its file descriptor
+// available to put the terminal in raw mode. The file isn't embedded, since
+// its WriteTo would let io.Copy read it without going through Read.
+type recordedStdin struct {
+	file *os.File
+	r    *sessionRecorder
+}
+
+func (s recordedStdin) Read(b []byte) (int, error) {
+	n, err := s.file.Read(b)
+	if n > 0 {
+		s.r.record("in", b[:n])
+	}
+	return n, err
+}
+
+func (s recordedStdin) Fd() uintptr {
+	return s.file.Fd()
+}
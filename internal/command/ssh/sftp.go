@@ -9,7 +9,6 @@ import (
 	"io/fs"
 	"os"
 	"path"
-	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -37,6 +36,7 @@ func NewSFTP() *cobra.Command {
 		newFind(),
 		newSFTPShell(),
 		newGet(),
+		newSync(),
 	)
 
 	return cmd
@@ -72,9 +72,12 @@ func newFind() *cobra.Command {
 
 func newGet() *cobra.Command {
 	const (
-		long  = `The SFTP GET retrieves a file from a remote VM.`
-		short = long
-		usage = "get <path>"
+		long = `The SFTP GET retrieves a file from a remote VM.
+
+With --recursive, it retrieves a directory and everything in it, skipping the
+files already retrieved and resuming interrupted transfers when run again.`
+		short = "The SFTP GET retrieves a file from a remote VM."
+		usage = "get [<machine-id>:]<path> [local-path]"
 	)
 
 	cmd := command.New(usage, short, long, runGet, command.RequireSession, command.RequireAppName)
@@ -83,6 +86,14 @@ func newGet() *cobra.Command {
 
 	stdArgsSSH(cmd)
 
+	flag.Add(cmd,
+		flag.Bool{
+			Name:        "recursive",
+			Shorthand:   "r",
+			Description: "Retrieve a directory recursively",
+		},
+	)
+
 	return cmd
 }
 
@@ -162,13 +173,42 @@ func runGet(ctx context.Context) error {
 
 	case 1:
 		remote = args[0]
-		local = filepath.Base(remote)
 
 	default:
 		remote = args[0]
 		local = args[1]
 	}
 
+	remote, err := remoteMachinePath(ctx, remote)
+	if err != nil {
+		return err
+	}
+	if local == "" {
+		local = path.Base(remote)
+	}
+
+	if flag.GetBool(ctx, "recursive") {
+		ftp, err := newSFTPConnection(ctx)
+		if err != nil {
+			return err
+		}
+		defer ftp.Close()
+
+		if inf, err := ftp.Stat(remote); err != nil {
+			return fmt.Errorf("get: remote directory %s: %w", remote, err)
+		} else if !inf.IsDir() {
+			return fmt.Errorf("get: %s is not a directory, retrieve it without --recursive", remote)
+		}
+
+		t := newDirTransfer(ctx)
+		if err := t.getDir(ctx, ftp, remote, local); err != nil {
+			return fmt.Errorf("get %s -> %s: %w", remote, local, err)
+		}
+
+		t.summary()
+		return nil
+	}
+
 	if _, err := os.Stat(local); err == nil {
 		return fmt.Errorf("file %s is already there. `fly ssh` doesn't override existing files for safety.", local)
 	}
@@ -0,0 +1,335 @@
+package ssh
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/pkg/sftp"
+	"github.com/spf13/cobra"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+// partialSuffix is appended to the names of files while they're transferred,
+// so interrupted transfers resume where they stopped.
+const partialSuffix = ".flypart"
+
+func newSync() *cobra.Command {
+	const (
+		long = `The SFTP SYNC command uploads a local directory to a remote VM, recursively.
+
+Files already on the VM with the same size and modification time are skipped,
+and the transfer of a file that was interrupted resumes where it stopped, so
+running the command again after a failure only transfers what's missing.
+
+The VM can be given with the remote directory, as <machine-id>:<remote-dir>.`
+		short = "Upload a local directory to a remote VM"
+		usage = "sync <local-dir> [<machine-id>:]<remote-dir>"
+	)
+
+	cmd := command.New(usage, short, long, runSync, command.RequireSession, command.RequireAppName)
+
+	cmd.Args = cobra.ExactArgs(2)
+
+	stdArgsSSH(cmd)
+
+	return cmd
+}
+
+func runSync(ctx context.Context) error {
+	args := flag.Args(ctx)
+	local := args[0]
+
+	remote, err := remoteMachinePath(ctx, args[1])
+	if err != nil {
+		return err
+	}
+
+	if inf, err := os.Stat(local); err != nil {
+		return fmt.Errorf("sync: local directory %s: %w", local, err)
+	} else if !inf.IsDir() {
+		return fmt.Errorf("sync: %s is not a directory", local)
+	}
+
+	ftp, err := newSFTPConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer ftp.Close()
+
+	t := newDirTransfer(ctx)
+	if err := t.putDir(ctx, ftp, local, remote); err != nil {
+		return fmt.Errorf("sync %s -> %s: %w", local, remote, err)
+	}
+
+	t.summary()
+	return nil
+}
+
+// putDir uploads the local directory to the remote one, recursively.
+func (t *dirTransfer) putDir(ctx context.Context, ftp *sftp.Client, local, remote string) error {
+	return filepath.WalkDir(local, func(lpath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(local, lpath)
+		if err != nil {
+			return err
+		}
+		rpath := path.Join(remote, filepath.ToSlash(rel))
+
+		inf, err := d.Info()
+		switch {
+		case err != nil:
+			return err
+		case d.IsDir():
+			return ftp.MkdirAll(rpath)
+		case !inf.Mode().IsRegular() || strings.HasSuffix(lpath, partialSuffix):
+			return nil
+		}
+
+		return t.upload(ftp, lpath, rpath, inf)
+	})
+}
+
+// getDir downloads the remote directory to the local one, recursively.
+func (t *dirTransfer) getDir(ctx context.Context, ftp *sftp.Client, remote, local string) error {
+	walker := ftp.Walk(remote)
+	for walker.Step() {
+		if err := walker.Err(); err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		rpath := walker.Path()
+		rel := strings.TrimPrefix(strings.TrimPrefix(rpath, remote), "/")
+		lpath := filepath.Join(local, filepath.FromSlash(rel))
+
+		inf := walker.Stat()
+		switch {
+		case inf.IsDir():
+			if err := os.MkdirAll(lpath, 0o755); err != nil {
+				return err
+			}
+			continue
+		case !inf.Mode().IsRegular() || strings.HasSuffix(rpath, partialSuffix):
+			continue
+		}
+
+		if err := t.download(ftp, rpath, lpath, inf); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// remoteMachinePath returns the path of a [<machine-id>:]<path> argument,
+// selecting the machine when it's given.
+func remoteMachinePath(ctx context.Context, arg string) (string, error) {
+	machineID, remote, ok := strings.Cut(arg, ":")
+	if !ok {
+		return arg, nil
+	}
+	if flag.IsSpecified(ctx, "machine") && flag.GetString(ctx, "machine") != machineID {
+		return "", fmt.Errorf("machine %s of %s conflicts with --machine", machineID, arg)
+	}
+	if err := flag.SetString(ctx, "machine", machineID); err != nil {
+		return "", err
+	}
+	return remote, nil
+}
+
+// dirTransfer transfers the files of a directory, reporting its progress.
+type dirTransfer struct {
+	io       *iostreams.IOStreams
+	files    int
+	skipped  int
+	bytes    int64
+	started  time.Time
+	progress bool
+}
+
+func newDirTransfer(ctx context.Context) *dirTransfer {
+	io := iostreams.FromContext(ctx)
+	return &dirTransfer{
+		io:       io,
+		started:  time.Now(),
+		progress: io.IsStdoutTTY(),
+	}
+}
+
+func (t *dirTransfer) upload(ftp *sftp.Client, lpath, rpath string, inf fs.FileInfo) error {
+	if rinf, err := ftp.Stat(rpath); err == nil && sameFile(inf, rinf) {
+		t.skipped++
+		return nil
+	}
+
+	// Resume the transfer of a previous run
+	var offset int64
+	part := rpath + partialSuffix
+	if pinf, err := ftp.Stat(part); err == nil && pinf.Size() < inf.Size() {
+		offset = pinf.Size()
+	}
+
+	lf, err := os.Open(lpath)
+	if err != nil {
+		return err
+	}
+	defer lf.Close()
+
+	flags := os.O_WRONLY | os.O_CREATE
+	if offset == 0 {
+		flags |= os.O_TRUNC
+	}
+	rf, err := ftp.OpenFile(part, flags)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", part, err)
+	}
+	defer rf.Close()
+
+	if err := t.copy(rf, lf, rpath, offset, inf.Size()); err != nil {
+		return err
+	}
+	if err := rf.Close(); err != nil {
+		return err
+	}
+
+	if err := ftp.Chmod(part, inf.Mode().Perm()); err != nil {
+		return err
+	}
+	if err := ftp.Chtimes(part, inf.ModTime(), inf.ModTime()); err != nil {
+		return err
+	}
+	if err := ftp.PosixRename(part, rpath); err != nil {
+		// Servers without the posix-rename extension don't replace files
+		_ = ftp.Remove(rpath)
+		if err := ftp.Rename(part, rpath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *dirTransfer) download(ftp *sftp.Client, rpath, lpath string, inf fs.FileInfo) error {
+	if linf, err := os.Stat(lpath); err == nil && sameFile(inf, linf) {
+		t.skipped++
+		return nil
+	}
+
+	// Resume the transfer of a previous run
+	var offset int64
+	part := lpath + partialSuffix
+	if pinf, err := os.Stat(part); err == nil && pinf.Size() < inf.Size() {
+		offset = pinf.Size()
+	}
+
+	rf, err := ftp.Open(rpath)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", rpath, err)
+	}
+	defer rf.Close()
+
+	flags := os.O_WRONLY | os.O_CREATE
+	if offset == 0 {
+		flags |= os.O_TRUNC
+	}
+	lf, err := os.OpenFile(part, flags, inf.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	defer lf.Close()
+
+	if err := t.copy(lf, rf, lpath, offset, inf.Size()); err != nil {
+		return err
+	}
+	if err := lf.Sync(); err != nil {
+		return err
+	}
+	if err := lf.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chtimes(part, inf.ModTime(), inf.ModTime()); err != nil {
+		return err
+	}
+	return os.Rename(part, lpath)
+}
+
+// copy copies src to dst from offset, both seeked to it first, showing the
+// progress of the file name of size.
+func (t *dirTransfer) copy(dst io.WriteSeeker, src io.ReadSeeker, name string, offset, size int64) error {
+	if offset > 0 {
+		if _, err := dst.Seek(offset, io.SeekStart); err != nil {
+			return err
+		}
+		if _, err := src.Seek(offset, io.SeekStart); err != nil {
+			return err
+		}
+	}
+
+	p := &progressWriter{t: t, name: name, done: offset, size: size}
+	n, err := io.Copy(io.MultiWriter(dst, p), src)
+	t.bytes += n
+	p.finish(err)
+	if err != nil {
+		return fmt.Errorf("copy %s: %w", name, err)
+	}
+
+	t.files++
+	return nil
+}
+
+func (t *dirTransfer) summary() {
+	elapsed := time.Since(t.started).Round(time.Second)
+	fmt.Fprintf(t.io.Out, "%d files transferred (%s) in %s, %d up to date\n", t.files, humanize.Bytes(uint64(t.bytes)), elapsed, t.skipped)
+}
+
+// sameFile reports whether two files have the same size and modification
+// time, to the second which is what SFTP keeps.
+func sameFile(a, b fs.FileInfo) bool {
+	return a.Size() == b.Size() && a.ModTime().Unix() == b.ModTime().Unix()
+}
+
+// progressWriter counts the bytes of a file written, and shows them on
+// terminals.
+type progressWriter struct {
+	t     *dirTransfer
+	name  string
+	done  int64
+	size  int64
+	shown time.Time
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	p.done += int64(len(b))
+	if p.t.progress && time.Since(p.shown) > 200*time.Millisecond {
+		p.shown = time.Now()
+		fmt.Fprintf(p.t.io.Out, "\r\033[K%s %s / %s", p.name, humanize.Bytes(uint64(p.done)), humanize.Bytes(uint64(p.size)))
+	}
+	return len(b), nil
+}
+
+func (p *progressWriter) finish(err error) {
+	if p.t.progress {
+		fmt.Fprint(p.t.io.Out, "\r\033[K")
+	}
+	if err == nil {
+		fmt.Fprintf(p.t.io.Out, "%s (%s)\n", p.name, humanize.Bytes(uint64(p.size)))
+	}
+}
@@ -16,6 +16,7 @@ func New() *cobra.Command {
 
 	cmd.AddCommand(
 		newConsole(),
+		newCopy(),
 		newIssue(),
 		newLog(),
 		NewSFTP(),
@@ -63,6 +63,56 @@ func RunSSHCommand(ctx context.Context, app *fly.AppCompact, dialer agent.Dialer
 }
 
 func SSHConnect(p *SSHParams, addr string) error {
+	var endSpin context.CancelFunc
+	if !p.DisableSpinner {
+		endSpin = spin(fmt.Sprintf("Connecting to %s...", addr),
+			fmt.Sprintf("Connecting to %s... complete\n", addr))
+		defer endSpin()
+	}
+
+	sshClient, err := dialSSH(p, addr)
+	if err != nil {
+		return err
+	}
+	defer sshClient.Close()
+
+	if !p.DisableSpinner {
+		endSpin()
+	}
+
+	sessIO := &ssh.SessionIO{
+		Stdin:    p.Stdin,
+		Stdout:   p.Stdout,
+		Stderr:   p.Stderr,
+		AllocPTY: true,
+		TermEnv:  "xterm",
+	}
+
+	if err := sshClient.Shell(context.Background(), sessIO, p.Cmd); err != nil {
+		return errors.Wrap(err, "ssh shell")
+	}
+
+	return nil
+}
+
+// SSHExec runs p.Cmd like SSHConnect but without a PTY, which would mangle
+// line endings and merge stderr into stdout, so that binary output, like
+// archives, is written to p.Stdout as is.
+func SSHExec(p *SSHParams, addr string) error {
+	sshClient, err := dialSSH(p, addr)
+	if err != nil {
+		return err
+	}
+	defer sshClient.Close()
+
+	if err := sshClient.Exec(p.Ctx, p.Stdin, p.Stdout, p.Stderr, p.Cmd); err != nil {
+		return errors.Wrap(err, "ssh exec")
+	}
+
+	return nil
+}
+
+func dialSSH(p *SSHParams, addr string) (*ssh.Client, error) {
 	terminal.Debugf("Fetching certificate for %s at %s\n", p.App, addr)
 
 	var appNames []string
@@ -70,9 +120,9 @@ func SSHConnect(p *SSHParams, addr string) error {
 		appNames = append(appNames, p.App)
 	}
 
-	cert, pk, err := singleUseSSHCertificate(p.Ctx, p.Org, appNames, p.Username)
+	cert, pk, err := sshCertificate(p.Ctx, p.Org, appNames, p.Username)
 	if err != nil {
-		return fmt.Errorf("create ssh certificate: %w (if you haven't created a key for your org yet, try `flyctl ssh issue`)", err)
+		return nil, fmt.Errorf("create ssh certificate: %w (if you haven't created a key for your org yet, try `flyctl ssh issue`)", err)
 	}
 
 	pemkey := ssh.MarshalED25519PrivateKey(pk, "single-use certificate")
@@ -89,35 +139,11 @@ func SSHConnect(p *SSHParams, addr string) error {
 		PrivateKey:  string(pemkey),
 	}
 
-	var endSpin context.CancelFunc
-	if !p.DisableSpinner {
-		endSpin = spin(fmt.Sprintf("Connecting to %s...", addr),
-			fmt.Sprintf("Connecting to %s... complete\n", addr))
-		defer endSpin()
-	}
-
 	if err := sshClient.Connect(context.Background()); err != nil {
-		return errors.Wrap(err, "error connecting to SSH server")
+		return nil, errors.Wrap(err, "error connecting to SSH server")
 	}
-	defer sshClient.Close()
 
 	terminal.Debugf("Connection %s completed.\n", addr)
 
-	if !p.DisableSpinner {
-		endSpin()
-	}
-
-	sessIO := &ssh.SessionIO{
-		Stdin:    p.Stdin,
-		Stdout:   p.Stdout,
-		Stderr:   p.Stderr,
-		AllocPTY: true,
-		TermEnv:  "xterm",
-	}
-
-	if err := sshClient.Shell(context.Background(), sessIO, p.Cmd); err != nil {
-		return errors.Wrap(err, "ssh shell")
-	}
-
-	return nil
+	return sshClient, nil
 }
@@ -0,0 +1,70 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+	fly "github.com/superfly/fly-go"
+
+	"github.com/superfly/flyctl/iostreams"
+
+	"github.com/superfly/flyctl/internal/incidents"
+	"github.com/superfly/flyctl/internal/logger"
+)
+
+// incidentsRefreshInterval is how often --watch fetches the incidents and
+// maintenances of the platform again, rather than on every refresh.
+const incidentsRefreshInterval = 5 * time.Minute
+
+// renderIncidents warns of the incidents and maintenances of the platform
+// affecting the regions of machines, so they aren't mistaken for issues of
+// the app. Failures to fetch them are only logged. When watched with w, they
+// are fetched at most every incidentsRefreshInterval.
+func renderIncidents(ctx context.Context, out io.Writer, colorize *iostreams.ColorScheme, machines []*fly.Machine, w *watcher) {
+	if !incidents.Check() {
+		return
+	}
+
+	regions := lo.Uniq(lo.Map(machines, func(m *fly.Machine, _ int) string { return m.Region }))
+	notices := lo.Filter(w.platformNotices(ctx, time.Now()), func(n incidents.Notice, _ int) bool {
+		return n.Affects(regions)
+	})
+	if len(notices) == 0 {
+		return
+	}
+
+	fmt.Fprintln(out, colorize.WarningIcon(), colorize.Yellow("Platform incidents or maintenances may be affecting this app:"))
+	for _, n := range notices {
+		affected := "all regions"
+		if !n.Global() {
+			affected = strings.Join(lo.Intersect(n.Regions, regions), ", ")
+		}
+		fmt.Fprintln(out, colorize.Yellow(fmt.Sprintf("  %s: %s (%s, %s)", n.Kind, n.Name, n.Status, affected)))
+	}
+	fmt.Fprintln(out, colorize.Yellow("Run `fly incidents` for details."))
+	fmt.Fprintln(out)
+}
+
+// platformNotices returns the incidents and maintenances of the platform,
+// those fetched by a previous refresh of w when recent enough.
+func (w *watcher) platformNotices(ctx context.Context, now time.Time) []incidents.Notice {
+	if w != nil && !w.noticesAt.IsZero() && now.Sub(w.noticesAt) < incidentsRefreshInterval {
+		return w.notices
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	notices, err := incidents.Notices(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Debugf("failed querying for incidents: %v", err)
+	}
+	if w != nil {
+		w.notices, w.noticesAt = notices, now
+	}
+	return notices
+}
@@ -8,6 +8,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	fly "github.com/superfly/fly-go"
 	"github.com/superfly/fly-go/flaps"
@@ -70,6 +71,12 @@ func getImage(machines []*fly.Machine) (string, error) {
 }
 
 func RenderMachineStatus(ctx context.Context, app *fly.AppCompact, out io.Writer) error {
+	return renderMachineStatus(ctx, app, out, nil)
+}
+
+// renderMachineStatus renders the status of the machines of app, along with
+// their transitions and the rollout progress when watched with w.
+func renderMachineStatus(ctx context.Context, app *fly.AppCompact, out io.Writer, w *watcher) error {
 	var (
 		io         = iostreams.FromContext(ctx)
 		colorize   = io.ColorScheme()
@@ -98,8 +105,16 @@ func RenderMachineStatus(ctx context.Context, app *fly.AppCompact, out io.Writer
 		return renderMachineJSONStatus(ctx, app, machines)
 	}
 
+	transitions := w.observe(machines, time.Now())
+
+	renderIncidents(ctx, out, colorize, machines, w)
+
 	if app.IsPostgresApp() {
-		return renderPGStatus(ctx, app, machines, out)
+		if err := renderPGStatus(ctx, app, machines, out); err != nil {
+			return err
+		}
+		w.render(out, colorize, machines)
+		return nil
 	}
 
 	// Tracks latest eligible version
@@ -186,12 +201,16 @@ func RenderMachineStatus(ctx context.Context, app *fly.AppCompact, out io.Writer
 			if v := mConfig.Metadata["role"]; v != "" {
 				role = v
 			}
+			state := machine.State
+			if t, ok := transitions[machine.ID]; ok {
+				state = t
+			}
 			rows = append(rows, []string{
 				getProcessgroup(machine),
 				machine.ID,
 				getReleaseVersion(machine),
 				machine.Region,
-				machine.State,
+				state,
 				role,
 				render.MachineHealthChecksSummary(machine),
 				machine.UpdatedAt,
@@ -216,6 +235,8 @@ func RenderMachineStatus(ctx context.Context, app *fly.AppCompact, out io.Writer
 		if hasNotOk {
 			fmt.Fprintf(out, "  💀 The machine's host is unreachable\n")
 		}
+
+		w.render(out, colorize, managed)
 	}
 
 	if len(unmanaged) > 0 {
@@ -53,7 +53,7 @@ currently allocated.
 		},
 		flag.Bool{
 			Name:        "watch",
-			Description: "Refresh details",
+			Description: "Refresh details, showing machine state changes and release rollouts",
 		},
 		flag.Int{
 			Name:        "rate",
@@ -79,10 +79,10 @@ func run(ctx context.Context) error {
 }
 
 func runOnce(ctx context.Context) error {
-	return once(ctx, iostreams.FromContext(ctx).Out)
+	return once(ctx, iostreams.FromContext(ctx).Out, nil)
 }
 
-func once(ctx context.Context, out io.Writer) (err error) {
+func once(ctx context.Context, out io.Writer, w *watcher) (err error) {
 	var (
 		appName = appconfig.NameFromContext(ctx)
 		client  = flyutil.ClientFromContext(ctx)
@@ -93,7 +93,7 @@ func once(ctx context.Context, out io.Writer) (err error) {
 		return fmt.Errorf("failed to get app: %w", err)
 	}
 
-	return RenderMachineStatus(ctx, app, out)
+	return renderMachineStatus(ctx, app, out, w)
 }
 
 func runWatch(ctx context.Context) (err error) {
@@ -115,11 +115,12 @@ func runWatch(ctx context.Context) (err error) {
 	appName := appconfig.NameFromContext(ctx)
 
 	var buf bytes.Buffer
+	w := newWatcher()
 
 	for err == nil {
 		buf.Reset()
 
-		if err = once(ctx, &buf); err != nil {
+		if err = once(ctx, &buf, w); err != nil {
 			break
 		}
 
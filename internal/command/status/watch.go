@@ -0,0 +1,129 @@
+package status
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/flyctl/internal/incidents"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+// maxWatchChanges is how many of the latest state transitions --watch shows.
+const maxWatchChanges = 10
+
+// watcher keeps the machine states between refreshes of --watch, to show
+// their transitions.
+type watcher struct {
+	observed bool
+	states   map[string]string
+	changes  []string
+
+	// The incidents and maintenances of the platform, and when they were
+	// fetched
+	notices   []incidents.Notice
+	noticesAt time.Time
+}
+
+func newWatcher() *watcher {
+	return &watcher{states: map[string]string{}}
+}
+
+// observe records the states of machines and returns the transitions since
+// the previous refresh, by machine ID, like "stopped → started".
+func (w *watcher) observe(machines []*fly.Machine, now time.Time) map[string]string {
+	if w == nil {
+		return nil
+	}
+
+	first := !w.observed
+	w.observed = true
+	transitions := map[string]string{}
+	seen := map[string]bool{}
+
+	for _, machine := range machines {
+		seen[machine.ID] = true
+
+		prev, ok := w.states[machine.ID]
+		w.states[machine.ID] = machine.State
+
+		switch {
+		case first:
+		case !ok:
+			transitions[machine.ID] = "new → " + machine.State
+		case prev != machine.State:
+			transitions[machine.ID] = prev + " → " + machine.State
+		default:
+			continue
+		}
+		if !first {
+			w.record(now, fmt.Sprintf("%s %s", machine.ID, transitions[machine.ID]))
+		}
+	}
+
+	for id, prev := range w.states {
+		if !seen[id] {
+			delete(w.states, id)
+			w.record(now, fmt.Sprintf("%s %s → gone", id, prev))
+		}
+	}
+
+	return transitions
+}
+
+func (w *watcher) record(now time.Time, change string) {
+	w.changes = append(w.changes, now.UTC().Format("15:04:05")+" "+change)
+	if len(w.changes) > maxWatchChanges {
+		w.changes = w.changes[len(w.changes)-maxWatchChanges:]
+	}
+}
+
+// render writes the rollout progress of the latest release and the latest
+// transitions.
+func (w *watcher) render(out io.Writer, colorize *iostreams.ColorScheme, machines []*fly.Machine) {
+	if w == nil {
+		return
+	}
+
+	if progress := rolloutProgress(machines); progress != "" {
+		fmt.Fprintf(out, "%s\n\n", progress)
+	}
+
+	if len(w.changes) > 0 {
+		fmt.Fprintln(out, "Recent changes:")
+		for i := len(w.changes) - 1; i >= 0; i-- {
+			fmt.Fprintf(out, "  %s\n", colorize.Yellow(w.changes[i]))
+		}
+		fmt.Fprintln(out)
+	}
+}
+
+// rolloutProgress describes how many machines run the latest release.
+func rolloutProgress(machines []*fly.Machine) string {
+	latest, updated, total := 0, 0, 0
+	for _, machine := range machines {
+		version, err := strconv.Atoi(getReleaseVersion(machine))
+		if err != nil {
+			continue
+		}
+
+		total++
+		switch {
+		case version > latest:
+			latest, updated = version, 1
+		case version == latest:
+			updated++
+		}
+	}
+
+	switch {
+	case total == 0:
+		return ""
+	case updated == total:
+		return fmt.Sprintf("Release v%d is on all %d machines", latest, total)
+	default:
+		return fmt.Sprintf("Release v%d rolling out: %d of %d machines updated", latest, updated, total)
+	}
+}
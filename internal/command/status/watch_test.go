@@ -0,0 +1,71 @@
+package status
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/flyctl/internal/incidents"
+)
+
+func TestWatcherObserve(t *testing.T) {
+	now := time.Date(2024, 5, 1, 13, 45, 0, 0, time.UTC)
+	w := newWatcher()
+
+	transitions := w.observe([]*fly.Machine{
+		{ID: "a", State: "started"},
+		{ID: "b", State: "stopped"},
+	}, now)
+	require.Empty(t, transitions)
+	require.Empty(t, w.changes)
+
+	transitions = w.observe([]*fly.Machine{
+		{ID: "a", State: "started"},
+		{ID: "b", State: "started"},
+		{ID: "c", State: "created"},
+	}, now)
+	require.Equal(t, map[string]string{
+		"b": "stopped → started",
+		"c": "new → created",
+	}, transitions)
+
+	transitions = w.observe([]*fly.Machine{
+		{ID: "b", State: "started"},
+		{ID: "c", State: "created"},
+	}, now)
+	require.Empty(t, transitions)
+	require.Equal(t, []string{
+		"13:45:00 b stopped → started",
+		"13:45:00 c new → created",
+		"13:45:00 a started → gone",
+	}, w.changes)
+}
+
+func TestRolloutProgress(t *testing.T) {
+	machine := func(version string) *fly.Machine {
+		return &fly.Machine{
+			Config: &fly.MachineConfig{
+				Metadata: map[string]string{
+					fly.MachineConfigMetadataKeyFlyReleaseVersion: version,
+				},
+			},
+		}
+	}
+
+	require.Equal(t, "", rolloutProgress(nil))
+	require.Equal(t, "Release v3 is on all 2 machines", rolloutProgress([]*fly.Machine{machine("3"), machine("3")}))
+	require.Equal(t, "Release v4 rolling out: 1 of 3 machines updated", rolloutProgress([]*fly.Machine{machine("3"), machine("4"), machine("3")}))
+}
+
+func TestWatcherPlatformNotices(t *testing.T) {
+	now := time.Date(2024, 5, 1, 13, 45, 0, 0, time.UTC)
+	notices := []incidents.Notice{{Kind: "incident", Name: "Network issues in ams"}}
+
+	w := newWatcher()
+	w.notices, w.noticesAt = notices, now
+
+	// Fetched again only once incidentsRefreshInterval has passed
+	require.Equal(t, notices, w.platformNotices(context.Background(), now.Add(incidentsRefreshInterval-time.Second)))
+}
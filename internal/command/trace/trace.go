@@ -0,0 +1,264 @@
+// Package trace implements the trace command chain.
+package trace
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyutil"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+	"github.com/superfly/flyctl/logs"
+)
+
+func New() (cmd *cobra.Command) {
+	const (
+		long = `Show how requests to an app are routed by the Fly proxy.
+
+With a request ID, the value of the Fly-Request-Id header of a response, the
+edge region that received the request is shown, along with the logs of the
+app mentioning the ID, which show the machine that handled it for apps that
+log request IDs. Only the logs the platform still buffers are searched.
+
+With --sample, requests are sent to the app, showing for each of them the
+edge region that received it, its status and the timings of its DNS lookup,
+connection, TLS handshake and first byte:
+
+  fly trace --sample 5 --path /api/health
+`
+		short = "Trace how requests are routed to an app"
+		usage = "trace [request-id]"
+	)
+
+	cmd = command.New(usage, short, long, run,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.MaximumNArgs(1)
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.JSONOutput(),
+		flag.Int{
+			Name:        "sample",
+			Description: "Send this many requests to the app and trace them",
+		},
+		flag.String{
+			Name:        "path",
+			Default:     "/",
+			Description: "Path of the requests sent with --sample",
+		},
+	)
+
+	return
+}
+
+func run(ctx context.Context) error {
+	requestID := flag.FirstArg(ctx)
+	samples := flag.GetInt(ctx, "sample")
+
+	switch {
+	case requestID != "" && samples > 0:
+		return errors.New("a request ID can't be used with --sample")
+	case requestID != "":
+		return traceRequestID(ctx, requestID)
+	case samples > 0:
+		return traceSamples(ctx, samples)
+	default:
+		return errors.New("a request ID or --sample is required")
+	}
+}
+
+// edgeRegion returns the region of the edge proxy that received a request,
+// the suffix of its ID.
+func edgeRegion(requestID string) string {
+	if i := strings.LastIndexByte(requestID, '-'); i >= 0 {
+		return requestID[i+1:]
+	}
+	return ""
+}
+
+func traceRequestID(ctx context.Context, requestID string) error {
+	var (
+		out     = iostreams.FromContext(ctx).Out
+		client  = flyutil.ClientFromContext(ctx)
+		appName = appconfig.NameFromContext(ctx)
+	)
+
+	stream := make(chan logs.LogEntry)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(stream)
+		errc <- logs.Poll(ctx, stream, client, &logs.LogOptions{
+			AppName: appName,
+			// Pages through the logs buffered until now
+			To: time.Now(),
+		})
+	}()
+
+	var entries []logs.LogEntry
+	for entry := range stream {
+		if strings.Contains(entry.Message, requestID) {
+			entries = append(entries, entry)
+		}
+	}
+	if err := <-errc; err != nil {
+		return fmt.Errorf("failed fetching logs: %w", err)
+	}
+
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(out, struct {
+			RequestID  string          `json:"request_id"`
+			EdgeRegion string          `json:"edge_region"`
+			Logs       []logs.LogEntry `json:"logs"`
+		}{requestID, edgeRegion(requestID), entries})
+	}
+
+	fmt.Fprintf(out, "Request %s was received by the edge proxy in %s\n\n", requestID, lo.CoalesceOrEmpty(edgeRegion(requestID), "-"))
+
+	if len(entries) == 0 {
+		fmt.Fprintf(out, "No logs of %s mention the request. Apps that log the Fly-Request-Id header show the machine that handled their requests.\n", appName)
+		return nil
+	}
+
+	rows := make([][]string, 0, len(entries))
+	for _, entry := range entries {
+		rows = append(rows, []string{entry.Timestamp, entry.Region, entry.Instance, entry.Message})
+	}
+	return render.Table(out, "Logs", rows, "Time", "Region", "Machine", "Message")
+}
+
+// sample is a request sent to an app, with how it was routed and timed.
+type sample struct {
+	RequestID  string        `json:"request_id"`
+	EdgeRegion string        `json:"edge_region"`
+	Status     int           `json:"status"`
+	DNS        time.Duration `json:"dns"`
+	Connect    time.Duration `json:"connect"`
+	TLS        time.Duration `json:"tls"`
+	FirstByte  time.Duration `json:"first_byte"`
+	Total      time.Duration `json:"total"`
+	Error      string        `json:"error,omitempty"`
+}
+
+func traceSamples(ctx context.Context, n int) error {
+	var (
+		out     = iostreams.FromContext(ctx).Out
+		client  = flyutil.ClientFromContext(ctx)
+		appName = appconfig.NameFromContext(ctx)
+	)
+
+	app, err := client.GetAppCompact(ctx, appName)
+	if err != nil {
+		return fmt.Errorf("failed to get app: %w", err)
+	}
+
+	path := flag.GetString(ctx, "path")
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	url := "https://" + app.Hostname + path
+
+	// Connections aren't reused so that every request is routed anew
+	httpClient := &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: &http.Transport{DisableKeepAlives: true},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	samples := make([]sample, 0, n)
+	for i := 0; i < n && ctx.Err() == nil; i++ {
+		samples = append(samples, send(ctx, httpClient, url))
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(out, samples)
+	}
+
+	rows := make([][]string, 0, len(samples))
+	for _, s := range samples {
+		status := strconv.Itoa(s.Status)
+		if s.Error != "" {
+			status = s.Error
+		}
+		rows = append(rows, []string{
+			lo.CoalesceOrEmpty(s.RequestID, "-"),
+			lo.CoalesceOrEmpty(s.EdgeRegion, "-"),
+			status,
+			formatDuration(s.DNS),
+			formatDuration(s.Connect),
+			formatDuration(s.TLS),
+			formatDuration(s.FirstByte),
+			formatDuration(s.Total),
+		})
+	}
+	return render.Table(out, url, rows, "Request ID", "Edge", "Status", "DNS", "Connect", "TLS", "First Byte", "Total")
+}
+
+// send sends a GET request to url, tracing its timings.
+func send(ctx context.Context, client *http.Client, url string) (s sample) {
+	var start, dnsStart, connectStart, tlsStart time.Time
+
+	trace := &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:           func(httptrace.DNSDoneInfo) { s.DNS = time.Since(dnsStart) },
+		ConnectStart:      func(string, string) { connectStart = time.Now() },
+		ConnectDone:       func(string, string, error) { s.Connect = time.Since(connectStart) },
+		TLSHandshakeStart: func() { tlsStart = time.Now() },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { s.TLS = time.Since(tlsStart) },
+		GotFirstResponseByte: func() {
+			s.FirstByte = time.Since(start)
+		},
+	}
+
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, trace), http.MethodGet, url, http.NoBody)
+	if err != nil {
+		s.Error = err.Error()
+		return
+	}
+
+	start = time.Now()
+	res, err := client.Do(req)
+	if err != nil {
+		s.Error = err.Error()
+		return
+	}
+	defer res.Body.Close() // skipcq: GO-S2307
+
+	_, _ = io.Copy(io.Discard, res.Body)
+	s.Total = time.Since(start)
+
+	s.Status = res.StatusCode
+	s.RequestID = res.Header.Get("Fly-Request-Id")
+	s.EdgeRegion = edgeRegion(s.RequestID)
+	return
+}
+
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 1, 64) + "ms"
+}
@@ -0,0 +1,195 @@
+package volumes
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/flyctl/helpers"
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flapsutil"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/prompt"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+// autoExtend is how a volume grows once its usage crosses ThresholdPercent.
+// A zero ThresholdPercent turns auto-extend off.
+type autoExtend struct {
+	ThresholdPercent int
+	AddSizeGb        int
+	SizeGbLimit      int
+}
+
+func autoExtendSpecified(ctx context.Context) bool {
+	return flag.IsSpecified(ctx, "auto-extend-threshold") ||
+		flag.IsSpecified(ctx, "auto-extend-by") ||
+		flag.IsSpecified(ctx, "max-size")
+}
+
+// getAutoExtend reads the auto-extend flags on top of the current settings of
+// mount, with the same bounds fly.toml [mounts] are validated against.
+func getAutoExtend(ctx context.Context, mount fly.MachineMount) (autoExtend, error) {
+	ae := autoExtend{
+		ThresholdPercent: mount.ExtendThresholdPercent,
+		AddSizeGb:        mount.AddSizeGb,
+		SizeGbLimit:      mount.SizeGbLimit,
+	}
+	if flag.IsSpecified(ctx, "auto-extend-threshold") {
+		ae.ThresholdPercent = flag.GetInt(ctx, "auto-extend-threshold")
+	}
+	if flag.IsSpecified(ctx, "auto-extend-by") {
+		ae.AddSizeGb = flag.GetInt(ctx, "auto-extend-by")
+	}
+	if flag.IsSpecified(ctx, "max-size") {
+		ae.SizeGbLimit = flag.GetInt(ctx, "max-size")
+	}
+
+	if ae.ThresholdPercent == 0 {
+		return autoExtend{}, nil
+	}
+	return ae, ae.validate()
+}
+
+func (ae autoExtend) validate() error {
+	switch {
+	case ae.ThresholdPercent < 50 || ae.ThresholdPercent > 99:
+		return fmt.Errorf("--auto-extend-threshold must be between 50 and 99, or 0 to turn auto-extend off")
+	case ae.AddSizeGb < 1 || ae.AddSizeGb > 100:
+		return fmt.Errorf("--auto-extend-by must be between 1 and 100 GB")
+	case ae.SizeGbLimit != 0 && (ae.SizeGbLimit < 1 || ae.SizeGbLimit > 500):
+		return fmt.Errorf("--max-size must be between 1 and 500 GB")
+	}
+	return nil
+}
+
+// updateAutoExtend sets the auto-extend policy on the mount of the machine
+// using volume. The policy belongs to the machine rather than the volume, so
+// the [mounts] section of the local fly.toml is updated too for deploys to
+// keep it. A stopped machine stays stopped, a running one is restarted once
+// confirmed; it returns nil when that is declined.
+func updateAutoExtend(ctx context.Context, appName string, volume *fly.Volume) (*autoExtend, error) {
+	flapsClient := flapsutil.ClientFromContext(ctx)
+
+	if volume.AttachedMachine == nil {
+		return nil, fmt.Errorf("volume %s isn't attached to a machine, auto-extend is set on the machine it is mounted on", volume.ID)
+	}
+
+	machine, err := flapsClient.Get(ctx, *volume.AttachedMachine)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := -1
+	for i, m := range machine.Config.Mounts {
+		if m.Volume == volume.ID {
+			idx = i
+		}
+	}
+	if idx < 0 {
+		return nil, fmt.Errorf("machine %s doesn't mount volume %s", machine.ID, volume.ID)
+	}
+
+	ae, err := getAutoExtend(ctx, machine.Config.Mounts[idx])
+	if err != nil {
+		return nil, err
+	}
+
+	stopped := machine.State == fly.MachineStateStopped
+	if !stopped && !flag.GetYes(ctx) {
+		switch confirmed, err := prompt.Confirmf(ctx, "Machine %s is %s and restarts to apply auto-extend. Continue?", machine.ID, machine.State); {
+		case err == nil:
+			if !confirmed {
+				return nil, nil
+			}
+		case prompt.IsNonInteractive(err):
+			return nil, prompt.NonInteractiveError("--yes flag must be specified when not running interactively")
+		default:
+			return nil, err
+		}
+	}
+
+	machine, releaseFunc, err := mach.AcquireLease(ctx, machine)
+	defer releaseFunc()
+	if err != nil {
+		return nil, err
+	}
+
+	mount := &machine.Config.Mounts[idx]
+	mount.ExtendThresholdPercent = ae.ThresholdPercent
+	mount.AddSizeGb = ae.AddSizeGb
+	mount.SizeGbLimit = ae.SizeGbLimit
+
+	input := &fly.LaunchMachineInput{
+		Name:       machine.Name,
+		Region:     machine.Region,
+		Config:     machine.Config,
+		SkipLaunch: stopped,
+	}
+	if err := mach.Update(ctx, machine, input); err != nil {
+		return nil, err
+	}
+
+	return &ae, saveAutoExtend(ctx, appName, volume.Name, ae)
+}
+
+// saveAutoExtend writes ae to the [mounts] of volumeName in the local fly.toml
+// of appName, if there is one, keeping its comments and formatting.
+func saveAutoExtend(ctx context.Context, appName, volumeName string, ae autoExtend) error {
+	io := iostreams.FromContext(ctx)
+
+	cfg := appconfig.ConfigFromContext(ctx)
+	if cfg == nil || cfg.AppName != appName {
+		fmt.Fprintf(io.ErrOut, "No fly.toml found for %s, set auto_extend_size_* on the [mounts] of '%s' or the next deploy reverts the change\n", appName, volumeName)
+		return nil
+	}
+
+	var increment, limit string
+	if ae.AddSizeGb > 0 {
+		increment = fmt.Sprintf("%dGB", ae.AddSizeGb)
+	}
+	if ae.SizeGbLimit > 0 {
+		limit = fmt.Sprintf("%dGB", ae.SizeGbLimit)
+	}
+
+	path := cfg.ConfigFilePath()
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	buf, changed, err := appconfig.SetConfigFileMountAutoExtend(path, buf, volumeName, ae.ThresholdPercent, increment, limit)
+	if err != nil {
+		return fmt.Errorf("could not save auto-extend to %s: %w", path, err)
+	}
+	if changed == 0 {
+		fmt.Fprintf(io.ErrOut, "%s has no [mounts] for '%s', the next deploy may revert the change\n", helpers.PathRelativeToCWD(path), volumeName)
+		return nil
+	}
+	if err := os.WriteFile(path, buf, info.Mode()); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(io.ErrOut, "Updated %s, auto-extend applies to every volume mounted from '%s' on the next deploy\n", helpers.PathRelativeToCWD(path), volumeName)
+	return nil
+}
+
+func printAutoExtend(w io.Writer, ae autoExtend) {
+	if ae.ThresholdPercent == 0 {
+		fmt.Fprintf(w, "%20s: %s\n", "Auto-extend", "off")
+		return
+	}
+
+	limit := "none"
+	if ae.SizeGbLimit > 0 {
+		limit = strconv.Itoa(ae.SizeGbLimit) + "GB"
+	}
+	fmt.Fprintf(w, "%20s: +%dGB at %d%% used, up to %s\n", "Auto-extend", ae.AddSizeGb, ae.ThresholdPercent, limit)
+}
@@ -0,0 +1,183 @@
+package volumes
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dustin/go-humanize"
+	"github.com/samber/lo"
+	"github.com/sourcegraph/conc/pool"
+	"github.com/spf13/cobra"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/fly-go/flaps"
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flapsutil"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func newDF() *cobra.Command {
+	const (
+		short = "Show disk usage of an app's volumes."
+
+		long = short + ` The used and total space of each volume is read by running df
+on the started machine it is mounted on. Volumes above --threshold percent
+used are flagged. Unattached volumes and volumes of stopped machines only show
+their provisioned size.`
+
+		usage = "df"
+	)
+
+	cmd := command.New(usage, short, long, runDF,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.JSONOutput(),
+		flag.Int{
+			Name:        "threshold",
+			Description: "Flag volumes using more than this percentage of their space",
+			Default:     80,
+		},
+	)
+
+	return cmd
+}
+
+type volumeUsage struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Region     string `json:"region"`
+	SizeGb     int    `json:"size_gb"`
+	MachineID  string `json:"machine_id,omitempty"`
+	Path       string `json:"path,omitempty"`
+	UsedBytes  uint64 `json:"used_bytes,omitempty"`
+	TotalBytes uint64 `json:"total_bytes,omitempty"`
+	// Status explains why usage is missing, or flags a volume above the threshold
+	Status string `json:"status,omitempty"`
+}
+
+func (u *volumeUsage) percent() uint64 {
+	if u.TotalBytes == 0 {
+		return 0
+	}
+	return u.UsedBytes * 100 / u.TotalBytes
+}
+
+func runDF(ctx context.Context) error {
+	var (
+		io        = iostreams.FromContext(ctx)
+		cfg       = config.FromContext(ctx)
+		appName   = appconfig.NameFromContext(ctx)
+		threshold = flag.GetInt(ctx, "threshold")
+	)
+
+	if threshold < 1 || threshold > 100 {
+		return fmt.Errorf("--threshold must be between 1 and 100, got: %d", threshold)
+	}
+
+	flapsClient, err := flapsutil.NewClientWithOptions(ctx, flaps.NewClientOpts{
+		AppName: appName,
+	})
+	if err != nil {
+		return err
+	}
+	ctx = flapsutil.NewContextWithClient(ctx, flapsClient)
+
+	volumes, err := flapsClient.GetVolumes(ctx)
+	if err != nil {
+		return fmt.Errorf("failed retrieving volumes: %w", err)
+	}
+	machines, err := flapsClient.List(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed retrieving machines: %w", err)
+	}
+	machinesByID := lo.KeyBy(machines, func(m *fly.Machine) string { return m.ID })
+
+	usages := make([]*volumeUsage, len(volumes))
+	p := pool.New().WithMaxGoroutines(8)
+	for i, vol := range volumes {
+		usages[i] = &volumeUsage{
+			ID:     vol.ID,
+			Name:   vol.Name,
+			Region: vol.Region,
+			SizeGb: vol.SizeGb,
+		}
+		p.Go(func() {
+			getVolumeUsage(ctx, usages[i], vol, machinesByID, threshold)
+		})
+	}
+	p.Wait()
+
+	if cfg.JSONOutput {
+		return render.JSON(io.Out, usages)
+	}
+
+	if len(usages) == 0 {
+		fmt.Fprintf(io.ErrOut, "No volumes found in app '%s'\n", appName)
+		return nil
+	}
+
+	rows := make([][]string, 0, len(usages))
+	for _, u := range usages {
+		used, total, pct := "-", fmt.Sprintf("%dGB", u.SizeGb), "-"
+		if u.TotalBytes > 0 {
+			used = humanize.IBytes(u.UsedBytes)
+			total = humanize.IBytes(u.TotalBytes)
+			pct = fmt.Sprintf("%d%%", u.percent())
+		}
+		rows = append(rows, []string{u.ID, u.Name, u.Region, u.MachineID, u.Path, used, total, pct, u.Status})
+	}
+	if err := render.Table(io.Out, "", rows, "ID", "Name", "Region", "Machine", "Path", "Used", "Size", "Use%", "Status"); err != nil {
+		return err
+	}
+
+	if full := lo.CountBy(usages, func(u *volumeUsage) bool { return u.percent() >= uint64(threshold) }); full > 0 {
+		fmt.Fprintf(io.ErrOut, "%d volumes are above %d%% used, extend them with 'fly volumes extend' or set auto-extend with 'fly volumes update'\n", full, threshold)
+	}
+	return nil
+}
+
+func getVolumeUsage(ctx context.Context, u *volumeUsage, vol fly.Volume, machinesByID map[string]*fly.Machine, threshold int) {
+	if vol.AttachedMachine == nil {
+		u.Status = "unattached"
+		return
+	}
+	u.MachineID = *vol.AttachedMachine
+
+	m, ok := machinesByID[u.MachineID]
+	if !ok || m.Config == nil {
+		u.Status = "machine not found"
+		return
+	}
+	if mount, ok := lo.Find(m.Config.Mounts, func(mount fly.MachineMount) bool { return mount.Volume == vol.ID }); ok {
+		u.Path = mount.Path
+	}
+	switch {
+	case u.Path == "":
+		u.Status = "not mounted"
+		return
+	case m.State != fly.MachineStateStarted:
+		u.Status = "machine " + m.State
+		return
+	}
+
+	used, total, err := mach.DiskUsage(ctx, m, u.Path)
+	if err != nil {
+		u.Status = "df failed: " + err.Error()
+		return
+	}
+	u.UsedBytes, u.TotalBytes = used, total
+	if u.percent() >= uint64(threshold) {
+		u.Status = fmt.Sprintf("above %d%%", threshold)
+	}
+}
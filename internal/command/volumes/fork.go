@@ -3,12 +3,14 @@ package volumes
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 	fly "github.com/superfly/fly-go"
 	"github.com/superfly/fly-go/flaps"
 	"github.com/superfly/flyctl/internal/appconfig"
 	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/command/volumes/volumeexec"
 	"github.com/superfly/flyctl/internal/config"
 	"github.com/superfly/flyctl/internal/flag"
 	"github.com/superfly/flyctl/internal/flapsutil"
@@ -21,9 +23,15 @@ func newFork() *cobra.Command {
 	const (
 		short = "Fork the specified volume."
 
-		long = short + ` Volume forking creates an independent copy of a storage volume for backup, testing, and experimentation without altering the original data.`
+		long = short + ` Volume forking creates an independent copy of a storage volume for backup, testing, and experimentation without altering the original data.
 
-		usage = "fork <volume id>"
+With --all, the volumes of every machine in the app, or in the process group given by --process-group, are forked and a table maps the old volumes to the new ones. Adding --attach replaces those machines with new ones attached to the forks, e.g. to move a group to dedicated hosts:
+
+  fly volumes fork --all --process-group db --host-dedication-id abc --attach
+
+With --verify, the fork is only reported once it holds all the data and the checksums of the files on both volumes match. Each volume is read on an ephemeral machine of its own, so the source can't be attached to a machine, which could write to it while it's read: destroy the machine first or fork a volume restored from a snapshot of it.`
+
+		usage = "fork [volume id]"
 	)
 
 	cmd := command.New(usage, short, long, runFork,
@@ -32,6 +40,7 @@ func newFork() *cobra.Command {
 	)
 
 	cmd.Args = cobra.MaximumNArgs(1)
+	cmd.Aliases = []string{"clone"}
 
 	flag.Add(cmd,
 		flag.App(),
@@ -56,6 +65,25 @@ func newFork() *cobra.Command {
 			Shorthand:   "r",
 			Description: "The target region. By default, the new volume will be created in the source volume's region.",
 		},
+		flag.Bool{
+			Name:        "all",
+			Description: "Fork the volumes of all the machines in the app or process group",
+		},
+		flag.ProcessGroup("With --all, only fork the volumes of this process group"),
+		flag.Bool{
+			Name:        "attach",
+			Description: "With --all, stop each machine, fork its volume and move the machine to the fork",
+		},
+		flag.Bool{
+			Name:        "verify",
+			Description: "Compare the checksums of the files on the source and forked volumes before reporting success",
+		},
+		flag.Duration{
+			Name:        "wait-timeout",
+			Description: "With --verify, how long to wait for the fork to hold all the data",
+			Default:     10 * time.Minute,
+		},
+		flag.Yes(),
 		flag.VMSizeFlags,
 	)
 
@@ -78,6 +106,19 @@ func runFork(ctx context.Context) error {
 		return err
 	}
 
+	if flag.GetBool(ctx, "all") {
+		if volID != "" {
+			return fmt.Errorf("a volume ID can't be given with --all")
+		}
+		if flag.GetBool(ctx, "verify") {
+			return fmt.Errorf("--verify can't be used with --all")
+		}
+		return runForkAll(ctx, flapsClient)
+	}
+	if flag.GetProcessGroup(ctx) != "" || flag.GetBool(ctx, "attach") {
+		return fmt.Errorf("--process-group and --attach require --all")
+	}
+
 	var vol *fly.Volume
 	if volID == "" {
 		app, err := client.GetAppBasic(ctx, appName)
@@ -94,6 +135,9 @@ func runFork(ctx context.Context) error {
 			return fmt.Errorf("failed to get volume: %w", err)
 		}
 	}
+	if flag.GetBool(ctx, "verify") && vol.IsAttached() {
+		return fmt.Errorf("--verify can't read volume %s while it's attached to machine %s, which could write to it meanwhile", vol.ID, *vol.AttachedMachine)
+	}
 
 	name := vol.Name
 	if flag.IsSpecified(ctx, "name") {
@@ -143,6 +187,13 @@ func runFork(ctx context.Context) error {
 		return fmt.Errorf("failed to fork volume: %w", err)
 	}
 
+	if flag.GetBool(ctx, "verify") {
+		ctx = flapsutil.NewContextWithClient(ctx, flapsClient)
+		if err := verifyFork(ctx, appName, vol, volume); err != nil {
+			return err
+		}
+	}
+
 	out := iostreams.FromContext(ctx).Out
 
 	if cfg.JSONOutput {
@@ -155,3 +206,39 @@ func runFork(ctx context.Context) error {
 
 	return nil
 }
+
+// verifyFork waits for forked to hold all the data of vol and compares the
+// checksums of their files.
+func verifyFork(ctx context.Context, appName string, vol, forked *fly.Volume) error {
+	var (
+		io     = iostreams.FromContext(ctx)
+		client = flyutil.ClientFromContext(ctx)
+	)
+
+	app, err := client.GetAppCompact(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(io.ErrOut, "Waiting for volume %s to hold all the data of %s\n", forked.ID, vol.ID)
+	if err := waitForHydration(ctx, forked.ID, flag.GetDuration(ctx, "wait-timeout")); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(io.ErrOut, "Computing the checksum of volume %s\n", vol.ID)
+	want, err := volumeexec.Checksum(ctx, app, vol)
+	if err != nil {
+		return fmt.Errorf("failed to compute the checksum of volume %s: %w", vol.ID, err)
+	}
+	fmt.Fprintf(io.ErrOut, "Computing the checksum of volume %s\n", forked.ID)
+	got, err := volumeexec.Checksum(ctx, app, forked)
+	if err != nil {
+		return fmt.Errorf("failed to compute the checksum of volume %s: %w", forked.ID, err)
+	}
+
+	if got != want {
+		return fmt.Errorf("volume %s doesn't match its source %s (checksum %s, want %s)", forked.ID, vol.ID, got, want)
+	}
+	fmt.Fprintf(io.ErrOut, "Verified volume %s matches %s (checksum %s)\n", forked.ID, vol.ID, got)
+	return nil
+}
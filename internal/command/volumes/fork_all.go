@@ -0,0 +1,252 @@
+package volumes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/samber/lo"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/flyctl/helpers"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flapsutil"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/prompt"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+type volumeFork struct {
+	MachineID    string `json:"machine_id"`
+	ProcessGroup string `json:"process_group"`
+	Region       string `json:"region"`
+	OldVolumeID  string `json:"old_volume_id"`
+	NewVolumeID  string `json:"new_volume_id"`
+	// NewMachineID is set once the machine is replaced by one attached to the new volume
+	NewMachineID string `json:"new_machine_id,omitempty"`
+}
+
+// forkAttachTimeout bounds how long --attach waits for a machine to stop, and
+// for the machine using the fork to start and pass its checks.
+const forkAttachTimeout = 5 * time.Minute
+
+// runForkAll forks the volume of every machine in the process group, or in
+// the app when no group is given, and optionally moves the machines to the
+// forks.
+func runForkAll(ctx context.Context, flapsClient flapsutil.FlapsClient) error {
+	var (
+		io     = iostreams.FromContext(ctx)
+		cfg    = config.FromContext(ctx)
+		group  = flag.GetProcessGroup(ctx)
+		attach = flag.GetBool(ctx, "attach")
+	)
+
+	if flag.IsSpecified(ctx, "name") {
+		return fmt.Errorf("--name can't be used with --all, forks keep the name of their source volume")
+	}
+
+	ctx = flapsutil.NewContextWithClient(ctx, flapsClient)
+	machines, err := mach.ListActive(ctx)
+	if err != nil {
+		return err
+	}
+	machines = lo.Filter(machines, func(m *fly.Machine, _ int) bool {
+		return len(m.Config.Mounts) > 0 && (group == "" || m.ProcessGroup() == group)
+	})
+	if len(machines) == 0 {
+		if group != "" {
+			return fmt.Errorf("no machines with volumes in process group '%s'", group)
+		}
+		return fmt.Errorf("no machines with volumes in this app")
+	}
+
+	if attach {
+		if !flag.GetYes(ctx) {
+			switch confirmed, err := prompt.Confirmf(ctx, "Stop %d machines one at a time to fork their volumes and move them to the forks?", len(machines)); {
+			case err == nil:
+				if !confirmed {
+					return nil
+				}
+			case prompt.IsNonInteractive(err):
+				return prompt.NonInteractiveError("--yes flag must be specified when not running interactively")
+			default:
+				return err
+			}
+		}
+
+		var releaseFunc func()
+		machines, releaseFunc, err = mach.AcquireLeases(ctx, machines)
+		defer releaseFunc()
+		if err != nil {
+			return err
+		}
+	}
+
+	var requireUniqueZone *bool
+	if flag.IsSpecified(ctx, "require-unique-zone") {
+		requireUniqueZone = fly.Pointer(flag.GetBool(ctx, "require-unique-zone"))
+	}
+
+	forks := make([]*volumeFork, 0, len(machines))
+	for _, m := range machines {
+		vol, err := flapsClient.GetVolume(ctx, m.Config.Mounts[0].Volume)
+		if err != nil {
+			return fmt.Errorf("failed to get volume of machine %s: %w", m.ID, err)
+		}
+
+		guest, err := flag.GetMachineGuest(ctx, helpers.Clone(m.Config.Guest))
+		if err != nil {
+			return err
+		}
+
+		// A fork only holds what was written before it, so the machine is
+		// stopped first when it moves to the fork
+		var wasRunning bool
+		if attach {
+			fmt.Fprintf(io.ErrOut, "Stopping machine %s\n", m.ID)
+			if wasRunning, err = stopForFork(ctx, flapsClient, m, forkAttachTimeout); err != nil {
+				return restartStopped(ctx, flapsClient, m, wasRunning, err)
+			}
+		}
+
+		fmt.Fprintf(io.ErrOut, "Forking volume %s of machine %s\n", vol.ID, m.ID)
+		forked, err := flapsClient.CreateVolume(ctx, fly.CreateVolumeRequest{
+			Name:                vol.Name,
+			RequireUniqueZone:   requireUniqueZone,
+			SourceVolumeID:      &vol.ID,
+			ComputeRequirements: guest,
+			ComputeImage:        m.FullImageRef(),
+			Region:              flag.GetString(ctx, "region"),
+		})
+		if err != nil {
+			return restartStopped(ctx, flapsClient, m, wasRunning, fmt.Errorf("failed to fork volume %s: %w", vol.ID, err))
+		}
+
+		f := &volumeFork{
+			MachineID:    m.ID,
+			ProcessGroup: m.ProcessGroup(),
+			Region:       forked.Region,
+			OldVolumeID:  vol.ID,
+			NewVolumeID:  forked.ID,
+		}
+		forks = append(forks, f)
+
+		if attach {
+			newMachine, err := replaceMachineVolume(ctx, flapsClient, m, guest, forked, forkAttachTimeout)
+			if err != nil {
+				if newMachine == nil {
+					err = restartStopped(ctx, flapsClient, m, wasRunning, err)
+				}
+				return err
+			}
+			f.NewMachineID = newMachine.ID
+			fmt.Fprintf(io.ErrOut, "Machine %s uses volume %s\n", newMachine.ID, forked.ID)
+		}
+	}
+
+	if attach {
+		fmt.Fprintf(io.ErrOut, "The old volumes were kept, destroy them with 'fly volumes destroy' once the data is checked\n")
+	}
+
+	if cfg.JSONOutput {
+		return render.JSON(io.Out, forks)
+	}
+
+	rows := make([][]string, 0, len(forks))
+	for _, f := range forks {
+		rows = append(rows, []string{f.MachineID, f.ProcessGroup, f.Region, f.OldVolumeID, f.NewVolumeID, f.NewMachineID})
+	}
+	return render.Table(io.Out, "", rows, "Machine", "Process Group", "Region", "Old Volume", "New Volume", "New Machine")
+}
+
+// stopForFork stops the leased machine m, so that a fork of its volume holds
+// everything it wrote. It reports whether m was running, to start it again
+// if moving it to the fork fails.
+func stopForFork(ctx context.Context, flapsClient flapsutil.FlapsClient, m *fly.Machine, timeout time.Duration) (bool, error) {
+	if m.State == fly.MachineStateStopped {
+		return false, nil
+	}
+	if err := flapsClient.Stop(ctx, fly.StopMachineInput{ID: m.ID}, m.LeaseNonce); err != nil {
+		return false, fmt.Errorf("could not stop machine %s: %w", m.ID, err)
+	}
+	if err := flapsClient.Wait(ctx, m, fly.MachineStateStopped, timeout); err != nil {
+		return true, fmt.Errorf("machine %s didn't stop: %w", m.ID, err)
+	}
+	return true, nil
+}
+
+// restartStopped starts m again, with its previous volume, when it was
+// stopped to move it to a fork and the move failed with err.
+func restartStopped(ctx context.Context, flapsClient flapsutil.FlapsClient, m *fly.Machine, wasRunning bool, err error) error {
+	if !wasRunning {
+		return err
+	}
+	if _, startErr := flapsClient.Start(ctx, m.ID, m.LeaseNonce); startErr != nil {
+		return fmt.Errorf("%w\nmachine %s is stopped and could not be started again: %v", err, m.ID, startErr)
+	}
+	return fmt.Errorf("%w\nmachine %s was started again with its previous volume", err, m.ID)
+}
+
+// replaceMachineVolume moves the leased and stopped machine m to vol with
+// guest. In the region and on the hosts of m, m is updated to mount vol, like
+// 'fly machine volumes attach' does. Otherwise a machine with the same name
+// and config is launched in the region of vol, and m is only destroyed once
+// the new machine is started and healthy.
+//
+// The returned machine is nil when m still uses its previous volume.
+func replaceMachineVolume(ctx context.Context, flapsClient flapsutil.FlapsClient, m *fly.Machine, guest *fly.MachineGuest, vol *fly.Volume, timeout time.Duration) (*fly.Machine, error) {
+	io := iostreams.FromContext(ctx)
+
+	config := mach.CloneConfig(m.Config)
+	config.Guest = guest
+	config.Mounts[0].Volume = vol.ID
+
+	var hdid string
+	if m.Config.Guest != nil {
+		hdid = m.Config.Guest.HostDedicationID
+	}
+
+	if vol.Region == m.Region && guest.HostDedicationID == hdid {
+		updated, err := flapsClient.Update(ctx, fly.LaunchMachineInput{
+			ID:     m.ID,
+			Name:   m.Name,
+			Region: m.Region,
+			Config: config,
+		}, m.LeaseNonce)
+		if err != nil {
+			return nil, fmt.Errorf("could not attach volume %s to machine %s: %w", vol.ID, m.ID, err)
+		}
+		if err := waitStartedAndHealthy(ctx, flapsClient, io, updated, timeout); err != nil {
+			return updated, err
+		}
+		return updated, nil
+	}
+
+	newMachine, err := flapsClient.Launch(ctx, fly.LaunchMachineInput{
+		Name:   m.Name,
+		Region: vol.Region,
+		Config: config,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not launch the replacement of machine %s: %w", m.ID, err)
+	}
+	if err := waitStartedAndHealthy(ctx, flapsClient, io, newMachine, timeout); err != nil {
+		return nil, fmt.Errorf("%w\nmachine %s was kept, destroy its replacement %s before trying again", err, m.ID, newMachine.ID)
+	}
+
+	if err := flapsClient.Destroy(ctx, fly.RemoveMachineInput{ID: m.ID, Kill: true}, m.LeaseNonce); err != nil {
+		return newMachine, fmt.Errorf("machine %s was replaced by %s but could not be destroyed: %w", m.ID, newMachine.ID, err)
+	}
+	// The lease went away with the machine
+	m.LeaseNonce = ""
+	return newMachine, nil
+}
+
+func waitStartedAndHealthy(ctx context.Context, flapsClient flapsutil.FlapsClient, io *iostreams.IOStreams, m *fly.Machine, timeout time.Duration) error {
+	lm := mach.NewLeasableMachine(flapsClient, io, m, false)
+	if err := lm.WaitForState(ctx, fly.MachineStateStarted, timeout, false); err != nil {
+		return err
+	}
+	return lm.WaitForHealthchecksToPass(ctx, timeout)
+}
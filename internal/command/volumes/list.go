@@ -38,6 +38,10 @@ func newList() *cobra.Command {
 			Name:        "all",
 			Description: "Show all volumes including those in destroyed states",
 		},
+		flag.Bool{
+			Name:        "orphaned",
+			Description: "Only show volumes that aren't attached to a machine nor used by the [mounts] of the app's config",
+		},
 	)
 
 	flag.Add(cmd, flag.JSONOutput())
@@ -72,6 +76,13 @@ func runList(ctx context.Context) error {
 		return fmt.Errorf("failed retrieving volumes: %w", err)
 	}
 
+	if flag.GetBool(ctx, "orphaned") {
+		ctx = flapsutil.NewContextWithClient(ctx, flapsClient)
+		if volumes, err = orphanedVolumes(ctx, appName, volumes); err != nil {
+			return err
+		}
+	}
+
 	out := iostreams.FromContext(ctx).Out
 
 	if cfg.JSONOutput {
@@ -0,0 +1,243 @@
+package volumes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/fly-go/flaps"
+	"github.com/superfly/flyctl/helpers"
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flag/completion"
+	"github.com/superfly/flyctl/internal/flapsutil"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/internal/prompt"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func newMigrate() *cobra.Command {
+	const (
+		short = "Move a volume to another region or dedicated host."
+
+		long = short + ` The machine the volume is attached to is stopped, the volume is
+forked to the new placement and the machine is replaced by one using the fork.
+Once the new machine is started and healthy and the fork holds all the data,
+the old volume is destroyed after a confirmation unless --keep-old is set.`
+
+		usage = "migrate <volume id>"
+	)
+
+	cmd := command.New(usage, short, long, runMigrate,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.ExactArgs(1)
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Yes(),
+		flag.String{
+			Name:         "region",
+			Shorthand:    "r",
+			Description:  "The region to move the volume to",
+			CompletionFn: completion.CompleteRegions,
+		},
+		flag.String{
+			Name:        "host-dedication-id",
+			Description: "The dedication id of the reserved hosts to move the volume to",
+		},
+		flag.Bool{
+			Name:        "keep-old",
+			Description: "Keep the old volume instead of destroying it once the migration is verified",
+		},
+		flag.Duration{
+			Name:        "wait-timeout",
+			Description: "How long to wait for the new machine to be healthy and the fork to hold all the data",
+			Default:     10 * time.Minute,
+		},
+	)
+
+	return cmd
+}
+
+func runMigrate(ctx context.Context) error {
+	var (
+		appName = appconfig.NameFromContext(ctx)
+		volID   = flag.FirstArg(ctx)
+		region  = flag.GetString(ctx, "region")
+		hdid    = flag.GetString(ctx, "host-dedication-id")
+		timeout = flag.GetDuration(ctx, "wait-timeout")
+	)
+
+	if region == "" && hdid == "" {
+		return fmt.Errorf("pass --region, --host-dedication-id or both to say where the volume goes")
+	}
+
+	flapsClient, err := flapsutil.NewClientWithOptions(ctx, flaps.NewClientOpts{
+		AppName: appName,
+	})
+	if err != nil {
+		return err
+	}
+	ctx = flapsutil.NewContextWithClient(ctx, flapsClient)
+
+	vol, err := flapsClient.GetVolume(ctx, volID)
+	if err != nil {
+		return fmt.Errorf("failed to get volume: %w", err)
+	}
+
+	input := fly.CreateVolumeRequest{
+		Name:           vol.Name,
+		SourceVolumeID: &vol.ID,
+		Region:         region,
+	}
+	return replaceVolume(ctx, vol, input, hdid, "Migrate", timeout)
+}
+
+// replaceVolume stops the machine vol is attached to, forks vol with input
+// and moves the machine to the fork. Once the machine is healthy and the fork
+// holds all the data, vol is destroyed after a confirmation unless --keep-old
+// is set. hdid moves the fork and the machine to other dedicated hosts.
+func replaceVolume(ctx context.Context, vol *fly.Volume, input fly.CreateVolumeRequest, hdid, action string, timeout time.Duration) error {
+	var (
+		io          = iostreams.FromContext(ctx)
+		flapsClient = flapsutil.ClientFromContext(ctx)
+		err         error
+	)
+
+	var machine *fly.Machine
+	guest := &fly.MachineGuest{}
+	if vol.AttachedMachine != nil {
+		machine, err = flapsClient.Get(ctx, *vol.AttachedMachine)
+		if err != nil {
+			return err
+		}
+		guest = helpers.Clone(machine.Config.Guest)
+		input.ComputeImage = machine.FullImageRef()
+	}
+	if hdid != "" {
+		guest.HostDedicationID = hdid
+	}
+	if machine != nil || hdid != "" {
+		input.ComputeRequirements = guest
+	}
+
+	if !flag.GetYes(ctx) {
+		msg := fmt.Sprintf("%s volume %s?", action, vol.ID)
+		if machine != nil {
+			msg = fmt.Sprintf("%s volume %s? Machine %s will be stopped while the volume is forked", action, vol.ID, machine.ID)
+		}
+		switch confirmed, err := prompt.Confirm(ctx, msg); {
+		case err == nil:
+			if !confirmed {
+				return nil
+			}
+		case prompt.IsNonInteractive(err):
+			return prompt.NonInteractiveError("--yes flag must be specified when not running interactively")
+		default:
+			return err
+		}
+	}
+
+	// A fork only holds what was written before it, so the machine is stopped
+	// and kept stopped by the lease until it is moved to the fork
+	var wasRunning bool
+	if machine != nil {
+		var releaseFunc func()
+		machine, releaseFunc, err = mach.AcquireLease(ctx, machine)
+		defer releaseFunc()
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(io.Out, "Stopping machine %s\n", machine.ID)
+		if wasRunning, err = stopForFork(ctx, flapsClient, machine, timeout); err != nil {
+			return restartStopped(ctx, flapsClient, machine, wasRunning, err)
+		}
+	}
+
+	fmt.Fprintf(io.Out, "Forking volume %s\n", vol.ID)
+	forked, err := flapsClient.CreateVolume(ctx, input)
+	if err != nil {
+		err = fmt.Errorf("failed to fork volume: %w", err)
+		if machine != nil {
+			err = restartStopped(ctx, flapsClient, machine, wasRunning, err)
+		}
+		return err
+	}
+	fmt.Fprintf(io.Out, "  Created volume %s region:%s\n", forked.ID, forked.Region)
+
+	if machine != nil {
+		newMachine, err := replaceMachineVolume(ctx, flapsClient, machine, guest, forked, timeout)
+		if err != nil {
+			if newMachine == nil {
+				err = restartStopped(ctx, flapsClient, machine, wasRunning, err)
+			}
+			return fmt.Errorf("%w\nThe old volume %s was kept", err, vol.ID)
+		}
+		fmt.Fprintf(io.Out, "  Machine %s is started and healthy on volume %s\n", newMachine.ID, forked.ID)
+	}
+
+	// A fork copies the data in the background, the source has to stay until
+	// it is done
+	if err := waitForHydration(ctx, forked.ID, timeout); err != nil {
+		return fmt.Errorf("%w\nThe old volume %s was kept", err, vol.ID)
+	}
+
+	if flag.GetBool(ctx, "keep-old") {
+		fmt.Fprintf(io.Out, "Volume %s replaced by %s, the old volume was kept\n", vol.ID, forked.ID)
+		return nil
+	}
+
+	if !flag.GetYes(ctx) {
+		switch confirmed, err := prompt.Confirmf(ctx, "Volume %s holds all the data, destroy the old volume %s?", forked.ID, vol.ID); {
+		case err == nil:
+			if !confirmed {
+				fmt.Fprintf(io.Out, "Volume %s replaced by %s, the old volume was kept\n", vol.ID, forked.ID)
+				return nil
+			}
+		case prompt.IsNonInteractive(err):
+			return prompt.NonInteractiveError("--yes flag must be specified when not running interactively")
+		default:
+			return err
+		}
+	}
+
+	if _, err := flapsClient.DeleteVolume(ctx, vol.ID); err != nil {
+		return fmt.Errorf("volume replaced by %s but the old volume %s could not be destroyed: %w", forked.ID, vol.ID, err)
+	}
+	fmt.Fprintf(io.Out, "Volume %s replaced by %s and destroyed\n", vol.ID, forked.ID)
+	return nil
+}
+
+// waitForHydration waits until the forked volume holds all the data of its
+// source.
+func waitForHydration(ctx context.Context, volID string, timeout time.Duration) error {
+	flapsClient := flapsutil.ClientFromContext(ctx)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		vol, err := flapsClient.GetVolume(ctx, volID)
+		switch {
+		case ctx.Err() != nil:
+			return fmt.Errorf("timeout reached waiting for volume %s to be hydrated", volID)
+		case err != nil:
+			return fmt.Errorf("failed to get volume %s: %w", volID, err)
+		case vol.State == "created":
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
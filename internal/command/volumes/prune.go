@@ -0,0 +1,124 @@
+package volumes
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/fly-go/flaps"
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flapsutil"
+	"github.com/superfly/flyctl/internal/flyutil"
+	"github.com/superfly/flyctl/internal/prompt"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func newPrune() *cobra.Command {
+	const (
+		short = "Destroy an app's orphaned volumes."
+
+		long = short + ` A volume is orphaned when it isn't attached to a machine and its
+name isn't the source of any [mounts] in the app's config, so no deploy or scale
+will ever use it. The local fly.toml is used when it belongs to the app, the
+deployed config otherwise. Orphaned volumes are listed by
+'fly volumes list --orphaned'.`
+	)
+
+	cmd := command.New("prune", short, long, runPrune,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Yes(),
+	)
+
+	return cmd
+}
+
+func runPrune(ctx context.Context) error {
+	var (
+		io      = iostreams.FromContext(ctx)
+		client  = flyutil.ClientFromContext(ctx)
+		appName = appconfig.NameFromContext(ctx)
+	)
+
+	app, err := client.GetAppBasic(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	flapsClient, err := flapsutil.NewClientWithOptions(ctx, flaps.NewClientOpts{
+		AppName: appName,
+	})
+	if err != nil {
+		return err
+	}
+	ctx = flapsutil.NewContextWithClient(ctx, flapsClient)
+
+	volumes, err := flapsClient.GetVolumes(ctx)
+	if err != nil {
+		return fmt.Errorf("failed retrieving volumes: %w", err)
+	}
+	orphaned, err := orphanedVolumes(ctx, appName, volumes)
+	if err != nil {
+		return err
+	}
+	if len(orphaned) == 0 {
+		fmt.Fprintf(io.Out, "No orphaned volumes in app '%s'\n", appName)
+		return nil
+	}
+
+	if err := renderTable(ctx, orphaned, app, io.Out, false); err != nil {
+		return err
+	}
+
+	if !flag.GetYes(ctx) {
+		fmt.Fprintln(io.ErrOut, io.ColorScheme().Red("Destroying volumes permanently deletes all their data."))
+		switch confirmed, err := prompt.Confirmf(ctx, "Destroy these %d volumes?", len(orphaned)); {
+		case err == nil:
+			if !confirmed {
+				return nil
+			}
+		case prompt.IsNonInteractive(err):
+			return prompt.NonInteractiveError("--yes flag must be specified when not running interactively")
+		default:
+			return err
+		}
+	}
+
+	for _, vol := range orphaned {
+		if _, err := flapsClient.DeleteVolume(ctx, vol.ID); err != nil {
+			return fmt.Errorf("failed destroying volume %s: %w", vol.ID, err)
+		}
+		fmt.Fprintf(io.Out, "Destroyed volume ID: %s name: %s\n", vol.ID, vol.Name)
+	}
+
+	return nil
+}
+
+// orphanedVolumes returns the volumes that are neither attached to a machine
+// nor named as a mount source in the app's config. Unattached volumes that
+// match a mount are kept around for new machines of the app.
+func orphanedVolumes(ctx context.Context, appName string, volumes []fly.Volume) ([]fly.Volume, error) {
+	cfg := appconfig.ConfigFromContext(ctx)
+	if cfg == nil || cfg.AppName != appName {
+		var err error
+		if cfg, err = appconfig.FromRemoteApp(ctx, appName); err != nil {
+			return nil, fmt.Errorf("failed to get the config of app '%s' to check its mounts: %w", appName, err)
+		}
+	}
+
+	sources := lo.SliceToMap(cfg.Mounts, func(m appconfig.Mount) (string, bool) { return m.Source, true })
+	return lo.Filter(volumes, func(v fly.Volume, _ int) bool {
+		return !v.IsAttached() && !sources[v.Name]
+	}), nil
+}
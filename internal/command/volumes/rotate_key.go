@@ -0,0 +1,85 @@
+package volumes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/fly-go/flaps"
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flapsutil"
+)
+
+func newRotateKey() *cobra.Command {
+	const (
+		short = "Rotate the encryption key of a volume."
+
+		long = short + ` Every volume is encrypted with its own key, which can't be
+changed in place, so the machine the volume is attached to is stopped and the
+volume is forked in its region to a new volume with a new key. The machine is
+then moved to the fork and, once it is healthy and the fork holds all the data,
+the old volume and its key are destroyed after a confirmation unless --keep-old
+is set.`
+
+		usage = "rotate-key <volume id>"
+	)
+
+	cmd := command.New(usage, short, long, runRotateKey,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.ExactArgs(1)
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Yes(),
+		flag.Bool{
+			Name:        "keep-old",
+			Description: "Keep the old volume instead of destroying it once the rotation is verified",
+		},
+		flag.Duration{
+			Name:        "wait-timeout",
+			Description: "How long to wait for the new machine to be healthy and the fork to hold all the data",
+			Default:     10 * time.Minute,
+		},
+	)
+
+	return cmd
+}
+
+func runRotateKey(ctx context.Context) error {
+	var (
+		appName = appconfig.NameFromContext(ctx)
+		volID   = flag.FirstArg(ctx)
+	)
+
+	flapsClient, err := flapsutil.NewClientWithOptions(ctx, flaps.NewClientOpts{
+		AppName: appName,
+	})
+	if err != nil {
+		return err
+	}
+	ctx = flapsutil.NewContextWithClient(ctx, flapsClient)
+
+	vol, err := flapsClient.GetVolume(ctx, volID)
+	if err != nil {
+		return fmt.Errorf("failed to get volume: %w", err)
+	}
+	if !vol.Encrypted {
+		return fmt.Errorf("volume %s isn't encrypted, there is no key to rotate", vol.ID)
+	}
+
+	input := fly.CreateVolumeRequest{
+		Name:              vol.Name,
+		SourceVolumeID:    &vol.ID,
+		Encrypted:         fly.Pointer(true),
+		RequireUniqueZone: fly.Pointer(false),
+	}
+	return replaceVolume(ctx, vol, input, "", "Rotate the key of", flag.GetDuration(ctx, "wait-timeout"))
+}
@@ -0,0 +1,157 @@
+package snapshots
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/fly-go/flaps"
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/command/volumes/volumeexec"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flapsutil"
+	"github.com/superfly/flyctl/internal/flyutil"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+func newDownload() *cobra.Command {
+	const (
+		short = "Download a volume snapshot as a tar.gz archive."
+
+		long = short + ` The snapshot is restored to a temporary volume, mounted on an
+ephemeral machine running the app's current image, and archived over SSH to
+the output file. The temporary volume and machine are destroyed afterwards.
+The image must provide tar and gzip.`
+
+		usage = "download <snapshot id>"
+	)
+
+	cmd := command.New(usage, short, long, runDownload,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.ExactArgs(1)
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.String{
+			Name:        "output",
+			Shorthand:   "o",
+			Description: "The file to write the archive to",
+		},
+		flag.String{
+			Name:        "volume",
+			Description: "The volume the snapshot was taken from, found among the app's volumes when not set",
+		},
+	)
+
+	return cmd
+}
+
+func runDownload(ctx context.Context) error {
+	var (
+		io         = iostreams.FromContext(ctx)
+		client     = flyutil.ClientFromContext(ctx)
+		appName    = appconfig.NameFromContext(ctx)
+		snapshotID = flag.FirstArg(ctx)
+		output     = flag.GetString(ctx, "output")
+	)
+
+	if output == "" {
+		output = snapshotID + ".tar.gz"
+	}
+
+	app, err := client.GetAppCompact(ctx, appName)
+	if err != nil {
+		return fmt.Errorf("failed retrieving app: %w", err)
+	}
+
+	flapsClient, err := flapsutil.NewClientWithOptions(ctx, flaps.NewClientOpts{
+		AppName: appName,
+	})
+	if err != nil {
+		return err
+	}
+	ctx = flapsutil.NewContextWithClient(ctx, flapsClient)
+
+	vol, snapshot, err := findSnapshot(ctx, snapshotID, flag.GetString(ctx, "volume"))
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(io.ErrOut, "Restoring snapshot %s of volume %s to a temporary volume\n", snapshot.ID, vol.ID)
+	restored, err := flapsClient.CreateVolume(ctx, fly.CreateVolumeRequest{
+		Name:       vol.Name,
+		Region:     vol.Region,
+		SizeGb:     fly.Pointer(vol.SizeGb),
+		SnapshotID: fly.Pointer(snapshot.ID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore snapshot: %w", err)
+	}
+	defer func() {
+		if _, err := flapsClient.DeleteVolume(context.WithoutCancel(ctx), restored.ID); err != nil {
+			fmt.Fprintf(io.ErrOut, "Failed to destroy temporary volume %s, destroy it with 'fly volumes destroy %s': %v\n", restored.ID, restored.ID, err)
+		}
+	}()
+
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = volumeexec.Run(ctx, app, restored, fmt.Sprintf("tar -czf - -C %s .", volumeexec.MountPath), f)
+	if err != nil {
+		f.Close()
+		os.Remove(output)
+		return fmt.Errorf("failed to archive snapshot: %w", err)
+	}
+
+	stat, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(io.Out, "Snapshot %s downloaded to %s (%s)\n", snapshot.ID, output, humanize.IBytes(uint64(stat.Size())))
+	return nil
+}
+
+// findSnapshot looks up the snapshot among the snapshots of volID, or of every
+// volume of the app when volID is empty.
+func findSnapshot(ctx context.Context, snapshotID, volID string) (*fly.Volume, *fly.VolumeSnapshot, error) {
+	flapsClient := flapsutil.ClientFromContext(ctx)
+
+	var volumes []fly.Volume
+	if volID != "" {
+		vol, err := flapsClient.GetVolume(ctx, volID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get volume: %w", err)
+		}
+		volumes = []fly.Volume{*vol}
+	} else {
+		var err error
+		volumes, err = flapsClient.GetVolumes(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed retrieving volumes: %w", err)
+		}
+	}
+
+	for i := range volumes {
+		snapshots, err := flapsClient.GetVolumeSnapshots(ctx, volumes[i].ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed retrieving snapshots of volume %s: %w", volumes[i].ID, err)
+		}
+		for j := range snapshots {
+			if snapshots[j].ID == snapshotID {
+				return &volumes[i], &snapshots[j], nil
+			}
+		}
+	}
+	return nil, nil, fmt.Errorf("snapshot %s not found, pass the volume it was taken from with --volume", snapshotID)
+}
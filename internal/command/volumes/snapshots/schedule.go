@@ -0,0 +1,200 @@
+package snapshots
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/fly-go/flaps"
+	"github.com/superfly/flyctl/internal/appconfig"
+	"github.com/superfly/flyctl/internal/command"
+	"github.com/superfly/flyctl/internal/config"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flapsutil"
+	"github.com/superfly/flyctl/internal/flyutil"
+	"github.com/superfly/flyctl/internal/render"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+// The platform takes automatic snapshots once a day and keeps them for a
+// number of days within these bounds.
+const (
+	frequencyDaily   = "daily"
+	frequencyOff     = "off"
+	minRetentionDays = 1
+	maxRetentionDays = 60
+)
+
+func newSchedule() *cobra.Command {
+	const (
+		short = "Manage automatic volume snapshots."
+		long  = short + " Volumes are snapshotted once a day by default and the snapshots are kept for 5 days. Use 'set' to change the policy of a volume and 'get' to see it."
+		usage = "schedule"
+	)
+
+	cmd := command.New(usage, short, long, nil)
+	cmd.AddCommand(
+		newScheduleSet(),
+		newScheduleGet(),
+	)
+	return cmd
+}
+
+func newScheduleSet() *cobra.Command {
+	const (
+		short = "Set the automatic snapshot policy of a volume."
+		long  = short + " Automatic snapshots are taken daily or turned off, and kept for the retention in days."
+		usage = "set <volume id>"
+	)
+
+	cmd := command.New(usage, short, long, runScheduleSet, command.RequireSession)
+	cmd.Args = cobra.ExactArgs(1)
+
+	flag.Add(cmd,
+		flag.JSONOutput(),
+		flag.String{
+			Name:        "frequency",
+			Description: fmt.Sprintf("How often automatic snapshots are taken, '%s' or '%s'", frequencyDaily, frequencyOff),
+		},
+		flag.Int{
+			Name:        "retention",
+			Description: fmt.Sprintf("Days to keep snapshots, between %d and %d", minRetentionDays, maxRetentionDays),
+		},
+	)
+	return cmd
+}
+
+func newScheduleGet() *cobra.Command {
+	const (
+		short = "Show the automatic snapshot policy of a volume."
+		long  = short + " Includes when the latest snapshot was taken."
+		usage = "get <volume id>"
+	)
+
+	cmd := command.New(usage, short, long, runScheduleGet, command.RequireSession)
+	cmd.Args = cobra.ExactArgs(1)
+	cmd.Aliases = []string{"show"}
+
+	flag.Add(cmd, flag.JSONOutput())
+	return cmd
+}
+
+type snapshotSchedule struct {
+	VolumeID      string     `json:"volume_id"`
+	Frequency     string     `json:"frequency"`
+	RetentionDays int        `json:"retention_days"`
+	LastSnapshot  *time.Time `json:"last_snapshot,omitempty"`
+}
+
+func runScheduleSet(ctx context.Context) error {
+	volumeID := flag.FirstArg(ctx)
+
+	if !flag.IsSpecified(ctx, "frequency") && !flag.IsSpecified(ctx, "retention") {
+		return fmt.Errorf("pass --frequency, --retention or both")
+	}
+
+	var input fly.UpdateVolumeRequest
+	if flag.IsSpecified(ctx, "frequency") {
+		switch frequency := flag.GetString(ctx, "frequency"); frequency {
+		case frequencyDaily:
+			input.AutoBackupEnabled = fly.BoolPointer(true)
+		case frequencyOff:
+			input.AutoBackupEnabled = fly.BoolPointer(false)
+		default:
+			return fmt.Errorf("invalid frequency %q, automatic snapshots are either '%s' or '%s'", frequency, frequencyDaily, frequencyOff)
+		}
+	}
+	if flag.IsSpecified(ctx, "retention") {
+		retention := flag.GetInt(ctx, "retention")
+		if retention < minRetentionDays || retention > maxRetentionDays {
+			return fmt.Errorf("--retention must be between %d and %d days, got: %d", minRetentionDays, maxRetentionDays, retention)
+		}
+		input.SnapshotRetention = fly.Pointer(retention)
+	}
+
+	flapsClient, err := volumeFlapsClient(ctx, volumeID)
+	if err != nil {
+		return err
+	}
+
+	volume, err := flapsClient.UpdateVolume(ctx, volumeID, input)
+	if err != nil {
+		return fmt.Errorf("failed updating volume: %w", err)
+	}
+
+	return printSchedule(ctx, flapsClient, volume)
+}
+
+func runScheduleGet(ctx context.Context) error {
+	volumeID := flag.FirstArg(ctx)
+
+	flapsClient, err := volumeFlapsClient(ctx, volumeID)
+	if err != nil {
+		return err
+	}
+
+	volume, err := flapsClient.GetVolume(ctx, volumeID)
+	if err != nil {
+		return fmt.Errorf("failed retrieving volume: %w", err)
+	}
+
+	return printSchedule(ctx, flapsClient, volume)
+}
+
+func volumeFlapsClient(ctx context.Context, volumeID string) (flapsutil.FlapsClient, error) {
+	appName := appconfig.NameFromContext(ctx)
+	if appName == "" {
+		n, err := flyutil.ClientFromContext(ctx).GetAppNameFromVolume(ctx, volumeID)
+		if err != nil {
+			return nil, err
+		}
+		appName = *n
+	}
+
+	return flapsutil.NewClientWithOptions(ctx, flaps.NewClientOpts{
+		AppName: appName,
+	})
+}
+
+func printSchedule(ctx context.Context, flapsClient flapsutil.FlapsClient, volume *fly.Volume) error {
+	var (
+		io  = iostreams.FromContext(ctx)
+		cfg = config.FromContext(ctx)
+	)
+
+	schedule := snapshotSchedule{
+		VolumeID:      volume.ID,
+		Frequency:     frequencyOff,
+		RetentionDays: volume.SnapshotRetention,
+	}
+	if volume.AutoBackupEnabled {
+		schedule.Frequency = frequencyDaily
+	}
+
+	snapshots, err := flapsClient.GetVolumeSnapshots(ctx, volume.ID)
+	if err != nil {
+		return fmt.Errorf("failed retrieving snapshots: %w", err)
+	}
+	for _, snapshot := range snapshots {
+		if schedule.LastSnapshot == nil || snapshot.CreatedAt.After(*schedule.LastSnapshot) {
+			schedule.LastSnapshot = &snapshot.CreatedAt
+		}
+	}
+
+	if cfg.JSONOutput {
+		return render.JSON(io.Out, schedule)
+	}
+
+	lastSnapshot := "never"
+	if schedule.LastSnapshot != nil {
+		lastSnapshot = timeToString(*schedule.LastSnapshot)
+	}
+
+	fmt.Fprintf(io.Out, "%20s: %s\n", "Volume", schedule.VolumeID)
+	fmt.Fprintf(io.Out, "%20s: %s\n", "Frequency", schedule.Frequency)
+	fmt.Fprintf(io.Out, "%20s: %d days\n", "Retention", schedule.RetentionDays)
+	fmt.Fprintf(io.Out, "%20s: %s\n", "Last snapshot", lastSnapshot)
+	return nil
+}
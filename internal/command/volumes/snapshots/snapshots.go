@@ -24,6 +24,8 @@ func New() *cobra.Command {
 	snapshots.AddCommand(
 		newList(),
 		newCreate(),
+		newSchedule(),
+		newDownload(),
 	)
 
 	return snapshots
@@ -22,7 +22,14 @@ func newUpdate() *cobra.Command {
 		short = "Update a volume for an app."
 
 		long = short + ` Volumes are persistent storage for
-		Fly Machines.`
+		Fly Machines.
+
+Auto-extend grows a volume by --auto-extend-by GB once its usage crosses
+--auto-extend-threshold percent, up to --max-size GB. It is set on the
+machine the volume is attached to and saved to the [mounts] section of
+fly.toml so deploys keep it. Set --auto-extend-threshold=0 to turn it off.
+A stopped machine is updated without being started, a running one restarts
+after a confirmation.`
 
 		usage = "update <volume id>"
 	)
@@ -37,6 +44,7 @@ func newUpdate() *cobra.Command {
 	flag.Add(cmd,
 		flag.App(),
 		flag.AppConfig(),
+		flag.Yes(),
 		flag.Int{
 			Name:        "snapshot-retention",
 			Description: "Snapshot retention in days",
@@ -45,6 +53,18 @@ func newUpdate() *cobra.Command {
 			Name:        "scheduled-snapshots",
 			Description: "Activate/deactivate scheduled automatic snapshots",
 		},
+		flag.Int{
+			Name:        "auto-extend-threshold",
+			Description: "Extend the volume when its usage reaches this percentage (50-99), 0 turns auto-extend off",
+		},
+		flag.Int{
+			Name:        "auto-extend-by",
+			Description: "GB added to the volume each time it is auto-extended (1-100)",
+		},
+		flag.Int{
+			Name:        "max-size",
+			Description: "Size in GB past which the volume isn't auto-extended (1-500)",
+		},
 	)
 
 	flag.Add(cmd, flag.JSONOutput())
@@ -77,6 +97,7 @@ func runUpdate(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	ctx = flapsutil.NewContextWithClient(ctx, flapsClient)
 
 	var snapshotRetention *int
 	if flag.GetInt(ctx, "snapshot-retention") != 0 {
@@ -92,14 +113,36 @@ func runUpdate(ctx context.Context) error {
 		input.AutoBackupEnabled = fly.BoolPointer(flag.GetBool(ctx, "scheduled-snapshots"))
 	}
 
-	updatedVolume, err := flapsClient.UpdateVolume(ctx, volumeID, input)
-	if err != nil {
-		return fmt.Errorf("failed updating volume: %w", err)
+	var updatedVolume *fly.Volume
+	if input.SnapshotRetention != nil || input.AutoBackupEnabled != nil || !autoExtendSpecified(ctx) {
+		updatedVolume, err = flapsClient.UpdateVolume(ctx, volumeID, input)
+		if err != nil {
+			return fmt.Errorf("failed updating volume: %w", err)
+		}
+	} else {
+		updatedVolume, err = flapsClient.GetVolume(ctx, volumeID)
+		if err != nil {
+			return fmt.Errorf("failed to get volume: %w", err)
+		}
+	}
+
+	var ae *autoExtend
+	if autoExtendSpecified(ctx) {
+		ae, err = updateAutoExtend(ctx, appName, updatedVolume)
+		if err != nil {
+			return fmt.Errorf("failed updating auto-extend: %w", err)
+		}
 	}
 
 	if cfg.JSONOutput {
 		return render.JSON(out, updatedVolume)
 	}
 
-	return printVolume(out, updatedVolume, appName)
+	if err := printVolume(out, updatedVolume, appName); err != nil {
+		return err
+	}
+	if ae != nil {
+		printAutoExtend(out, *ae)
+	}
+	return nil
 }
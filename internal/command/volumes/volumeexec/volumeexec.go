@@ -0,0 +1,104 @@
+// Package volumeexec reads the content of volumes, on ephemeral machines for
+// volumes that aren't mounted anywhere.
+package volumeexec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/docker/docker/pkg/ioutils"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/flyctl/internal/command/ssh"
+	"github.com/superfly/flyctl/internal/flyutil"
+	mach "github.com/superfly/flyctl/internal/machine"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+// MountPath is where the volume is mounted on the ephemeral machine.
+const MountPath = "/volume"
+
+// Run launches an ephemeral machine running the app's current image with vol
+// mounted at MountPath, runs cmd on it over SSH, without a PTY, with its
+// standard output written to stdout as is, and destroys the machine. The flaps client of the app
+// must be in ctx.
+func Run(ctx context.Context, app *fly.AppCompact, vol *fly.Volume, cmd string, stdout io.Writer) error {
+	var (
+		io     = iostreams.FromContext(ctx)
+		client = flyutil.ClientFromContext(ctx)
+	)
+
+	release, err := client.GetAppCurrentReleaseMachines(ctx, app.Name)
+	if err != nil {
+		return err
+	}
+	if release == nil {
+		return errors.New("can't mount the volume on an ephemeral machine since the app has not yet been released")
+	}
+
+	machine, cleanup, err := mach.LaunchEphemeral(ctx, &mach.EphemeralInput{
+		LaunchInput: fly.LaunchMachineInput{
+			Region: vol.Region,
+			Config: &fly.MachineConfig{
+				Image: release.ImageRef,
+				Init: fly.MachineInit{
+					Exec: []string{"sleep", "inf"},
+				},
+				Guest: &fly.MachineGuest{
+					CPUKind:  "shared",
+					CPUs:     1,
+					MemoryMB: 256,
+				},
+				Mounts: []fly.MachineMount{{
+					Volume: vol.ID,
+					Path:   MountPath,
+				}},
+				Restart: &fly.MachineRestart{
+					Policy: fly.MachineRestartPolicyNo,
+				},
+				AutoDestroy: true,
+			},
+		},
+		What: "to read volume " + vol.ID,
+	})
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	_, dialer, err := ssh.BringUpAgent(ctx, client, app, "", true)
+	if err != nil {
+		return err
+	}
+
+	return ssh.SSHExec(&ssh.SSHParams{
+		Ctx:            ctx,
+		Org:            app.Organization,
+		Dialer:         dialer,
+		App:            app.Name,
+		Username:       ssh.DefaultSshUsername,
+		Cmd:            cmd,
+		Stdout:         ioutils.NewWriteCloserWrapper(stdout, func() error { return nil }),
+		Stderr:         ioutils.NewWriteCloserWrapper(io.ErrOut, func() error { return nil }),
+		DisableSpinner: true,
+	}, machine.PrivateIP)
+}
+
+// Checksum returns a digest of the names and content of every file on vol,
+// which must not be attached to a machine, reading it on an ephemeral machine.
+func Checksum(ctx context.Context, app *fly.AppCompact, vol *fly.Volume) (string, error) {
+	const script = `cd %s && find . -path ./lost+found -prune -o -type f -print0 | LC_ALL=C sort -z | xargs -0 -r sha256sum | sha256sum | cut -d' ' -f1`
+
+	if vol.IsAttached() {
+		return "", fmt.Errorf("volume %s is attached to machine %s", vol.ID, *vol.AttachedMachine)
+	}
+
+	var out bytes.Buffer
+	if err := Run(ctx, app, vol, fmt.Sprintf(script, MountPath), &out); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out.String()), nil
+}
@@ -45,6 +45,10 @@ func New() *cobra.Command {
 		newExtend(),
 		newShow(),
 		newFork(),
+		newDF(),
+		newMigrate(),
+		newPrune(),
+		newRotateKey(),
 		lsvd.New(),
 		snapshots.New(),
 	)
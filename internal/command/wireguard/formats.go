@@ -0,0 +1,154 @@
+package wireguard
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"text/template"
+
+	fly "github.com/superfly/fly-go"
+)
+
+// wgConfFormats are the formats of --format of wireguard create.
+var wgConfFormats = []string{"conf", "qr", "networkmanager", "systemd"}
+
+// checkWgFormat checks that configurations can be written in format, before
+// a peer is created for them, since private keys can't be recovered.
+func checkWgFormat(format string) error {
+	if !slices.Contains(wgConfFormats, format) {
+		return fmt.Errorf("unsupported format %s, must be one of %s", format, strings.Join(wgConfFormats, ", "))
+	}
+	if format == "qr" {
+		if _, err := exec.LookPath("qrencode"); err != nil {
+			return fmt.Errorf("the qrencode CLI is required for --format qr, install it from your package manager: %w", err)
+		}
+	}
+	return nil
+}
+
+// generateWgQR renders the .conf configuration of a peer as a QR code for
+// the mobile WireGuard apps, with the qrencode CLI.
+func generateWgQR(ctx context.Context, peer *fly.CreatedWireGuardPeer, privkey string, w io.Writer) error {
+	var conf bytes.Buffer
+	generateWgConf(peer, privkey, &conf)
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "qrencode", "-t", "ansiutf8")
+	cmd.Stdin = &conf
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("qrencode failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// generateWgNetworkManager writes the configuration of a peer as a
+// NetworkManager keyfile, to install in
+// /etc/NetworkManager/system-connections.
+func generateWgNetworkManager(name, ifname string, peer *fly.CreatedWireGuardPeer, privkey string, w io.Writer) {
+	templateStr := `[connection]
+id={{.Name}}
+type=wireguard
+interface-name={{.Ifname}}
+
+[wireguard]
+private-key={{.Meta.Privkey}}
+
+[wireguard-peer.{{.Peer.Pubkey}}]
+endpoint={{.Peer.Endpointip}}:51820
+allowed-ips={{.Meta.AllowedIPs}};
+persistent-keepalive=15
+
+[ipv4]
+method=disabled
+
+[ipv6]
+method=manual
+address1={{.Peer.Peerip}}/120
+dns={{.Meta.DNS}};
+dns-search=~internal;
+`
+	tmpl := template.Must(template.New("networkmanager").Parse(templateStr))
+	tmpl.Execute(w, struct {
+		*wgConfData
+		Name   string
+		Ifname string
+	}{newWgConfData(peer, privkey), name, ifname})
+}
+
+// generateWgSystemdNetworkd writes the configuration of a peer as the
+// .netdev and .network units of systemd-networkd, to install in
+// /etc/systemd/network.
+func generateWgSystemdNetworkd(name, ifname string, peer *fly.CreatedWireGuardPeer, privkey string, netdev, network io.Writer) {
+	data := struct {
+		*wgConfData
+		Name   string
+		Ifname string
+	}{newWgConfData(peer, privkey), name, ifname}
+
+	netdevTemplate := `[NetDev]
+Name={{.Ifname}}
+Kind=wireguard
+Description=Fly.io WireGuard peer {{.Name}}
+
+[WireGuard]
+PrivateKey={{.Meta.Privkey}}
+
+[WireGuardPeer]
+PublicKey={{.Peer.Pubkey}}
+AllowedIPs={{.Meta.AllowedIPs}}
+Endpoint={{.Peer.Endpointip}}:51820
+PersistentKeepalive=15
+`
+	networkTemplate := `[Match]
+Name={{.Ifname}}
+
+[Network]
+Address={{.Peer.Peerip}}/120
+DNS={{.Meta.DNS}}
+Domains=~internal
+`
+	template.Must(template.New("netdev").Parse(netdevTemplate)).Execute(netdev, data)
+	template.Must(template.New("network").Parse(networkTemplate)).Execute(network, data)
+}
+
+// writeSystemdNetworkd writes the systemd-networkd units of a peer to
+// <path>.netdev and <path>.network, or to w with "stdout".
+func writeSystemdNetworkd(path, name, ifname string, peer *fly.CreatedWireGuardPeer, privkey string, w io.Writer) ([]string, error) {
+	if path == "stdout" {
+		var netdev, network bytes.Buffer
+		generateWgSystemdNetworkd(name, ifname, peer, privkey, &netdev, &network)
+		fmt.Fprintf(w, "# %s.netdev\n%s\n# %s.network\n%s", ifname, netdev.String(), ifname, network.String())
+		return nil, nil
+	}
+
+	path = strings.TrimSuffix(strings.TrimSuffix(path, ".netdev"), ".network")
+	paths := []string{path + ".netdev", path + ".network"}
+
+	var files []*os.File
+	for _, p := range paths {
+		f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err != nil {
+			for _, f := range files {
+				f.Close()
+				os.Remove(f.Name())
+			}
+			return nil, fmt.Errorf("can't create '%s': %w", p, err)
+		}
+		files = append(files, f)
+	}
+	defer func() {
+		for _, f := range files {
+			f.Close() // skipcq: GO-S2307
+		}
+	}()
+
+	generateWgSystemdNetworkd(name, ifname, peer, privkey, files[0], files[1])
+	return paths, nil
+}
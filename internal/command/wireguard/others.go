@@ -76,28 +76,18 @@ func resolveOutputWriter(ctx context.Context, idx int, prompt string) (w io.Writ
 	}
 }
 
-func generateWgConf(peer *fly.CreatedWireGuardPeer, privkey string, w io.Writer) {
-	templateStr := `
-[Interface]
-PrivateKey = {{.Meta.Privkey}}
-Address = {{.Peer.Peerip}}/120
-DNS = {{.Meta.DNS}}
-
-[Peer]
-PublicKey = {{.Peer.Pubkey}}
-AllowedIPs = {{.Meta.AllowedIPs}}
-Endpoint = {{.Peer.Endpointip}}:51820
-PersistentKeepalive = 15
+// wgConfData is the data of the configuration templates of a peer.
+type wgConfData struct {
+	Peer *fly.CreatedWireGuardPeer
+	Meta struct {
+		Privkey    string
+		AllowedIPs string
+		DNS        string
+	}
+}
 
-`
-	data := struct {
-		Peer *fly.CreatedWireGuardPeer
-		Meta struct {
-			Privkey    string
-			AllowedIPs string
-			DNS        string
-		}
-	}{
+func newWgConfData(peer *fly.CreatedWireGuardPeer, privkey string) *wgConfData {
+	data := &wgConfData{
 		Peer: peer,
 	}
 
@@ -113,9 +103,25 @@ PersistentKeepalive = 15
 
 	data.Meta.DNS = addr.String()
 	data.Meta.Privkey = privkey
+	return data
+}
+
+func generateWgConf(peer *fly.CreatedWireGuardPeer, privkey string, w io.Writer) {
+	templateStr := `
+[Interface]
+PrivateKey = {{.Meta.Privkey}}
+Address = {{.Peer.Peerip}}/120
+DNS = {{.Meta.DNS}}
 
+[Peer]
+PublicKey = {{.Peer.Pubkey}}
+AllowedIPs = {{.Meta.AllowedIPs}}
+Endpoint = {{.Peer.Endpointip}}:51820
+PersistentKeepalive = 15
+
+`
 	tmpl := template.Must(template.New("name").Parse(templateStr))
-	tmpl.Execute(w, &data)
+	tmpl.Execute(w, newWgConfData(peer, privkey))
 }
 
 func selectWireGuardPeer(ctx context.Context, client flyutil.Client, slug string) (string, error) {
@@ -1,6 +1,8 @@
 package wireguard
 
 import (
+	"strings"
+
 	"github.com/superfly/flyctl/internal/command"
 	"github.com/superfly/flyctl/internal/flag"
 
@@ -18,6 +20,7 @@ func New() *cobra.Command {
 		newWireguardList(),
 		newWireguardCreate(),
 		newWireguardRemove(),
+		newWireguardRotate(),
 		newWireguardReset(),
 		newWireguardWebsockets(),
 		newWireguardToken(),
@@ -35,6 +38,14 @@ func newWireguardList() *cobra.Command {
 	)
 	flag.Add(cmd,
 		flag.JSONOutput(),
+		flag.String{
+			Name:        "network",
+			Description: "Custom network the peer is on, to create it again there",
+		},
+		flag.Duration{
+			Name:        "max-age",
+			Description: "Only list the peers added to their gateway longer ago than this, to find stale peers",
+		},
 	)
 	cmd.Args = cobra.MaximumNArgs(1)
 	return cmd
@@ -43,11 +54,32 @@ func newWireguardList() *cobra.Command {
 func newWireguardCreate() *cobra.Command {
 	const (
 		short = "Add a WireGuard peer connection"
-		long  = `Add a WireGuard peer connection to an organization`
+		long  = `Add a WireGuard peer connection to an organization.
+
+The configuration of the peer is written, with --format, as:
+
+  conf            a WireGuard .conf file, for wg-quick and the WireGuard apps
+  qr              a QR code of the .conf file, printed for the mobile WireGuard
+                  apps to scan; requires the qrencode CLI
+  networkmanager  a NetworkManager keyfile, for /etc/NetworkManager/system-connections
+  systemd         systemd-networkd units, for /etc/systemd/network: [file] is the
+                  path of the units without their .netdev and .network extensions`
 	)
 	cmd := command.New("create [org] [region] [name] [file]", short, long, runWireguardCreate,
 		command.RequireSession,
 	)
+	flag.Add(cmd,
+		flag.String{
+			Name:        "format",
+			Description: "Format of the configuration: " + strings.Join(wgConfFormats, ", "),
+			Default:     "conf",
+		},
+		flag.String{
+			Name:        "interface",
+			Description: "Name of the network interface, for the networkmanager and systemd formats",
+			Default:     "fly0",
+		},
+	)
 	cmd.Args = cobra.MaximumNArgs(4)
 	return cmd
 }
@@ -64,6 +96,47 @@ func newWireguardRemove() *cobra.Command {
 	return cmd
 }
 
+func newWireguardRotate() *cobra.Command {
+	const (
+		short = "Rotate the keys of a WireGuard peer connection"
+		long  = `Rotate the keys of a WireGuard peer connection: the peer is removed
+and created again with the same name and region, and new keys. Its new
+configuration is written like with 'fly wireguard create', and replaces the
+previous one, which stops working.
+
+The network of a peer can't be looked up, so a peer on a custom network must
+be rotated with --network, or it's created again on the default one.
+
+With --max-age, the peer is only rotated when it was added to its gateway
+longer ago than that, so running the command periodically re-keys long-lived peers.`
+	)
+	cmd := command.New("rotate [org] [name] [file]", short, long, runWireguardRotate,
+		command.RequireSession,
+	)
+	flag.Add(cmd,
+		flag.String{
+			Name:        "format",
+			Description: "Format of the configuration: " + strings.Join(wgConfFormats, ", "),
+			Default:     "conf",
+		},
+		flag.String{
+			Name:        "interface",
+			Description: "Name of the network interface, for the networkmanager and systemd formats",
+			Default:     "fly0",
+		},
+		flag.String{
+			Name:        "network",
+			Description: "Custom network the peer is on, to create it again there",
+		},
+		flag.Duration{
+			Name:        "max-age",
+			Description: "Only rotate the peer if it was added to its gateway longer ago than this, e.g. 720h",
+		},
+	)
+	cmd.Args = cobra.MaximumNArgs(3)
+	return cmd
+}
+
 func newWireguardReset() *cobra.Command {
 	const (
 		short = "Reset WireGuard peer connection for an organization"
@@ -79,9 +152,18 @@ func newWireguardReset() *cobra.Command {
 func newWireguardWebsockets() *cobra.Command {
 	const (
 		short = "Enable or disable WireGuard tunneling over WebSockets"
-		long  = `Enable or disable WireGuard tunneling over WebSockets`
+		long  = `Enable or disable WireGuard tunneling over WebSockets, which goes through
+networks that block the UDP port of WireGuard, 51820:
+
+  enable   tunnels always use WebSockets
+  disable  tunnels always use UDP
+  auto     tunnels use UDP, and fall back to WebSockets when the gateway
+           doesn't respond over UDP
+
+The setting persists in the flyctl config file and applies to the tunnels of
+the agent, which is restarted.`
 	)
-	cmd := command.New("websockets [enable|disable]", short, long, runWireguardWebsockets,
+	cmd := command.New("websockets [enable|disable|auto]", short, long, runWireguardWebsockets,
 		command.RequireSession,
 	)
 	cmd.Args = cobra.ExactArgs(1)
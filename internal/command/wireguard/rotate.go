@@ -0,0 +1,204 @@
+package wireguard
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/flyctl/internal/flag"
+	"github.com/superfly/flyctl/internal/flyutil"
+	"github.com/superfly/flyctl/internal/wireguard"
+	"github.com/superfly/flyctl/iostreams"
+	"github.com/superfly/flyctl/terminal"
+)
+
+func runWireguardRotate(ctx context.Context) error {
+	io := iostreams.FromContext(ctx)
+	apiClient := flyutil.ClientFromContext(ctx)
+
+	// Checked before the peer is removed, so a format that can't be written
+	// doesn't cost a working tunnel
+	format := flag.GetString(ctx, "format")
+	if err := checkWgFormat(format); err != nil {
+		return err
+	}
+	ifname := flag.GetString(ctx, "interface")
+
+	org, err := orgByArg(ctx)
+	if err != nil {
+		return err
+	}
+
+	args := flag.Args(ctx)
+	var name string
+	if len(args) >= 2 {
+		name = args[1]
+	} else {
+		name, err = selectWireGuardPeer(ctx, apiClient, org.Slug)
+		if err != nil {
+			return err
+		}
+	}
+
+	peers, err := wireGuardPeers(ctx, apiClient, org.Slug)
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(peers, func(peer *fly.WireGuardPeer) bool { return peer.Name == name })
+	if i < 0 {
+		return fmt.Errorf("no WireGuard peer named %s in organization %s", name, org.Slug)
+	}
+	peer := peers[i]
+
+	if maxAge := flag.GetDuration(ctx, "max-age"); maxAge > 0 {
+		age, ok := peerAge(peer)
+		if !ok {
+			return fmt.Errorf("the gateway status of WireGuard peer %s is unavailable, run without --max-age to rotate it anyway", name)
+		}
+		if age < maxAge {
+			fmt.Fprintf(io.Out, "WireGuard peer %s was added to its gateway %s ago, less than %s; not rotating it\n", name, formatPeerDuration(age), maxAge)
+			return nil
+		}
+	}
+
+	fmt.Fprintf(io.Out, "Rotating the keys of WireGuard peer \"%s\" for organization %s\n", name, org.Slug)
+
+	if err := apiClient.RemoveWireGuardPeer(ctx, org, name); err != nil {
+		return err
+	}
+
+	// The peer keeps its name and region, with new keys and a new peer IP.
+	// Its network isn't part of the peer the API returns, so it comes from
+	// --network.
+	network := flag.GetString(ctx, "network")
+	state, err := wireguard.Create(apiClient, org, peer.Region, name, network, "static")
+	switch {
+	case err != nil && network == "":
+		return fmt.Errorf("removed WireGuard peer %s but failed to create it again, run 'fly wireguard create %s %s %s': %w", name, org.Slug, peer.Region, name, err)
+	case err != nil:
+		return fmt.Errorf("removed WireGuard peer %s but failed to create it again on network %s: %w", name, network, err)
+	}
+
+	if err := wireguard.PruneInvalidPeers(ctx, apiClient); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(io.Out, `
+!!!! WARNING: Output includes private key. Private keys cannot be recovered !!!!
+!!!! after creating the peer; if you lose the key, you'll need to remove    !!!!
+!!!! and re-add the peering connection.                                     !!!!
+`)
+
+	if err := writeWgConfig(ctx, state, format, ifname, 2); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(io.Out, "The previous configuration of the peer no longer works, replace it with the new one.")
+	return nil
+}
+
+// wireGuardPeers lists the WireGuard peers of an organization. Their status on
+// the gateways is filled in when the API provides it; peers without a status
+// have an unknown age.
+func wireGuardPeers(ctx context.Context, apiClient flyutil.Client, orgSlug string) ([]*fly.WireGuardPeer, error) {
+	peers, err := apiClient.GetWireGuardPeers(ctx, orgSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses, err := wireGuardPeerStatuses(ctx, apiClient, orgSlug)
+	if err != nil {
+		terminal.Debugf("error fetching the gateway status of WireGuard peers: %s\n", err)
+		return peers, nil
+	}
+	for _, peer := range peers {
+		peer.GatewayStatus = statuses[peer.Name]
+	}
+	return peers, nil
+}
+
+// wireGuardPeerStatuses fetches the gateway status of the WireGuard peers of an
+// organization, by peer name. The field isn't part of the published schema, so
+// callers must cope with it failing.
+func wireGuardPeerStatuses(ctx context.Context, apiClient flyutil.Client, orgSlug string) (map[string]*fly.WireGuardPeerStatus, error) {
+	query := `
+		query($slug: String!) {
+			organization(slug: $slug) {
+				wireGuardPeers {
+					nodes {
+						name
+						gatewayStatus {
+							endpoint
+							lastHandshake
+							sinceHandshake
+							added
+							sinceAdded
+							live
+						}
+					}
+				}
+			}
+		}
+	`
+
+	req := apiClient.NewRequest(query)
+	req.Var("slug", orgSlug)
+
+	data, err := apiClient.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := map[string]*fly.WireGuardPeerStatus{}
+	if data.Organization.WireGuardPeers.Nodes != nil {
+		for _, peer := range *data.Organization.WireGuardPeers.Nodes {
+			statuses[peer.Name] = peer.GatewayStatus
+		}
+	}
+	return statuses, nil
+}
+
+// peerAge returns how long ago the peer was added to its gateway, which is
+// not necessarily when the peer was created.
+func peerAge(peer *fly.WireGuardPeer) (time.Duration, bool) {
+	if peer.GatewayStatus == nil {
+		return 0, false
+	}
+	return peerSince(peer.GatewayStatus.Added, peer.GatewayStatus.SinceAdded)
+}
+
+// peerSinceHandshake returns how long ago the peer last completed a handshake
+// with its gateway.
+func peerSinceHandshake(peer *fly.WireGuardPeer) (time.Duration, bool) {
+	if peer.GatewayStatus == nil {
+		return 0, false
+	}
+	return peerSince(peer.GatewayStatus.LastHandshake, peer.GatewayStatus.SinceHandshake)
+}
+
+// peerSince parses a gateway status, given both as a timestamp and as the
+// duration since then.
+func peerSince(at, since string) (time.Duration, bool) {
+	if t, err := time.Parse(time.RFC3339, at); err == nil && !t.IsZero() {
+		return time.Since(t), true
+	}
+	if d, err := time.ParseDuration(since); err == nil {
+		return d, true
+	}
+	return 0, false
+}
+
+func formatPeerDuration(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+}
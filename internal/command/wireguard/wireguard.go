@@ -4,10 +4,13 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
+	fly "github.com/superfly/fly-go"
 	"github.com/superfly/flyctl/agent"
 	"github.com/superfly/flyctl/flyctl"
 	"github.com/superfly/flyctl/internal/config"
@@ -18,6 +21,7 @@ import (
 	"github.com/superfly/flyctl/internal/wireguard"
 	"github.com/superfly/flyctl/iostreams"
 	"github.com/superfly/flyctl/terminal"
+	"github.com/superfly/flyctl/wg"
 )
 
 func runWireguardList(ctx context.Context) error {
@@ -29,11 +33,25 @@ func runWireguardList(ctx context.Context) error {
 		return err
 	}
 
-	peers, err := apiClient.GetWireGuardPeers(ctx, org.Slug)
+	peers, err := wireGuardPeers(ctx, apiClient, org.Slug)
 	if err != nil {
 		return err
 	}
 
+	if maxAge := flag.GetDuration(ctx, "max-age"); maxAge > 0 {
+		var unknown int
+		peers = slices.DeleteFunc(peers, func(peer *fly.WireGuardPeer) bool {
+			age, ok := peerAge(peer)
+			if !ok {
+				unknown++
+			}
+			return !ok || age < maxAge
+		})
+		if unknown > 0 {
+			terminal.Warnf("The gateway status of %d WireGuard peers is unavailable, they're left out of the list\n", unknown)
+		}
+	}
+
 	if config.FromContext(ctx).JSONOutput {
 		render.JSON(io.Out, peers)
 		return nil
@@ -45,10 +63,19 @@ func runWireguardList(ctx context.Context) error {
 		"Name",
 		"Region",
 		"Peer IP",
+		"Added to Gateway",
+		"Last Handshake",
 	})
 
 	for _, peer := range peers {
-		table.Append([]string{peer.Name, peer.Region, peer.Peerip})
+		added, handshake := "-", "-"
+		if d, ok := peerAge(peer); ok {
+			added = formatPeerDuration(d) + " ago"
+		}
+		if d, ok := peerSinceHandshake(peer); ok {
+			handshake = formatPeerDuration(d) + " ago"
+		}
+		table.Append([]string{peer.Name, peer.Region, peer.Peerip, added, handshake})
 	}
 
 	table.Render()
@@ -63,15 +90,22 @@ func runWireguardWebsockets(ctx context.Context) error {
 		configPath = state.ConfigFile(ctx)
 		err        error
 	)
+	var websockets, fallback bool
 	switch flag.FirstArg(ctx) {
 	case "enable":
-		viper.Set(flyctl.ConfigWireGuardWebsockets, true)
-		err = config.SetWireGuardWebsocketsEnabled(configPath, true)
+		websockets = true
 	case "disable":
-		viper.Set(flyctl.ConfigWireGuardWebsockets, false)
-		err = config.SetWireGuardWebsocketsEnabled(configPath, false)
+	case "auto":
+		fallback = true
 	default:
-		fmt.Fprintf(io.Out, "bad arg: flyctl wireguard websockets (enable|disable)\n")
+		return errors.New("bad arg: flyctl wireguard websockets (enable|disable|auto)")
+	}
+
+	viper.Set(flyctl.ConfigWireGuardWebsockets, websockets)
+	viper.Set(flyctl.ConfigWireGuardWebsocketsFallback, fallback)
+	err = config.SetWireGuardWebsocketsEnabled(configPath, websockets)
+	if err == nil {
+		err = config.SetWireGuardWebsocketsFallback(configPath, fallback)
 	}
 	if err != nil {
 		return errors.Wrap(err, "error saving config file")
@@ -124,6 +158,12 @@ func runWireguardCreate(ctx context.Context) error {
 	io := iostreams.FromContext(ctx)
 	apiClient := flyutil.ClientFromContext(ctx)
 
+	format := flag.GetString(ctx, "format")
+	if err := checkWgFormat(format); err != nil {
+		return err
+	}
+	ifname := flag.GetString(ctx, "interface")
+
 	org, err := orgByArg(ctx)
 	if err != nil {
 		return err
@@ -149,15 +189,40 @@ func runWireguardCreate(ctx context.Context) error {
 		return err
 	}
 
-	data := &state.Peer
-
 	fmt.Fprintf(io.Out, `
 !!!! WARNING: Output includes private key. Private keys cannot be recovered !!!!
 !!!! after creating the peer; if you lose the key, you'll need to remove    !!!!
 !!!! and re-add the peering connection.                                     !!!!
 `)
 
-	w, shouldClose, err := resolveOutputWriter(ctx, 3, "Filename to store WireGuard configuration in, or 'stdout': ")
+	return writeWgConfig(ctx, state, format, ifname, 3)
+}
+
+// writeWgConfig writes the configuration of a new peer in format, to the file
+// given by the nth argument or prompted for.
+func writeWgConfig(ctx context.Context, state *wg.WireGuardState, format, ifname string, nth int) error {
+	io := iostreams.FromContext(ctx)
+	data := &state.Peer
+
+	switch format {
+	case "qr":
+		return generateWgQR(ctx, data, state.LocalPrivate, io.Out)
+	case "systemd":
+		path, err := argOrPrompt(ctx, nth, "Path of the systemd-networkd units, without extension, or 'stdout': ")
+		if err != nil {
+			return err
+		}
+		paths, err := writeSystemdNetworkd(path, state.Name, ifname, data, state.LocalPrivate, io.Out)
+		if err != nil {
+			return err
+		}
+		if len(paths) > 0 {
+			fmt.Fprintf(io.Out, "Wrote systemd-networkd units to %s; install them in /etc/systemd/network\n", strings.Join(paths, " and "))
+		}
+		return nil
+	}
+
+	w, shouldClose, err := resolveOutputWriter(ctx, nth, "Filename to store WireGuard configuration in, or 'stdout': ")
 	if err != nil {
 		return err
 	}
@@ -165,7 +230,11 @@ func runWireguardCreate(ctx context.Context) error {
 		defer w.Close() // skipcq: GO-S2307
 	}
 
-	generateWgConf(data, state.LocalPrivate, w)
+	if format == "networkmanager" {
+		generateWgNetworkManager(state.Name, ifname, data, state.LocalPrivate, w)
+	} else {
+		generateWgConf(data, state.LocalPrivate, w)
+	}
 
 	if shouldClose {
 		filename := w.(*os.File).Name()
@@ -33,6 +33,7 @@ const (
 	AutoUpdateFileKey          = "auto_update"
 	WireGuardStateFileKey      = "wire_guard_state"
 	WireGuardWebsocketsFileKey = "wire_guard_websockets"
+	WireGuardFallbackFileKey   = "wire_guard_websockets_fallback"
 	APITokenEnvKey             = "FLY_API_TOKEN"
 	orgEnvKey                  = "FLY_ORG"
 	registryHostEnvKey         = "FLY_REGISTRY_HOST"
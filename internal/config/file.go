@@ -77,6 +77,14 @@ func SetWireGuardWebsocketsEnabled(path string, enabled bool) error {
 	})
 }
 
+// SetWireGuardWebsocketsFallback sets whether tunnels fall back to WebSockets
+// when UDP is blocked, in the configuration file found at path.
+func SetWireGuardWebsocketsFallback(path string, enabled bool) error {
+	return set(path, map[string]interface{}{
+		WireGuardFallbackFileKey: enabled,
+	})
+}
+
 // Clear clears the access token, metrics token, and wireguard-related keys of the configuration
 // file found at path.
 func Clear(path string) (err error) {
@@ -320,6 +320,14 @@ func Org() String {
 	}
 }
 
+// Network returns a string flag for the custom private network of a new app.
+func Network() String {
+	return String{
+		Name:        "network",
+		Description: "Name of the custom private network to create the app on, instead of the organization's default one (see 'flyctl networks list')",
+	}
+}
+
 // Region returns a region string flag.
 func Region() String {
 	return String{
@@ -20,6 +20,16 @@ var (
 	}
 )
 
+// ParseGPUKind resolves aliases such as a100-40gb to the GPU kind machines
+// expect. "none" is returned as is and means no GPU.
+func ParseGPUKind(kind string) (string, error) {
+	kind = lo.ValueOr(gpuKindAliases, kind, kind)
+	if !slices.Contains(validGPUKinds, kind) {
+		return "", fmt.Errorf("--vm-gpu-kind must be set to one of: %v", strings.Join(validGPUKinds, ", "))
+	}
+	return kind, nil
+}
+
 // Returns a MachineGuest based on the flags provided overwriting a default VM
 func GetMachineGuest(ctx context.Context, guest *fly.MachineGuest) (*fly.MachineGuest, error) {
 	defaultVMSize := fly.DefaultVMSize
@@ -66,10 +76,9 @@ func GetMachineGuest(ctx context.Context, guest *fly.MachineGuest) (*fly.Machine
 	}
 
 	if IsSpecified(ctx, "vm-gpu-kind") {
-		m := GetString(ctx, "vm-gpu-kind")
-		m = lo.ValueOr(gpuKindAliases, m, m)
-		if !slices.Contains(validGPUKinds, m) {
-			return nil, fmt.Errorf("--vm-gpu-kind must be set to one of: %v", strings.Join(validGPUKinds, ", "))
+		m, err := ParseGPUKind(GetString(ctx, "vm-gpu-kind"))
+		if err != nil {
+			return nil, err
 		}
 		if m == "none" {
 			guest.GPUs = 0
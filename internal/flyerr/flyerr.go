@@ -46,6 +46,25 @@ func GetErrorDocUrl(err error) string {
 // ErrAbort is an error for when the CLI aborts
 var ErrAbort = errors.New("abort")
 
+// ExitCodeError is an error for when the CLI should exit with a specific
+// status code, e.g. to relay the exit code of a command run on a machine.
+type ExitCodeError struct {
+	Code int
+}
+
+func (e ExitCodeError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// GetExitCode returns the exit code carried by err and whether there was one.
+func GetExitCode(err error) (int, bool) {
+	var ferr ExitCodeError
+	if errors.As(err, &ferr) {
+		return ferr.Code, true
+	}
+	return 0, false
+}
+
 // ErrorDescription is an error with a detailed description that will be printed before the CLI exits
 type ErrorDescription interface {
 	error
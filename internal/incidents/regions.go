@@ -0,0 +1,115 @@
+package incidents
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/samber/lo"
+	"golang.org/x/sync/errgroup"
+)
+
+// regionPattern matches the region codes of status page components, which are
+// named like "Amsterdam, Netherlands (AMS)".
+var regionPattern = regexp.MustCompile(`\(([A-Za-z]{3})\)\s*$`)
+
+// Notice is an ongoing incident or maintenance of the platform.
+type Notice struct {
+	Kind       string   `json:"kind"`
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Status     string   `json:"status"`
+	Components []string `json:"components"`
+	// Regions are the regions affected, none when all of them are.
+	Regions   []string `json:"regions"`
+	StartedAt string   `json:"started_at"`
+	UpdatedAt string   `json:"updated_at"`
+}
+
+// Global reports whether the notice affects all regions.
+func (n Notice) Global() bool {
+	return len(n.Regions) == 0
+}
+
+// Affects reports whether the notice affects any of regions.
+func (n Notice) Affects(regions []string) bool {
+	if n.Global() {
+		return true
+	}
+	return lo.SomeBy(n.Regions, func(r string) bool {
+		return lo.Contains(regions, r)
+	})
+}
+
+// Notices returns the ongoing incidents and maintenances of the platform.
+func Notices(ctx context.Context) ([]Notice, error) {
+	var (
+		incidents    *StatusPageApiResponse
+		maintenances *StatusPageMaintenancesResponse
+	)
+
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.Go(func() (err error) {
+		incidents, err = StatuspageIncidentsRequest(ctx)
+		return
+	})
+	eg.Go(func() (err error) {
+		maintenances, err = StatuspageMaintenancesRequest(ctx)
+		return
+	})
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	var notices []Notice
+	if incidents != nil {
+		for _, incident := range incidents.Incidents {
+			notices = append(notices, newNotice("incident", incident.ID, incident.Name, incident.Status, incident.Components, incident.StartedAt, incident.UpdatedAt))
+		}
+	}
+	if maintenances != nil {
+		for _, maintenance := range maintenances.ScheduledMaintenances {
+			notices = append(notices, newNotice("maintenance", maintenance.ID, maintenance.Name, maintenance.Status, maintenance.Components, maintenance.ScheduledFor, maintenance.UpdatedAt))
+		}
+	}
+	return notices, nil
+}
+
+// AppNotices returns the ongoing incidents and maintenances affecting any of
+// the regions of an app.
+func AppNotices(ctx context.Context, regions []string) ([]Notice, error) {
+	notices, err := Notices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return lo.Filter(notices, func(n Notice, _ int) bool {
+		return n.Affects(regions)
+	}), nil
+}
+
+func newNotice(kind, id, name, status string, components []Component, startedAt, updatedAt string) Notice {
+	n := Notice{
+		Kind:      kind,
+		ID:        id,
+		Name:      name,
+		Status:    status,
+		StartedAt: startedAt,
+		UpdatedAt: updatedAt,
+	}
+
+	// Notices are of regions when all of their components are
+	regional := len(components) > 0
+	for _, component := range components {
+		n.Components = append(n.Components, component.Name)
+
+		if m := regionPattern.FindStringSubmatch(component.Name); m != nil {
+			n.Regions = append(n.Regions, strings.ToLower(m[1]))
+		} else {
+			regional = false
+		}
+	}
+	if !regional {
+		n.Regions = nil
+	}
+	return n
+}
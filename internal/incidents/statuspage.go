@@ -29,6 +29,20 @@ type Incident struct {
 	UpdatedAt  string      `json:"updated_at"`
 }
 
+type StatusPageMaintenancesResponse struct {
+	ScheduledMaintenances []Maintenance `json:"scheduled_maintenances"`
+}
+
+type Maintenance struct {
+	Components     []Component `json:"components"`
+	ID             string      `json:"id"`
+	Name           string      `json:"name"`
+	ScheduledFor   string      `json:"scheduled_for"`
+	ScheduledUntil string      `json:"scheduled_until"`
+	Status         string      `json:"status"`
+	UpdatedAt      string      `json:"updated_at"`
+}
+
 type Component struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
@@ -43,6 +57,15 @@ func getStatuspageUnresolvedIncidentsUrl() string {
 	return "https://incidents.flyio.net/v1/incidents"
 }
 
+func getStatuspageActiveMaintenancesUrl() string {
+	url := os.Getenv("FLY_STATUSPAGE_ACTIVE_MAINTENANCES_URL")
+	if url != "" {
+		return url
+	}
+
+	return "https://status.fly.io/api/v2/scheduled-maintenances/active.json"
+}
+
 func QueryStatuspageIncidents(ctx context.Context) {
 
 	logger := logger.FromContext(ctx)
@@ -105,3 +128,32 @@ func StatuspageIncidentsRequest(ctx context.Context) (*StatusPageApiResponse, er
 
 	return &apiResponse, nil
 }
+
+func StatuspageMaintenancesRequest(ctx context.Context) (*StatusPageMaintenancesResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", getStatuspageActiveMaintenancesUrl(), http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{}
+	response, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close() // skipcq: GO-S2307
+
+	if response.StatusCode != http.StatusOK {
+		return nil, nil
+	}
+
+	var apiResponse StatusPageMaintenancesResponse
+	decoder := json.NewDecoder(response.Body)
+	if err := decoder.Decode(&apiResponse); err != nil {
+		return nil, fmt.Errorf("error: %s", err)
+	}
+
+	return &apiResponse, nil
+}
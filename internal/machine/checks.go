@@ -0,0 +1,194 @@
+package machine
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	fly "github.com/superfly/fly-go"
+)
+
+const (
+	defaultCheckTimeout = 5 * time.Second
+	maxCheckOutputBytes = 4096
+)
+
+// DialFunc dials addr on network, e.g. through a WireGuard tunnel.
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// NamedCheck is a check from a machine config along with the name flyd
+// reports its status under.
+type NamedCheck struct {
+	Name  string
+	Check fly.MachineCheck
+}
+
+// CheckResult is the outcome of evaluating a single check.
+type CheckResult struct {
+	Name     string        `json:"name"`
+	Type     string        `json:"type"`
+	Target   string        `json:"target"`
+	Passing  bool          `json:"passing"`
+	Duration time.Duration `json:"duration"`
+	Output   string        `json:"output"`
+}
+
+// ConfiguredChecks returns the top level and service checks of m, sorted
+// by name. Service checks without a port check the service's internal port.
+func ConfiguredChecks(m *fly.Machine) []NamedCheck {
+	config := m.GetConfig()
+
+	var checks []NamedCheck
+	for name, check := range config.Checks {
+		checks = append(checks, NamedCheck{Name: name, Check: check})
+	}
+	sort.Slice(checks, func(i, j int) bool {
+		return checks[i].Name < checks[j].Name
+	})
+
+	i := 0
+	for _, service := range config.Services {
+		for _, check := range service.Checks {
+			if check.Port == nil {
+				port := service.InternalPort
+				check.Port = &port
+			}
+			checks = append(checks, NamedCheck{
+				Name:  fmt.Sprintf("servicecheck-%02d-%s-%d", i, checkType(check), *check.Port),
+				Check: check,
+			})
+			i++
+		}
+	}
+
+	return checks
+}
+
+// RunCheck evaluates check against host once, dialing with dial.
+func RunCheck(ctx context.Context, dial DialFunc, host string, nc NamedCheck) *CheckResult {
+	check := nc.Check
+	result := &CheckResult{
+		Name: nc.Name,
+		Type: checkType(check),
+	}
+
+	if check.Port == nil {
+		result.Output = "check has no port"
+		return result
+	}
+	addr := net.JoinHostPort(host, strconv.Itoa(*check.Port))
+	result.Target = addr
+
+	timeout := defaultCheckTimeout
+	if check.Timeout != nil && check.Timeout.Duration > 0 {
+		timeout = check.Timeout.Duration
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	switch result.Type {
+	case "tcp":
+		runTCPCheck(ctx, dial, addr, result)
+	case "http":
+		runHTTPCheck(ctx, dial, addr, check, result)
+	default:
+		result.Output = fmt.Sprintf("unsupported check type %q", result.Type)
+	}
+	result.Duration = time.Since(start)
+
+	return result
+}
+
+func checkType(check fly.MachineCheck) string {
+	if check.Type == nil || *check.Type == "" {
+		return "tcp"
+	}
+	return strings.ToLower(*check.Type)
+}
+
+func runTCPCheck(ctx context.Context, dial DialFunc, addr string, result *CheckResult) {
+	conn, err := dial(ctx, "tcp", addr)
+	if err != nil {
+		result.Output = err.Error()
+		return
+	}
+	conn.Close()
+
+	result.Passing = true
+	result.Output = fmt.Sprintf("TCP connect %s: Success", addr)
+}
+
+func runHTTPCheck(ctx context.Context, dial DialFunc, addr string, check fly.MachineCheck, result *CheckResult) {
+	var (
+		method = http.MethodGet
+		path   = "/"
+		scheme = "http"
+	)
+	if check.HTTPMethod != nil && *check.HTTPMethod != "" {
+		method = strings.ToUpper(*check.HTTPMethod)
+	}
+	if check.HTTPPath != nil && *check.HTTPPath != "" {
+		path = *check.HTTPPath
+	}
+	if check.HTTPProtocol != nil && *check.HTTPProtocol != "" {
+		scheme = strings.ToLower(*check.HTTPProtocol)
+	}
+
+	url := fmt.Sprintf("%s://%s%s", scheme, addr, path)
+	result.Target = fmt.Sprintf("%s %s", method, url)
+
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		result.Output = err.Error()
+		return
+	}
+	for _, header := range check.HTTPHeaders {
+		for _, value := range header.Values {
+			req.Header.Add(header.Name, value)
+		}
+	}
+
+	tlsConfig := &tls.Config{}
+	if check.HTTPSkipTLSVerify != nil {
+		tlsConfig.InsecureSkipVerify = *check.HTTPSkipTLSVerify
+	}
+	if check.HTTPTLSServerName != nil {
+		tlsConfig.ServerName = *check.HTTPTLSServerName
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext:     dial,
+			TLSClientConfig: tlsConfig,
+		},
+		// Checks don't follow redirects, a redirect is a passing response
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	defer client.CloseIdleConnections()
+
+	res, err := client.Do(req)
+	if err != nil {
+		result.Output = err.Error()
+		return
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxCheckOutputBytes))
+	if err != nil {
+		result.Output = fmt.Sprintf("%s; reading body: %v", res.Status, err)
+		return
+	}
+
+	result.Passing = res.StatusCode >= 200 && res.StatusCode < 400
+	result.Output = strings.TrimSpace(fmt.Sprintf("%s\n%s", res.Status, body))
+}
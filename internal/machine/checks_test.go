@@ -0,0 +1,84 @@
+package machine
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	fly "github.com/superfly/fly-go"
+)
+
+func TestConfiguredChecks(t *testing.T) {
+	m := &fly.Machine{
+		Config: &fly.MachineConfig{
+			Checks: map[string]fly.MachineCheck{
+				"db":    {Type: fly.Pointer("tcp"), Port: fly.Pointer(5432)},
+				"alive": {Type: fly.Pointer("http"), Port: fly.Pointer(8080)},
+			},
+			Services: []fly.MachineService{{
+				InternalPort: 8080,
+				Checks: []fly.MachineCheck{
+					{Type: fly.Pointer("http")},
+					{Port: fly.Pointer(9090)},
+				},
+			}},
+		},
+	}
+
+	checks := ConfiguredChecks(m)
+	names := make([]string, 0, len(checks))
+	for _, c := range checks {
+		names = append(names, c.Name)
+	}
+	require.Equal(t, []string{"alive", "db", "servicecheck-00-http-8080", "servicecheck-01-tcp-9090"}, names)
+	require.Equal(t, 8080, *checks[2].Check.Port)
+}
+
+func TestRunCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/healthz" && r.Header.Get("X-Check") == "yes" {
+			w.Write([]byte("ok"))
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("database unreachable"))
+	}))
+	defer server.Close()
+
+	host, portStr, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	port, err := strconv.Atoi(portStr)
+	require.NoError(t, err)
+
+	dial := (&net.Dialer{}).DialContext
+	ctx := context.Background()
+
+	result := RunCheck(ctx, dial, host, NamedCheck{Name: "tcp", Check: fly.MachineCheck{Port: &port}})
+	require.True(t, result.Passing)
+	require.Equal(t, "tcp", result.Type)
+
+	result = RunCheck(ctx, dial, host, NamedCheck{Name: "http", Check: fly.MachineCheck{
+		Type:        fly.Pointer("http"),
+		Port:        &port,
+		HTTPPath:    fly.Pointer("/healthz"),
+		HTTPHeaders: []fly.MachineHTTPHeader{{Name: "X-Check", Values: []string{"yes"}}},
+	}})
+	require.True(t, result.Passing)
+	require.Contains(t, result.Output, "ok")
+
+	result = RunCheck(ctx, dial, host, NamedCheck{Name: "http", Check: fly.MachineCheck{
+		Type:     fly.Pointer("http"),
+		Port:     &port,
+		HTTPPath: fly.Pointer("/broken"),
+	}})
+	require.False(t, result.Passing)
+	require.Contains(t, result.Output, "503")
+	require.Contains(t, result.Output, "database unreachable")
+
+	result = RunCheck(ctx, dial, host, NamedCheck{Name: "noport", Check: fly.MachineCheck{}})
+	require.False(t, result.Passing)
+}
@@ -0,0 +1,92 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/samber/lo"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/flyctl/internal/flapsutil"
+	"github.com/superfly/flyctl/internal/flyutil"
+	"github.com/superfly/flyctl/iostreams"
+)
+
+// Controller machines run flyctl inside an app, on a schedule or for good, to
+// act on its other machines, like the schedulers of `fly machine schedule` and
+// `fly scale schedule`. They authenticate with the FLY_API_TOKEN secret of the
+// app and aren't part of any process group managed by deploys.
+
+// ControllerProcessGroup is the process group of controller machines.
+const ControllerProcessGroup = "fly_scheduler"
+
+type ControllerSpec struct {
+	Name     string
+	Region   string
+	Image    string
+	Schedule string
+	Cmd      []string
+	Restart  fly.MachineRestartPolicy
+	// Metadata has to include a marker key set to "true" that FindController
+	// looks the machine up by.
+	Metadata map[string]string
+}
+
+// FindController returns the machine of the app with markerKey set to "true"
+// in its metadata, or nil if it has none.
+func FindController(ctx context.Context, markerKey string) (*fly.Machine, error) {
+	machines, err := flapsutil.ClientFromContext(ctx).List(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("could not get a list of machines: %w", err)
+	}
+
+	controller, _ := lo.Find(machines, func(m *fly.Machine) bool {
+		return m.GetMetadataByKey(markerKey) == "true" && m.State != fly.MachineStateDestroyed
+	})
+	return controller, nil
+}
+
+// LaunchController creates a controller machine for the app, warning when
+// the app has no FLY_API_TOKEN secret for it to authenticate with.
+func LaunchController(ctx context.Context, appName string, spec ControllerSpec) (*fly.Machine, error) {
+	io := iostreams.FromContext(ctx)
+
+	secrets, err := flyutil.ClientFromContext(ctx).GetAppSecrets(ctx, appName)
+	if err != nil {
+		return nil, fmt.Errorf("could not list secrets of app %s: %w", appName, err)
+	}
+	if !slices.ContainsFunc(secrets, func(s fly.Secret) bool { return s.Name == "FLY_API_TOKEN" }) {
+		fmt.Fprintf(io.ErrOut, "%s the app has no FLY_API_TOKEN secret, %s won't be able to act on the app until it is set\n", io.ColorScheme().Yellow("Warning:"), spec.Name)
+	}
+
+	metadata := lo.Assign(spec.Metadata, map[string]string{
+		fly.MachineConfigMetadataKeyFlyProcessGroup: ControllerProcessGroup,
+	})
+
+	input := fly.LaunchMachineInput{
+		Name:   spec.Name,
+		Region: spec.Region,
+		Config: &fly.MachineConfig{
+			Image:    spec.Image,
+			Schedule: spec.Schedule,
+			Init: fly.MachineInit{
+				Cmd: spec.Cmd,
+			},
+			Guest: &fly.MachineGuest{
+				CPUKind:  "shared",
+				CPUs:     1,
+				MemoryMB: 256,
+			},
+			Restart: &fly.MachineRestart{
+				Policy: spec.Restart,
+			},
+			Metadata: metadata,
+		},
+	}
+
+	machine, err := flapsutil.ClientFromContext(ctx).Launch(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("could not create %s machine: %w", spec.Name, err)
+	}
+	return machine, nil
+}
@@ -0,0 +1,151 @@
+package machine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronLookback is how far back to look for the last time a schedule fired,
+// which covers any schedule firing at least once a year.
+const CronLookback = 366 * 24 * time.Hour
+
+// CronSchedule is a parsed five field cron expression
+// (minute hour day-of-month month day-of-week).
+type CronSchedule struct {
+	expr   string
+	fields [5]map[int]bool
+	// Like in standard cron, a day matches either of the day of month and
+	// day of week fields when both are restricted, i.e. don't start with *.
+	anyDay bool
+}
+
+var cronFieldBounds = [5][2]int{
+	{0, 59}, // minute
+	{0, 23}, // hour
+	{1, 31}, // day of month
+	{1, 12}, // month
+	{0, 6},  // day of week, 0 is Sunday
+}
+
+// ParseCron parses a five field cron expression. Each field accepts *,
+// single values, ranges (a-b), lists (a,b) and steps (*/n, a-b/n).
+func ParseCron(expr string) (*CronSchedule, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q: expected 5 fields, got %d", expr, len(parts))
+	}
+
+	schedule := &CronSchedule{expr: strings.Join(parts, " ")}
+	for i, part := range parts {
+		values, err := parseCronField(part, cronFieldBounds[i][0], cronFieldBounds[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", expr, err)
+		}
+		schedule.fields[i] = values
+	}
+
+	// Sunday can be written as 7 too.
+	if schedule.fields[4][7] {
+		schedule.fields[4][0] = true
+	}
+
+	schedule.anyDay = !strings.HasPrefix(parts[2], "*") && !strings.HasPrefix(parts[4], "*")
+
+	return schedule, nil
+}
+
+func parseCronField(field string, min, max int) (map[int]bool, error) {
+	values := map[int]bool{}
+
+	for _, term := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(term, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepStr); err != nil || step < 1 {
+				return nil, fmt.Errorf("invalid step in %q", term)
+			}
+		}
+
+		lo, hi := min, max
+		if rng != "*" {
+			loStr, hiStr, isRange := strings.Cut(rng, "-")
+
+			var err error
+			if lo, err = strconv.Atoi(loStr); err != nil {
+				return nil, fmt.Errorf("invalid value in %q", term)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(hiStr); err != nil {
+					return nil, fmt.Errorf("invalid value in %q", term)
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+
+		// Allow 7 as an alias for Sunday in the day of week field.
+		limit := max
+		if max == 6 {
+			limit = 7
+		}
+		if lo < min || hi > limit || lo > hi {
+			return nil, fmt.Errorf("%q is out of range [%d, %d]", term, min, max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			values[v] = true
+		}
+	}
+
+	return values, nil
+}
+
+// String returns the cron expression s was parsed from.
+func (s *CronSchedule) String() string {
+	return s.expr
+}
+
+// Matches reports whether s fires at the minute t falls in.
+func (s *CronSchedule) Matches(t time.Time) bool {
+	return s.fields[0][t.Minute()] &&
+		s.fields[1][t.Hour()] &&
+		s.fields[3][int(t.Month())] &&
+		s.matchesDay(t)
+}
+
+func (s *CronSchedule) matchesDay(t time.Time) bool {
+	dom := s.fields[2][t.Day()]
+	dow := s.fields[4][int(t.Weekday())]
+	if s.anyDay {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// Prev returns the latest time at or before t at which s fires, looking back
+// at most lookback. The second return value is false if s doesn't fire in
+// that window.
+func (s *CronSchedule) Prev(t time.Time, lookback time.Duration) (time.Time, bool) {
+	t = t.Truncate(time.Minute)
+	for earliest := t.Add(-lookback); !t.Before(earliest); {
+		year, month, day := t.Date()
+		switch {
+		case !s.fields[3][int(month)] || !s.matchesDay(t):
+			// Skip to the last minute of the previous day
+			t = time.Date(year, month, day, 0, 0, 0, 0, t.Location()).Add(-time.Minute)
+		case !s.fields[1][t.Hour()]:
+			// Skip to the last minute of the previous hour
+			t = time.Date(year, month, day, t.Hour(), 0, 0, 0, t.Location()).Add(-time.Minute)
+		case !s.fields[0][t.Minute()]:
+			t = t.Add(-time.Minute)
+		default:
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,69 @@
+package machine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCron(t *testing.T) {
+	for _, expr := range []string{"* * * * *", "0 8 * * 1-5", "*/15 9-17 * * mon", "0 0 1 13 *", "60 * * * *", "* * *"} {
+		_, err := ParseCron(expr)
+		switch expr {
+		case "* * * * *", "0 8 * * 1-5":
+			require.NoError(t, err, expr)
+		default:
+			require.Error(t, err, expr)
+		}
+	}
+}
+
+func TestCronMatches(t *testing.T) {
+	schedule, err := ParseCron("*/15 9-17 * * 1-5")
+	require.NoError(t, err)
+
+	// 2024-01-08 is a Monday.
+	require.True(t, schedule.Matches(time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC)))
+	require.True(t, schedule.Matches(time.Date(2024, 1, 8, 17, 45, 30, 0, time.UTC)))
+	require.False(t, schedule.Matches(time.Date(2024, 1, 8, 9, 5, 0, 0, time.UTC)))
+	require.False(t, schedule.Matches(time.Date(2024, 1, 8, 18, 0, 0, 0, time.UTC)))
+	require.False(t, schedule.Matches(time.Date(2024, 1, 7, 9, 0, 0, 0, time.UTC)))
+
+	sundays, err := ParseCron("0 0 * * 7")
+	require.NoError(t, err)
+	require.True(t, sundays.Matches(time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC)))
+
+	// Either day field matches when both are restricted
+	firstOrMonday, err := ParseCron("0 0 1 * 1")
+	require.NoError(t, err)
+	require.True(t, firstOrMonday.Matches(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+	require.True(t, firstOrMonday.Matches(time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)))
+	require.True(t, firstOrMonday.Matches(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)))
+	require.False(t, firstOrMonday.Matches(time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)))
+
+	mondaysInJanuary, err := ParseCron("0 0 * 1 1")
+	require.NoError(t, err)
+	require.True(t, mondaysInJanuary.Matches(time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)))
+	require.False(t, mondaysInJanuary.Matches(time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestCronPrev(t *testing.T) {
+	schedule, err := ParseCron("0 20 * * *")
+	require.NoError(t, err)
+
+	now := time.Date(2024, 1, 8, 9, 30, 0, 0, time.UTC)
+	prev, ok := schedule.Prev(now, 24*time.Hour)
+	require.True(t, ok)
+	require.Equal(t, time.Date(2024, 1, 7, 20, 0, 0, 0, time.UTC), prev)
+
+	_, ok = schedule.Prev(now, time.Hour)
+	require.False(t, ok)
+
+	monthly, err := ParseCron("30 6 15 * *")
+	require.NoError(t, err)
+
+	prev, ok = monthly.Prev(now, CronLookback)
+	require.True(t, ok)
+	require.Equal(t, time.Date(2023, 12, 15, 6, 30, 0, 0, time.UTC), prev)
+}
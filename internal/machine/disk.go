@@ -0,0 +1,60 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kballard/go-shellquote"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/flyctl/internal/flapsutil"
+)
+
+// DiskUsage runs df in machine m to get the used and total bytes of the
+// filesystem at path. The machine must be started and its image ship df.
+func DiskUsage(ctx context.Context, m *fly.Machine, path string) (used, total uint64, err error) {
+	flapsClient := flapsutil.ClientFromContext(ctx)
+
+	out, err := flapsClient.Exec(ctx, m.ID, &fly.MachineExecRequest{
+		Cmd:     "df -Pk " + shellquote.Join(path),
+		Timeout: 5,
+	})
+	switch {
+	case err != nil:
+		return 0, 0, err
+	case out.ExitCode != 0:
+		return 0, 0, fmt.Errorf("df exited with code %d: %s", out.ExitCode, strings.TrimSpace(out.StdErr))
+	}
+
+	used, total = ParseDF(out.StdOut)
+	if total == 0 {
+		return 0, 0, fmt.Errorf("unexpected df output: %q", out.StdOut)
+	}
+	return used, total, nil
+}
+
+// ParseDF parses the output of `df -Pk <path>`, returning the used and total
+// size of the filesystem in bytes.
+func ParseDF(out string) (used, total uint64) {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) < 2 {
+		return 0, 0
+	}
+
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) < 3 {
+		return 0, 0
+	}
+
+	totalKB, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return 0, 0
+	}
+	usedKB, err := strconv.ParseUint(fields[2], 10, 64)
+	if err != nil {
+		return 0, 0
+	}
+
+	return usedKB * 1024, totalKB * 1024
+}
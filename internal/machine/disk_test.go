@@ -0,0 +1,20 @@
+package machine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDF(t *testing.T) {
+	out := `Filesystem     1024-blocks   Used Available Capacity Mounted on
+/dev/vdb           1019856 261236    688620      28% /data
+`
+	used, total := ParseDF(out)
+	assert.Equal(t, uint64(261236*1024), used)
+	assert.Equal(t, uint64(1019856*1024), total)
+
+	used, total = ParseDF("df: /data: No such file or directory")
+	assert.Zero(t, used)
+	assert.Zero(t, total)
+}
@@ -0,0 +1,45 @@
+package machine
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// gpuRegions lists the regions offering each GPU kind.
+// TODO: fetch this list from the graphql endpoint once it is there
+var gpuRegions = map[string][]string{
+	"a100-pcie-40gb": {"ord"},
+	"a100-sxm4-80gb": {"ams", "iad", "sjc", "syd"},
+	"l40s":           {"ord"},
+	"a10":            {"ord"},
+}
+
+// GPURegions returns the regions offering gpuKind, or the regions offering
+// any GPU when gpuKind is empty.
+func GPURegions(gpuKind string) []string {
+	if gpuKind != "" {
+		return gpuRegions[gpuKind]
+	}
+	regions := lo.Uniq(lo.Flatten(lo.Values(gpuRegions)))
+	sort.Strings(regions)
+	return regions
+}
+
+// ValidateGPURegions returns an error listing the regions that don't offer
+// gpuKind.
+func ValidateGPURegions(gpuKind string, regions []string) error {
+	available := GPURegions(gpuKind)
+	missing := lo.Uniq(lo.Reject(regions, func(r string, _ int) bool {
+		return slices.Contains(available, r)
+	}))
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	return fmt.Errorf("GPU kind %s isn't available in %s, it is offered in %s",
+		gpuKind, strings.Join(missing, ", "), strings.Join(available, ", "))
+}
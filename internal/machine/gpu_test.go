@@ -0,0 +1,20 @@
+package machine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateGPURegions(t *testing.T) {
+	assert.NoError(t, ValidateGPURegions("a100-sxm4-80gb", []string{"iad", "ams", "iad"}))
+	assert.NoError(t, ValidateGPURegions("l40s", nil))
+
+	err := ValidateGPURegions("a100-pcie-40gb", []string{"ord", "iad", "cdg", "iad"})
+	assert.EqualError(t, err, "GPU kind a100-pcie-40gb isn't available in cdg, iad, it is offered in ord")
+}
+
+func TestGPURegions(t *testing.T) {
+	assert.Equal(t, []string{"ams", "iad", "ord", "sjc", "syd"}, GPURegions(""))
+	assert.Equal(t, []string{"ord"}, GPURegions("a10"))
+}
@@ -30,7 +30,7 @@ type LeasableMachine interface {
 	StartBackgroundLeaseRefresh(context.Context, time.Duration, time.Duration)
 	Update(context.Context, fly.LaunchMachineInput) error
 	Start(context.Context) error
-	Stop(context.Context, string) error
+	Stop(context.Context, string, time.Duration) error
 	Destroy(context.Context, bool) error
 	Cordon(context.Context) error
 	WaitForState(context.Context, string, time.Duration, bool) error
@@ -83,7 +83,9 @@ func (lm *leasableMachine) Update(ctx context.Context, input fly.LaunchMachineIn
 	return nil
 }
 
-func (lm *leasableMachine) Stop(ctx context.Context, signal string) error {
+// Stop stops the machine with signal, killing it if it hasn't stopped after
+// timeout. An empty signal or zero timeout use the machine's stop config.
+func (lm *leasableMachine) Stop(ctx context.Context, signal string, timeout time.Duration) error {
 	if lm.IsDestroyed() {
 		return fmt.Errorf("cannon stop machine %s that was already destroyed", lm.machine.ID)
 	}
@@ -92,6 +94,9 @@ func (lm *leasableMachine) Stop(ctx context.Context, signal string) error {
 		ID:     lm.machine.ID,
 		Signal: signal,
 	}
+	if timeout > 0 {
+		input.Timeout = fly.Duration{Duration: timeout}
+	}
 
 	return lm.flapsClient.Stop(ctx, input, lm.leaseNonce)
 }
@@ -0,0 +1,50 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/kballard/go-shellquote"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/flyctl/internal/flapsutil"
+)
+
+// MissingPaths runs ls in machine m to find which of paths don't exist in its
+// filesystem. The machine must be started and its image ship ls.
+func MissingPaths(ctx context.Context, m *fly.Machine, paths []string) ([]string, error) {
+	flapsClient := flapsutil.ClientFromContext(ctx)
+
+	out, err := flapsClient.Exec(ctx, m.ID, &fly.MachineExecRequest{
+		Cmd:     "ls -d " + shellquote.Join(paths...),
+		Timeout: 5,
+	})
+	switch {
+	case err != nil:
+		return nil, err
+	case out.ExitCode == 126 || out.ExitCode == 127:
+		return nil, fmt.Errorf("ls exited with code %d: %s", out.ExitCode, strings.TrimSpace(out.StdErr))
+	}
+
+	return MissingFromLS(paths, out.StdOut), nil
+}
+
+// MissingFromLS returns the paths that the output of `ls -d <paths>`
+// doesn't list, ls only listing the ones that exist.
+func MissingFromLS(paths []string, out string) []string {
+	listed := map[string]bool{}
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			listed[path.Clean(line)] = true
+		}
+	}
+
+	var missing []string
+	for _, p := range paths {
+		if !listed[path.Clean(p)] {
+			missing = append(missing, p)
+		}
+	}
+	return missing
+}
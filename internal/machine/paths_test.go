@@ -0,0 +1,13 @@
+package machine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMissingFromLS(t *testing.T) {
+	paths := []string{"/app/public/", "/app/assets", "/srv/media"}
+	assert.Equal(t, []string{"/app/assets"}, MissingFromLS(paths, "/app/public/\n/srv/media\n"))
+	assert.Equal(t, paths, MissingFromLS(paths, ""))
+}
@@ -22,3 +22,22 @@ func ListActive(ctx context.Context) ([]*fly.Machine, error) {
 
 	return machines, nil
 }
+
+// CordonedMetadataKey is set on machines cordoned with `fly machine cordon`.
+// Deployments leave cordoned machines alone until they are uncordoned.
+const CordonedMetadataKey = "fly_cordoned"
+
+// IsCordoned reports whether m was cordoned with `fly machine cordon`.
+func IsCordoned(m *fly.Machine) bool {
+	return m.Config != nil && m.Config.Metadata[CordonedMetadataKey] == "true"
+}
+
+// Metadata keys of the controller machines that `fly scale schedule` and
+// `fly scale autoscale` run, holding their rules and decisions.
+const (
+	ScaleSchedulerMetadataKey     = "fly_scale_scheduler"
+	ScaleScheduleMetadataKey      = "fly_scale_schedule"
+	AutoscalerMetadataKey         = "fly_autoscaler"
+	AutoscalePoliciesMetadataKey  = "fly_autoscale_policies"
+	AutoscaleDecisionsMetadataKey = "fly_autoscale_decisions"
+)
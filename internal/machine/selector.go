@@ -0,0 +1,169 @@
+package machine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	fly "github.com/superfly/fly-go"
+)
+
+// Selector matches machines against a set of key=value or key!=value
+// requirements, e.g. "process_group=worker,region=iad". The keys id, name,
+// state, region, process_group (or group) and image match the corresponding
+// machine attributes; any other key is looked up in the machine's metadata.
+//
+// The keys created_before, created_after, updated_before and updated_after
+// compare the machine's timestamps against either an age such as 30d, 12h
+// or 2w, or a date such as 2024-06-01.
+type Selector []selectorRequirement
+
+type selectorRequirement struct {
+	key    string
+	value  string
+	negate bool
+
+	// threshold is set for timestamp keys.
+	threshold time.Time
+}
+
+var timeSelectorKeys = map[string]bool{
+	"created_before": true,
+	"created_after":  true,
+	"updated_before": true,
+	"updated_after":  true,
+}
+
+// ParseSelector parses a comma separated list of selector requirements.
+func ParseSelector(s string) (Selector, error) {
+	var selector Selector
+
+	for _, term := range strings.Split(s, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+
+		var req selectorRequirement
+		if key, value, ok := strings.Cut(term, "!="); ok {
+			req = selectorRequirement{key: key, value: value, negate: true}
+		} else if key, value, ok := strings.Cut(term, "="); ok {
+			req = selectorRequirement{key: key, value: value}
+		} else {
+			return nil, fmt.Errorf("invalid selector requirement %q, expected key=value or key!=value", term)
+		}
+
+		req.key = strings.TrimSpace(req.key)
+		req.value = strings.TrimSpace(req.value)
+		if req.key == "" {
+			return nil, fmt.Errorf("invalid selector requirement %q, missing key", term)
+		}
+
+		if timeSelectorKeys[req.key] {
+			if req.negate {
+				return nil, fmt.Errorf("invalid selector requirement %q, %s can't be negated", term, req.key)
+			}
+			threshold, err := parseSelectorTime(req.value, time.Now())
+			if err != nil {
+				return nil, fmt.Errorf("invalid selector requirement %q: %w", term, err)
+			}
+			req.threshold = threshold
+		}
+
+		selector = append(selector, req)
+	}
+
+	if len(selector) == 0 {
+		return nil, fmt.Errorf("selector %q has no requirements", s)
+	}
+
+	return selector, nil
+}
+
+// Matches reports whether m satisfies every requirement of s.
+func (s Selector) Matches(m *fly.Machine) bool {
+	for _, req := range s {
+		if timeSelectorKeys[req.key] {
+			if !matchesSelectorTime(m, req) {
+				return false
+			}
+			continue
+		}
+		if (selectorValue(m, req.key) == req.value) == req.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter returns the machines matching s.
+func (s Selector) Filter(machines []*fly.Machine) []*fly.Machine {
+	var matched []*fly.Machine
+	for _, m := range machines {
+		if s.Matches(m) {
+			matched = append(matched, m)
+		}
+	}
+	return matched
+}
+
+func selectorValue(m *fly.Machine, key string) string {
+	switch key {
+	case "id":
+		return m.ID
+	case "name":
+		return m.Name
+	case "state":
+		return m.State
+	case "region":
+		return m.Region
+	case "process_group", "group":
+		return m.ProcessGroup()
+	case "image":
+		return m.FullImageRef()
+	}
+
+	return m.GetMetadataByKey(key)
+}
+
+func matchesSelectorTime(m *fly.Machine, req selectorRequirement) bool {
+	raw := m.CreatedAt
+	if strings.HasPrefix(req.key, "updated_") {
+		raw = m.UpdatedAt
+	}
+
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return false
+	}
+
+	if strings.HasSuffix(req.key, "_before") {
+		return t.Before(req.threshold)
+	}
+	return t.After(req.threshold)
+}
+
+// parseSelectorTime parses an age relative to now, e.g. 30d, 2w or 12h, or
+// an absolute date or RFC 3339 timestamp.
+func parseSelectorTime(value string, now time.Time) (time.Time, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(value, suffix); ok {
+			if count, err := strconv.Atoi(n); err == nil && count >= 0 {
+				return now.Add(-time.Duration(count) * unit), nil
+			}
+		}
+	}
+
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%q is neither an age such as 30d or 12h nor a date such as 2024-06-01", value)
+}
@@ -0,0 +1,130 @@
+package machine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	fly "github.com/superfly/fly-go"
+)
+
+func TestParseSelector(t *testing.T) {
+	selector, err := ParseSelector("process_group=worker, region!=iad,team=")
+	require.NoError(t, err)
+	require.Equal(t, Selector{
+		{key: "process_group", value: "worker"},
+		{key: "region", value: "iad", negate: true},
+		{key: "team", value: ""},
+	}, selector)
+
+	_, err = ParseSelector("region")
+	require.Error(t, err)
+
+	_, err = ParseSelector("=iad")
+	require.Error(t, err)
+
+	_, err = ParseSelector(" , ")
+	require.Error(t, err)
+}
+
+func TestSelectorFilter(t *testing.T) {
+	newMachine := func(id, region, group string, metadata map[string]string) *fly.Machine {
+		if metadata == nil {
+			metadata = map[string]string{}
+		}
+		metadata[fly.MachineConfigMetadataKeyFlyProcessGroup] = group
+		return &fly.Machine{
+			ID:     id,
+			Region: region,
+			State:  fly.MachineStateStarted,
+			Config: &fly.MachineConfig{Metadata: metadata},
+		}
+	}
+
+	machines := []*fly.Machine{
+		newMachine("1", "iad", "app", nil),
+		newMachine("2", "iad", "worker", map[string]string{"team": "billing"}),
+		newMachine("3", "ord", "worker", nil),
+	}
+
+	testcases := []struct {
+		selector string
+		expect   []string
+	}{
+		{"process_group=worker,region=iad", []string{"2"}},
+		{"group=worker", []string{"2", "3"}},
+		{"region!=iad", []string{"3"}},
+		{"team=billing", []string{"2"}},
+		{"state=started", []string{"1", "2", "3"}},
+		{"region=ams", nil},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.selector, func(t *testing.T) {
+			selector, err := ParseSelector(tc.selector)
+			require.NoError(t, err)
+
+			var ids []string
+			for _, m := range selector.Filter(machines) {
+				ids = append(ids, m.ID)
+			}
+			require.Equal(t, tc.expect, ids)
+		})
+	}
+}
+
+func TestParseSelectorTime(t *testing.T) {
+	now := time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC)
+
+	testcases := map[string]time.Time{
+		"30d":                  now.AddDate(0, 0, -30),
+		"2w":                   now.AddDate(0, 0, -14),
+		"12h":                  now.Add(-12 * time.Hour),
+		"2024-06-01":           time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+		"2024-06-01T10:00:00Z": time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC),
+	}
+	for value, expect := range testcases {
+		got, err := parseSelectorTime(value, now)
+		require.NoError(t, err, value)
+		require.Equal(t, expect, got, value)
+	}
+
+	for _, value := range []string{"", "d", "-3d", "yesterday"} {
+		_, err := parseSelectorTime(value, now)
+		require.Error(t, err, value)
+	}
+}
+
+func TestSelectorTimeFilter(t *testing.T) {
+	machines := []*fly.Machine{
+		{ID: "old", State: fly.MachineStateStopped, CreatedAt: "2023-01-01T00:00:00Z", UpdatedAt: "2024-05-01T00:00:00Z"},
+		{ID: "new", State: fly.MachineStateStopped, CreatedAt: "2024-06-15T00:00:00Z", UpdatedAt: "2024-06-15T00:00:00Z"},
+		{ID: "started", State: fly.MachineStateStarted, CreatedAt: "2023-01-01T00:00:00Z"},
+		{ID: "unknown", State: fly.MachineStateStopped},
+	}
+
+	testcases := []struct {
+		selector string
+		expect   []string
+	}{
+		{"state=stopped,created_before=2024-06-01", []string{"old"}},
+		{"created_after=2024-06-01", []string{"new"}},
+		{"updated_before=2024-06-01", []string{"old"}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.selector, func(t *testing.T) {
+			selector, err := ParseSelector(tc.selector)
+			require.NoError(t, err)
+
+			var ids []string
+			for _, m := range selector.Filter(machines) {
+				ids = append(ids, m.ID)
+			}
+			require.Equal(t, tc.expect, ids)
+		})
+	}
+
+	_, err := ParseSelector("created_before!=30d")
+	require.Error(t, err)
+}
@@ -0,0 +1,151 @@
+// Package prometheus queries the Prometheus of organizations, where the
+// metrics of their apps are.
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/samber/lo"
+	fly "github.com/superfly/fly-go"
+	"github.com/superfly/flyctl/internal/buildinfo"
+	"github.com/superfly/flyctl/internal/config"
+)
+
+// DefaultURL is the base URL of the Prometheus of organizations, overridden
+// with FLY_PROMETHEUS_URL.
+const DefaultURL = "https://api.fly.io/prometheus"
+
+var httpClient = &http.Client{
+	Timeout: 30 * time.Second,
+}
+
+// Sample is a value of a series at a time.
+type Sample struct {
+	Time  time.Time
+	Value float64
+}
+
+// Series is the samples of a metric with its labels.
+type Series struct {
+	Metric  map[string]string
+	Samples []Sample
+}
+
+// Last returns the value of the last sample of the series.
+func (s Series) Last() (float64, bool) {
+	if len(s.Samples) == 0 {
+		return 0, false
+	}
+	return s.Samples[len(s.Samples)-1].Value, true
+}
+
+// Query runs an instant query, returning series of a sample each.
+func Query(ctx context.Context, orgSlug, query string) ([]Series, error) {
+	return get(ctx, orgSlug, "query", url.Values{"query": {query}})
+}
+
+// QueryRange runs a query over the time range with a sample every step.
+func QueryRange(ctx context.Context, orgSlug, query string, start, end time.Time, step time.Duration) ([]Series, error) {
+	return get(ctx, orgSlug, "query_range", url.Values{
+		"query": {query},
+		"start": {strconv.FormatInt(start.Unix(), 10)},
+		"end":   {strconv.FormatInt(end.Unix(), 10)},
+		"step":  {strconv.Itoa(max(int(step.Seconds()), 1))},
+	})
+}
+
+func get(ctx context.Context, orgSlug, endpoint string, params url.Values) ([]Series, error) {
+	baseURL := DefaultURL
+	if val := os.Getenv("FLY_PROMETHEUS_URL"); val != "" {
+		baseURL = val
+	}
+
+	u := fmt.Sprintf("%s/%s/api/v1/%s?%s", baseURL, url.PathEscape(orgSlug), endpoint, params.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", buildinfo.UserAgent())
+	req.Header.Set("Authorization", fly.AuthorizationHeader(config.Tokens(ctx).GraphQL()))
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying metrics: %w", err)
+	}
+	defer res.Body.Close()
+
+	var body response
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed decoding metrics (status %d): %w", res.StatusCode, err)
+	}
+	if body.Status != "success" {
+		return nil, fmt.Errorf("failed querying metrics: %s", lo.CoalesceOrEmpty(body.Error, res.Status))
+	}
+
+	return body.series(), nil
+}
+
+// response is the body of responses of the Prometheus HTTP API, for vector
+// and matrix results.
+type response struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string `json:"metric"`
+			Value  []any             `json:"value"`
+			Values [][]any           `json:"values"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+func (r *response) series() []Series {
+	series := make([]Series, 0, len(r.Data.Result))
+	for _, result := range r.Data.Result {
+		s := Series{Metric: result.Metric}
+
+		values := result.Values
+		if result.Value != nil {
+			values = [][]any{result.Value}
+		}
+		for _, v := range values {
+			if sample, ok := parseSample(v); ok {
+				s.Samples = append(s.Samples, sample)
+			}
+		}
+
+		series = append(series, s)
+	}
+	return series
+}
+
+// parseSample parses a [<unix time>, "<value>"] pair, skipping NaNs.
+func parseSample(pair []any) (Sample, bool) {
+	if len(pair) != 2 {
+		return Sample{}, false
+	}
+	ts, ok := pair[0].(float64)
+	if !ok {
+		return Sample{}, false
+	}
+	raw, ok := pair[1].(string)
+	if !ok {
+		return Sample{}, false
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(v) {
+		return Sample{}, false
+	}
+
+	sec, frac := math.Modf(ts)
+	return Sample{Time: time.Unix(int64(sec), int64(frac*1e9)), Value: v}, true
+}
@@ -0,0 +1,52 @@
+package prometheus
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseSeries(t *testing.T) {
+	const vector = `{
+		"status": "success",
+		"data": {
+			"resultType": "vector",
+			"result": [
+				{"metric": {"instance": "a"}, "value": [1714571100.5, "1.5"]},
+				{"metric": {"instance": "b"}, "value": [1714571100, "NaN"]}
+			]
+		}
+	}`
+
+	var r response
+	require.NoError(t, json.Unmarshal([]byte(vector), &r))
+	require.Equal(t, []Series{
+		{Metric: map[string]string{"instance": "a"}, Samples: []Sample{{Time: time.Unix(1714571100, 5e8), Value: 1.5}}},
+		{Metric: map[string]string{"instance": "b"}},
+	}, r.series())
+
+	const matrix = `{
+		"status": "success",
+		"data": {
+			"resultType": "matrix",
+			"result": [
+				{"metric": {"instance": "a"}, "values": [[1714571100, "1"], [1714571160, "2"]]}
+			]
+		}
+	}`
+
+	r = response{}
+	require.NoError(t, json.Unmarshal([]byte(matrix), &r))
+	series := r.series()
+	require.Len(t, series, 1)
+	require.Equal(t, []Sample{
+		{Time: time.Unix(1714571100, 0), Value: 1},
+		{Time: time.Unix(1714571160, 0), Value: 2},
+	}, series[0].Samples)
+
+	last, ok := series[0].Last()
+	require.True(t, ok)
+	require.Equal(t, 2.0, last)
+}
@@ -2,9 +2,14 @@ package logs
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"regexp"
+	"slices"
 	"time"
+
+	"github.com/superfly/flyctl/terminal"
 )
 
 type LogOptions struct {
@@ -15,9 +20,110 @@ type LogOptions struct {
 	VMID       string
 	RegionCode string
 	NoTail     bool
+
+	// VMIDs, when set, narrow the logs to those of several machines, where
+	// VMID narrows them to one.
+	VMIDs []string
+
+	// Grep and GrepV, when set, narrow the logs to those with messages that
+	// match, or don't match, them.
+	Grep  *regexp.Regexp
+	GrepV *regexp.Regexp
+
+	// From and To, when set, bound the logs fetched to a time range. Logs of
+	// a range aren't tailed, and are filtered from those the platform still
+	// buffers, which it has no way to fetch past.
+	From time.Time
+	To   time.Time
+}
+
+// HasRange reports whether the logs are fetched for a time range.
+func (opts *LogOptions) HasRange() bool {
+	return !opts.From.IsZero() || !opts.To.IsZero()
+}
+
+// inRange reports whether the timestamp is in the range of the options, and
+// whether it's past it. Unparseable timestamps are in range.
+func (opts *LogOptions) inRange(timestamp string) (in, past bool) {
+	t, err := time.Parse(time.RFC3339Nano, timestamp)
+	switch {
+	case err != nil:
+		return true, false
+	case !opts.From.IsZero() && t.Before(opts.From):
+		return false, false
+	case !opts.To.IsZero() && t.After(opts.To):
+		return false, true
+	default:
+		return true, false
+	}
+}
+
+// matches reports whether the log of the instance with the message is one of
+// those narrowed to by the options.
+func (opts *LogOptions) matches(instance, message string) bool {
+	switch {
+	case len(opts.VMIDs) > 0 && !slices.Contains(opts.VMIDs, instance):
+		return false
+	case opts.Grep != nil && !opts.Grep.MatchString(message):
+		return false
+	case opts.GrepV != nil && opts.GrepV.MatchString(message):
+		return false
+	default:
+		return true
+	}
+}
+
+// instance returns the machine the logs are fetched for by the API, which
+// narrows them to a single one at most.
+func (opts *LogOptions) instance() string {
+	if opts.VMID == "" && len(opts.VMIDs) == 1 {
+		return opts.VMIDs[0]
+	}
+	return opts.VMID
+}
+
+// toNatsSubjects returns the subjects of the logs, one per machine when
+// they're narrowed to several.
+// ErrNotBuffered is returned for ranges of logs that ended before the oldest
+// log the platform still buffers.
+var ErrNotBuffered = errors.New("the logs of the range are no longer buffered")
+
+// checkBuffered checks the range of the options against the timestamp of the
+// oldest buffered log, warning of ranges that are partly buffered.
+func (opts *LogOptions) checkBuffered(oldest string) error {
+	t, err := time.Parse(time.RFC3339Nano, oldest)
+	switch {
+	case err != nil:
+		return nil
+	case !opts.To.IsZero() && t.After(opts.To):
+		return fmt.Errorf("%w, the oldest buffered log is from %s", ErrNotBuffered, t.Local().Format(time.DateTime))
+	case !opts.From.IsZero() && t.After(opts.From):
+		terminal.Warnf("Logs before %s are no longer buffered, only those since are shown\n", t.Local().Format(time.DateTime))
+	}
+	return nil
+}
+
+// warnEmptyRange warns that none of the buffered logs are in the range when
+// none were shown.
+func (opts *LogOptions) warnEmptyRange(shown int) {
+	if shown == 0 {
+		terminal.Warnf("None of the buffered logs are in the range\n")
+	}
+}
+
+func (opts *LogOptions) toNatsSubjects() []string {
+	if opts.VMID != "" || len(opts.VMIDs) == 0 {
+		return []string{opts.toNatsSubject(opts.VMID)}
+	}
+
+	subjects := make([]string, 0, len(opts.VMIDs))
+	for _, id := range opts.VMIDs {
+		subjects = append(subjects, opts.toNatsSubject(id))
+	}
+	return subjects
 }
 
-func (opts *LogOptions) toNatsSubject() (subject string) {
+func (opts *LogOptions) toNatsSubject(vmID string) (subject string) {
 	subject = fmt.Sprintf("logs.%s", opts.AppName)
 
 	add := func(what string) {
@@ -29,7 +135,7 @@ func (opts *LogOptions) toNatsSubject() (subject string) {
 	}
 
 	add(opts.RegionCode)
-	add(opts.VMID)
+	add(vmID)
 
 	return
 }
@@ -3,6 +3,7 @@ package logs
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/superfly/flyctl/agent"
 	"github.com/superfly/flyctl/internal/config"
 	"github.com/superfly/flyctl/internal/flyutil"
+	"github.com/superfly/flyctl/terminal"
 )
 
 type natsLogStream struct {
@@ -98,17 +100,33 @@ func (d *natsDialer) Dial(network, address string) (net.Conn, error) {
 }
 
 func fromNats(ctx context.Context, out chan<- LogEntry, nc *nats.Conn, opts *LogOptions) (err error) {
-	var sub *nats.Subscription
-	if sub, err = nc.SubscribeSync(opts.toNatsSubject()); err != nil {
-		return
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	subjects := opts.toNatsSubjects()
+	msgs := make(chan *nats.Msg)
+	errs := make(chan error, len(subjects))
+	for _, subject := range subjects {
+		var sub *nats.Subscription
+		if sub, err = nc.SubscribeSync(subject); err != nil {
+			return
+		}
+		defer sub.Unsubscribe()
+
+		go func() {
+			errs <- nextMsgs(ctx, sub, msgs)
+		}()
 	}
-	defer sub.Unsubscribe()
 
 	var log natsLog
 	for {
 		var msg *nats.Msg
-		if msg, err = sub.NextMsgWithContext(ctx); err != nil {
-			break
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err = <-errs:
+			return
+		case msg = <-msgs:
 		}
 
 		if err = json.Unmarshal(msg.Data, &log); err != nil {
@@ -117,6 +135,10 @@ func fromNats(ctx context.Context, out chan<- LogEntry, nc *nats.Conn, opts *Log
 			break
 		}
 
+		if !opts.matches(log.Fly.App.Instance, log.Message) {
+			continue
+		}
+
 		out <- LogEntry{
 			Instance:  log.Fly.App.Instance,
 			Level:     log.Log.Level,
@@ -133,3 +155,24 @@ func fromNats(ctx context.Context, out chan<- LogEntry, nc *nats.Conn, opts *Log
 
 	return
 }
+
+// nextMsgs sends the messages of sub to msgs, warning of those dropped when
+// they arrive faster than they're handled.
+func nextMsgs(ctx context.Context, sub *nats.Subscription, msgs chan<- *nats.Msg) error {
+	for {
+		msg, err := sub.NextMsgWithContext(ctx)
+		switch {
+		case errors.Is(err, nats.ErrSlowConsumer):
+			terminal.Warnf("Some logs were dropped since they arrived faster than they could be handled\n")
+			continue
+		case err != nil:
+			return err
+		}
+
+		select {
+		case msgs <- msg:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
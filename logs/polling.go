@@ -46,6 +46,8 @@ func Poll(ctx context.Context, out chan<- LogEntry, client flyutil.Client, opts
 		errorCount int
 		nextToken  string
 		waitFor    = minWait
+		checked    bool
+		shown      int
 	)
 
 	for {
@@ -53,7 +55,7 @@ func Poll(ctx context.Context, out chan<- LogEntry, client flyutil.Client, opts
 			pause.For(ctx, waitFor)
 		}
 
-		entries, token, err := client.GetAppLogs(ctx, opts.AppName, nextToken, opts.RegionCode, opts.VMID)
+		entries, token, err := client.GetAppLogs(ctx, opts.AppName, nextToken, opts.RegionCode, opts.instance())
 		if err != nil {
 			switch errorCount++; {
 			default:
@@ -71,6 +73,11 @@ func Poll(ctx context.Context, out chan<- LogEntry, client flyutil.Client, opts
 
 		errorCount = 0
 		if len(entries) == 0 {
+			if opts.HasRange() {
+				// Caught up with the logs of the range
+				opts.warnEmptyRange(shown)
+				return nil
+			}
 			waitFor = backoff(minWait, maxWait)
 
 			continue
@@ -82,7 +89,26 @@ func Poll(ctx context.Context, out chan<- LogEntry, client flyutil.Client, opts
 			nextToken = token
 		}
 
+		// Polling starts from the oldest buffered log
+		if opts.HasRange() && !checked {
+			checked = true
+			if err := opts.checkBuffered(entries[0].Timestamp); err != nil {
+				return err
+			}
+		}
+
+		var past bool
 		for _, entry := range entries {
+			in, after := opts.inRange(entry.Timestamp)
+			if !in {
+				past = past || after
+				continue
+			}
+			if !opts.matches(entry.Instance, entry.Message) {
+				continue
+			}
+			shown++
+
 			out <- LogEntry{
 				Instance:  entry.Instance,
 				Level:     entry.Level,
@@ -93,7 +119,12 @@ func Poll(ctx context.Context, out chan<- LogEntry, client flyutil.Client, opts
 			}
 		}
 
-		if opts.NoTail {
+		// Logs of a range stop past its end, or without a token to continue from
+		if past || (opts.HasRange() && token == "") {
+			opts.warnEmptyRange(shown)
+			return nil
+		}
+		if opts.NoTail && !opts.HasRange() {
 			return nil
 		}
 	}
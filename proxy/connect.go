@@ -11,6 +11,7 @@ import (
 	"github.com/superfly/flyctl/internal/prompt"
 	"github.com/superfly/flyctl/iostreams"
 	"github.com/superfly/flyctl/ip"
+	"golang.org/x/sync/errgroup"
 )
 
 type ConnectParams struct {
@@ -23,6 +24,7 @@ type ConnectParams struct {
 	PromptInstance   bool
 	DisableSpinner   bool
 	Network          string
+	NoReconnect      bool
 }
 
 // Binds to a local port and runs a proxy to a remote address over Wireguard.
@@ -36,6 +38,51 @@ func Connect(ctx context.Context, p *ConnectParams) (err error) {
 	return server.ProxyServer(ctx)
 }
 
+// ConnectAll binds a local port for each of mappings, local and remote ports
+// like the Ports of p, and runs proxies for all of them over the dialer of p.
+// Blocks until context is cancelled or a proxy fails.
+func ConnectAll(ctx context.Context, p *ConnectParams, mappings [][]string) (err error) {
+	// Select the instance once for all the ports
+	if p.PromptInstance {
+		agentclient, err := agent.Establish(ctx, flyutil.ClientFromContext(ctx))
+		if err != nil {
+			return err
+		}
+		instance, err := selectInstance(ctx, p.OrganizationSlug, p.AppName, agentclient)
+		if err != nil {
+			return err
+		}
+
+		selected := *p
+		selected.RemoteHost = instance
+		selected.PromptInstance = false
+		p = &selected
+	}
+
+	servers := make([]*Server, 0, len(mappings))
+	for _, ports := range mappings {
+		params := *p
+		params.Ports = ports
+
+		server, err := NewServer(ctx, &params)
+		if err != nil {
+			for _, s := range servers {
+				s.Listener.Close()
+			}
+			return err
+		}
+		servers = append(servers, server)
+	}
+
+	eg, ctx := errgroup.WithContext(ctx)
+	for _, server := range servers {
+		eg.Go(func() error {
+			return server.ProxyServer(ctx)
+		})
+	}
+	return eg.Wait()
+}
+
 // Binds to a local port and then starts a goroutine to run a proxy to a remote
 // address over Wireguard. Proxy runs until context is cancelled.
 // Blocks only until local listener is bound and ready to accept connections.
@@ -127,11 +174,48 @@ func NewServer(ctx context.Context, p *ConnectParams) (*Server, error) {
 
 	fmt.Fprintf(io.Out, "Proxying local port %s to remote %s\n", localPort, remoteAddr)
 
-	return &Server{
+	server := &Server{
 		Addr:     remoteAddr,
 		Listener: listener,
 		Dial:     p.Dialer.DialContext,
-	}, nil
+	}
+	if !p.NoReconnect {
+		server.Reconnect = reconnectFunc(client, p)
+		server.Events = io.ErrOut
+	}
+
+	return server, nil
+}
+
+// reconnectFunc returns a function re-establishing the tunnel of p, starting
+// the agent again if it died, and returning the dial function through it.
+func reconnectFunc(client flyutil.Client, p *ConnectParams) func(ctx context.Context) (DialFunc, error) {
+	return func(ctx context.Context) (DialFunc, error) {
+		agentclient, err := agent.Establish(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		// An agent started again has no tunnel yet, and one that doesn't
+		// respond is replaced by a new one
+		if _, err := agentclient.Establish(ctx, p.OrganizationSlug, p.Network); err != nil {
+			return nil, err
+		}
+		if err := agentclient.Probe(ctx, p.OrganizationSlug, p.Network); err != nil {
+			if _, err := agentclient.Reestablish(ctx, p.OrganizationSlug, p.Network); err != nil {
+				return nil, err
+			}
+		}
+
+		dialer, err := agentclient.Dialer(ctx, p.OrganizationSlug, p.Network)
+		if err != nil {
+			return nil, err
+		}
+		if err := agentclient.WaitForTunnel(ctx, p.OrganizationSlug, p.Network); err != nil {
+			return nil, err
+		}
+		return dialer.DialContext, nil
+	}
 }
 
 func selectInstance(ctx context.Context, org, app string, c *agent.Client) (instance string, err error) {
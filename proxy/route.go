@@ -0,0 +1,57 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/superfly/flyctl/agent"
+)
+
+// OrgDialer is a dialer through the tunnel to the private network of an
+// organization.
+type OrgDialer struct {
+	Org     string
+	Network string
+	Dialer  agent.Dialer
+}
+
+// RouteDial returns a dial function routing each connection through the
+// tunnel of the organization its address belongs to: 6PN addresses by the
+// network of the tunnels, .internal names by the first organization, in the
+// order of dialers, they resolve in. Other names go through the first one.
+func RouteDial(agentclient *agent.Client, dialers []OrgDialer) DialFunc {
+	return func(ctx context.Context, proto, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		if ip := net.ParseIP(host); ip != nil {
+			for _, d := range dialers {
+				if remote := d.Dialer.Config().RemoteNetwork; remote != nil && (*net.IPNet)(remote).Contains(ip) {
+					return d.Dialer.DialContext(ctx, proto, addr)
+				}
+			}
+			return dialers[0].Dialer.DialContext(ctx, proto, addr)
+		}
+
+		if len(dialers) == 1 || !strings.HasSuffix(strings.TrimSuffix(host, "."), ".internal") {
+			return dialers[0].Dialer.DialContext(ctx, proto, addr)
+		}
+
+		for _, d := range dialers {
+			resolved, err := agentclient.Resolve(ctx, d.Org, host, d.Network)
+			switch {
+			case errors.Is(err, agent.ErrNoSuchHost):
+				continue
+			case err != nil:
+				return nil, fmt.Errorf("failed resolving %s in organization %s: %w", host, d.Org, err)
+			}
+			return d.Dialer.DialContext(ctx, proto, net.JoinHostPort(resolved, port))
+		}
+		return nil, fmt.Errorf("%s: %w", host, agent.ErrNoSuchHost)
+	}
+}
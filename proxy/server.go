@@ -2,22 +2,39 @@ package proxy
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"net"
 	"os"
 	"sync"
 	"time"
 
+	"github.com/azazeal/pause"
 	"github.com/superfly/flyctl/terminal"
 )
 
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
 type Server struct {
 	LocalAddr string
 	Addr      string
 	Listener  net.Listener
-	Dial      func(ctx context.Context, network, addr string) (net.Conn, error)
+	Dial      DialFunc
+
+	// Reconnect, when set, re-establishes the tunnel after a connection
+	// failed to dial through it, and returns the dial function to use from
+	// then on. Events reports the reconnections.
+	Reconnect func(ctx context.Context) (DialFunc, error)
+	Events    io.Writer
+
+	mu         sync.Mutex
+	generation int
 }
 
+// reconnectAttempts is how many times a connection is dialed again, with
+// the tunnel re-established in between, before giving up on it.
+const reconnectAttempts = 3
+
 func (srv *Server) ProxyServer(ctx context.Context) error {
 	defer srv.Listener.Close() //skipcq: GO-S2307
 
@@ -43,42 +60,99 @@ func (srv *Server) ProxyServer(ctx context.Context) error {
 					continue
 				}
 				terminal.Debug("Error accepting connection: ", err)
+				continue
 			}
 			terminal.Debug("accepted new connection from: ", source.RemoteAddr())
 
 			go func() {
 				defer source.Close() //skipcq: GO-S2307
 
-				target, err := srv.Dial(ctx, "tcp", srv.Addr)
+				target, err := srv.dial(ctx)
 				if err != nil {
 					terminal.Debug("failed to connect to target: ", err)
 					return
 				}
 				defer target.Close() //skipcq: GO-S2307
 
-				wg := &sync.WaitGroup{}
+				relay(source, target)
 
-				wg.Add(2)
+				terminal.Debug("connection closed")
+			}()
+		}
+	}
+}
 
-				copyFunc := func(dst net.Conn, src net.Conn) {
-					defer wg.Done()
-					io.Copy(dst, src)
+// dial connects to the target of the proxy, re-establishing the tunnel and
+// retrying when it fails and Reconnect is set.
+func (srv *Server) dial(ctx context.Context) (net.Conn, error) {
+	for attempt := 1; ; attempt++ {
+		srv.mu.Lock()
+		dial, generation := srv.Dial, srv.generation
+		srv.mu.Unlock()
+
+		target, err := dial(ctx, "tcp", srv.Addr)
+		if err == nil || srv.Reconnect == nil || ctx.Err() != nil || attempt > reconnectAttempts {
+			return target, err
+		}
 
-					// close the write half if it exports a CloseWrite() method
-					if conn, ok := dst.(ClosableWrite); ok {
-						conn.CloseWrite()
-					}
-				}
+		if err := srv.reconnect(ctx, generation, err); err != nil {
+			srv.event("failed to re-establish the tunnel: %v", err)
+			pause.For(ctx, time.Duration(attempt)*time.Second)
+		}
+	}
+}
 
-				go copyFunc(target, source)
-				go copyFunc(source, target)
+// reconnect re-establishes the tunnel after a connection failed with err,
+// unless another connection did since it dialed with the given generation of
+// the tunnel.
+func (srv *Server) reconnect(ctx context.Context, generation int, err error) error {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
 
-				wg.Wait()
+	if srv.generation != generation {
+		return nil
+	}
 
-				terminal.Debug("connection closed")
-			}()
+	srv.event("connection to %s failed: %v; re-establishing the tunnel", srv.Addr, err)
+	dial, err := srv.Reconnect(ctx)
+	if err != nil {
+		return err
+	}
+	srv.Dial = dial
+	srv.generation++
+	srv.event("tunnel re-established, connections to %s resume", srv.Addr)
+
+	return nil
+}
+
+func (srv *Server) event(format string, a ...any) {
+	if srv.Events == nil {
+		return
+	}
+	fmt.Fprintf(srv.Events, "%s %s\n", time.Now().Format(time.TimeOnly), fmt.Sprintf(format, a...))
+}
+
+// relay copies data both ways between source and target until both sides are
+// done writing.
+func relay(source, target net.Conn) {
+	wg := &sync.WaitGroup{}
+
+	wg.Add(2)
+
+	copyFunc := func(dst net.Conn, src net.Conn) {
+		defer wg.Done()
+		io.Copy(dst, src)
+
+		// close the write half if it exports a CloseWrite() method
+		if conn, ok := dst.(ClosableWrite); ok {
+			conn.CloseWrite()
 		}
 	}
+
+	go copyFunc(target, source)
+	go copyFunc(source, target)
+
+	wg.Wait()
 }
 
 type ClosableWrite interface {
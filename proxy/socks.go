@@ -0,0 +1,194 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/superfly/flyctl/iostreams"
+	"github.com/superfly/flyctl/terminal"
+)
+
+// SOCKS5 protocol constants, see RFC 1928
+const (
+	socks5Version = 0x05
+
+	socks5MethodNoAuth       = 0x00
+	socks5MethodNoAcceptable = 0xff
+
+	socks5CmdConnect = 0x01
+
+	socks5AddrIPv4   = 0x01
+	socks5AddrDomain = 0x03
+	socks5AddrIPv6   = 0x04
+
+	socks5Succeeded           = 0x00
+	socks5HostUnreachable     = 0x04
+	socks5CmdNotSupported     = 0x07
+	socks5AddrTypeUnsupported = 0x08
+)
+
+// SOCKS5Server is a SOCKS5 proxy, without authentication, for the CONNECT
+// command. Its connections are dialed with Dial, so names are resolved on
+// the other side of the tunnel, like .internal ones.
+type SOCKS5Server struct {
+	Listener net.Listener
+	Dial     DialFunc
+}
+
+// ConnectSOCKS5 binds to addr and runs a SOCKS5 proxy to the private networks
+// of orgs over Wireguard, dialing with dial. Blocks until context is
+// cancelled.
+func ConnectSOCKS5(ctx context.Context, addr string, orgs []string, dial DialFunc) error {
+	io := iostreams.FromContext(ctx)
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(io.Out, "SOCKS5 proxy to the private network of %s listening on %s\n", strings.Join(orgs, ", "), listener.Addr())
+
+	server := &SOCKS5Server{
+		Listener: listener,
+		Dial:     dial,
+	}
+	return server.Serve(ctx)
+}
+
+// Serve accepts SOCKS5 connections until ctx is cancelled.
+func (srv *SOCKS5Server) Serve(ctx context.Context) error {
+	go func() {
+		<-ctx.Done()
+		srv.Listener.Close()
+	}()
+
+	for {
+		source, err := srv.Listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		terminal.Debug("accepted new SOCKS5 connection from: ", source.RemoteAddr())
+
+		go func() {
+			defer source.Close() //skipcq: GO-S2307
+
+			if err := srv.handle(ctx, source); err != nil {
+				terminal.Debug("SOCKS5 connection failed: ", err)
+			}
+		}()
+	}
+}
+
+func (srv *SOCKS5Server) handle(ctx context.Context, source net.Conn) error {
+	r := bufio.NewReader(source)
+
+	// Method negotiation: only no authentication is supported
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return err
+	}
+	if header[0] != socks5Version {
+		return fmt.Errorf("unsupported SOCKS version %d", header[0])
+	}
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(r, methods); err != nil {
+		return err
+	}
+	method := byte(socks5MethodNoAcceptable)
+	for _, m := range methods {
+		if m == socks5MethodNoAuth {
+			method = socks5MethodNoAuth
+		}
+	}
+	if _, err := source.Write([]byte{socks5Version, method}); err != nil {
+		return err
+	}
+	if method == socks5MethodNoAcceptable {
+		return errors.New("the client requires authentication")
+	}
+
+	// Request
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(r, request); err != nil {
+		return err
+	}
+	if request[1] != socks5CmdConnect {
+		socks5Reply(source, socks5CmdNotSupported)
+		return fmt.Errorf("unsupported SOCKS command %d", request[1])
+	}
+
+	var host string
+	switch request[3] {
+	case socks5AddrIPv4, socks5AddrIPv6:
+		ip := make(net.IP, net.IPv4len)
+		if request[3] == socks5AddrIPv6 {
+			ip = make(net.IP, net.IPv6len)
+		}
+		if _, err := io.ReadFull(r, ip); err != nil {
+			return err
+		}
+		host = ip.String()
+	case socks5AddrDomain:
+		n, err := r.ReadByte()
+		if err != nil {
+			return err
+		}
+		name := make([]byte, n)
+		if _, err := io.ReadFull(r, name); err != nil {
+			return err
+		}
+		host = string(name)
+	default:
+		socks5Reply(source, socks5AddrTypeUnsupported)
+		return fmt.Errorf("unsupported SOCKS address type %d", request[3])
+	}
+
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(r, port); err != nil {
+		return err
+	}
+	addr := net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port))))
+
+	target, err := srv.Dial(ctx, "tcp", addr)
+	if err != nil {
+		socks5Reply(source, socks5HostUnreachable)
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	defer target.Close() //skipcq: GO-S2307
+
+	if err := socks5Reply(source, socks5Succeeded); err != nil {
+		return err
+	}
+	terminal.Debug("SOCKS5 connection to: ", addr)
+
+	// The client doesn't send more than the request before the reply, but
+	// don't lose what it pipelined
+	if n := r.Buffered(); n > 0 {
+		pipelined, _ := r.Peek(n)
+		if _, err := target.Write(pipelined); err != nil {
+			return err
+		}
+	}
+
+	relay(source, target)
+
+	terminal.Debug("SOCKS5 connection closed")
+	return nil
+}
+
+// socks5Reply writes a SOCKS5 reply with status. The bound address is unspecified,
+// it's on the other side of the tunnel.
+func socks5Reply(w io.Writer, status byte) error {
+	_, err := w.Write([]byte{socks5Version, status, 0x00, socks5AddrIPv4, 0, 0, 0, 0, 0, 0})
+	return err
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net"
 
@@ -119,3 +120,36 @@ func (c *Client) Shell(ctx context.Context, sessIO *SessionIO, cmd string) error
 
 	return sessIO.attach(ctx, sess, cmd)
 }
+
+// Exec runs cmd without a PTY, keeping its standard output and error apart
+// and copying them in full before returning, for commands that output binary
+// data.
+func (c *Client) Exec(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, cmd string) error {
+	if c.Client == nil {
+		if err := c.Connect(ctx); err != nil {
+			return err
+		}
+	}
+
+	sess, err := c.Client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer sess.Close()
+
+	sess.Stdin = stdin
+	sess.Stdout = stdout
+	sess.Stderr = stderr
+
+	cmdC := make(chan error, 1)
+	go func() {
+		cmdC <- sess.Run(cmd)
+	}()
+
+	select {
+	case err := <-cmdC:
+		return err
+	case <-ctx.Done():
+		return errors.New("session forcibly closed; the remote process may still be running")
+	}
+}
@@ -0,0 +1,130 @@
+package ssh
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+// serveExec serves a single SSH connection on conn, answering exec requests
+// with stdout and stderr.
+func serveExec(t *testing.T, conn net.Conn, stdout, stderr []byte) {
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(hostKey)
+	require.NoError(t, err)
+
+	config := &ssh.ServerConfig{NoClientAuth: true}
+	config.AddHostKey(signer)
+
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			for req := range requests {
+				if req.Type != "exec" {
+					req.Reply(false, nil)
+					continue
+				}
+				req.Reply(true, nil)
+
+				channel.Write(stdout)
+				channel.Stderr().Write(stderr)
+
+				status := make([]byte, 4)
+				binary.BigEndian.PutUint32(status, 0)
+				channel.SendRequest("exit-status", false, status)
+				channel.Close()
+			}
+		}()
+	}
+}
+
+func testArchive(t *testing.T, files map[string][]byte) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content))}))
+		_, err := tw.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestExecRoundTripsArchives(t *testing.T) {
+	files := map[string][]byte{
+		"lines.txt":  []byte("one\ntwo\r\nthree\n"),
+		"binary.bin": {0x00, '\n', 0x1f, 0x8b, '\r', '\n', 0xff},
+	}
+	archive := testArchive(t, files)
+	// Bytes a PTY would mangle
+	archive = append(archive, '\n', '\r', '\n')
+
+	// Both ends of an SSH handshake write at once, which net.Pipe can't take
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		if serverConn, err := listener.Accept(); err == nil {
+			serveExec(t, serverConn, archive, []byte("tar: removing leading '/'\n"))
+		}
+	}()
+
+	clientConn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+
+	conn, chans, reqs, err := ssh.NewClientConn(clientConn, listener.Addr().String(), &ssh.ClientConfig{
+		User:            "root",
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	})
+	require.NoError(t, err)
+	client := &Client{Client: ssh.NewClient(conn, chans, reqs)}
+	defer client.Client.Close()
+
+	var stdout, stderr bytes.Buffer
+	err = client.Exec(context.Background(), nil, &stdout, &stderr, "tar -czf - -C /volume .")
+	require.NoError(t, err)
+
+	assert.Equal(t, archive, stdout.Bytes())
+	assert.Equal(t, "tar: removing leading '/'\n", stderr.String())
+
+	zr, err := gzip.NewReader(bytes.NewReader(stdout.Bytes()))
+	require.NoError(t, err)
+	zr.Multistream(false)
+	tr := tar.NewReader(zr)
+	got := map[string][]byte{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		got[hdr.Name] = content
+	}
+	assert.Equal(t, files, got)
+}
@@ -4,10 +4,14 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"net"
 	"net/netip"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/miekg/dns"
 	"golang.zx2c4.com/wireguard/conn"
@@ -210,3 +214,52 @@ func (t *Tunnel) queryDNS(ctx context.Context, msg *dns.Msg) (*dns.Msg, error) {
 	r, _, err := client.ExchangeWithConn(msg, conn)
 	return r, err
 }
+
+// TunnelStats reports the state of the WireGuard peer of a tunnel.
+type TunnelStats struct {
+	Endpoint      string
+	Websockets    bool
+	LastHandshake time.Time
+	RxBytes       int64
+	TxBytes       int64
+}
+
+// Stats returns the state of the WireGuard peer of the tunnel, from the
+// device's configuration protocol.
+func (t *Tunnel) Stats() (stats TunnelStats, err error) {
+	if t.dev == nil {
+		return stats, errors.New("tunnel is closed")
+	}
+
+	conf, err := t.dev.IpcGet()
+	if err != nil {
+		return stats, err
+	}
+
+	stats.Websockets = t.wscancel != nil
+
+	var sec, nsec int64
+	for _, line := range strings.Split(conf, "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "endpoint":
+			stats.Endpoint = value
+		case "last_handshake_time_sec":
+			sec, _ = strconv.ParseInt(value, 10, 64)
+		case "last_handshake_time_nsec":
+			nsec, _ = strconv.ParseInt(value, 10, 64)
+		case "rx_bytes":
+			stats.RxBytes, _ = strconv.ParseInt(value, 10, 64)
+		case "tx_bytes":
+			stats.TxBytes, _ = strconv.ParseInt(value, 10, 64)
+		}
+	}
+	if sec != 0 || nsec != 0 {
+		stats.LastHandshake = time.Unix(sec, nsec)
+	}
+
+	return stats, nil
+}